@@ -119,6 +119,7 @@ func (src *Machine) ConvertTo(dstRaw conversion.Hub) error {
 	restoreMachineSpec(&restored.Spec, &dst.Spec)
 	dst.Status.ObservedGeneration = restored.Status.ObservedGeneration
 	dst.Status.Conditions = restored.Status.Conditions
+	dst.Status.NodeDrainStartTime = restored.Status.NodeDrainStartTime
 
 	return nil
 }
@@ -129,6 +130,7 @@ func restoreMachineSpec(restored *v1alpha3.MachineSpec, dst *v1alpha3.MachineSpe
 	}
 	dst.Bootstrap.DataSecretName = restored.Bootstrap.DataSecretName
 	dst.FailureDomain = restored.FailureDomain
+	dst.NodeDrain = restored.NodeDrain
 }
 
 func (dst *Machine) ConvertFrom(srcRaw conversion.Hub) error {
@@ -254,7 +256,10 @@ func (src *MachineDeployment) ConvertTo(dstRaw conversion.Hub) error {
 		dst.Spec.ClusterName = restored.Spec.ClusterName
 	}
 	dst.Spec.Paused = restored.Spec.Paused
+	dst.Spec.AutoRollback = restored.Spec.AutoRollback
 	dst.Status.Phase = restored.Status.Phase
+	dst.Status.LastProgressTime = restored.Status.LastProgressTime
+	dst.Status.Conditions = restored.Status.Conditions
 	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
 
 	return nil
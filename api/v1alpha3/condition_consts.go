@@ -69,6 +69,39 @@ const (
 	WaitingForDataSecretFallbackReason = "WaitingForDataSecret"
 )
 
+const (
+	// PreDrainDeleteHookSucceededCondition reports a machine waiting for pre-drain deletion hooks to be removed
+	// before draining its node; it is set only while the machine is deleted.
+	PreDrainDeleteHookSucceededCondition ConditionType = "PreDrainDeleteHookSucceeded"
+
+	// PreTerminateDeleteHookSucceededCondition reports a machine waiting for pre-terminate deletion hooks to be removed
+	// before deleting its bootstrap and infrastructure objects; it is set only while the machine is deleted.
+	PreTerminateDeleteHookSucceededCondition ConditionType = "PreTerminateDeleteHookSucceeded"
+
+	// WaitingExternalHookReason (Severity=Info) documents a machine deletion waiting for the external controllers
+	// owning the deletion hooks to complete their work and to remove the hook annotations.
+	WaitingExternalHookReason = "WaitingExternalHook"
+)
+
+const (
+	// DrainingSucceededCondition reports the result of the drain of the node hosted by a machine being deleted.
+	// This condition is set only while a machine is deleted and it is not set if the drain is skipped, e.g. because
+	// the machine has the exclude-node-draining annotation or it has no node.
+	DrainingSucceededCondition ConditionType = "DrainingSucceeded"
+
+	// DrainingReason (Severity=Info) documents a machine node being drained.
+	DrainingReason = "Draining"
+
+	// DrainingFailedReason (Severity=Warning) documents a machine node drain operation failed; the controller
+	// keeps retrying until the drain succeeds or the drain timeout expires.
+	DrainingFailedReason = "DrainingFailed"
+
+	// DrainingTimedOutReason (Severity=Warning) documents a machine node drain that did not complete within the
+	// timeout defined in the machine's node drain spec; pods still running on the node are ignored and
+	// the machine deletion goes ahead.
+	DrainingTimedOutReason = "DrainingTimedOut"
+)
+
 const (
 	// MachineHealthCheckSuccededCondition is set on machines that have passed a healthcheck by the MachineHealthCheck controller.
 	// In the event that the health check fails it will be set to False.
@@ -95,3 +128,15 @@ const (
 	// WaitingForRemediation is the reason used when a machine fails a health check and remediation is needed.
 	WaitingForRemediation = "WaitingForRemediation"
 )
+
+// Conditions and condition Reasons for the MachineDeployment object
+
+const (
+	// MachineDeploymentProgressingCondition reports whether the rollout of a MachineDeployment is making progress
+	// within the deadline defined by its progressDeadlineSeconds; it is True also once the rollout is complete.
+	MachineDeploymentProgressingCondition ConditionType = "Progressing"
+
+	// ProgressDeadlineExceededReason (Severity=Error) documents a MachineDeployment whose rollout did not make any
+	// progress within the progress deadline, e.g. because the machines of the new revision never became ready.
+	ProgressDeadlineExceededReason = "ProgressDeadlineExceeded"
+)
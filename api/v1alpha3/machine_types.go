@@ -32,6 +32,19 @@ const (
 	// ExcludeNodeDrainingAnnotation annotation explicitly skips node draining if set
 	ExcludeNodeDrainingAnnotation = "machine.cluster.x-k8s.io/exclude-node-draining"
 
+	// PreDrainDeleteHookAnnotationPrefix is the prefix of the annotations registering pre-drain deletion hooks;
+	// while any of these annotations is set, the deletion of the machine is paused before draining its node.
+	// Hooks are registered as `pre-drain.delete.hook.machine.cluster.x-k8s.io/<hook-name>: <owner>`, and
+	// the external controller owning the hook removes the annotation once its work is completed.
+	PreDrainDeleteHookAnnotationPrefix = "pre-drain.delete.hook.machine.cluster.x-k8s.io"
+
+	// PreTerminateDeleteHookAnnotationPrefix is the prefix of the annotations registering pre-terminate deletion hooks;
+	// while any of these annotations is set, the deletion of the machine is paused before deleting its bootstrap and
+	// infrastructure objects.
+	// Hooks are registered as `pre-terminate.delete.hook.machine.cluster.x-k8s.io/<hook-name>: <owner>`, and
+	// the external controller owning the hook removes the annotation once its work is completed.
+	PreTerminateDeleteHookAnnotationPrefix = "pre-terminate.delete.hook.machine.cluster.x-k8s.io"
+
 	// MachineSetLabelName is the label set on machines if they're controlled by MachineSet
 	MachineSetLabelName = "cluster.x-k8s.io/set-name"
 
@@ -77,10 +90,45 @@ type MachineSpec struct {
 	// Must match a key in the FailureDomains map stored on the cluster object.
 	// +optional
 	FailureDomain *string `json:"failureDomain,omitempty"`
+
+	// NodeDrain defines how the node hosted by the machine is drained before the machine is deleted.
+	// If not set, all the pods but DaemonSet ones are evicted, deleting emptyDir data and honoring
+	// their own termination grace period, and deletion waits until the drain succeeds.
+	// +optional
+	NodeDrain *NodeDrainSpec `json:"nodeDrain,omitempty"`
 }
 
 // ANCHOR_END: MachineSpec
 
+// ANCHOR: NodeDrainSpec
+
+// NodeDrainSpec defines how a node is drained before deleting the machine hosting it.
+type NodeDrainSpec struct {
+	// Timeout is the total amount of time the controller spends draining the node;
+	// once expired, the pods still running are ignored and the machine deletion goes ahead.
+	// If not set, the controller keeps retrying until the drain succeeds.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// SkipPodSelectors is a list of label selectors for pods that must not be evicted;
+	// pods matching any of the selectors are left running on the node.
+	// +optional
+	SkipPodSelectors []metav1.LabelSelector `json:"skipPodSelectors,omitempty"`
+
+	// DeleteEmptyDirData defines whether pods using emptyDir volumes are evicted, thus deleting their local data.
+	// If false, such pods are left running on the node.
+	// Defaults to true.
+	// +optional
+	DeleteEmptyDirData *bool `json:"deleteEmptyDirData,omitempty"`
+
+	// GracePeriodSeconds overrides the termination grace period of the evicted pods.
+	// If not set, the grace period defined in each pod is used.
+	// +optional
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+}
+
+// ANCHOR_END: NodeDrainSpec
+
 // ANCHOR: MachineStatus
 
 // MachineStatus defines the observed state of Machine
@@ -163,6 +211,11 @@ type MachineStatus struct {
 	// Conditions defines current service state of the Machine.
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
+
+	// NodeDrainStartTime is the time when the controller started draining the node hosted by the machine;
+	// it is used to enforce the drain timeout.
+	// +optional
+	NodeDrainStartTime *metav1.Time `json:"nodeDrainStartTime,omitempty"`
 }
 
 // ANCHOR_END: MachineStatus
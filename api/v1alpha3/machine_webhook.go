@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -129,8 +130,40 @@ func (m *Machine) validate(old *Machine) error {
 		}
 	}
 
+	allErrs = append(allErrs, validateNodeDrain(m.Spec.NodeDrain, field.NewPath("spec", "nodeDrain"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Machine").GroupKind(), m.Name, allErrs)
 }
+
+// validateNodeDrain validates the node drain spec of a machine or of a machine template.
+func validateNodeDrain(spec *NodeDrainSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec == nil {
+		return allErrs
+	}
+
+	if spec.Timeout != nil && spec.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), spec.Timeout.Duration.String(), "must be greater than zero"))
+	}
+
+	for i := range spec.SkipPodSelectors {
+		selector := spec.SkipPodSelectors[i]
+		if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+			// An empty selector matches all the pods, thus skipping the drain entirely;
+			// the exclude-node-draining annotation should be used instead.
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("skipPodSelectors").Index(i), selector, "must not be empty"))
+			continue
+		}
+		if _, err := metav1.LabelSelectorAsSelector(&selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("skipPodSelectors").Index(i), selector, err.Error()))
+		}
+	}
+
+	if spec.GracePeriodSeconds != nil && *spec.GracePeriodSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("gracePeriodSeconds"), *spec.GracePeriodSeconds, "must be greater than or equal to zero"))
+	}
+	return allErrs
+}
@@ -18,6 +18,7 @@ package v1alpha3
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -246,3 +247,72 @@ func TestMachineVersionValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineNodeDrainValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		nodeDrain *NodeDrainSpec
+		expectErr bool
+	}{
+		{
+			name:      "should succeed when node drain is not set",
+			nodeDrain: nil,
+			expectErr: false,
+		},
+		{
+			name: "should succeed when given a valid node drain",
+			nodeDrain: &NodeDrainSpec{
+				Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+				SkipPodSelectors: []metav1.LabelSelector{
+					{MatchLabels: map[string]string{"app": "foo"}},
+				},
+				DeleteEmptyDirData: pointer.BoolPtr(false),
+				GracePeriodSeconds: pointer.Int64Ptr(0),
+			},
+			expectErr: false,
+		},
+		{
+			name:      "should return error when given a zero timeout",
+			nodeDrain: &NodeDrainSpec{Timeout: &metav1.Duration{}},
+			expectErr: true,
+		},
+		{
+			name:      "should return error when given an empty skip pod selector",
+			nodeDrain: &NodeDrainSpec{SkipPodSelectors: []metav1.LabelSelector{{}}},
+			expectErr: true,
+		},
+		{
+			name: "should return error when given an invalid skip pod selector",
+			nodeDrain: &NodeDrainSpec{SkipPodSelectors: []metav1.LabelSelector{
+				{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Foo"}}},
+			}},
+			expectErr: true,
+		},
+		{
+			name:      "should return error when given a negative grace period",
+			nodeDrain: &NodeDrainSpec{GracePeriodSeconds: pointer.Int64Ptr(-1)},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				Spec: MachineSpec{
+					NodeDrain: tt.nodeDrain,
+					Bootstrap: Bootstrap{ConfigRef: nil, DataSecretName: pointer.StringPtr("test")},
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
+			}
+		})
+	}
+}
@@ -28,6 +28,10 @@ const (
 	// i.e. gradually scale down the old MachineSet and scale up the new one.
 	RollingUpdateMachineDeploymentStrategyType MachineDeploymentStrategyType = "RollingUpdate"
 
+	// Replace the old MachineSet by new one only when the machines of the old MachineSet are deleted,
+	// i.e. the new MachineSet is scaled up each time a machine of the old MachineSet is deleted by the user.
+	OnDeleteMachineDeploymentStrategyType MachineDeploymentStrategyType = "OnDelete"
+
 	// RevisionAnnotation is the revision annotation of a machine deployment's machine sets which records its rollout sequence
 	RevisionAnnotation = "machinedeployment.clusters.x-k8s.io/revision"
 	// RevisionHistoryAnnotation maintains the history of all old revisions that a machine set has served for a machine deployment.
@@ -40,6 +44,13 @@ const (
 	// is machinedeployment.spec.replicas + maxSurge. Used by the underlying machine sets to estimate their
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "machinedeployment.clusters.x-k8s.io/max-replicas"
+	// DisableMachineCreateAnnotation is set by the machine deployment controller on the old machine sets of a
+	// deployment using the OnDelete strategy; it prevents the machine set from replacing the machines deleted by
+	// the user, so the deleted machines are replaced by the machine set matching the current machine template.
+	DisableMachineCreateAnnotation = "machineset.clusters.x-k8s.io/disable-machine-create"
+	// RolloutFailedAnnotation is set by the machine deployment controller on a machine set whose rollout exceeded the
+	// progress deadline; the machine sets with this annotation are never the target of an automatic rollback.
+	RolloutFailedAnnotation = "machinedeployment.clusters.x-k8s.io/rollout-failed"
 )
 
 // ANCHOR: MachineDeploymentSpec
@@ -90,6 +101,13 @@ type MachineDeploymentSpec struct {
 	// reason will be surfaced in the deployment status. Note that progress will
 	// not be estimated during the time a deployment is paused. Defaults to 600s.
 	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// AutoRollback enables the automatic rollback of a rollout exceeding the progress deadline.
+	// When enabled, the machine template of the previous revision is restored, so the MachineSet
+	// of the previous revision is scaled back up and the MachineSet of the failed one is scaled down.
+	// Defaults to false.
+	// +optional
+	AutoRollback bool `json:"autoRollback,omitempty"`
 }
 
 // ANCHOR_END: MachineDeploymentSpec
@@ -99,9 +117,11 @@ type MachineDeploymentSpec struct {
 // MachineDeploymentStrategy describes how to replace existing machines
 // with new ones.
 type MachineDeploymentStrategy struct {
-	// Type of deployment. Currently the only supported strategy is
-	// "RollingUpdate".
+	// Type of deployment. Allowed values are "RollingUpdate" and "OnDelete".
+	// With OnDelete, changes to the machine template create a new MachineSet, but the machines
+	// of the old MachineSets are replaced only when deleted by the user.
 	// Default is RollingUpdate.
+	// +kubebuilder:validation:Enum=RollingUpdate;OnDelete
 	// +optional
 	Type MachineDeploymentStrategyType `json:"type,omitempty"`
 
@@ -195,6 +215,15 @@ type MachineDeploymentStatus struct {
 	// Phase represents the current phase of a MachineDeployment (ScalingUp, ScalingDown, Running, Failed, or Unknown).
 	// +optional
 	Phase string `json:"phase,omitempty"`
+
+	// LastProgressTime is the last time the rollout of the deployment started or made progress.
+	// It is not set when the deployment is paused or the rollout is complete.
+	// +optional
+	LastProgressTime *metav1.Time `json:"lastProgressTime,omitempty"`
+
+	// Conditions defines current service state of the MachineDeployment.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // ANCHOR_END: MachineDeploymentStatus
@@ -248,6 +277,7 @@ func (md *MachineDeploymentStatus) GetTypedPhase() MachineDeploymentPhase {
 // +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas",description="Total number of non-terminated machines targeted by this deployment"
 // +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.availableReplicas",description="Total number of available machines (ready for at least minReadySeconds)"
 // +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas",description="Total number of ready machines targeted by this deployment."
+// +kubebuilder:printcolumn:name="Updated",type="integer",JSONPath=".status.updatedReplicas",description="Total number of non-terminated machines targeted by this deployment that have the desired template spec"
 
 // MachineDeployment is the Schema for the machinedeployments API
 type MachineDeployment struct {
@@ -258,6 +288,14 @@ type MachineDeployment struct {
 	Status MachineDeploymentStatus `json:"status,omitempty"`
 }
 
+func (m *MachineDeployment) GetConditions() Conditions {
+	return m.Status.Conditions
+}
+
+func (m *MachineDeployment) SetConditions(conditions Conditions) {
+	m.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineDeploymentList contains a list of MachineDeployment
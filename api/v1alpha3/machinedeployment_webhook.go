@@ -92,6 +92,8 @@ func (m *MachineDeployment) validate(old *MachineDeployment) error {
 		)
 	}
 
+	allErrs = append(allErrs, validateNodeDrain(m.Spec.Template.Spec.NodeDrain, field.NewPath("spec", "template", "spec", "nodeDrain"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
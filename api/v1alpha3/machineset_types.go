@@ -44,8 +44,8 @@ type MachineSetSpec struct {
 	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
 
 	// DeletePolicy defines the policy used to identify nodes to delete when downscaling.
-	// Defaults to "Random".  Valid values are "Random, "Newest", "Oldest"
-	// +kubebuilder:validation:Enum=Random;Newest;Oldest
+	// Defaults to "Random".  Valid values are "Random, "Newest", "Oldest", "LeastDisruptive"
+	// +kubebuilder:validation:Enum=Random;Newest;Oldest;LeastDisruptive
 	DeletePolicy string `json:"deletePolicy,omitempty"`
 
 	// Selector is a label query over machines that should match the replica count.
@@ -102,6 +102,14 @@ const (
 	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
 	// It then prioritizes the oldest Machines for deletion based on the Machine's CreationTimestamp.
 	OldestMachineSetDeletePolicy MachineSetDeletePolicy = "Oldest"
+
+	// LeastDisruptiveMachineSetDeletePolicy prioritizes both Machines that have the annotation
+	// "cluster.x-k8s.io/delete-machine=yes" and Machines that are unhealthy
+	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
+	// It then prioritizes the Machines whose Node is the least useful for the workload cluster: unhealthy or
+	// NotReady Nodes first, then cordoned Nodes, then Nodes running the fewest Pods not owned by a DaemonSet.
+	// Ties are broken by deleting Machines from the failure domains with the most Machines.
+	LeastDisruptiveMachineSetDeletePolicy MachineSetDeletePolicy = "LeastDisruptive"
 )
 
 // ANCHOR: MachineSetStatus
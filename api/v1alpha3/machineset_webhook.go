@@ -116,6 +116,8 @@ func (m *MachineSet) validate(old *MachineSet) error {
 		)
 	}
 
+	allErrs = append(allErrs, validateNodeDrain(m.Spec.Template.Spec.NodeDrain, field.NewPath("spec", "template", "spec", "nodeDrain"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -0,0 +1,32 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains the types used by clusterctl for printing the result of a command
+// in a structured format (json or yaml).
+//
+// Each output document has an apiVersion and a kind, so tools consuming the clusterctl output can
+// detect the schema in use; the output types follow the Kubernetes API versioning conventions,
+// so fields are never removed or renamed within the same version.
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupVersion is the group version of the clusterctl output types.
+	GroupVersion = schema.GroupVersion{Group: "output.clusterctl.cluster.x-k8s.io", Version: "v1alpha1"}
+)
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Provider describes a provider instance, either installed or deleted from a management cluster.
+type Provider struct {
+	// Name of the provider, e.g. cluster-api or aws.
+	Name string `json:"name"`
+
+	// Namespace where the provider is installed.
+	Namespace string `json:"namespace"`
+
+	// Type of the provider, e.g. CoreProvider or InfrastructureProvider.
+	Type string `json:"type"`
+
+	// Version of the provider.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// WatchedNamespace is the namespace the provider is watching; if empty the provider is watching all namespaces.
+	// +optional
+	WatchedNamespace string `json:"watchedNamespace,omitempty"`
+}
+
+// ProviderList is the output of clusterctl init and clusterctl delete, listing
+// respectively the providers installed in or deleted from a management cluster.
+type ProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Items []Provider `json:"items"`
+}
+
+// ImageList is the output of clusterctl init --list-images, listing the container images
+// required for initializing a management cluster.
+type ImageList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Images []string `json:"images"`
+}
+
+// Variable describes a variable required by a cluster template.
+type Variable struct {
+	// Name of the variable.
+	Name string `json:"name"`
+
+	// Description of the variable, if provided by the template variables schema.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Type of the variable, if provided by the template variables schema, e.g. string, int, cidr or kubernetesVersion.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Default value of the variable, if provided by the template variables schema.
+	// +optional
+	Default *string `json:"default,omitempty"`
+
+	// Enum lists the values accepted by the variable, if provided by the template variables schema.
+	// +optional
+	Enum []string `json:"enum,omitempty"`
+
+	// Required is true if the value of the variable must be set explicitly.
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// VariableList is the output of clusterctl config cluster --list-variables, listing
+// the variables required by a cluster template.
+type VariableList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Variables []Variable `json:"variables"`
+}
+
+// UpgradeItem describes the upgrade option for a provider instance.
+type UpgradeItem struct {
+	Provider `json:",inline"`
+
+	// NextVersion is the version the provider can be upgraded to; if empty the provider is already up to date.
+	// +optional
+	NextVersion string `json:"nextVersion,omitempty"`
+
+	// VersionConstraint is the range of versions the provider is pinned to in the clusterctl configuration, if any.
+	// +optional
+	VersionConstraint string `json:"versionConstraint,omitempty"`
+
+	// Changes lists the components added, removed or changed by the upgrade; it is set only if the diff is requested.
+	// +optional
+	Changes []ComponentChange `json:"changes,omitempty"`
+}
+
+// ComponentChange describes a change to a provider component resulting from an upgrade.
+type ComponentChange struct {
+	// Object is the component added, removed or changed.
+	Object ObjectReference `json:"object"`
+
+	// Type of the change, one of Added, Removed or Changed.
+	Type string `json:"type"`
+
+	// Diff is a human readable report of the changes to the component; it is empty for added or removed components.
+	// +optional
+	Diff string `json:"diff,omitempty"`
+}
+
+// UpgradePlan describes the upgrade options for a management group towards an API Version of Cluster API (contract).
+type UpgradePlan struct {
+	// ManagementGroup is the instance name of the core provider of the management group, e.g. capi-system/cluster-api.
+	ManagementGroup string `json:"managementGroup"`
+
+	// Contract is the API Version of Cluster API (contract) targeted by the plan, e.g. v1alpha3.
+	Contract string `json:"contract"`
+
+	// Providers lists the upgrade options for each provider in the management group.
+	Providers []UpgradeItem `json:"providers"`
+}
+
+// CertManagerUpgradePlan describes the upgrade option for cert-manager.
+type CertManagerUpgradePlan struct {
+	// Installed is true if cert-manager is installed in the management cluster.
+	Installed bool `json:"installed"`
+
+	// ExternallyManaged is true if cert-manager is not managed by clusterctl.
+	// +optional
+	ExternallyManaged bool `json:"externallyManaged,omitempty"`
+
+	// Version is the installed version of cert-manager; if empty the version is unknown.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// NextVersion is the version cert-manager can be upgraded to; if empty cert-manager is already up to date.
+	// +optional
+	NextVersion string `json:"nextVersion,omitempty"`
+}
+
+// UpgradePlanList is the output of clusterctl upgrade plan.
+type UpgradePlanList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// CertManager describes the upgrade option for cert-manager.
+	// +optional
+	CertManager *CertManagerUpgradePlan `json:"certManager,omitempty"`
+
+	Items []UpgradePlan `json:"items"`
+}
+
+// ObjectReference identifies an object moved between management clusters.
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// MoveResult is the output of clusterctl move.
+type MoveResult struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// DryRun is true if the objects were not actually moved.
+	DryRun bool `json:"dryRun"`
+
+	// Groups lists the objects moved to the target management cluster, in the order they are created;
+	// objects in the same group are created in parallel, and groups are deleted from the source
+	// management cluster in reverse order.
+	Groups [][]ObjectReference `json:"groups"`
+}
+
+// CheckResult describes the result of a doctor check.
+type CheckResult struct {
+	// Check is the name of the check, e.g. Controllers.
+	Check string `json:"check"`
+
+	// Status is the outcome of the check, one of pass, warn or fail.
+	Status string `json:"status"`
+
+	// Message is a human readable description of the result.
+	Message string `json:"message"`
+
+	// Remediation is a hint about how to fix the problem, if the check did not pass.
+	// +optional
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// CheckResultList is the output of clusterctl doctor.
+type CheckResultList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Items []CheckResult `json:"items"`
+}
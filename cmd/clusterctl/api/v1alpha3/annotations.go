@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+const (
+	// MoveProgressAnnotation is applied by clusterctl move to the Cluster objects in the source management cluster
+	// for recording the progress of the move operation, so an interrupted move can be resumed or rolled back.
+	//
+	// Example: "Creating/2" means that the first two groups of objects were created in the target management cluster,
+	// while "Deleting" means that all the objects were created in the target management cluster and the deletion
+	// from the source management cluster is started.
+	MoveProgressAnnotation = "clusterctl.cluster.x-k8s.io/move-progress"
+
+	// MovedFromAnnotation is applied by clusterctl move to the objects it creates in the target management cluster
+	// for recording the UID of the corresponding object in the source management cluster, so rolling back an interrupted
+	// move deletes only the objects created by the move and not objects with the same name that already existed.
+	MovedFromAnnotation = "clusterctl.cluster.x-k8s.io/moved-from"
+
+	// RestartedAtAnnotation is applied by clusterctl rollout restart to the machine template of a MachineDeployment
+	// for triggering the rollout of new Machines without changing the MachineDeployment spec.
+	RestartedAtAnnotation = "clusterctl.cluster.x-k8s.io/restartedAt"
+
+	// CertManagerVersionAnnotation is applied by clusterctl to all the cert-manager objects it installs
+	// for tracking the installed cert-manager version, so it is possible to detect when an upgrade is required.
+	CertManagerVersionAnnotation = "clusterctl.cluster.x-k8s.io/cert-manager-version"
+)
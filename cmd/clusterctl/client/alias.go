@@ -41,9 +41,33 @@ type Template repository.Template
 // UpgradePlan defines a list of possible upgrade targets for a management group.
 type UpgradePlan cluster.UpgradePlan
 
+// ComponentsDiff defines the changes to the components of a provider resulting from an upgrade.
+type ComponentsDiff cluster.ComponentsDiff
+
+// CertManagerUpgradePlan defines the upgrade plan for cert-manager.
+type CertManagerUpgradePlan cluster.CertManagerUpgradePlan
+
 // Kubeconfig is a type that specifies inputs related to the actual kubeconfig.
 type Kubeconfig cluster.Kubeconfig
 
 // Processor defines the methods necessary for creating a specific yaml
 // processor.
 type Processor yaml.Processor
+
+// ObjectTree defines the tree of objects composing a workload cluster.
+type ObjectTree cluster.ObjectTree
+
+// RolloutStatus defines the status of the rollout of a MachineDeployment.
+type RolloutStatus cluster.RolloutStatus
+
+// RolloutRevision defines a revision of a MachineDeployment.
+type RolloutRevision cluster.RolloutRevision
+
+// KubernetesUpgradePlan defines the steps required for upgrading the Kubernetes version of a workload cluster.
+type KubernetesUpgradePlan cluster.KubernetesUpgradePlan
+
+// CheckResult defines the result of a doctor check.
+type CheckResult cluster.CheckResult
+
+// RemainingObject describes an object of a workload cluster still existing when the deletion times out.
+type RemainingObject cluster.RemainingObject
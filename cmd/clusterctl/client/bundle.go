@@ -0,0 +1,491 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/homedir"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	bundleManifestFile    = "bundle.yaml"
+	bundleConfigFile      = "clusterctl.yaml"
+	bundleProvidersFolder = "providers"
+	bundleCertManagerFile = "cert-manager/cert-manager.yaml"
+	bundlesFolder         = "bundles"
+
+	metadataFile           = "metadata.yaml"
+	checksumsFile          = "checksums.txt"
+	checksumsSignatureFile = "checksums.txt.sig"
+)
+
+// bundleManifest describes the content of a bundle.
+type bundleManifest struct {
+	// Providers included in the bundle.
+	Providers []bundleProvider `json:"providers"`
+
+	// Images required for installing the providers and the cert-manager included in the bundle, with the
+	// image overrides applied.
+	Images []string `json:"images"`
+
+	// ImageOverrides defines the image overrides to be applied when installing the providers included in the
+	// bundle, by component.
+	ImageOverrides map[string]config.ImageMeta `json:"imageOverrides,omitempty"`
+}
+
+// bundleProvider describes a provider included in a bundle; provider's files are stored as published in the provider
+// repository in the providers/{provider-label}/{version} folder, thus making it possible to use the bundle as local
+// repository and to verify the files against the checksums published by the provider.
+type bundleProvider struct {
+	Name           string                    `json:"name"`
+	Type           clusterctlv1.ProviderType `json:"type"`
+	Version        string                    `json:"version"`
+	ComponentsPath string                    `json:"componentsPath"`
+	Files          []string                  `json:"files"`
+}
+
+// BundleExportOptions carries the options supported by BundleExport.
+type BundleExportOptions struct {
+	// CoreProvider version (e.g. cluster-api:v0.3.0) to add to the bundle. If unspecified, the
+	// cluster-api core provider's latest release is used.
+	CoreProvider string
+
+	// BootstrapProviders and versions (e.g. kubeadm:v0.3.0) to add to the bundle.
+	// If unspecified, the kubeadm bootstrap provider's latest release is used.
+	BootstrapProviders []string
+
+	// ControlPlaneProviders and versions (e.g. kubeadm:v0.3.0) to add to the bundle.
+	// If unspecified, the kubeadm control plane provider latest release is used.
+	ControlPlaneProviders []string
+
+	// InfrastructureProviders and versions (e.g. aws:v0.5.0) to add to the bundle.
+	InfrastructureProviders []string
+
+	// Flavors defines the additional cluster template flavors to add to the bundle for each infrastructure provider;
+	// the default cluster template is always added, if it exists.
+	Flavors []string
+
+	// File defines the path of the bundle tarball to be created.
+	File string
+}
+
+// BundleImportOptions carries the options supported by BundleImport.
+type BundleImportOptions struct {
+	// File defines the path of the bundle tarball to import.
+	File string
+
+	// Directory defines the directory where the bundle should be extracted. If unspecified,
+	// the bundle is extracted in $HOME/.cluster-api/bundles/{bundle name}.
+	Directory string
+
+	// ConfigFile defines the path of the clusterctl configuration file to be written with the providers and the image
+	// overrides included in the bundle. If unspecified, {directory}/clusterctl.yaml is used.
+	// Nb. the file is overwritten, so it should not be the clusterctl configuration file in use.
+	ConfigFile string
+}
+
+// BundleExport writes a self-contained tarball with all the artifacts required for installing the selected providers,
+// the cert-manager manifest and the list of the required images. Provider files are not modified, so they can be
+// verified against the checksums published by the providers; instead, the image overrides are stored in the
+// bundle manifest, and they are applied to the list of the required images.
+func (c *clusterctlClient) BundleExport(options BundleExportOptions) error {
+	log := logf.Log
+
+	if options.File == "" {
+		return errors.New("the bundle file must be specified")
+	}
+
+	if options.CoreProvider == "" {
+		options.CoreProvider = config.ClusterAPIProviderName
+	}
+	if len(options.BootstrapProviders) == 0 {
+		options.BootstrapProviders = append(options.BootstrapProviders, config.KubeadmBootstrapProviderName)
+	}
+	if len(options.ControlPlaneProviders) == 0 {
+		options.ControlPlaneProviders = append(options.ControlPlaneProviders, config.KubeadmControlPlaneProviderName)
+	}
+
+	files := map[string][]byte{}
+	manifest := bundleManifest{}
+	images := sets.NewString()
+
+	providers := []struct {
+		providerType clusterctlv1.ProviderType
+		providers    []string
+	}{
+		{providerType: clusterctlv1.CoreProviderType, providers: []string{options.CoreProvider}},
+		{providerType: clusterctlv1.BootstrapProviderType, providers: options.BootstrapProviders},
+		{providerType: clusterctlv1.ControlPlaneProviderType, providers: options.ControlPlaneProviders},
+		{providerType: clusterctlv1.InfrastructureProviderType, providers: options.InfrastructureProviders},
+	}
+	for _, p := range providers {
+		for _, provider := range p.providers {
+			if provider == NoopProvider {
+				if p.providerType == clusterctlv1.CoreProviderType {
+					return errors.New("the '-' value can not be used for the core provider")
+				}
+				continue
+			}
+
+			log.Info("Exporting", "Provider", provider, "Type", p.providerType)
+			bp, providerFiles, providerImages, err := c.exportProvider(provider, p.providerType, options.Flavors)
+			if err != nil {
+				return errors.Wrapf(err, "failed to export the %q provider", provider)
+			}
+
+			manifest.Providers = append(manifest.Providers, *bp)
+			for name, content := range providerFiles {
+				files[name] = content
+			}
+			images.Insert(providerImages...)
+		}
+	}
+
+	// Gets the cert-manager manifest; the cluster client is used only for accessing the embedded manifest,
+	// so it is not required to have a management cluster for exporting a bundle.
+	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{})
+	if err != nil {
+		return err
+	}
+	certManagerManifest, err := cluster.CertManager().Manifest()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the cert-manager manifest")
+	}
+	certManagerImages, err := inspectImages(certManagerManifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect the images required by the cert-manager")
+	}
+	files[bundleCertManagerFile] = certManagerManifest
+	images.Insert(certManagerImages...)
+
+	manifest.Images = images.List()
+	manifest.ImageOverrides, err = c.configClient.ImageMeta().Overrides()
+	if err != nil {
+		return err
+	}
+	rawManifest, err := yaml.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the bundle manifest")
+	}
+	files[bundleManifestFile] = rawManifest
+
+	if err := writeTarball(options.File, files); err != nil {
+		return err
+	}
+
+	log.Info("Bundle exported", "File", options.File)
+	return nil
+}
+
+// exportProvider returns the files and the images required for installing a provider.
+func (c *clusterctlClient) exportProvider(provider string, providerType clusterctlv1.ProviderType, flavors []string) (*bundleProvider, map[string][]byte, []string, error) {
+	log := logf.Log
+
+	name, version, err := parseProviderName(provider)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	providerConfig, err := c.configClient.Providers().Get(name, providerType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	repositoryClient, err := c.repositoryClientFactory(RepositoryClientFactoryInput{provider: providerConfig})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if version == "" {
+		version = repositoryClient.DefaultVersion()
+	}
+
+	bp := &bundleProvider{
+		Name:           providerConfig.Name(),
+		Type:           providerConfig.Type(),
+		Version:        version,
+		ComponentsPath: repositoryClient.ComponentsPath(),
+	}
+	folder := path.Join(bundleProvidersFolder, providerConfig.ManifestLabel(), version)
+	files := map[string][]byte{}
+
+	// Gets the provider components and the required images, applying image overrides.
+	components, err := repositoryClient.GetFile(version, bp.ComponentsPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	images, err := inspectImages(components)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "failed to detect required images")
+	}
+	for i := range images {
+		images[i], err = c.configClient.ImageMeta().AlterImage(providerConfig.ManifestLabel(), images[i])
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "failed to apply image overrides")
+		}
+	}
+	files[path.Join(folder, bp.ComponentsPath)] = components
+	bp.Files = append(bp.Files, bp.ComponentsPath)
+
+	// Gets the provider metadata.
+	metadata, err := repositoryClient.GetFile(version, metadataFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files[path.Join(folder, metadataFile)] = metadata
+	bp.Files = append(bp.Files, metadataFile)
+
+	// Gets the checksums and their signature, if published by the provider, so the files in the bundle can be verified
+	// when installing the provider.
+	for _, name := range []string{checksumsFile, checksumsSignatureFile} {
+		content, err := repositoryClient.GetFile(version, name)
+		if err != nil {
+			log.V(1).Info("Skipping "+name, "Provider", providerConfig.ManifestLabel(), "Version", version, "Reason", err.Error())
+			break
+		}
+		files[path.Join(folder, name)] = content
+		bp.Files = append(bp.Files, name)
+	}
+
+	// Gets the cluster templates; the default template is optional, while the requested flavors must exist.
+	if providerType == clusterctlv1.InfrastructureProviderType {
+		processor := yamlprocessor.NewSimpleProcessor()
+		for _, flavor := range append([]string{""}, flavors...) {
+			templateName := processor.GetTemplateName(version, flavor)
+			template, err := repositoryClient.GetFile(version, templateName)
+			if err != nil {
+				if flavor == "" {
+					log.V(1).Info("Skipping the default cluster template", "Provider", providerConfig.ManifestLabel(), "Version", version, "Reason", err.Error())
+					continue
+				}
+				return nil, nil, nil, err
+			}
+			files[path.Join(folder, templateName)] = template
+			bp.Files = append(bp.Files, templateName)
+		}
+	}
+
+	return bp, files, images, nil
+}
+
+// BundleImport extracts a bundle and writes the providers included in the bundle, registered as local repositories,
+// and the image overrides to a clusterctl configuration file; the existing clusterctl configuration file is not
+// changed, so the user can either use the new file with --config or copy its content to the existing file.
+func (c *clusterctlClient) BundleImport(options BundleImportOptions) ([]Provider, error) {
+	log := logf.Log
+
+	if options.File == "" {
+		return nil, errors.New("the bundle file must be specified")
+	}
+
+	if options.Directory == "" {
+		name := filepath.Base(options.File)
+		for _, ext := range []string{".tar.gz", ".tgz"} {
+			name = strings.TrimSuffix(name, ext)
+		}
+		options.Directory = filepath.Join(homedir.HomeDir(), config.ConfigFolder, bundlesFolder, name)
+	}
+	directory, err := filepath.Abs(options.Directory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the absolute path for %q", options.Directory)
+	}
+
+	if options.ConfigFile == "" {
+		options.ConfigFile = filepath.Join(directory, bundleConfigFile)
+	}
+
+	log.Info("Extracting bundle", "File", options.File, "Directory", directory)
+	if err := extractTarball(options.File, directory); err != nil {
+		return nil, err
+	}
+
+	rawManifest, err := ioutil.ReadFile(filepath.Join(directory, bundleManifestFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the bundle manifest")
+	}
+	manifest := &bundleManifest{}
+	if err := yaml.Unmarshal(rawManifest, manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the bundle manifest")
+	}
+
+	providers := []Provider{}
+	for _, bp := range manifest.Providers {
+		// Ensure the components path is within the bundle directory.
+		for _, name := range []string{bp.Name, bp.Version, bp.ComponentsPath} {
+			if !isBundlePathValid(name) {
+				return nil, errors.Errorf("invalid provider %q in the bundle manifest: %q is not a valid path", bp.Name, name)
+			}
+		}
+		label := clusterctlv1.ManifestLabel(bp.Name, bp.Type)
+		componentsPath := filepath.Join(directory, bundleProvidersFolder, label, bp.Version, bp.ComponentsPath)
+		providers = append(providers, config.NewProvider(bp.Name, "file://"+filepath.ToSlash(componentsPath), bp.Type))
+	}
+
+	if err := writeBundleConfig(options.ConfigFile, providers, manifest.ImageOverrides); err != nil {
+		return nil, err
+	}
+	log.Info("Providers and image overrides written to the clusterctl configuration file; use it with --config, or copy its content to the clusterctl configuration file in use", "ConfigFile", options.ConfigFile)
+
+	return providers, nil
+}
+
+// writeBundleConfig writes a clusterctl configuration file with the providers and the image overrides included in a bundle.
+func writeBundleConfig(configFile string, providers []Provider, imageOverrides map[string]config.ImageMeta) error {
+	registered := []interface{}{}
+	for _, p := range providers {
+		registered = append(registered, map[string]interface{}{
+			"name": p.Name(),
+			"url":  p.URL(),
+			"type": string(p.Type()),
+		})
+	}
+	cfg := map[string]interface{}{
+		config.ProvidersConfigKey: registered,
+	}
+	if len(imageOverrides) > 0 {
+		cfg[config.ImagesConfigKey] = imageOverrides
+	}
+
+	content, err := yaml.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the clusterctl configuration")
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create the folder for the clusterctl configuration file %q", configFile)
+	}
+	if err := ioutil.WriteFile(configFile, content, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the clusterctl configuration file %q", configFile)
+	}
+	return nil
+}
+
+// inspectImages returns the list of images required for installing the objects defined in a YAML.
+func inspectImages(rawYaml []byte) ([]string, error) {
+	objs, err := utilyaml.ToUnstructured(rawYaml)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse yaml")
+	}
+	return util.InspectImages(objs)
+}
+
+// writeTarball writes files in a gzipped tarball.
+func writeTarball(file string, files map[string][]byte) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %q", file)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			return errors.Wrapf(err, "failed to write %q to %q", name, file)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return errors.Wrapf(err, "failed to write %q to %q", name, file)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write %q", file)
+	}
+	if err := gw.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write %q", file)
+	}
+	return nil
+}
+
+// extractTarball extracts a gzipped tarball in a directory.
+func extractTarball(file, directory string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %q", file)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %q", file)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q", file)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Ensure files are extracted within the target directory.
+		if !isBundlePathValid(h.Name) {
+			return errors.Errorf("invalid file name %q in %q", h.Name, file)
+		}
+		target := filepath.Join(directory, filepath.FromSlash(h.Name))
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return errors.Wrapf(err, "failed to create folder %q", filepath.Dir(target))
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q from %q", h.Name, file)
+		}
+		if err := ioutil.WriteFile(target, content, 0644); err != nil {
+			return errors.Wrapf(err, "failed to write %q", target)
+		}
+	}
+	return nil
+}
+
+// isBundlePathValid returns true if a path read from a bundle is relative and it does not refer to a parent directory,
+// so it is within the bundle directory.
+func isBundlePathValid(name string) bool {
+	name = filepath.Clean(filepath.FromSlash(name))
+	return !filepath.IsAbs(name) && name != ".." && !strings.HasPrefix(name, ".."+string(filepath.Separator))
+}
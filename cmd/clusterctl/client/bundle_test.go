@@ -0,0 +1,337 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/yaml"
+)
+
+var certManagerYAML = []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cert-manager
+  namespace: cert-manager
+spec:
+  template:
+    spec:
+      containers:
+      - image: quay.io/jetstack/cert-manager-controller:v0.11.0
+        name: cert-manager
+`)
+
+func Test_clusterctlClient_BundleExportImport(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	cfg := fakeConfig(
+		[]config.Provider{capiProviderConfig, bootstrapProviderConfig, controlPlaneProviderConfig, infraProviderConfig},
+		nil,
+	)
+	cfg.fakeReader.WithImageMeta("infrastructure-infra", "myorg.io/local-repo", "")
+
+	repositories := fakeRepositories(cfg, nil)
+	// Nb. the cluster client is used only for reading the cert-manager manifest, so it uses the default kubeconfig.
+	cluster1 := newFakeCluster(cluster.Kubeconfig{}, cfg).
+		WithCertManagerClient(newFakeCertManagerClient(nil, nil).WithManifest(certManagerYAML))
+	client := fakeClusterCtlClient(cfg, repositories, []*fakeClusterClient{cluster1})
+
+	// Export the bundle.
+	bundleFile := filepath.Join(tmpDir, "bundle.tar.gz")
+	err = client.BundleExport(BundleExportOptions{
+		InfrastructureProviders: []string{"infra"},
+		File:                    bundleFile,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Export fails if the requested flavor does not exist.
+	err = client.BundleExport(BundleExportOptions{
+		InfrastructureProviders: []string{"infra"},
+		Flavors:                 []string{"not-a-flavor"},
+		File:                    filepath.Join(tmpDir, "invalid.tar.gz"),
+	})
+	g.Expect(err).To(HaveOccurred())
+
+	// Import the bundle.
+	bundleDir := filepath.Join(tmpDir, "bundle")
+	providers, err := client.BundleImport(BundleImportOptions{
+		File:      bundleFile,
+		Directory: bundleDir,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(providers).To(HaveLen(4))
+
+	// The bundle contains the manifest, the cert-manager and all the provider files.
+	rawManifest, err := ioutil.ReadFile(filepath.Join(bundleDir, bundleManifestFile))
+	g.Expect(err).NotTo(HaveOccurred())
+	manifest := &bundleManifest{}
+	g.Expect(yaml.Unmarshal(rawManifest, manifest)).To(Succeed())
+	g.Expect(manifest.Images).To(Equal([]string{
+		"myorg.io/local-repo/cluster-api-aws-controller:v0.5.3",
+		"myorg.io/local-repo/kube-rbac-proxy:v0.4.1",
+		"quay.io/jetstack/cert-manager-controller:v0.11.0",
+	}))
+	g.Expect(manifest.Providers).To(ContainElement(bundleProvider{
+		Name:           "infra",
+		Type:           clusterctlv1.InfrastructureProviderType,
+		Version:        "v3.0.0",
+		ComponentsPath: "components.yaml",
+		Files:          []string{"components.yaml", "metadata.yaml", "cluster-template.yaml"},
+	}))
+	g.Expect(filepath.Join(bundleDir, bundleCertManagerFile)).To(BeAnExistingFile())
+
+	// The components YAML is stored as published, while the image overrides are stored in the manifest.
+	components, err := ioutil.ReadFile(filepath.Join(bundleDir, bundleProvidersFolder, "infrastructure-infra", "v3.0.0", "components.yaml"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(components).To(Equal(infraComponentsYAML("ns4")))
+	g.Expect(manifest.ImageOverrides).To(Equal(map[string]config.ImageMeta{
+		"infrastructure-infra": {Repository: "myorg.io/local-repo"},
+	}))
+
+	// Providers and image overrides are written to a config file in the bundle directory.
+	configClient, err := config.New(filepath.Join(bundleDir, bundleConfigFile))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	image, err := configClient.ImageMeta().AlterImage("infrastructure-infra", "gcr.io/k8s-staging-cluster-api-aws/cluster-api-aws-controller:v0.5.3")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(image).To(Equal("myorg.io/local-repo/cluster-api-aws-controller:v0.5.3"))
+
+	// Registered providers can be used as local repositories.
+	infra, err := configClient.Providers().Get("infra", clusterctlv1.InfrastructureProviderType)
+	g.Expect(err).NotTo(HaveOccurred())
+	repositoryClient, err := repository.New(infra, configClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repositoryClient.DefaultVersion()).To(Equal("v3.0.0"))
+	g.Expect(repositoryClient.GetFile("v3.0.0", "cluster-template.yaml")).To(Equal(templateYAML("ns4", "test")))
+}
+
+func Test_clusterctlClient_BundleExportImport_verification(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	derKey, err := x509.MarshalPKIXPublicKey(publicKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: derKey})
+
+	cfg := fakeConfig(
+		[]config.Provider{capiProviderConfig, bootstrapProviderConfig, controlPlaneProviderConfig, infraProviderConfig},
+		nil,
+	)
+
+	// All the providers publish signed checksums.
+	repositories := fakeRepositories(cfg, nil)
+	for _, r := range repositories {
+		version := r.DefaultVersion()
+		checksums := ""
+		for _, name := range []string{"components.yaml", "metadata.yaml", "cluster-template.yaml"} {
+			content, err := r.GetFile(version, name)
+			if err != nil {
+				continue
+			}
+			checksums += fmt.Sprintf("%x  %s\n", sha256.Sum256(content), name)
+		}
+		r.WithFile(version, checksumsFile, []byte(checksums)).
+			WithFile(version, checksumsSignatureFile, ed25519.Sign(privateKey, []byte(checksums)))
+	}
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{}, cfg).
+		WithCertManagerClient(newFakeCertManagerClient(nil, nil).WithManifest(certManagerYAML))
+	client := fakeClusterCtlClient(cfg, repositories, []*fakeClusterClient{cluster1})
+
+	bundleFile := filepath.Join(tmpDir, "bundle.tar.gz")
+	g.Expect(client.BundleExport(BundleExportOptions{
+		InfrastructureProviders: []string{"infra"},
+		File:                    bundleFile,
+	})).To(Succeed())
+
+	bundleDir := filepath.Join(tmpDir, "bundle")
+	providers, err := client.BundleImport(BundleImportOptions{
+		File:      bundleFile,
+		Directory: bundleDir,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(providers).To(HaveLen(4))
+
+	// Add the bundle configuration to a config file requiring signed checksums.
+	rawConfig, err := yaml.Marshal(map[string]interface{}{
+		"verification": map[string]interface{}{
+			"strict":      true,
+			"trustedKeys": []interface{}{map[string]interface{}{"name": "test", "key": string(pemKey)}},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	bundleConfig, err := ioutil.ReadFile(filepath.Join(bundleDir, bundleConfigFile))
+	g.Expect(err).NotTo(HaveOccurred())
+	configFile := filepath.Join(tmpDir, "clusterctl.yaml")
+	g.Expect(ioutil.WriteFile(configFile, append(rawConfig, bundleConfig...), 0600)).To(Succeed())
+
+	// The providers can be installed from the bundle, because the files are verified against the signed checksums.
+	configClient, err := config.New(configFile)
+	g.Expect(err).NotTo(HaveOccurred())
+	strict, err := configClient.Verification().Strict()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(strict).To(BeTrue())
+
+	for _, p := range providers {
+		repositoryClient, err := repository.New(p, configClient)
+		g.Expect(err).NotTo(HaveOccurred())
+		_, err = repositoryClient.Components().Get(repository.ComponentsOptions{
+			Version:         repositoryClient.DefaultVersion(),
+			TargetNamespace: "ns1",
+			SkipVariables:   true,
+		})
+		g.Expect(err).NotTo(HaveOccurred(), "provider %s", p.ManifestLabel())
+	}
+
+	// Files tampered after the export are rejected.
+	infra, err := configClient.Providers().Get("infra", clusterctlv1.InfrastructureProviderType)
+	g.Expect(err).NotTo(HaveOccurred())
+	componentsFile := filepath.Join(bundleDir, bundleProvidersFolder, "infrastructure-infra", "v3.0.0", "components.yaml")
+	g.Expect(ioutil.WriteFile(componentsFile, append(infraComponentsYAML("ns4"), []byte("\n---\n")...), 0600)).To(Succeed())
+	repositoryClient, err := repository.New(infra, configClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = repositoryClient.Components().Get(repository.ComponentsOptions{
+		Version:         "v3.0.0",
+		TargetNamespace: "ns1",
+		SkipVariables:   true,
+	})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_clusterctlClient_BundleImport_configFile(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	bundleFile := filepath.Join(tmpDir, "bundle.tar.gz")
+	g.Expect(writeTarball(bundleFile, map[string][]byte{
+		bundleManifestFile: []byte(`providers:
+- name: infra
+  type: InfrastructureProvider
+  version: v3.0.0
+  componentsPath: components.yaml
+`),
+	})).To(Succeed())
+
+	// The bundle configuration is written to the given file, while the clusterctl configuration file in use is not changed.
+	userConfig := []byte(`# comment
+FOO: bar
+providers:
+- name: infra
+  url: https://example.com/infra/v3.0.0/components.yaml
+  type: InfrastructureProvider
+`)
+	userConfigFile := filepath.Join(tmpDir, "clusterctl.yaml")
+	g.Expect(ioutil.WriteFile(userConfigFile, userConfig, 0600)).To(Succeed())
+
+	client, err := New(userConfigFile)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	bundleConfigFile := filepath.Join(tmpDir, "bundle-config.yaml")
+	_, err = client.BundleImport(BundleImportOptions{
+		File:       bundleFile,
+		Directory:  filepath.Join(tmpDir, "bundle"),
+		ConfigFile: bundleConfigFile,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(ioutil.ReadFile(userConfigFile)).To(Equal(userConfig))
+
+	configClient, err := config.New(bundleConfigFile)
+	g.Expect(err).NotTo(HaveOccurred())
+	infra, err := configClient.Providers().Get("infra", clusterctlv1.InfrastructureProviderType)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(infra.URL()).To(Equal("file://" + filepath.ToSlash(filepath.Join(tmpDir, "bundle", bundleProvidersFolder, "infrastructure-infra", "v3.0.0", "components.yaml"))))
+}
+
+func Test_clusterctlClient_BundleImport_InvalidManifest(t *testing.T) {
+	tests := []struct {
+		name           string
+		version        string
+		componentsPath string
+	}{
+		{name: "components path referring to a parent directory", version: "v3.0.0", componentsPath: "../../../../components.yaml"},
+		{name: "absolute components path", version: "v3.0.0", componentsPath: "/etc/components.yaml"},
+		{name: "version referring to a parent directory", version: "../../..", componentsPath: "components.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tmpDir, err := ioutil.TempDir("", "cc")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(tmpDir)
+
+			bundleFile := filepath.Join(tmpDir, "bundle.tar.gz")
+			g.Expect(writeTarball(bundleFile, map[string][]byte{
+				bundleManifestFile: []byte(fmt.Sprintf(`providers:
+- name: infra
+  type: InfrastructureProvider
+  version: %q
+  componentsPath: %q
+`, tt.version, tt.componentsPath)),
+			})).To(Succeed())
+
+			client := fakeClusterCtlClient(fakeConfig(nil, nil), nil, nil)
+			_, err = client.BundleImport(BundleImportOptions{
+				File:      bundleFile,
+				Directory: filepath.Join(tmpDir, "bundle"),
+			})
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
+
+func Test_extractTarball_InvalidFileName(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	file := filepath.Join(tmpDir, "bundle.tar.gz")
+	g.Expect(writeTarball(file, map[string][]byte{"../outside.yaml": []byte("content")})).To(Succeed())
+
+	g.Expect(extractTarball(file, filepath.Join(tmpDir, "bundle"))).ToNot(Succeed())
+	g.Expect(filepath.Join(tmpDir, "outside.yaml")).ToNot(BeAnExistingFile())
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
@@ -40,11 +41,19 @@ type Client interface {
 	// GetClusterTemplate returns a workload cluster template.
 	GetClusterTemplate(options GetClusterTemplateOptions) (Template, error)
 
-	// Delete deletes providers from a management cluster.
-	Delete(options DeleteOptions) error
+	// Delete deletes providers from a management cluster, and returns the list of the deleted providers.
+	Delete(options DeleteOptions) ([]clusterctlv1.Provider, error)
 
-	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
-	Move(options MoveOptions) error
+	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster,
+	// and returns the moved objects grouped in the order they are created in the target management cluster.
+	// In case of rollback, no objects are returned.
+	Move(options MoveOptions) ([][]corev1.ObjectReference, error)
+
+	// Backup saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target directory.
+	Backup(options BackupOptions) error
+
+	// Restore restores all the Cluster API objects existing in a source directory to a target management cluster.
+	Restore(options RestoreOptions) error
 
 	// PlanUpgrade returns a set of suggested Upgrade plans for the cluster, and more specifically:
 	// - Each management group gets separated upgrade plans.
@@ -53,12 +62,65 @@ type Client interface {
 	//   - Upgrade to the latest version in the the v1alpha3 series: ....
 	PlanUpgrade(options PlanUpgradeOptions) ([]UpgradePlan, error)
 
+	// PlanCertManagerUpgrade returns the upgrade plan for the cert-manager installed in the management cluster.
+	PlanCertManagerUpgrade(options PlanUpgradeOptions) (CertManagerUpgradePlan, error)
+
+	// DiffUpgrade returns the changes to the provider components resulting from applying an upgrade plan.
+	DiffUpgrade(options DiffUpgradeOptions) ([]ComponentsDiff, error)
+
 	// ApplyUpgrade executes an upgrade plan.
 	ApplyUpgrade(options ApplyUpgradeOptions) error
 
 	// ProcessYAML provides a direct way to process a yaml and inspect its
 	// variables.
 	ProcessYAML(options ProcessYAMLOptions) (YamlPrinter, error)
+
+	// Describe returns the tree of objects composing a workload cluster, including for each object the Ready condition.
+	Describe(options DescribeOptions) (*ObjectTree, error)
+
+	// BundleExport writes a self-contained tarball with all the artifacts required for installing a set of providers in air-gapped environments.
+	BundleExport(options BundleExportOptions) error
+
+	// BundleImport extracts a bundle and registers the providers included in the bundle as local repositories.
+	BundleImport(options BundleImportOptions) ([]Provider, error)
+
+	// RolloutStatus returns the rollout status of a MachineDeployment, eventually waiting for the rollout to complete.
+	RolloutStatus(options RolloutStatusOptions) (*RolloutStatus, error)
+
+	// RolloutHistory returns the revisions of a MachineDeployment.
+	RolloutHistory(options RolloutOptions) ([]RolloutRevision, error)
+
+	// RolloutUndo restores the machine template of a previous revision of a MachineDeployment.
+	RolloutUndo(options RolloutUndoOptions) error
+
+	// RolloutPause pauses the rollout of a MachineDeployment.
+	RolloutPause(options RolloutOptions) error
+
+	// RolloutResume resumes the rollout of a paused MachineDeployment.
+	RolloutResume(options RolloutOptions) error
+
+	// RolloutRestart triggers the rollout of new Machines for a MachineDeployment without changing its spec.
+	RolloutRestart(options RolloutOptions) error
+
+	// GetKubeconfig returns the kubeconfig of a workload cluster, optionally writing it to a file or merging it into an existing kubeconfig file.
+	GetKubeconfig(options GetKubeconfigOptions) ([]byte, error)
+
+	// PlanClusterUpgrade returns the steps required for upgrading the Kubernetes version of a workload cluster.
+	PlanClusterUpgrade(options ClusterUpgradeOptions) (*KubernetesUpgradePlan, error)
+
+	// ApplyClusterUpgrade upgrades the Kubernetes version of a workload cluster, upgrading the control plane first
+	// and then each MachineDeployment, waiting for each rollout to complete.
+	ApplyClusterUpgrade(options ClusterUpgradeOptions) error
+
+	// Doctor runs health and consistency checks against a management cluster.
+	Doctor(options DoctorOptions) ([]CheckResult, error)
+
+	// DeleteCluster deletes a workload cluster and waits for all the objects it is composed of to be deleted.
+	DeleteCluster(options DeleteClusterOptions) ([]RemainingObject, error)
+
+	// Pivot makes a workload cluster self-hosted, installing the providers of the source management cluster and then
+	// moving the Cluster API objects into it.
+	Pivot(options PivotOptions) error
 }
 
 // YamlPrinter exposes methods that prints the processed template and
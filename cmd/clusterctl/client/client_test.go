@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -89,18 +90,34 @@ func (f fakeClient) InitImages(options InitOptions) ([]string, error) {
 	return f.internalClient.InitImages(options)
 }
 
-func (f fakeClient) Delete(options DeleteOptions) error {
+func (f fakeClient) Delete(options DeleteOptions) ([]clusterctlv1.Provider, error) {
 	return f.internalClient.Delete(options)
 }
 
-func (f fakeClient) Move(options MoveOptions) error {
+func (f fakeClient) Move(options MoveOptions) ([][]corev1.ObjectReference, error) {
 	return f.internalClient.Move(options)
 }
 
+func (f fakeClient) Backup(options BackupOptions) error {
+	return f.internalClient.Backup(options)
+}
+
+func (f fakeClient) Restore(options RestoreOptions) error {
+	return f.internalClient.Restore(options)
+}
+
 func (f fakeClient) PlanUpgrade(options PlanUpgradeOptions) ([]UpgradePlan, error) {
 	return f.internalClient.PlanUpgrade(options)
 }
 
+func (f fakeClient) PlanCertManagerUpgrade(options PlanUpgradeOptions) (CertManagerUpgradePlan, error) {
+	return f.internalClient.PlanCertManagerUpgrade(options)
+}
+
+func (f fakeClient) DiffUpgrade(options DiffUpgradeOptions) ([]ComponentsDiff, error) {
+	return f.internalClient.DiffUpgrade(options)
+}
+
 func (f fakeClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 	return f.internalClient.ApplyUpgrade(options)
 }
@@ -109,6 +126,66 @@ func (f fakeClient) ProcessYAML(options ProcessYAMLOptions) (YamlPrinter, error)
 	return f.internalClient.ProcessYAML(options)
 }
 
+func (f fakeClient) Describe(options DescribeOptions) (*ObjectTree, error) {
+	return f.internalClient.Describe(options)
+}
+
+func (f fakeClient) BundleExport(options BundleExportOptions) error {
+	return f.internalClient.BundleExport(options)
+}
+
+func (f fakeClient) BundleImport(options BundleImportOptions) ([]Provider, error) {
+	return f.internalClient.BundleImport(options)
+}
+
+func (f fakeClient) RolloutStatus(options RolloutStatusOptions) (*RolloutStatus, error) {
+	return f.internalClient.RolloutStatus(options)
+}
+
+func (f fakeClient) RolloutHistory(options RolloutOptions) ([]RolloutRevision, error) {
+	return f.internalClient.RolloutHistory(options)
+}
+
+func (f fakeClient) RolloutUndo(options RolloutUndoOptions) error {
+	return f.internalClient.RolloutUndo(options)
+}
+
+func (f fakeClient) RolloutPause(options RolloutOptions) error {
+	return f.internalClient.RolloutPause(options)
+}
+
+func (f fakeClient) RolloutResume(options RolloutOptions) error {
+	return f.internalClient.RolloutResume(options)
+}
+
+func (f fakeClient) RolloutRestart(options RolloutOptions) error {
+	return f.internalClient.RolloutRestart(options)
+}
+
+func (f fakeClient) GetKubeconfig(options GetKubeconfigOptions) ([]byte, error) {
+	return f.internalClient.GetKubeconfig(options)
+}
+
+func (f fakeClient) PlanClusterUpgrade(options ClusterUpgradeOptions) (*KubernetesUpgradePlan, error) {
+	return f.internalClient.PlanClusterUpgrade(options)
+}
+
+func (f fakeClient) ApplyClusterUpgrade(options ClusterUpgradeOptions) error {
+	return f.internalClient.ApplyClusterUpgrade(options)
+}
+
+func (f fakeClient) Doctor(options DoctorOptions) ([]CheckResult, error) {
+	return f.internalClient.Doctor(options)
+}
+
+func (f fakeClient) DeleteCluster(options DeleteClusterOptions) ([]RemainingObject, error) {
+	return f.internalClient.DeleteCluster(options)
+}
+
+func (f fakeClient) Pivot(options PivotOptions) error {
+	return f.internalClient.Pivot(options)
+}
+
 // newFakeClient returns a clusterctl client that allows to execute tests on a set of fake config, fake repositories and fake clusters.
 // you can use WithCluster and WithRepository to prepare for the test case.
 func newFakeClient(configClient config.Client) *fakeClient {
@@ -190,7 +267,7 @@ func newFakeCluster(kubeconfig cluster.Kubeconfig, configClient config.Client) *
 
 // newFakeCertManagerClient creates a new CertManagerClient
 // allows the caller to define which images are needed for the manager to run
-func newFakeCertManagerClient(imagesReturnImages []string, imagesReturnError error) cluster.CertManagerClient {
+func newFakeCertManagerClient(imagesReturnImages []string, imagesReturnError error) *fakeCertManagerClient {
 	return &fakeCertManagerClient{
 		images:      imagesReturnImages,
 		imagesError: imagesReturnError,
@@ -200,6 +277,10 @@ func newFakeCertManagerClient(imagesReturnImages []string, imagesReturnError err
 type fakeCertManagerClient struct {
 	images      []string
 	imagesError error
+	manifest    []byte
+	upgradePlan cluster.CertManagerUpgradePlan
+	upgraded    bool
+	deleted     bool
 }
 
 var _ cluster.CertManagerClient = &fakeCertManagerClient{}
@@ -212,6 +293,34 @@ func (p *fakeCertManagerClient) Images() ([]string, error) {
 	return p.images, p.imagesError
 }
 
+func (p *fakeCertManagerClient) Manifest() ([]byte, error) {
+	return p.manifest, nil
+}
+
+func (p *fakeCertManagerClient) PlanUpgrade() (cluster.CertManagerUpgradePlan, error) {
+	return p.upgradePlan, nil
+}
+
+func (p *fakeCertManagerClient) EnsureLatestVersion() error {
+	p.upgraded = p.upgradePlan.ShouldUpgrade
+	return nil
+}
+
+func (p *fakeCertManagerClient) Delete() error {
+	p.deleted = true
+	return nil
+}
+
+func (p *fakeCertManagerClient) WithUpgradePlan(plan cluster.CertManagerUpgradePlan) *fakeCertManagerClient {
+	p.upgradePlan = plan
+	return p
+}
+
+func (p *fakeCertManagerClient) WithManifest(manifest []byte) *fakeCertManagerClient {
+	p.manifest = manifest
+	return p
+}
+
 type fakeClusterClient struct {
 	kubeconfig      cluster.Kubeconfig
 	fakeProxy       *test.FakeProxy
@@ -262,6 +371,30 @@ func (f *fakeClusterClient) Template() cluster.TemplateClient {
 	return f.internalclient.Template()
 }
 
+func (f *fakeClusterClient) ClusterDescriber() cluster.ClusterDescriber {
+	return f.internalclient.ClusterDescriber()
+}
+
+func (f *fakeClusterClient) Rollout() cluster.RolloutClient {
+	return f.internalclient.Rollout()
+}
+
+func (f *fakeClusterClient) WorkloadCluster() cluster.WorkloadCluster {
+	return f.internalclient.WorkloadCluster()
+}
+
+func (f *fakeClusterClient) KubernetesUpgrader() cluster.KubernetesUpgrader {
+	return f.internalclient.KubernetesUpgrader()
+}
+
+func (f *fakeClusterClient) Doctor() cluster.Doctor {
+	return f.internalclient.Doctor()
+}
+
+func (f *fakeClusterClient) ClusterDeleter() cluster.ClusterDeleter {
+	return f.internalclient.ClusterDeleter()
+}
+
 func (f *fakeClusterClient) WithObjs(objs ...runtime.Object) *fakeClusterClient {
 	f.fakeProxy.WithObjs(objs...)
 	return f
@@ -320,6 +453,14 @@ func (f fakeConfigClient) ImageMeta() config.ImageMetaClient {
 	return f.internalclient.ImageMeta()
 }
 
+func (f fakeConfigClient) Verification() config.VerificationClient {
+	return f.internalclient.Verification()
+}
+
+func (f fakeConfigClient) VersionConstraints() config.VersionConstraintsClient {
+	return f.internalclient.VersionConstraints()
+}
+
 func (f *fakeConfigClient) WithVar(key, value string) *fakeConfigClient {
 	f.fakeReader.WithVar(key, value)
 	return f
@@ -365,6 +506,14 @@ func (f fakeRepositoryClient) GetVersions() ([]string, error) {
 	return f.fakeRepository.GetVersions()
 }
 
+func (f fakeRepositoryClient) ComponentsPath() string {
+	return f.fakeRepository.ComponentsPath()
+}
+
+func (f fakeRepositoryClient) GetFile(version, path string) ([]byte, error) {
+	return f.fakeRepository.GetFile(version, path)
+}
+
 func (f fakeRepositoryClient) Components() repository.ComponentsClient {
 	// use a fakeComponentClient (instead of the internal client used in other fake objects) we can de deterministic on what is returned (e.g. avoid interferences from overrides)
 	return &fakeComponentClient{
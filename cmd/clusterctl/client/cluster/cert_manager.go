@@ -17,11 +17,18 @@ limitations under the License.
 package cluster
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	manifests "sigs.k8s.io/cluster-api/cmd/clusterctl/config"
@@ -35,13 +42,42 @@ import (
 const (
 	embeddedCertManagerManifestPath = "cmd/clusterctl/config/assets/cert-manager.yaml"
 
+	// embeddedCertManagerVersion is the version of the cert-manager manifest embedded in the clusterctl binary;
+	// it must be kept in sync with the manifest.
+	embeddedCertManagerVersion = "v0.11.0"
+
+	certManagerNamespace      = "cert-manager"
+	certManagerDeploymentName = "cert-manager"
+	certManagerCoreLabelValue = "cert-manager"
+
 	waitCertManagerInterval       = 1 * time.Second
 	waitCertManagerDefaultTimeout = 10 * time.Minute
 
 	certManagerImageComponent = "cert-manager"
 	timeoutConfigKey          = "cert-manager-timeout"
+	externalConfigKey         = "cert-manager-external"
 )
 
+// CertManagerUpgradePlan defines the upgrade plan for cert-manager.
+type CertManagerUpgradePlan struct {
+	// Installed is true if cert-manager is installed in the cluster.
+	Installed bool
+
+	// ExternallyManaged is true if cert-manager is not managed by clusterctl, because it is configured
+	// as an external cert-manager or because it was not installed by clusterctl.
+	ExternallyManaged bool
+
+	// From is the installed version of cert-manager; it is empty if the version is unknown, e.g. because
+	// cert-manager was installed by a clusterctl version not tracking it.
+	From string
+
+	// To is the version of cert-manager embedded in clusterctl.
+	To string
+
+	// ShouldUpgrade is true if cert-manager is managed by clusterctl and the installed version is older than To.
+	ShouldUpgrade bool
+}
+
 // CertManagerClient has methods to work with cert-manager components in the cluster.
 type CertManagerClient interface {
 	// EnsureWebhook makes sure the cert-manager Webhook is Available in a cluster:
@@ -50,6 +86,19 @@ type CertManagerClient interface {
 
 	// Images return the list of images required for installing the cert-manager.
 	Images() ([]string, error)
+
+	// Manifest returns the YAML manifest for installing the cert-manager, with image overrides applied.
+	Manifest() ([]byte, error)
+
+	// PlanUpgrade returns the upgrade plan for the cert-manager installed in the cluster.
+	PlanUpgrade() (CertManagerUpgradePlan, error)
+
+	// EnsureLatestVersion upgrades the cert-manager installed by clusterctl to the version embedded in
+	// the clusterctl binary, if it is outdated.
+	EnsureLatestVersion() error
+
+	// Delete removes the cert-manager installed by clusterctl, including its CRDs; an externally managed cert-manager is never deleted.
+	Delete() error
 }
 
 // certManagerClient implements CertManagerClient .
@@ -73,6 +122,11 @@ func newCertMangerClient(configClient config.Client, proxy Proxy, pollImmediateW
 
 // Images return the list of images required for installing the cert-manager.
 func (cm *certManagerClient) Images() ([]string, error) {
+	// If cert-manager is externally managed, no images are required.
+	if cm.isExternal() {
+		return []string{}, nil
+	}
+
 	// Checks if the cert-manager web-hook already exists, if yes, no additional images are required for the web-hook.
 	// Nb. we are ignoring the error so this operation can support listing images even if there is no an existing management cluster;
 	// in case there is no an existing management cluster, we assume there is no web-hook installed in the cluster.
@@ -94,6 +148,20 @@ func (cm *certManagerClient) Images() ([]string, error) {
 	return images, nil
 }
 
+// Manifest returns the YAML manifest for installing the cert-manager, with image overrides applied.
+func (cm *certManagerClient) Manifest() ([]byte, error) {
+	objs, err := cm.getManifestObjs()
+	if err != nil {
+		return nil, err
+	}
+
+	yaml, err := utilyaml.FromUnstructured(objs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize the cert-manager manifest")
+	}
+	return yaml, nil
+}
+
 // EnsureWebhook makes sure the cert-manager Web-hook is Available in a cluster:
 // this is a requirement to install a new provider
 // Nb. In order to provide a simpler out-of-the box experience, the cert-manager manifest
@@ -101,6 +169,12 @@ func (cm *certManagerClient) Images() ([]string, error) {
 func (cm *certManagerClient) EnsureWebhook() error {
 	log := logf.Log
 
+	// If cert-manager is externally managed, skip installation.
+	if cm.isExternal() {
+		log.V(1).Info("Skipping cert-manager installation, cert-manager is externally managed")
+		return nil
+	}
+
 	// Checks if the cert-manager web-hook already exists, if yes, exit immediately
 	hasWebhook, err := cm.hasWebhook()
 	if err != nil {
@@ -136,6 +210,141 @@ func (cm *certManagerClient) EnsureWebhook() error {
 	}
 
 	// Waits for for the cert-manager web-hook to be available.
+	return cm.waitForWebhook()
+}
+
+// PlanUpgrade returns the upgrade plan for the cert-manager installed in the cluster.
+func (cm *certManagerClient) PlanUpgrade() (CertManagerUpgradePlan, error) {
+	plan := CertManagerUpgradePlan{To: embeddedCertManagerVersion}
+
+	if cm.isExternal() {
+		plan.ExternallyManaged = true
+		return plan, nil
+	}
+
+	deployment, err := cm.getDeployment()
+	if err != nil {
+		return plan, err
+	}
+
+	// If the cert-manager Deployment does not exist, cert-manager is not installed or it was
+	// installed in a non standard way (the web-hook exists), and in this case it is considered externally managed.
+	if deployment == nil {
+		hasWebhook, err := cm.hasWebhook()
+		if err != nil {
+			return plan, err
+		}
+		plan.Installed = hasWebhook
+		plan.ExternallyManaged = hasWebhook
+		return plan, nil
+	}
+	plan.Installed = true
+
+	// If the cert-manager Deployment does not have the clusterctl label, cert-manager was not installed by clusterctl.
+	if deployment.Labels[clusterctlv1.ClusterctlCoreLabelName] != certManagerCoreLabelValue {
+		plan.ExternallyManaged = true
+		return plan, nil
+	}
+
+	// Compares the installed version with the embedded version; if the installed version is unknown
+	// (cert-manager was installed by a clusterctl version not tracking it), an upgrade is required.
+	plan.From = deployment.Annotations[clusterctlv1.CertManagerVersionAnnotation]
+	plan.ShouldUpgrade = true
+	if from, err := version.ParseSemantic(plan.From); err == nil {
+		plan.ShouldUpgrade = from.LessThan(version.MustParseSemantic(plan.To))
+	}
+	return plan, nil
+}
+
+// EnsureLatestVersion upgrades the cert-manager installed by clusterctl to the version embedded in
+// the clusterctl binary, if it is outdated.
+// Nb. Existing objects are updated in place, and CRDs are never deleted, thus preserving all the cert-manager
+// objects (e.g. Certificates, Issuers) existing in the cluster.
+func (cm *certManagerClient) EnsureLatestVersion() error {
+	log := logf.Log
+
+	plan, err := cm.PlanUpgrade()
+	if err != nil {
+		return err
+	}
+	if !plan.ShouldUpgrade {
+		return nil
+	}
+
+	log.Info("Upgrading cert-manager", "CurrentVersion", prettifyCertManagerVersion(plan.From), "TargetVersion", plan.To)
+
+	// Gets the cert-manager objects from the embedded assets.
+	objs, err := cm.getManifestObjs()
+	if err != nil {
+		return err
+	}
+
+	// Checks the new CRDs still serve all the versions used for storing objects in the cluster,
+	// otherwise the existing cert-manager objects would become inaccessible.
+	if err := cm.checkCRDs(objs); err != nil {
+		return err
+	}
+
+	// Applies the new version of all the objects.
+	applyCertManagerBackoff := newWriteBackoff()
+	objs = utilresource.SortForCreate(objs)
+	for i := range objs {
+		o := objs[i]
+		log.V(5).Info("Applying", logf.UnstructuredToValues(o)...)
+
+		// Nb. The operation is wrapped in a retry loop to make the upgrade more resilient to unexpected conditions.
+		if err := retryWithExponentialBackoff(applyCertManagerBackoff, func() error {
+			return cm.applyObj(o)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Deletes the objects belonging to the previous version and not included in the new version.
+	// Nb. CRDs are never deleted, because this would delete all the related cert-manager objects.
+	newObjs := sets.NewString()
+	for _, o := range objs {
+		newObjs.Insert(certManagerObjKey(o))
+	}
+	if err := cm.deleteObjs(func(o unstructured.Unstructured) bool {
+		if o.GetKind() == "CustomResourceDefinition" || o.GetKind() == "Namespace" {
+			return false
+		}
+		return !newObjs.Has(certManagerObjKey(o))
+	}); err != nil {
+		return err
+	}
+
+	// Waits for the cert-manager web-hook to be available.
+	return cm.waitForWebhook()
+}
+
+// Delete removes the cert-manager installed by clusterctl, including its CRDs; an externally managed cert-manager is never deleted.
+func (cm *certManagerClient) Delete() error {
+	log := logf.Log
+
+	plan, err := cm.PlanUpgrade()
+	if err != nil {
+		return err
+	}
+	if !plan.Installed {
+		return nil
+	}
+	if plan.ExternallyManaged {
+		log.Info("Skipping cert-manager deletion, cert-manager is externally managed")
+		return nil
+	}
+
+	log.Info("Deleting cert-manager", "Version", prettifyCertManagerVersion(plan.From))
+	return cm.deleteObjs(func(o unstructured.Unstructured) bool {
+		return true
+	})
+}
+
+// waitForWebhook waits for the cert-manager web-hook to be available.
+func (cm *certManagerClient) waitForWebhook() error {
+	log := logf.Log
+
 	log.Info("Waiting for cert-manager to be available...")
 	if err := cm.pollImmediateWaiter(waitCertManagerInterval, cm.getWaitTimeout(), func() (bool, error) {
 		webhook, err := cm.getWebhook()
@@ -160,6 +369,23 @@ func (cm *certManagerClient) EnsureWebhook() error {
 	return nil
 }
 
+// isExternal returns true if cert-manager is configured as externally managed, and thus clusterctl
+// should not install, upgrade or delete it.
+func (cm *certManagerClient) isExternal() bool {
+	log := logf.Log
+
+	external, err := cm.configClient.Variables().Get(externalConfigKey)
+	if err != nil || external == "" {
+		return false
+	}
+	isExternal, err := strconv.ParseBool(external)
+	if err != nil {
+		log.Info("Invalid value set for ", externalConfigKey, external)
+		return false
+	}
+	return isExternal
+}
+
 func (cm *certManagerClient) getWaitTimeout() time.Duration {
 	log := logf.Log
 
@@ -204,12 +430,7 @@ func (cm *certManagerClient) createObj(o unstructured.Unstructured) error {
 		return err
 	}
 
-	labels := o.GetLabels()
-	if labels == nil {
-		labels = map[string]string{}
-	}
-	labels[clusterctlv1.ClusterctlCoreLabelName] = "cert-manager"
-	o.SetLabels(labels)
+	setCertManagerMetadata(&o)
 
 	if err = c.Create(ctx, &o); err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -220,6 +441,143 @@ func (cm *certManagerClient) createObj(o unstructured.Unstructured) error {
 	return nil
 }
 
+// applyObj creates a cert-manager object or updates the existing one.
+func (cm *certManagerClient) applyObj(o unstructured.Unstructured) error {
+	c, err := cm.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	setCertManagerMetadata(&o)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(o.GroupVersionKind())
+	key, err := client.ObjectKeyFromObject(&o)
+	if err != nil {
+		return err
+	}
+	if err := c.Get(ctx, key, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get cert-manager component: %s, %s/%s", o.GroupVersionKind(), o.GetNamespace(), o.GetName())
+		}
+		if err := c.Create(ctx, &o); err != nil {
+			return errors.Wrapf(err, "failed to create cert-manager component: %s, %s/%s", o.GroupVersionKind(), o.GetNamespace(), o.GetName())
+		}
+		return nil
+	}
+
+	// The cluster IP of a Service is immutable, so it should be preserved.
+	if o.GetKind() == "Service" {
+		clusterIP, _, _ := unstructured.NestedString(existing.Object, "spec", "clusterIP")
+		if clusterIP != "" {
+			if err := unstructured.SetNestedField(o.Object, clusterIP, "spec", "clusterIP"); err != nil {
+				return errors.Wrapf(err, "failed to set the cluster IP for cert-manager component: %s, %s/%s", o.GroupVersionKind(), o.GetNamespace(), o.GetName())
+			}
+		}
+	}
+
+	o.SetResourceVersion(existing.GetResourceVersion())
+	if err := c.Update(ctx, &o); err != nil {
+		return errors.Wrapf(err, "failed to update cert-manager component: %s, %s/%s", o.GroupVersionKind(), o.GetNamespace(), o.GetName())
+	}
+	return nil
+}
+
+// checkCRDs checks the new version of the cert-manager CRDs can safely replace the CRDs existing in the cluster.
+func (cm *certManagerClient) checkCRDs(objs []unstructured.Unstructured) error {
+	c, err := cm.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objs {
+		if o.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(o.GroupVersionKind())
+		if err := c.Get(ctx, client.ObjectKey{Name: o.GetName()}, existing); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get the CRD %s", o.GetName())
+		}
+
+		if err := checkCRDStoredVersions(*existing, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteObjs deletes the cert-manager objects installed by clusterctl that match the given filter.
+func (cm *certManagerClient) deleteObjs(filter func(o unstructured.Unstructured) bool) error {
+	log := logf.Log
+
+	// Nb. cert-manager installs also some leader election RBAC rules in the kube-system namespace.
+	labels := map[string]string{clusterctlv1.ClusterctlCoreLabelName: certManagerCoreLabelValue}
+	resources, err := cm.proxy.ListResources(labels, certManagerNamespace, metav1.NamespaceSystem)
+	if err != nil {
+		return err
+	}
+
+	resourcesToDelete := []unstructured.Unstructured{}
+	namespacesToDelete := sets.NewString()
+	for _, obj := range resources {
+		if !filter(obj) {
+			continue
+		}
+		if obj.GetKind() == "Namespace" {
+			namespacesToDelete.Insert(obj.GetName())
+		}
+		resourcesToDelete = append(resourcesToDelete, obj)
+	}
+
+	c, err := cm.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	errList := []error{}
+	for i := range resourcesToDelete {
+		obj := resourcesToDelete[i]
+
+		// if the objects is in a namespace that is going to be deleted, skip deletion
+		// because everything that is contained in the namespace will be deleted by the Namespace controller
+		if namespacesToDelete.Has(obj.GetNamespace()) {
+			continue
+		}
+
+		log.V(5).Info("Deleting", logf.UnstructuredToValues(obj)...)
+		if err := c.Delete(ctx, &obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			errList = append(errList, errors.Wrapf(err, "failed to delete cert-manager component: %s, %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName()))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// getDeployment returns the cert-manager Deployment or nil if it does not exists.
+func (cm *certManagerClient) getDeployment() (*appsv1.Deployment, error) {
+	c, err := cm.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	deployment := &appsv1.Deployment{}
+	key := client.ObjectKey{Namespace: certManagerNamespace, Name: certManagerDeploymentName}
+	if err := c.Get(ctx, key, deployment); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "failed to get the cert-manager Deployment")
+		}
+		return nil, nil
+	}
+	return deployment, nil
+}
+
 // getWebhook returns the cert-manager Webhook or nil if it does not exists.
 func (cm *certManagerClient) getWebhook() (*unstructured.Unstructured, error) {
 	c, err := cm.proxy.NewClient()
@@ -303,3 +661,69 @@ func (cm *certManagerClient) isWebhookAvailable(webhook *unstructured.Unstructur
 
 	return false, nil
 }
+
+// setCertManagerMetadata sets the clusterctl label and the version annotation on a cert-manager object.
+func setCertManagerMetadata(o *unstructured.Unstructured) {
+	labels := o.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[clusterctlv1.ClusterctlCoreLabelName] = certManagerCoreLabelValue
+	o.SetLabels(labels)
+
+	annotations := o.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterctlv1.CertManagerVersionAnnotation] = embeddedCertManagerVersion
+	o.SetAnnotations(annotations)
+}
+
+// checkCRDStoredVersions returns an error if the new version of a CRD does not serve all the versions
+// listed in the stored versions of the existing CRD.
+func checkCRDStoredVersions(existing, desired unstructured.Unstructured) error {
+	storedVersions, _, err := unstructured.NestedStringSlice(existing.Object, "status", "storedVersions")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the stored versions for the CRD %s", existing.GetName())
+	}
+
+	servedVersions := sets.NewString()
+	if v, ok, _ := unstructured.NestedString(desired.Object, "spec", "version"); ok && v != "" {
+		servedVersions.Insert(v)
+	}
+	versions, _, err := unstructured.NestedSlice(desired.Object, "spec", "versions")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the versions for the CRD %s", desired.GetName())
+	}
+	for _, v := range versions {
+		versionMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(versionMap, "name")
+		served, _, _ := unstructured.NestedBool(versionMap, "served")
+		if served {
+			servedVersions.Insert(name)
+		}
+	}
+
+	for _, v := range storedVersions {
+		if !servedVersions.Has(v) {
+			return errors.Errorf("failed to upgrade the CRD %s: the new version does not serve the %s version, which is used by objects stored in the cluster; please migrate the objects to a served version before upgrading", existing.GetName(), v)
+		}
+	}
+	return nil
+}
+
+// certManagerObjKey returns a key identifying a cert-manager object, e.g. apps/Deployment, cert-manager/cert-manager.
+func certManagerObjKey(o unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s, %s/%s", o.GroupVersionKind().Group, o.GetKind(), o.GetNamespace(), o.GetName())
+}
+
+// prettifyCertManagerVersion returns the given version or "unknown" if empty.
+func prettifyCertManagerVersion(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
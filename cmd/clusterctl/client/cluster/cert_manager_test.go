@@ -22,11 +22,19 @@ import (
 
 	. "github.com/onsi/gomega"
 	admissionregistration "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func Test_certManagerClient_getManifestObjects(t *testing.T) {
@@ -199,6 +207,14 @@ func (f fakeConfigClient) ImageMeta() config.ImageMetaClient {
 	return f.internalclient.ImageMeta()
 }
 
+func (f fakeConfigClient) Verification() config.VerificationClient {
+	return f.internalclient.Verification()
+}
+
+func (f fakeConfigClient) VersionConstraints() config.VersionConstraintsClient {
+	return f.internalclient.VersionConstraints()
+}
+
 func (f *fakeConfigClient) WithVar(key, value string) *fakeConfigClient {
 	f.fakeReader.WithVar(key, value)
 	return f
@@ -208,3 +224,231 @@ func (f *fakeConfigClient) WithProvider(provider config.Provider) *fakeConfigCli
 	f.fakeReader.WithProvider(provider.Name(), provider.Type(), provider.URL())
 	return f
 }
+
+func Test_certManagerClient_embeddedVersion(t *testing.T) {
+	g := NewWithT(t)
+
+	cm := newCertMangerClient(newFakeConfig(""), nil, nil)
+	objs, err := cm.getManifestObjs()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The embedded version should match the version of all the cert-manager images.
+	images, err := util.InspectImages(objs)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(images).ToNot(BeEmpty())
+	for _, image := range images {
+		g.Expect(image).To(HaveSuffix(":" + embeddedCertManagerVersion))
+	}
+}
+
+func fakeCertManagerDeployment(labels, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   certManagerNamespace,
+			Name:        certManagerDeploymentName,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+}
+
+func Test_certManagerClient_PlanUpgrade(t *testing.T) {
+	clusterctlLabels := map[string]string{clusterctlv1.ClusterctlCoreLabelName: certManagerCoreLabelValue}
+
+	tests := []struct {
+		name     string
+		external string
+		objs     []runtime.Object
+		want     CertManagerUpgradePlan
+	}{
+		{
+			name: "cert-manager not installed",
+			objs: []runtime.Object{},
+			want: CertManagerUpgradePlan{To: embeddedCertManagerVersion},
+		},
+		{
+			name:     "cert-manager configured as externally managed",
+			external: "true",
+			objs:     []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, nil)},
+			want:     CertManagerUpgradePlan{ExternallyManaged: true, To: embeddedCertManagerVersion},
+		},
+		{
+			name: "cert-manager not installed by clusterctl",
+			objs: []runtime.Object{fakeCertManagerDeployment(nil, nil)},
+			want: CertManagerUpgradePlan{Installed: true, ExternallyManaged: true, To: embeddedCertManagerVersion},
+		},
+		{
+			name: "cert-manager installed by clusterctl without version tracking",
+			objs: []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, nil)},
+			want: CertManagerUpgradePlan{Installed: true, To: embeddedCertManagerVersion, ShouldUpgrade: true},
+		},
+		{
+			name: "cert-manager installed by clusterctl with an older version",
+			objs: []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, map[string]string{clusterctlv1.CertManagerVersionAnnotation: "v0.10.0"})},
+			want: CertManagerUpgradePlan{Installed: true, From: "v0.10.0", To: embeddedCertManagerVersion, ShouldUpgrade: true},
+		},
+		{
+			name: "cert-manager installed by clusterctl with the embedded version",
+			objs: []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, map[string]string{clusterctlv1.CertManagerVersionAnnotation: embeddedCertManagerVersion})},
+			want: CertManagerUpgradePlan{Installed: true, From: embeddedCertManagerVersion, To: embeddedCertManagerVersion},
+		},
+		{
+			name: "cert-manager installed by clusterctl with a newer version",
+			objs: []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, map[string]string{clusterctlv1.CertManagerVersionAnnotation: "v1.0.0"})},
+			want: CertManagerUpgradePlan{Installed: true, From: "v1.0.0", To: embeddedCertManagerVersion},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configClient := newFakeConfig("")
+			configClient.WithVar(externalConfigKey, tt.external)
+
+			cm := newCertMangerClient(configClient, test.NewFakeProxy().WithObjs(tt.objs...), nil)
+			got, err := cm.PlanUpgrade()
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func fakeCertManagerCRD(storedVersion string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1beta1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("certificates.cert-manager.io")
+	crd.SetLabels(map[string]string{clusterctlv1.ClusterctlCoreLabelName: certManagerCoreLabelValue})
+	_ = unstructured.SetNestedStringSlice(crd.Object, []string{storedVersion}, "status", "storedVersions")
+	return crd
+}
+
+func Test_certManagerClient_EnsureLatestVersion(t *testing.T) {
+	clusterctlLabels := map[string]string{clusterctlv1.ClusterctlCoreLabelName: certManagerCoreLabelValue}
+	oldVersion := map[string]string{clusterctlv1.CertManagerVersionAnnotation: "v0.10.0"}
+
+	staleConfigMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   certManagerNamespace,
+			Name:        "stale",
+			Labels:      clusterctlLabels,
+			Annotations: oldVersion,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		objs          []runtime.Object
+		wantErr       bool
+		wantVersion   string
+		wantStaleObjs bool
+	}{
+		{
+			name:          "does not upgrade if the embedded version is installed",
+			objs:          []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, map[string]string{clusterctlv1.CertManagerVersionAnnotation: embeddedCertManagerVersion}), staleConfigMap, fakeCertManagerCRD("v1alpha2")},
+			wantErr:       false,
+			wantVersion:   embeddedCertManagerVersion,
+			wantStaleObjs: true,
+		},
+		{
+			name:          "upgrades cert-manager and deletes objects of the previous version, preserving CRDs",
+			objs:          []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, oldVersion), staleConfigMap, fakeCertManagerCRD("v1alpha2")},
+			wantErr:       false,
+			wantVersion:   embeddedCertManagerVersion,
+			wantStaleObjs: false,
+		},
+		{
+			name:    "fails if the new CRDs do not serve a stored version",
+			objs:    []runtime.Object{fakeCertManagerDeployment(clusterctlLabels, oldVersion), fakeCertManagerCRD("v1alpha1")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			pollImmediateWaiter := func(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+				return nil
+			}
+			proxy := test.NewFakeProxy().WithObjs(tt.objs...)
+			cm := newCertMangerClient(newFakeConfig(""), proxy, pollImmediateWaiter)
+
+			err := cm.EnsureLatestVersion()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			deployment, err := cm.getDeployment()
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(deployment.Annotations).To(HaveKeyWithValue(clusterctlv1.CertManagerVersionAnnotation, tt.wantVersion))
+
+			c, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+			err = c.Get(ctx, client.ObjectKey{Namespace: certManagerNamespace, Name: "stale"}, &corev1.ConfigMap{})
+			if tt.wantStaleObjs {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+
+			// CRDs should never be deleted.
+			crd := fakeCertManagerCRD("")
+			g.Expect(c.Get(ctx, client.ObjectKey{Name: crd.GetName()}, crd)).To(Succeed())
+		})
+	}
+}
+
+func Test_certManagerClient_Delete(t *testing.T) {
+	clusterctlLabels := map[string]string{clusterctlv1.ClusterctlCoreLabelName: certManagerCoreLabelValue}
+
+	tests := []struct {
+		name        string
+		external    string
+		labels      map[string]string
+		wantDeleted bool
+	}{
+		{
+			name:        "deletes cert-manager installed by clusterctl",
+			labels:      clusterctlLabels,
+			wantDeleted: true,
+		},
+		{
+			name:        "does not delete cert-manager not installed by clusterctl",
+			labels:      nil,
+			wantDeleted: false,
+		},
+		{
+			name:        "does not delete cert-manager configured as externally managed",
+			external:    "true",
+			labels:      clusterctlLabels,
+			wantDeleted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configClient := newFakeConfig("")
+			configClient.WithVar(externalConfigKey, tt.external)
+
+			proxy := test.NewFakeProxy().WithObjs(fakeCertManagerDeployment(tt.labels, nil))
+			cm := newCertMangerClient(configClient, proxy, nil)
+
+			g.Expect(cm.Delete()).To(Succeed())
+
+			deployment, err := cm.getDeployment()
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(deployment == nil).To(Equal(tt.wantDeleted))
+		})
+	}
+}
@@ -86,6 +86,24 @@ type Client interface {
 
 	// Template has methods to work with templates stored in the cluster.
 	Template() TemplateClient
+
+	// ClusterDescriber returns a ClusterDescriber that supports describing a workload cluster and all the objects it is composed of.
+	ClusterDescriber() ClusterDescriber
+
+	// Rollout returns a RolloutClient that supports managing the rollout of MachineDeployments.
+	Rollout() RolloutClient
+
+	// WorkloadCluster returns a WorkloadCluster that supports accessing the workload clusters managed by the management cluster.
+	WorkloadCluster() WorkloadCluster
+
+	// KubernetesUpgrader returns a KubernetesUpgrader that supports upgrading the Kubernetes version of workload clusters.
+	KubernetesUpgrader() KubernetesUpgrader
+
+	// Doctor returns a Doctor that supports checking the health and the consistency of the management cluster.
+	Doctor() Doctor
+
+	// ClusterDeleter returns a ClusterDeleter that supports deleting workload clusters.
+	ClusterDeleter() ClusterDeleter
 }
 
 // PollImmediateWaiter tries a condition func until it returns true, an error, or the timeout is reached.
@@ -142,6 +160,30 @@ func (c *clusterClient) Template() TemplateClient {
 	return newTemplateClient(TemplateClientInput{c.proxy, c.configClient, c.processor})
 }
 
+func (c *clusterClient) ClusterDescriber() ClusterDescriber {
+	return newClusterDescriber(c.proxy)
+}
+
+func (c *clusterClient) Rollout() RolloutClient {
+	return newRolloutClient(c.proxy, c.pollImmediateWaiter)
+}
+
+func (c *clusterClient) WorkloadCluster() WorkloadCluster {
+	return newWorkloadCluster(c.proxy, c.pollImmediateWaiter)
+}
+
+func (c *clusterClient) KubernetesUpgrader() KubernetesUpgrader {
+	return newKubernetesUpgrader(c.proxy, c.pollImmediateWaiter)
+}
+
+func (c *clusterClient) Doctor() Doctor {
+	return newDoctor(c.proxy, c.ProviderInventory())
+}
+
+func (c *clusterClient) ClusterDeleter() ClusterDeleter {
+	return newClusterDeleter(c.proxy, c.pollImmediateWaiter)
+}
+
 // Option is a configuration option supplied to New
 type Option func(*clusterClient)
 
@@ -0,0 +1,312 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	waitClusterDeletionInterval = 10 * time.Second
+	waitClusterDeletionTimeout  = 30 * time.Minute
+)
+
+// ClusterDeleteOptions carries the options supported by ClusterDeleter.
+type ClusterDeleteOptions struct {
+	// Wait for all the objects in the object graph of the Cluster to be deleted.
+	Wait bool
+
+	// Timeout is the maximum time to wait for the objects to be deleted.
+	// If zero, a default timeout of 30 minutes is used.
+	Timeout time.Duration
+}
+
+// RemainingObject describes an object in the object graph of a Cluster that still exists when the deletion times out.
+type RemainingObject struct {
+	// Object is a reference to the remaining object.
+	Object corev1.ObjectReference `json:"object"`
+
+	// DeletionTimestamp is the time the deletion of the object was requested; if nil, the deletion of the object
+	// did not start yet.
+	DeletionTimestamp *metav1.Time `json:"deletionTimestamp,omitempty"`
+
+	// Finalizers are the finalizers still blocking the deletion of the object.
+	Finalizers []string `json:"finalizers,omitempty"`
+
+	// FailureMessages are the failure messages and the messages of the false conditions reported by the object.
+	FailureMessages []string `json:"failureMessages,omitempty"`
+}
+
+// ClusterDeleter defines methods for deleting workload clusters.
+type ClusterDeleter interface {
+	// Delete deletes a Cluster and, if requested, waits for all the objects in its object graph to be deleted, reporting
+	// the progress of the deletion in the logs. If the objects are not deleted before the timeout, it returns the objects
+	// still existing along with an error.
+	Delete(namespace, name string, options ClusterDeleteOptions) ([]RemainingObject, error)
+}
+
+// clusterDeleter implements ClusterDeleter.
+type clusterDeleter struct {
+	proxy               Proxy
+	pollImmediateWaiter PollImmediateWaiter
+}
+
+// ensure clusterDeleter implements ClusterDeleter.
+var _ ClusterDeleter = &clusterDeleter{}
+
+func newClusterDeleter(proxy Proxy, pollImmediateWaiter PollImmediateWaiter) *clusterDeleter {
+	return &clusterDeleter{
+		proxy:               proxy,
+		pollImmediateWaiter: pollImmediateWaiter,
+	}
+}
+
+func (d *clusterDeleter) Delete(namespace, name string, options ClusterDeleteOptions) ([]RemainingObject, error) {
+	// Gets all the types defines by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
+	discoveryTypes, err := newObjectGraph(d.proxy).getDiscoveryTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.delete(namespace, name, discoveryTypes, options)
+}
+
+// delete deletes a Cluster, tracking the objects of the given types in the object graph of the Cluster.
+func (d *clusterDeleter) delete(namespace, name string, discoveryTypes []metav1.TypeMeta, options ClusterDeleteOptions) ([]RemainingObject, error) {
+	log := logf.Log
+
+	c, err := d.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &clusterv1.Cluster{}
+	clusterKey := client.ObjectKey{Namespace: namespace, Name: name}
+	if err := c.Get(ctx, clusterKey, cluster); err != nil {
+		return nil, errors.Wrapf(err, "failed to get Cluster %s/%s", namespace, name)
+	}
+
+	// Gets the objects in the object graph of the Cluster before deleting it, so it is possible to keep track of them
+	// also after the Cluster object is gone.
+	objs, err := d.getClusterObjects(namespace, name, discoveryTypes, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cluster.DeletionTimestamp.IsZero() {
+		log.Info("Deleting", "Cluster", name, "Namespace", namespace, "Objects", len(objs))
+		deleteBackoff := newWriteBackoff()
+		if err := retryWithExponentialBackoff(deleteBackoff, func() error {
+			if err := c.Delete(ctx, cluster); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			return nil
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to delete Cluster %s/%s", namespace, name)
+		}
+	} else {
+		log.Info("Cluster is already being deleted", "Cluster", name, "Namespace", namespace, "Objects", len(objs))
+	}
+
+	if !options.Wait {
+		return nil, nil
+	}
+
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = waitClusterDeletionTimeout
+	}
+
+	log.Info("Waiting for the Cluster objects to be deleted", "Cluster", name, "Namespace", namespace, "Timeout", timeout.String())
+	if err := d.pollImmediateWaiter(waitClusterDeletionInterval, timeout, func() (bool, error) {
+		current, err := d.getClusterObjects(namespace, name, discoveryTypes, objs)
+		if err != nil {
+			//Nb. we are ignoring the error so the pollImmediateWaiter will execute another retry
+			log.V(5).Info("Failed to get the Cluster objects", "Error", err.Error())
+			return false, nil
+		}
+		logDeletionProgress(objs, current)
+		objs = current
+		return len(objs) == 0, nil
+	}); err != nil {
+		return getRemainingObjects(objs), errors.Wrapf(err, "failed to wait for Cluster %s/%s to be deleted", namespace, name)
+	}
+
+	log.Info("Cluster deleted", "Cluster", name, "Namespace", namespace)
+	return nil, nil
+}
+
+// clusterObjects maps the UIDs of the objects in the object graph of a Cluster to the corresponding objects.
+type clusterObjects map[types.UID]*unstructured.Unstructured
+
+// getClusterObjects returns the objects of the given types in the object graph of a Cluster, plus the tracked objects
+// still existing, so the objects are reported until they are actually deleted, no matter if the Cluster is already gone.
+func (d *clusterDeleter) getClusterObjects(namespace, name string, discoveryTypes []metav1.TypeMeta, tracked clusterObjects) (clusterObjects, error) {
+	graph := newObjectGraph(d.proxy)
+	objs := clusterObjects{}
+
+	selectors := []client.ListOption{client.InNamespace(namespace)}
+	for _, typeMeta := range discoveryTypes {
+		objList := new(unstructured.UnstructuredList)
+		if err := getObjList(d.proxy, typeMeta, selectors, objList); err != nil {
+			return nil, err
+		}
+		for i := range objList.Items {
+			obj := &objList.Items[i]
+			objs[obj.GetUID()] = obj
+			graph.addObj(obj)
+		}
+	}
+
+	// Nb. soft ownership is ignored, because Secrets without OwnerReferences are not deleted together with the Cluster.
+	graph.setClusterTenants()
+
+	ret := clusterObjects{}
+	for uid, obj := range objs {
+		if _, ok := tracked[uid]; ok || isClusterTenant(graph.uidToNode[uid], namespace, name) {
+			ret[uid] = obj
+		}
+	}
+	return ret, nil
+}
+
+// isClusterTenant returns true if the node belongs to the Cluster with the given namespace and name.
+func isClusterTenant(n *node, namespace, name string) bool {
+	for tenant := range n.tenantClusters {
+		if tenant.identity.Namespace == namespace && tenant.identity.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// logDeletionProgress logs the changes between two snapshots of the objects of a Cluster being deleted.
+func logDeletionProgress(previous, current clusterObjects) {
+	log := logf.Log
+
+	for _, obj := range sortedClusterObjects(previous) {
+		next, ok := current[obj.GetUID()]
+		if !ok {
+			log.Info("Deleted", "Kind", obj.GetKind(), "Name", obj.GetName())
+			continue
+		}
+
+		if obj.GetDeletionTimestamp() == nil && next.GetDeletionTimestamp() != nil {
+			if nodeName, ok := getDrainedNodeName(next); ok {
+				log.Info("Draining", "Kind", next.GetKind(), "Name", next.GetName(), "Node", nodeName)
+			} else {
+				log.Info("Deleting", "Kind", next.GetKind(), "Name", next.GetName())
+			}
+		}
+
+		if removed := sets.NewString(obj.GetFinalizers()...).Difference(sets.NewString(next.GetFinalizers()...)); removed.Len() > 0 {
+			log.Info("Finalizers removed", "Kind", next.GetKind(), "Name", next.GetName(), "Finalizers", strings.Join(removed.List(), ", "))
+		}
+
+		previousMessages := sets.NewString(getFailureMessages(obj)...)
+		for _, message := range getFailureMessages(next) {
+			if !previousMessages.Has(message) {
+				log.Info("Deletion blocked", "Kind", next.GetKind(), "Name", next.GetName(), "Message", message)
+			}
+		}
+	}
+}
+
+// getDrainedNodeName returns the name of the Node drained before deleting a Machine, if any.
+func getDrainedNodeName(obj *unstructured.Unstructured) (string, bool) {
+	if obj.GroupVersionKind().GroupKind() != clusterv1.GroupVersion.WithKind("Machine").GroupKind() {
+		return "", false
+	}
+	if _, ok := obj.GetAnnotations()[clusterv1.ExcludeNodeDrainingAnnotation]; ok {
+		return "", false
+	}
+	nodeName, ok, _ := unstructured.NestedString(obj.Object, "status", "nodeRef", "name")
+	return nodeName, ok && nodeName != ""
+}
+
+// getFailureMessages returns the failure reason and message, and the messages of the false conditions
+// with warning or error severity reported by an object.
+func getFailureMessages(obj *unstructured.Unstructured) []string {
+	var ret []string
+	if reason, _, _ := unstructured.NestedString(obj.Object, "status", "failureReason"); reason != "" {
+		ret = append(ret, fmt.Sprintf("FailureReason: %s", reason))
+	}
+	if message, _, _ := unstructured.NestedString(obj.Object, "status", "failureMessage"); message != "" {
+		ret = append(ret, fmt.Sprintf("FailureMessage: %s", message))
+	}
+	for _, condition := range conditions.UnstructuredGetter(obj).GetConditions() {
+		// The Ready condition is skipped, because it summarizes the other conditions.
+		if condition.Type == clusterv1.ReadyCondition || condition.Status != corev1.ConditionFalse || condition.Message == "" {
+			continue
+		}
+		if condition.Severity != clusterv1.ConditionSeverityWarning && condition.Severity != clusterv1.ConditionSeverityError {
+			continue
+		}
+		ret = append(ret, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+	}
+	return ret
+}
+
+// getRemainingObjects returns the list of objects still existing, sorted by kind and name.
+func getRemainingObjects(objs clusterObjects) []RemainingObject {
+	ret := []RemainingObject{}
+	for _, obj := range sortedClusterObjects(objs) {
+		ret = append(ret, RemainingObject{
+			Object: corev1.ObjectReference{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Namespace:  obj.GetNamespace(),
+				Name:       obj.GetName(),
+				UID:        obj.GetUID(),
+			},
+			DeletionTimestamp: obj.GetDeletionTimestamp(),
+			Finalizers:        obj.GetFinalizers(),
+			FailureMessages:   getFailureMessages(obj),
+		})
+	}
+	return ret
+}
+
+// sortedClusterObjects returns the objects sorted by kind and name.
+func sortedClusterObjects(objs clusterObjects) []*unstructured.Unstructured {
+	ret := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		ret = append(ret, obj)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].GetKind() != ret[j].GetKind() {
+			return ret[i].GetKind() < ret[j].GetKind()
+		}
+		return ret[i].GetName() < ret[j].GetName()
+	})
+	return ret
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_clusterDeleter_delete(t *testing.T) {
+	type args struct {
+		clusterName string
+		options     ClusterDeleteOptions
+	}
+	tests := []struct {
+		name string
+		args args
+		// pollsBeforeDeletion is the number of polls before all the objects in the graph are deleted; if negative, objects are never deleted.
+		pollsBeforeDeletion int
+		wantRemaining       []string
+		wantErr             bool
+	}{
+		{
+			name: "deletes the cluster without waiting",
+			args: args{
+				clusterName: "cluster1",
+				options:     ClusterDeleteOptions{Wait: false},
+			},
+			pollsBeforeDeletion: -1,
+			wantRemaining:       nil,
+			wantErr:             false,
+		},
+		{
+			name: "deletes the cluster and waits for the objects to be deleted",
+			args: args{
+				clusterName: "cluster1",
+				options:     ClusterDeleteOptions{Wait: true},
+			},
+			pollsBeforeDeletion: 2,
+			wantRemaining:       nil,
+			wantErr:             false,
+		},
+		{
+			name: "reports the remaining objects on timeout",
+			args: args{
+				clusterName: "cluster1",
+				options:     ClusterDeleteOptions{Wait: true, Timeout: time.Minute},
+			},
+			pollsBeforeDeletion: -1,
+			wantRemaining: []string{
+				"GenericBootstrapConfig/m1",
+				"GenericInfrastructureCluster/cluster1",
+				"GenericInfrastructureMachine/m1",
+				"Machine/m1",
+				"Secret/cluster1-kubeconfig",
+				"Secret/cluster1-sa",
+				"Secret/m1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the cluster does not exist",
+			args: args{
+				clusterName: "does-not-exist",
+				options:     ClusterDeleteOptions{Wait: true},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs := test.NewFakeCluster("ns1", "cluster1").
+				WithMachines(test.NewFakeMachine("m1")).
+				Objs()
+			objs = withBlockedMachine(objs, "m1")
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(objs)
+
+			// Get all the types to be considered for discovery
+			discoveryTypes, err := getFakeDiscoveryTypes(graph)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c, err := graph.proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			polls := 0
+			pollImmediateWaiter := func(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+				for {
+					if polls == tt.pollsBeforeDeletion {
+						// Simulates the controllers deleting all the objects in the graph.
+						for _, o := range objs {
+							_ = c.Delete(ctx, o.DeepCopyObject())
+						}
+					}
+					polls++
+
+					done, err := condition()
+					if err != nil {
+						return err
+					}
+					if done {
+						return nil
+					}
+					if tt.pollsBeforeDeletion < 0 {
+						return wait.ErrWaitTimeout
+					}
+				}
+			}
+
+			d := newClusterDeleter(graph.proxy, pollImmediateWaiter)
+			remaining, err := d.delete("ns1", tt.args.clusterName, discoveryTypes, tt.args.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+
+			var got []string
+			for _, r := range remaining {
+				got = append(got, r.Object.Kind+"/"+r.Object.Name)
+				if r.Object.Kind == "Machine" {
+					g.Expect(r.Finalizers).To(ConsistOf(clusterv1.MachineFinalizer))
+					g.Expect(r.FailureMessages).To(ConsistOf(
+						"FailureMessage: failed to delete the instance",
+						"InfrastructureReady: instance termination is stuck",
+					))
+				}
+			}
+			g.Expect(got).To(Equal(tt.wantRemaining))
+
+			if tt.args.clusterName == "cluster1" {
+				err = c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "cluster1"}, &clusterv1.Cluster{})
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+	}
+}
+
+// withBlockedMachine sets a finalizer and failure messages on the Machine with the given name.
+func withBlockedMachine(objs []runtime.Object, machineName string) []runtime.Object {
+	for _, o := range objs {
+		if m, ok := o.(*clusterv1.Machine); ok && m.Name == machineName {
+			m.Finalizers = []string{clusterv1.MachineFinalizer}
+			m.Status.FailureMessage = pointer.StringPtr("failed to delete the instance")
+			conditions.MarkFalse(m, clusterv1.InfrastructureReadyCondition, "Deleting", clusterv1.ConditionSeverityWarning, "instance termination is stuck")
+		}
+	}
+	return objs
+}
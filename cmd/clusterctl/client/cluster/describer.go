@@ -0,0 +1,303 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DescribeOptions carries the options supported by ClusterDescriber.Describe.
+type DescribeOptions struct {
+	// ShowOtherConditions adds to each node of the tree all the object's conditions, not only the Ready condition.
+	ShowOtherConditions bool
+
+	// DisableGrouping disables grouping sibling Machines with the same Ready condition status, severity and reason.
+	DisableGrouping bool
+}
+
+// ObjectTree defines the tree of objects composing a workload cluster, as returned by ClusterDescriber.Describe.
+type ObjectTree struct {
+	// Root of the tree, representing the Cluster object.
+	Root *ObjectTreeNode `json:"root"`
+}
+
+// ObjectTreeNode defines a node in the ObjectTree.
+type ObjectTreeNode struct {
+	// Object is a reference to the Kubernetes object represented by this node.
+	// If the node represents a group of objects, Name is empty.
+	Object corev1.ObjectReference `json:"object"`
+
+	// CreationTimestamp is the creation timestamp of the object; for a group of objects it is the creation
+	// timestamp of the oldest object in the group.
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+
+	// ReadyCondition is the Ready condition of the object, if any.
+	ReadyCondition *clusterv1.Condition `json:"readyCondition,omitempty"`
+
+	// OtherConditions are the conditions of the object other than Ready.
+	// This field is populated only when DescribeOptions.ShowOtherConditions is set.
+	OtherConditions clusterv1.Conditions `json:"otherConditions,omitempty"`
+
+	// GroupItems contains the names of the objects represented by this node, if the node represents a group of objects.
+	GroupItems []string `json:"groupItems,omitempty"`
+
+	// Children are the nodes for the objects owned by this node.
+	Children []*ObjectTreeNode `json:"children,omitempty"`
+}
+
+// IsGroup returns true if the node represents a group of objects.
+func (n *ObjectTreeNode) IsGroup() bool {
+	return len(n.GroupItems) > 0
+}
+
+// ClusterDescriber defines methods for describing a workload cluster and all the objects it is composed of.
+type ClusterDescriber interface {
+	// Describe returns the tree of the objects composing a workload cluster, built following the OwnerReference chain
+	// starting from the Cluster object.
+	Describe(namespace, name string, options DescribeOptions) (*ObjectTree, error)
+}
+
+// clusterDescriber implements the ClusterDescriber interface.
+type clusterDescriber struct {
+	proxy Proxy
+}
+
+// ensure clusterDescriber implements the ClusterDescriber interface.
+var _ ClusterDescriber = &clusterDescriber{}
+
+func newClusterDescriber(proxy Proxy) *clusterDescriber {
+	return &clusterDescriber{
+		proxy: proxy,
+	}
+}
+
+func (d *clusterDescriber) Describe(namespace, name string, options DescribeOptions) (*ObjectTree, error) {
+	objectGraph := newObjectGraph(d.proxy)
+
+	// Gets all the types defines by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
+	types, err := objectGraph.getDiscoveryTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Discovery the object graph for the selected types; this is the same graph used by move,
+	// with nodes defined by the Kubernetes objects and edges derived by the OwnerReferences between nodes.
+	if err := objectGraph.Discovery(namespace, types); err != nil {
+		return nil, err
+	}
+
+	return d.describe(objectGraph, namespace, name, options)
+}
+
+// describe builds the ObjectTree for a Cluster out of an object graph.
+func (d *clusterDescriber) describe(graph *objectGraph, namespace, name string, options DescribeOptions) (*ObjectTree, error) {
+	log := logf.Log
+
+	var clusterNode *node
+	for _, cluster := range graph.getClusters() {
+		if cluster.identity.Namespace == namespace && cluster.identity.Name == name {
+			clusterNode = cluster
+			break
+		}
+	}
+	if clusterNode == nil {
+		return nil, errors.Errorf("failed to find Cluster %s/%s", namespace, name)
+	}
+
+	log.V(1).Info("Building the object tree", "Cluster", name, "Namespace", namespace)
+
+	// Gets the list of objects owned by each node, so the tree can be built top down.
+	childrenMap := map[*node][]*node{}
+	for _, n := range graph.getNodes() {
+		if n.virtual || !isDescribedObject(n) {
+			continue
+		}
+		if parent := getTreeParent(n); parent != nil {
+			childrenMap[parent] = append(childrenMap[parent], n)
+		}
+	}
+
+	c, err := d.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := d.buildTreeNode(c, clusterNode, childrenMap, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectTree{Root: root}, nil
+}
+
+// buildTreeNode creates the ObjectTreeNode corresponding to an object graph node and to all its descendants.
+func (d *clusterDescriber) buildTreeNode(c client.Client, n *node, childrenMap map[*node][]*node, options DescribeOptions) (*ObjectTreeNode, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(n.identity.APIVersion)
+	obj.SetKind(n.identity.Kind)
+	objKey := client.ObjectKey{
+		Namespace: n.identity.Namespace,
+		Name:      n.identity.Name,
+	}
+
+	readObjBackoff := newReadBackoff()
+	if err := retryWithExponentialBackoff(readObjBackoff, func() error {
+		return c.Get(ctx, objKey, obj)
+	}); err != nil {
+		return nil, errors.Wrapf(err, "error reading %q %s/%s",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+
+	treeNode := &ObjectTreeNode{
+		Object:            n.identity,
+		CreationTimestamp: obj.GetCreationTimestamp(),
+	}
+
+	for _, condition := range conditions.UnstructuredGetter(obj).GetConditions() {
+		condition := condition
+		if condition.Type == clusterv1.ReadyCondition {
+			treeNode.ReadyCondition = &condition
+			continue
+		}
+		if options.ShowOtherConditions {
+			treeNode.OtherConditions = append(treeNode.OtherConditions, condition)
+		}
+	}
+
+	for _, child := range childrenMap[n] {
+		childTreeNode, err := d.buildTreeNode(c, child, childrenMap, options)
+		if err != nil {
+			return nil, err
+		}
+		treeNode.Children = append(treeNode.Children, childTreeNode)
+	}
+
+	if !options.DisableGrouping {
+		treeNode.Children = groupMachines(treeNode.Children)
+	}
+	sortTreeNodes(treeNode.Children)
+
+	return treeNode, nil
+}
+
+// isDescribedObject returns true if the object corresponding to the node should be included in the ObjectTree.
+// Secrets, ConfigMaps and templates are not included given that they are not reconciled and do not report conditions.
+func isDescribedObject(n *node) bool {
+	if n.identity.APIVersion == "v1" && (n.identity.Kind == "Secret" || n.identity.Kind == "ConfigMap") {
+		return false
+	}
+	return !strings.HasSuffix(n.identity.Kind, "Template")
+}
+
+// getTreeParent returns the node to be used as a parent in the ObjectTree; if a node has more than one
+// owner, the controller owner is preferred, otherwise the first owner ordered by Kind, Namespace and Name is used.
+func getTreeParent(n *node) *node {
+	var owners []*node
+	for owner, attributes := range n.owners {
+		if attributes.Controller != nil && *attributes.Controller {
+			return owner
+		}
+		owners = append(owners, owner)
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		a, b := owners[i].identity, owners[j].identity
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return owners[0]
+}
+
+// groupMachines groups sibling Machines with the same Ready condition status, severity and reason.
+// The children of grouped Machines are dropped from the tree.
+func groupMachines(nodes []*ObjectTreeNode) []*ObjectTreeNode {
+	var ret []*ObjectTreeNode
+	groups := map[string]*ObjectTreeNode{}
+	var groupKeys []string
+	for _, n := range nodes {
+		if n.Object.GroupVersionKind().GroupKind() != clusterv1.GroupVersion.WithKind("Machine").GroupKind() {
+			ret = append(ret, n)
+			continue
+		}
+
+		key := readyConditionKey(n.ReadyCondition)
+		group, ok := groups[key]
+		if !ok {
+			groups[key] = n
+			groupKeys = append(groupKeys, key)
+			continue
+		}
+
+		// Converts the first Machine in the group into a group node.
+		if !group.IsGroup() {
+			group.GroupItems = []string{group.Object.Name}
+			group.Object.Name = ""
+			group.Object.UID = ""
+			group.OtherConditions = nil
+			group.Children = nil
+		}
+		group.GroupItems = append(group.GroupItems, n.Object.Name)
+		if n.CreationTimestamp.Before(&group.CreationTimestamp) {
+			group.CreationTimestamp = n.CreationTimestamp
+		}
+	}
+
+	for _, key := range groupKeys {
+		group := groups[key]
+		sort.Strings(group.GroupItems)
+		ret = append(ret, group)
+	}
+	return ret
+}
+
+// readyConditionKey returns a key identifying the state of a Ready condition, if any.
+func readyConditionKey(c *clusterv1.Condition) string {
+	if c == nil {
+		return ""
+	}
+	return strings.Join([]string{string(c.Status), string(c.Severity), c.Reason}, "/")
+}
+
+// sortTreeNodes sorts nodes by Kind and Name, so the ObjectTree is always consistent.
+func sortTreeNodes(nodes []*ObjectTreeNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Object.Kind != nodes[j].Object.Kind {
+			return nodes[i].Object.Kind < nodes[j].Object.Kind
+		}
+		if nodes[i].Object.Name != nodes[j].Object.Name {
+			return nodes[i].Object.Name < nodes[j].Object.Name
+		}
+		return strings.Join(nodes[i].GroupItems, ",") < strings.Join(nodes[j].GroupItems, ",")
+	})
+}
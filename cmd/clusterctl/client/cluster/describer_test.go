@@ -0,0 +1,264 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func Test_clusterDescriber_describe(t *testing.T) {
+	type args struct {
+		objs        []runtime.Object
+		clusterName string
+		options     DescribeOptions
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantTree []string
+		wantErr  bool
+	}{
+		{
+			name: "Cluster with control plane and machine deployment, grouping machines",
+			args: args{
+				objs: test.NewFakeCluster("ns1", "cluster1").
+					WithControlPlane(
+						test.NewFakeControlPlane("cp1").
+							WithMachines(
+								test.NewFakeMachine("cp1-m1"),
+							),
+					).
+					WithMachineDeployments(
+						test.NewFakeMachineDeployment("md1").
+							WithMachineSets(
+								test.NewFakeMachineSet("ms1").
+									WithMachines(
+										test.NewFakeMachine("m1"),
+										test.NewFakeMachine("m2"),
+									),
+							),
+					).Objs(),
+				clusterName: "cluster1",
+			},
+			wantTree: []string{
+				"Cluster/cluster1",
+				"  GenericControlPlane/cp1",
+				"    Machine/cp1-m1",
+				"      GenericBootstrapConfig/cp1-m1",
+				"      GenericInfrastructureMachine/cp1-m1",
+				"  GenericInfrastructureCluster/cluster1",
+				"  MachineDeployment/md1",
+				"    MachineSet/ms1",
+				"      Machine/[m1 m2]",
+			},
+		},
+		{
+			name: "Cluster with machines, grouping disabled",
+			args: args{
+				objs: test.NewFakeCluster("ns1", "cluster1").
+					WithMachines(
+						test.NewFakeMachine("m1"),
+						test.NewFakeMachine("m2"),
+					).Objs(),
+				clusterName: "cluster1",
+				options: DescribeOptions{
+					DisableGrouping: true,
+				},
+			},
+			wantTree: []string{
+				"Cluster/cluster1",
+				"  GenericInfrastructureCluster/cluster1",
+				"  Machine/m1",
+				"    GenericBootstrapConfig/m1",
+				"    GenericInfrastructureMachine/m1",
+				"  Machine/m2",
+				"    GenericBootstrapConfig/m2",
+				"    GenericInfrastructureMachine/m2",
+			},
+		},
+		{
+			name: "Cluster with machines in different states, grouping machines",
+			args: args{
+				objs: withMachineReadyCondition(
+					test.NewFakeCluster("ns1", "cluster1").
+						WithMachines(
+							test.NewFakeMachine("m1"),
+							test.NewFakeMachine("m2"),
+							test.NewFakeMachine("m3"),
+						).Objs(),
+					"m3", conditions.FalseCondition(clusterv1.ReadyCondition, "Provisioning", clusterv1.ConditionSeverityInfo, ""),
+				),
+				clusterName: "cluster1",
+			},
+			wantTree: []string{
+				"Cluster/cluster1",
+				"  GenericInfrastructureCluster/cluster1",
+				"  Machine/[m1 m2]",
+				"  Machine/m3 (False, Provisioning)",
+				"    GenericBootstrapConfig/m3",
+				"    GenericInfrastructureMachine/m3",
+			},
+		},
+		{
+			name: "Other conditions are reported only if required",
+			args: args{
+				objs: withMachineReadyCondition(
+					test.NewFakeCluster("ns1", "cluster1").
+						WithMachines(
+							test.NewFakeMachine("m1"),
+						).Objs(),
+					"m1", conditions.TrueCondition(clusterv1.BootstrapReadyCondition),
+				),
+				clusterName: "cluster1",
+				options: DescribeOptions{
+					ShowOtherConditions: true,
+				},
+			},
+			wantTree: []string{
+				"Cluster/cluster1",
+				"  GenericInfrastructureCluster/cluster1",
+				"  Machine/m1 [BootstrapReady]",
+				"    GenericBootstrapConfig/m1",
+				"    GenericInfrastructureMachine/m1",
+			},
+		},
+		{
+			name: "Fails if the cluster does not exist",
+			args: args{
+				objs:        test.NewFakeCluster("ns1", "cluster1").Objs(),
+				clusterName: "does-not-exist",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(tt.args.objs)
+
+			// Get all the types to be considered for discovery
+			discoveryTypes, err := getFakeDiscoveryTypes(graph)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("ns1", discoveryTypes)).To(Succeed())
+
+			describer := newClusterDescriber(graph.proxy)
+			got, err := describer.describe(graph, "ns1", tt.args.clusterName, tt.args.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(treeToStrings(got.Root, "")).To(Equal(tt.wantTree))
+		})
+	}
+}
+
+// withMachineReadyCondition sets a condition on the Machine with the given name.
+func withMachineReadyCondition(objs []runtime.Object, machineName string, condition *clusterv1.Condition) []runtime.Object {
+	for _, o := range objs {
+		if m, ok := o.(*clusterv1.Machine); ok && m.Name == machineName {
+			conditions.Set(m, condition)
+		}
+	}
+	return objs
+}
+
+// treeToStrings returns a compact representation of an ObjectTree, using indentation for children.
+func treeToStrings(n *ObjectTreeNode, indent string) []string {
+	name := n.Object.Name
+	if n.IsGroup() {
+		name = fmt.Sprintf("%v", n.GroupItems)
+	}
+	s := fmt.Sprintf("%s%s/%s", indent, n.Object.Kind, name)
+	if n.ReadyCondition != nil {
+		s = fmt.Sprintf("%s (%s, %s)", s, n.ReadyCondition.Status, n.ReadyCondition.Reason)
+	}
+	if len(n.OtherConditions) > 0 {
+		var types []string
+		for _, c := range n.OtherConditions {
+			types = append(types, string(c.Type))
+		}
+		s = fmt.Sprintf("%s [%s]", s, strings.Join(types, " "))
+	}
+
+	ret := []string{s}
+	for _, c := range n.Children {
+		ret = append(ret, treeToStrings(c, indent+"  ")...)
+	}
+	return ret
+}
+
+func Test_getTreeParent(t *testing.T) {
+	newNode := func(kind, name, uid string) *node {
+		return &node{identity: corev1.ObjectReference{Kind: kind, Namespace: "ns1", Name: name, UID: types.UID(uid)}}
+	}
+	machineSet := newNode("MachineSet", "ms1", "1")
+	clusterB := newNode("Cluster", "b", "2")
+	clusterA := newNode("Cluster", "a", "3")
+
+	tests := []struct {
+		name   string
+		owners map[*node]ownerReferenceAttributes
+		want   *node
+	}{
+		{
+			name:   "no owners",
+			owners: map[*node]ownerReferenceAttributes{},
+			want:   nil,
+		},
+		{
+			name: "the controller owner is preferred",
+			owners: map[*node]ownerReferenceAttributes{
+				clusterA:   {},
+				machineSet: {Controller: pointer.BoolPtr(true)},
+			},
+			want: machineSet,
+		},
+		{
+			name: "owners are ordered by Kind, Namespace and Name",
+			owners: map[*node]ownerReferenceAttributes{
+				machineSet: {},
+				clusterB:   {},
+				clusterA:   {},
+			},
+			want: clusterA,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(getTreeParent(&node{owners: tt.owners})).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,667 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// certificateExpiryWarningThreshold defines how long before expiration a webhook certificate is reported as a warning.
+	certificateExpiryWarningThreshold = 7 * 24 * time.Hour
+
+	// maxReportedObjects defines the maximum number of objects listed in a check message.
+	maxReportedObjects = 5
+)
+
+// CheckStatus defines the outcome of a doctor check.
+type CheckStatus string
+
+const (
+	// CheckPassed means the management cluster is consistent with what clusterctl expects.
+	CheckPassed = CheckStatus("pass")
+
+	// CheckWarning means there is something that might require attention, but the management cluster is still working.
+	CheckWarning = CheckStatus("warn")
+
+	// CheckFailed means there is a problem that prevents the management cluster from working properly.
+	CheckFailed = CheckStatus("fail")
+)
+
+// CheckResult defines the result of a doctor check.
+type CheckResult struct {
+	// Check is the name of the check, e.g. Controllers.
+	Check string
+
+	// Status is the outcome of the check.
+	Status CheckStatus
+
+	// Message is a human readable description of the result.
+	Message string
+
+	// Remediation is a hint about how to fix the problem, if the check did not pass.
+	Remediation string
+}
+
+// Doctor runs health and consistency checks against a management cluster.
+type Doctor interface {
+	// Check runs all the checks and returns their results; checks are read-only, and they never change
+	// the management cluster.
+	Check() ([]CheckResult, error)
+}
+
+// doctor implements Doctor.
+type doctor struct {
+	proxy             Proxy
+	providerInventory InventoryClient
+	now               func() time.Time
+}
+
+// ensure doctor implements Doctor.
+var _ Doctor = &doctor{}
+
+func newDoctor(proxy Proxy, providerInventory InventoryClient) *doctor {
+	return &doctor{
+		proxy:             proxy,
+		providerInventory: providerInventory,
+		now:               time.Now,
+	}
+}
+
+// doctorCheck is a function implementing a doctor check.
+type doctorCheck func(c client.Client, providers *clusterctlv1.ProviderList) []CheckResult
+
+func (d *doctor) Check() ([]CheckResult, error) {
+	c, err := d.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reads the provider inventory; if this fails all the other checks are skipped, because they
+	// rely on the inventory for knowing what is expected to be installed in the management cluster.
+	providers, err := d.providerInventory.List()
+	if err != nil {
+		return []CheckResult{{
+			Check:       "Inventory",
+			Status:      CheckFailed,
+			Message:     fmt.Sprintf("Failed to read the provider inventory: %v", err),
+			Remediation: "Check the kubeconfig points to a management cluster initialized with clusterctl init.",
+		}}, nil
+	}
+
+	results := []CheckResult{{
+		Check:   "Inventory",
+		Status:  CheckPassed,
+		Message: fmt.Sprintf("Found %d providers in the inventory", len(providers.Items)),
+	}}
+
+	for _, check := range []doctorCheck{
+		d.checkControllers,
+		d.checkWebhooks,
+		d.checkCRDs,
+		d.checkOrphanedObjects,
+		d.checkPausedClusters,
+		d.checkWatchingNamespaces,
+	} {
+		results = append(results, check(c, providers)...)
+	}
+	return results, nil
+}
+
+// checkControllers checks each provider in the inventory has its controllers running, with the version recorded in the inventory.
+func (d *doctor) checkControllers(c client.Client, providers *clusterctlv1.ProviderList) []CheckResult {
+	const check = "Controllers"
+
+	var results []CheckResult
+	for _, provider := range providers.Items {
+		deployments, err := getProviderDeployments(c, provider)
+		if err != nil {
+			results = append(results, checkError(check, err))
+			continue
+		}
+
+		if len(deployments) == 0 {
+			results = append(results, CheckResult{
+				Check:       check,
+				Status:      CheckFailed,
+				Message:     fmt.Sprintf("No controller Deployments found for provider %s", provider.InstanceName()),
+				Remediation: "The provider components were deleted without updating the inventory; delete the provider with clusterctl delete and install it again with clusterctl init.",
+			})
+			continue
+		}
+
+		for _, deployment := range deployments {
+			name := fmt.Sprintf("%s/%s", deployment.Namespace, deployment.Name)
+
+			replicas := int32(1)
+			if deployment.Spec.Replicas != nil {
+				replicas = *deployment.Spec.Replicas
+			}
+			if deployment.Status.AvailableReplicas < replicas {
+				results = append(results, CheckResult{
+					Check:       check,
+					Status:      CheckFailed,
+					Message:     fmt.Sprintf("Deployment %s of provider %s has %d/%d available replicas", name, provider.InstanceName(), deployment.Status.AvailableReplicas, replicas),
+					Remediation: fmt.Sprintf("Check the Deployment events and the controller logs, e.g. kubectl describe deployment -n %s %s.", deployment.Namespace, deployment.Name),
+				})
+				continue
+			}
+
+			if !hasImageWithTag(deployment, provider.Version) {
+				results = append(results, CheckResult{
+					Check:       check,
+					Status:      CheckWarning,
+					Message:     fmt.Sprintf("Deployment %s of provider %s does not run any image with the version %s recorded in the inventory", name, provider.InstanceName(), provider.Version),
+					Remediation: "If the images were not changed on purpose (e.g. with an image override), upgrade the provider with clusterctl upgrade apply.",
+				})
+				continue
+			}
+
+			results = append(results, CheckResult{
+				Check:   check,
+				Status:  CheckPassed,
+				Message: fmt.Sprintf("Deployment %s of provider %s is available", name, provider.InstanceName()),
+			})
+		}
+	}
+	return results
+}
+
+// checkWebhooks checks the web-hook services have ready endpoints, the web-hook configurations have a CA bundle, and
+// the certificates used by the provider controllers are valid.
+func (d *doctor) checkWebhooks(c client.Client, providers *clusterctlv1.ProviderList) []CheckResult {
+	const check = "Webhooks"
+
+	clusterctlLabels := client.MatchingLabels{clusterctlv1.ClusterctlLabelName: ""}
+
+	// Collects the services referenced by web-hook configurations and by CRD conversion web-hooks.
+	type webhookRef struct {
+		owner    string
+		service  string
+		caBundle []byte
+	}
+	var refs []webhookRef
+
+	validatingWebhooks := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := c.List(ctx, validatingWebhooks, clusterctlLabels); err != nil {
+		return []CheckResult{checkError(check, err)}
+	}
+	for _, w := range validatingWebhooks.Items {
+		for _, wh := range w.Webhooks {
+			if wh.ClientConfig.Service != nil {
+				refs = append(refs, webhookRef{owner: "ValidatingWebhookConfiguration " + w.Name, service: wh.ClientConfig.Service.Namespace + "/" + wh.ClientConfig.Service.Name, caBundle: wh.ClientConfig.CABundle})
+			}
+		}
+	}
+
+	mutatingWebhooks := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := c.List(ctx, mutatingWebhooks, clusterctlLabels); err != nil {
+		return []CheckResult{checkError(check, err)}
+	}
+	for _, w := range mutatingWebhooks.Items {
+		for _, wh := range w.Webhooks {
+			if wh.ClientConfig.Service != nil {
+				refs = append(refs, webhookRef{owner: "MutatingWebhookConfiguration " + w.Name, service: wh.ClientConfig.Service.Namespace + "/" + wh.ClientConfig.Service.Name, caBundle: wh.ClientConfig.CABundle})
+			}
+		}
+	}
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := c.List(ctx, crds, clusterctlLabels); err != nil {
+		return []CheckResult{checkError(check, err)}
+	}
+	for _, crd := range crds.Items {
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil || conversion.Webhook.ClientConfig.Service == nil {
+			continue
+		}
+		service := conversion.Webhook.ClientConfig.Service
+		refs = append(refs, webhookRef{owner: "CustomResourceDefinition " + crd.Name, service: service.Namespace + "/" + service.Name, caBundle: conversion.Webhook.ClientConfig.CABundle})
+	}
+
+	var results []CheckResult
+
+	// Checks the CA bundle is injected in all the web-hook configurations.
+	missingCABundle := sets.NewString()
+	for _, ref := range refs {
+		if len(ref.caBundle) == 0 {
+			missingCABundle.Insert(ref.owner)
+		}
+	}
+	if missingCABundle.Len() > 0 {
+		results = append(results, CheckResult{
+			Check:       check,
+			Status:      CheckFailed,
+			Message:     fmt.Sprintf("Web-hooks without a CA bundle: %s", summarize(missingCABundle.List())),
+			Remediation: "The CA bundle is injected by the cert-manager CA injector; check cert-manager is running, e.g. kubectl get pods -n cert-manager.",
+		})
+	}
+
+	// Checks all the web-hook services have ready endpoints.
+	services := sets.NewString()
+	for _, ref := range refs {
+		services.Insert(ref.service)
+	}
+	for _, service := range services.List() {
+		namespace, name := splitNamespacedName(service)
+		endpoints := &corev1.Endpoints{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, endpoints); err != nil && !apierrors.IsNotFound(err) {
+			results = append(results, checkError(check, err))
+			continue
+		}
+
+		ready := 0
+		for _, subset := range endpoints.Subsets {
+			ready += len(subset.Addresses)
+		}
+		if ready == 0 {
+			results = append(results, CheckResult{
+				Check:       check,
+				Status:      CheckFailed,
+				Message:     fmt.Sprintf("Web-hook service %s does not have ready endpoints", service),
+				Remediation: fmt.Sprintf("Check the Pods backing the service are running, e.g. kubectl get pods -n %s.", namespace),
+			})
+			continue
+		}
+		results = append(results, CheckResult{
+			Check:   check,
+			Status:  CheckPassed,
+			Message: fmt.Sprintf("Web-hook service %s has %d ready endpoints", service, ready),
+		})
+	}
+
+	// Checks the certificates mounted by the provider controllers.
+	secrets := sets.NewString()
+	for _, provider := range providers.Items {
+		deployments, err := getProviderDeployments(c, provider)
+		if err != nil {
+			results = append(results, checkError(check, err))
+			continue
+		}
+		for _, deployment := range deployments {
+			for _, volume := range deployment.Spec.Template.Spec.Volumes {
+				if volume.Secret != nil {
+					secrets.Insert(deployment.Namespace + "/" + volume.Secret.SecretName)
+				}
+			}
+		}
+	}
+	for _, secretName := range secrets.List() {
+		namespace, name := splitNamespacedName(secretName)
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				results = append(results, CheckResult{
+					Check:       check,
+					Status:      CheckFailed,
+					Message:     fmt.Sprintf("Secret %s used by the provider controllers does not exist", secretName),
+					Remediation: "Web-hook certificates are issued by cert-manager; check cert-manager is running and the Certificate objects are ready, e.g. kubectl get certificates -A.",
+				})
+				continue
+			}
+			results = append(results, checkError(check, err))
+			continue
+		}
+
+		certData, ok := secret.Data[corev1.TLSCertKey]
+		if !ok {
+			continue
+		}
+		results = append(results, d.checkCertificate(check, secretName, certData))
+	}
+
+	if len(results) == 0 {
+		results = append(results, CheckResult{
+			Check:   check,
+			Status:  CheckPassed,
+			Message: "No web-hooks found",
+		})
+	}
+	return results
+}
+
+// checkCertificate checks a certificate is valid and not expiring soon.
+func (d *doctor) checkCertificate(check, secretName string, certData []byte) CheckResult {
+	remediation := "Web-hook certificates are issued and renewed by cert-manager; check cert-manager is running and the Certificate objects are ready, e.g. kubectl get certificates -A."
+
+	cert, err := certs.DecodeCertPEM(certData)
+	if err != nil || cert == nil {
+		return CheckResult{
+			Check:       check,
+			Status:      CheckFailed,
+			Message:     fmt.Sprintf("Secret %s does not contain a valid certificate", secretName),
+			Remediation: remediation,
+		}
+	}
+
+	now := d.now()
+	switch {
+	case now.Before(cert.NotBefore) || now.After(cert.NotAfter):
+		return CheckResult{
+			Check:       check,
+			Status:      CheckFailed,
+			Message:     fmt.Sprintf("Certificate in secret %s is not valid at the current time (valid from %s to %s)", secretName, cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339)),
+			Remediation: remediation,
+		}
+	case cert.NotAfter.Sub(now) < certificateExpiryWarningThreshold:
+		return CheckResult{
+			Check:       check,
+			Status:      CheckWarning,
+			Message:     fmt.Sprintf("Certificate in secret %s expires at %s", secretName, cert.NotAfter.UTC().Format(time.RFC3339)),
+			Remediation: remediation,
+		}
+	}
+	return CheckResult{
+		Check:   check,
+		Status:  CheckPassed,
+		Message: fmt.Sprintf("Certificate in secret %s is valid until %s", secretName, cert.NotAfter.UTC().Format(time.RFC3339)),
+	}
+}
+
+// checkCRDs checks the provider CRDs are consistent with the API Version of Cluster API (contract) supported by clusterctl.
+func (d *doctor) checkCRDs(c client.Client, providers *clusterctlv1.ProviderList) []CheckResult {
+	const check = "CRDs"
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := c.List(ctx, crds, client.MatchingLabels{clusterctlv1.ClusterctlLabelName: ""}); err != nil {
+		return []CheckResult{checkError(check, err)}
+	}
+
+	contract := clusterv1.GroupVersion.Version
+	contractLabel := clusterv1.GroupVersion.String()
+	coreProviderLabels := sets.NewString()
+	for _, provider := range providers.FilterCore() {
+		coreProviderLabels.Insert(provider.ManifestLabel())
+	}
+
+	var results []CheckResult
+	for _, crd := range crds.Items {
+		servedVersions := sets.NewString()
+		storageVersion := ""
+		for _, v := range crd.Spec.Versions {
+			if v.Served {
+				servedVersions.Insert(v.Name)
+			}
+			if v.Storage {
+				storageVersion = v.Name
+			}
+		}
+
+		// Objects stored with a version not served anymore can't be read.
+		if notServed := sets.NewString(crd.Status.StoredVersions...).Difference(servedVersions); notServed.Len() > 0 {
+			results = append(results, CheckResult{
+				Check:       check,
+				Status:      CheckFailed,
+				Message:     fmt.Sprintf("CRD %s has objects stored with versions not served anymore: %s", crd.Name, strings.Join(notServed.List(), ", ")),
+				Remediation: "Install a provider version serving the stored versions, and migrate the objects to the current storage version before upgrading.",
+			})
+			continue
+		}
+
+		// Checks the storage version is compliant with the contract; the core provider must use the contract version, while
+		// other providers declare the versions compliant with the contract using a label on the CRD.
+		if coreProviderLabels.Has(crd.Labels[clusterv1.ProviderLabelName]) {
+			if storageVersion != contract {
+				results = append(results, CheckResult{
+					Check:       check,
+					Status:      CheckFailed,
+					Message:     fmt.Sprintf("CRD %s has storage version %s, while clusterctl supports the %s API Version of Cluster API (contract)", crd.Name, storageVersion, contract),
+					Remediation: "Use a clusterctl version supporting the API Version of Cluster API (contract) of the installed core provider.",
+				})
+				continue
+			}
+		} else {
+			contractVersions, ok := crd.Labels[contractLabel]
+			if !ok {
+				results = append(results, CheckResult{
+					Check:       check,
+					Status:      CheckWarning,
+					Message:     fmt.Sprintf("CRD %s does not declare the versions compliant with the %s API Version of Cluster API (contract)", crd.Name, contract),
+					Remediation: fmt.Sprintf("If the CRD is referenced by Cluster API objects, upgrade the provider to a version compliant with the %s contract.", contract),
+				})
+				continue
+			}
+			if !sets.NewString(strings.Split(contractVersions, "_")...).Has(storageVersion) {
+				results = append(results, CheckResult{
+					Check:       check,
+					Status:      CheckWarning,
+					Message:     fmt.Sprintf("CRD %s has storage version %s, which is not one of the versions compliant with the %s API Version of Cluster API (contract): %s", crd.Name, storageVersion, contract, contractVersions),
+					Remediation: fmt.Sprintf("Upgrade the provider to a version compliant with the %s contract.", contract),
+				})
+				continue
+			}
+		}
+
+		// More than one stored version means a storage version migration is pending.
+		if len(crd.Status.StoredVersions) > 1 {
+			results = append(results, CheckResult{
+				Check:       check,
+				Status:      CheckWarning,
+				Message:     fmt.Sprintf("CRD %s has objects stored with more than one version: %s", crd.Name, strings.Join(crd.Status.StoredVersions, ", ")),
+				Remediation: fmt.Sprintf("Migrate the objects to the %s storage version, and then remove the old versions from the CRD status.storedVersions.", storageVersion),
+			})
+			continue
+		}
+	}
+
+	if len(results) == 0 {
+		results = append(results, CheckResult{
+			Check:   check,
+			Status:  CheckPassed,
+			Message: fmt.Sprintf("All the %d provider CRDs are consistent with the %s API Version of Cluster API (contract)", len(crds.Items), contract),
+		})
+	}
+	return results
+}
+
+// checkOrphanedObjects checks there are no provider components belonging to providers not included in the inventory,
+// e.g. leftovers of a failed delete.
+func (d *doctor) checkOrphanedObjects(c client.Client, providers *clusterctlv1.ProviderList) []CheckResult {
+	const check = "Orphaned objects"
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := c.List(ctx, namespaceList); err != nil {
+		return []CheckResult{checkError(check, err)}
+	}
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, ns := range namespaceList.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+
+	objs, err := d.proxy.ListResources(map[string]string{clusterctlv1.ClusterctlLabelName: ""}, namespaces...)
+	if err != nil {
+		return []CheckResult{checkError(check, err)}
+	}
+
+	installed := sets.NewString()
+	for _, provider := range providers.Items {
+		installed.Insert(provider.ManifestLabel())
+	}
+
+	orphans := sets.NewString()
+	for _, o := range objs {
+		if installed.Has(o.GetLabels()[clusterv1.ProviderLabelName]) {
+			continue
+		}
+		name := o.GetName()
+		if o.GetNamespace() != "" {
+			name = o.GetNamespace() + "/" + name
+		}
+		orphans.Insert(fmt.Sprintf("%s %s", o.GetKind(), name))
+	}
+
+	if orphans.Len() > 0 {
+		return []CheckResult{{
+			Check:       check,
+			Status:      CheckWarning,
+			Message:     fmt.Sprintf("Found %d provider components not belonging to any provider in the inventory: %s", orphans.Len(), summarize(orphans.List())),
+			Remediation: fmt.Sprintf("Delete the objects, e.g. kubectl delete <kind> <name> -n <namespace>; the objects can be listed with kubectl get <kind> -A -l %s.", clusterctlv1.ClusterctlLabelName),
+		}}
+	}
+	return []CheckResult{{
+		Check:   check,
+		Status:  CheckPassed,
+		Message: "All the provider components belong to a provider in the inventory",
+	}}
+}
+
+// checkPausedClusters checks there are no Clusters left paused, e.g. after a failed move.
+func (d *doctor) checkPausedClusters(c client.Client, _ *clusterctlv1.ProviderList) []CheckResult {
+	const check = "Paused Clusters"
+
+	clusters := &clusterv1.ClusterList{}
+	if err := c.List(ctx, clusters); err != nil {
+		return []CheckResult{checkError(check, err)}
+	}
+
+	var results []CheckResult
+	for _, cluster := range clusters.Items {
+		name := fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)
+		if progress, ok := cluster.Annotations[clusterctlv1.MoveProgressAnnotation]; ok {
+			results = append(results, CheckResult{
+				Check:       check,
+				Status:      CheckFailed,
+				Message:     fmt.Sprintf("Cluster %s was left paused by an interrupted move (progress: %s)", name, progress),
+				Remediation: "Run clusterctl move again to resume the move, or run clusterctl move with the --rollback flag to roll it back.",
+			})
+			continue
+		}
+		if cluster.Spec.Paused {
+			results = append(results, CheckResult{
+				Check:       check,
+				Status:      CheckWarning,
+				Message:     fmt.Sprintf("Cluster %s is paused", name),
+				Remediation: fmt.Sprintf("If the Cluster was not paused on purpose, resume the reconciliation with kubectl patch cluster -n %s %s --type merge -p '{\"spec\":{\"paused\":false}}'.", cluster.Namespace, cluster.Name),
+			})
+		}
+	}
+
+	if len(results) == 0 {
+		results = append(results, CheckResult{
+			Check:   check,
+			Status:  CheckPassed,
+			Message: fmt.Sprintf("None of the %d Clusters is paused", len(clusters.Items)),
+		})
+	}
+	return results
+}
+
+// checkWatchingNamespaces checks instances of the same provider are not watching the same namespaces, and
+// that providers can be grouped in management groups.
+func (d *doctor) checkWatchingNamespaces(_ client.Client, providers *clusterctlv1.ProviderList) []CheckResult {
+	const check = "Watching namespaces"
+
+	var results []CheckResult
+	for i, provider := range providers.Items {
+		for _, other := range providers.Items[i+1:] {
+			if provider.SameAs(other) && provider.HasWatchingOverlapWith(other) {
+				results = append(results, CheckResult{
+					Check:       check,
+					Status:      CheckFailed,
+					Message:     fmt.Sprintf("Providers %s and %s have overlapping watching namespaces, and their controllers are fighting for the same objects", provider.InstanceName(), other.InstanceName()),
+					Remediation: "Delete one of the provider instances with clusterctl delete, and install it again with clusterctl init using a different --watching-namespace.",
+				})
+			}
+		}
+	}
+
+	if _, err := deriveManagementGroups(providers); err != nil {
+		results = append(results, CheckResult{
+			Check:       check,
+			Status:      CheckFailed,
+			Message:     err.Error(),
+			Remediation: "Each provider should watch the same namespaces of exactly one core provider; delete the misconfigured providers with clusterctl delete and install them again with clusterctl init.",
+		})
+	}
+
+	if len(results) == 0 {
+		results = append(results, CheckResult{
+			Check:   check,
+			Status:  CheckPassed,
+			Message: "Providers do not have overlapping watching namespaces",
+		})
+	}
+	return results
+}
+
+// getProviderDeployments returns the Deployments belonging to a provider instance, including the web-hook Deployments.
+func getProviderDeployments(c client.Client, provider clusterctlv1.Provider) ([]appsv1.Deployment, error) {
+	var ret []appsv1.Deployment
+	for _, namespace := range []string{provider.Namespace, repository.WebhookNamespaceName} {
+		deployments := &appsv1.DeploymentList{}
+		if err := c.List(ctx, deployments, client.InNamespace(namespace), client.MatchingLabels{clusterv1.ProviderLabelName: provider.ManifestLabel()}); err != nil {
+			return nil, err
+		}
+		ret = append(ret, deployments.Items...)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Namespace+"/"+ret[i].Name < ret[j].Namespace+"/"+ret[j].Name
+	})
+	return ret, nil
+}
+
+// hasImageWithTag returns true if any of the containers of a Deployment runs an image with the given tag.
+func hasImageWithTag(deployment appsv1.Deployment, tag string) bool {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if strings.HasSuffix(container.Image, ":"+tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkError returns the result of a check that failed to run.
+func checkError(check string, err error) CheckResult {
+	return CheckResult{
+		Check:       check,
+		Status:      CheckFailed,
+		Message:     fmt.Sprintf("Failed to run the check: %v", err),
+		Remediation: "Check the connection to the management cluster and the permissions of the kubeconfig user.",
+	}
+}
+
+// splitNamespacedName splits a namespace/name string.
+func splitNamespacedName(s string) (string, string) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return parts[0], parts[1]
+}
+
+// summarize returns a comma separated list of items, truncated to maxReportedObjects.
+func summarize(items []string) string {
+	if len(items) <= maxReportedObjects {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxReportedObjects], ", "), len(items)-maxReportedObjects)
+}
@@ -0,0 +1,552 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+var (
+	coreProviderLabel  = clusterctlv1.ManifestLabel("cluster-api", clusterctlv1.CoreProviderType)
+	infraProviderLabel = clusterctlv1.ManifestLabel("infra", clusterctlv1.InfrastructureProviderType)
+)
+
+func providerComponentLabels(providerLabel string) map[string]string {
+	return map[string]string{
+		clusterctlv1.ClusterctlLabelName: "",
+		clusterv1.ProviderLabelName:      providerLabel,
+	}
+}
+
+func fakeProviderDeployment(namespace, name, image string, availableReplicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    providerComponentLabels(coreProviderLabel),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32Ptr(1),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "kube-rbac-proxy", Image: "gcr.io/kubebuilder/kube-rbac-proxy:v0.4.1"},
+						{Name: "manager", Image: image},
+					},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			AvailableReplicas: availableReplicas,
+		},
+	}
+}
+
+func checkStatuses(results []CheckResult) []CheckStatus {
+	statuses := []CheckStatus{}
+	for _, r := range results {
+		statuses = append(statuses, r.Status)
+	}
+	return statuses
+}
+
+func Test_doctor_checkControllers(t *testing.T) {
+	tests := []struct {
+		name string
+		objs []runtime.Object
+		want []CheckStatus
+	}{
+		{
+			name: "fails if there are no controllers",
+			objs: []runtime.Object{},
+			want: []CheckStatus{CheckFailed},
+		},
+		{
+			name: "fails if the controllers are not available",
+			objs: []runtime.Object{fakeProviderDeployment("capi-system", "capi-controller-manager", "registry/cluster-api-controller:v1.0.0", 0)},
+			want: []CheckStatus{CheckFailed},
+		},
+		{
+			name: "warns if the controllers do not run the version in the inventory",
+			objs: []runtime.Object{fakeProviderDeployment("capi-system", "capi-controller-manager", "registry/cluster-api-controller:v0.9.0", 1)},
+			want: []CheckStatus{CheckWarning},
+		},
+		{
+			name: "pass if the controllers, including the web-hooks, are available and run the version in the inventory",
+			objs: []runtime.Object{
+				fakeProviderDeployment("capi-system", "capi-controller-manager", "registry/cluster-api-controller:v1.0.0", 1),
+				fakeProviderDeployment("capi-webhook-system", "capi-controller-manager", "registry/cluster-api-controller:v1.0.0", 1),
+			},
+			want: []CheckStatus{CheckPassed, CheckPassed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system", "").
+				WithObjs(tt.objs...)
+			d := newDoctor(proxy, newInventoryClient(proxy, nil))
+
+			c, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+			providers, err := d.providerInventory.List()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := d.checkControllers(c, providers)
+			g.Expect(checkStatuses(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func fakeValidatingWebhook(caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingWebhookConfiguration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "capi-validating-webhook-configuration",
+			Labels: providerComponentLabels(coreProviderLabel),
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name: "validation.cluster.cluster.x-k8s.io",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service:  &admissionregistrationv1.ServiceReference{Namespace: "capi-webhook-system", Name: "capi-webhook-service"},
+					CABundle: caBundle,
+				},
+			},
+		},
+	}
+}
+
+func fakeWebhookEndpoints(addresses int) *corev1.Endpoints {
+	subset := corev1.EndpointSubset{}
+	for i := 0; i < addresses; i++ {
+		subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: "10.0.0.1"})
+	}
+	return &corev1.Endpoints{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Endpoints",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "capi-webhook-system",
+			Name:      "capi-webhook-service",
+		},
+		Subsets: []corev1.EndpointSubset{subset},
+	}
+}
+
+func Test_doctor_checkWebhooks(t *testing.T) {
+	tests := []struct {
+		name string
+		objs []runtime.Object
+		want []CheckStatus
+	}{
+		{
+			name: "fails if the CA bundle is not injected",
+			objs: []runtime.Object{fakeValidatingWebhook(nil), fakeWebhookEndpoints(1)},
+			want: []CheckStatus{CheckFailed, CheckPassed},
+		},
+		{
+			name: "fails if the web-hook service does not have ready endpoints",
+			objs: []runtime.Object{fakeValidatingWebhook([]byte("ca")), fakeWebhookEndpoints(0)},
+			want: []CheckStatus{CheckFailed},
+		},
+		{
+			name: "fails if the web-hook service does not exist",
+			objs: []runtime.Object{fakeValidatingWebhook([]byte("ca"))},
+			want: []CheckStatus{CheckFailed},
+		},
+		{
+			name: "fails if the web-hook certificate secret does not exist",
+			objs: []runtime.Object{
+				fakeValidatingWebhook([]byte("ca")),
+				fakeWebhookEndpoints(1),
+				func() runtime.Object {
+					d := fakeProviderDeployment("capi-webhook-system", "capi-controller-manager", "registry/cluster-api-controller:v1.0.0", 1)
+					d.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "cert", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "capi-webhook-service-cert"}}}}
+					return d
+				}(),
+			},
+			want: []CheckStatus{CheckPassed, CheckFailed},
+		},
+		{
+			name: "pass if the web-hook service has ready endpoints",
+			objs: []runtime.Object{fakeValidatingWebhook([]byte("ca")), fakeWebhookEndpoints(2)},
+			want: []CheckStatus{CheckPassed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system", "").
+				WithObjs(tt.objs...)
+			d := newDoctor(proxy, newInventoryClient(proxy, nil))
+
+			c, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+			providers, err := d.providerInventory.List()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := d.checkWebhooks(c, providers)
+			g.Expect(checkStatuses(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_doctor_checkCertificate(t *testing.T) {
+	now := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	newCert := func(notBefore, notAfter time.Time) []byte {
+		key, err := certs.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "capi-webhook-service.capi-webhook-system.svc"},
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certs.EncodeCertPEM(cert)
+	}
+
+	tests := []struct {
+		name     string
+		certData []byte
+		want     CheckStatus
+	}{
+		{
+			name:     "fails if the certificate is invalid",
+			certData: []byte("invalid"),
+			want:     CheckFailed,
+		},
+		{
+			name:     "fails if the certificate is expired",
+			certData: newCert(now.Add(-48*time.Hour), now.Add(-24*time.Hour)),
+			want:     CheckFailed,
+		},
+		{
+			name:     "warns if the certificate is expiring",
+			certData: newCert(now.Add(-24*time.Hour), now.Add(24*time.Hour)),
+			want:     CheckWarning,
+		},
+		{
+			name:     "pass if the certificate is valid",
+			certData: newCert(now.Add(-24*time.Hour), now.Add(90*24*time.Hour)),
+			want:     CheckPassed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			d := newDoctor(nil, nil)
+			d.now = func() time.Time { return now }
+
+			got := d.checkCertificate("Webhooks", "capi-webhook-system/capi-webhook-service-cert", tt.certData)
+			g.Expect(got.Status).To(Equal(tt.want))
+		})
+	}
+}
+
+func fakeProviderCRD(name, providerLabel, storageVersion string, storedVersions []string, contractVersions string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: providerComponentLabels(providerLabel),
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: storageVersion, Served: true, Storage: true},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			StoredVersions: storedVersions,
+		},
+	}
+	if contractVersions != "" {
+		crd.Labels[clusterv1.GroupVersion.String()] = contractVersions
+	}
+	return crd
+}
+
+func Test_doctor_checkCRDs(t *testing.T) {
+	contract := clusterv1.GroupVersion.Version
+
+	tests := []struct {
+		name string
+		objs []runtime.Object
+		want []CheckStatus
+	}{
+		{
+			name: "pass if CRDs are consistent with the contract",
+			objs: []runtime.Object{
+				fakeProviderCRD("clusters.cluster.x-k8s.io", coreProviderLabel, contract, []string{contract}, ""),
+				fakeProviderCRD("infraclusters.infrastructure.cluster.x-k8s.io", infraProviderLabel, "v1beta1", []string{"v1beta1"}, "v1alpha1_v1beta1"),
+			},
+			want: []CheckStatus{CheckPassed},
+		},
+		{
+			name: "fails if core provider CRDs do not use the contract version",
+			objs: []runtime.Object{fakeProviderCRD("clusters.cluster.x-k8s.io", coreProviderLabel, "v1alpha2", []string{"v1alpha2"}, "")},
+			want: []CheckStatus{CheckFailed},
+		},
+		{
+			name: "fails if objects are stored with versions not served",
+			objs: []runtime.Object{fakeProviderCRD("clusters.cluster.x-k8s.io", coreProviderLabel, contract, []string{"v1alpha2", contract}, "")},
+			want: []CheckStatus{CheckFailed},
+		},
+		{
+			name: "warns if provider CRDs do not declare the versions compliant with the contract",
+			objs: []runtime.Object{fakeProviderCRD("infraclusters.infrastructure.cluster.x-k8s.io", infraProviderLabel, "v1beta1", []string{"v1beta1"}, "")},
+			want: []CheckStatus{CheckWarning},
+		},
+		{
+			name: "warns if provider CRDs storage version is not compliant with the contract",
+			objs: []runtime.Object{fakeProviderCRD("infraclusters.infrastructure.cluster.x-k8s.io", infraProviderLabel, "v1beta1", []string{"v1beta1"}, "v1alpha1")},
+			want: []CheckStatus{CheckWarning},
+		},
+		{
+			name: "warns if a storage version migration is pending",
+			objs: []runtime.Object{func() runtime.Object {
+				crd := fakeProviderCRD("infraclusters.infrastructure.cluster.x-k8s.io", infraProviderLabel, "v1beta1", []string{"v1alpha1", "v1beta1"}, "v1beta1")
+				crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha1", Served: true})
+				return crd
+			}()},
+			want: []CheckStatus{CheckWarning},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system", "").
+				WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra-system", "").
+				WithObjs(tt.objs...)
+			d := newDoctor(proxy, newInventoryClient(proxy, nil))
+
+			c, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+			providers, err := d.providerInventory.List()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := d.checkCRDs(c, providers)
+			g.Expect(checkStatuses(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_doctor_checkOrphanedObjects(t *testing.T) {
+	namespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}
+	}
+	configMap := func(namespace, providerLabel string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "manager-config", Labels: providerComponentLabels(providerLabel)},
+		}
+	}
+
+	tests := []struct {
+		name string
+		objs []runtime.Object
+		want CheckStatus
+	}{
+		{
+			name: "pass if all the components belong to a provider in the inventory",
+			objs: []runtime.Object{namespace("capi-system"), configMap("capi-system", coreProviderLabel)},
+			want: CheckPassed,
+		},
+		{
+			name: "warns if there are components of a provider not in the inventory",
+			objs: []runtime.Object{namespace("capi-system"), namespace("infra-system"), configMap("infra-system", infraProviderLabel)},
+			want: CheckWarning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system", "").
+				WithObjs(tt.objs...)
+			d := newDoctor(proxy, newInventoryClient(proxy, nil))
+
+			c, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+			providers, err := d.providerInventory.List()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := d.checkOrphanedObjects(c, providers)
+			g.Expect(checkStatuses(got)).To(Equal([]CheckStatus{tt.want}))
+		})
+	}
+}
+
+func Test_doctor_checkPausedClusters(t *testing.T) {
+	cluster := func(paused bool, annotations map[string]string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster1", Annotations: annotations},
+			Spec:       clusterv1.ClusterSpec{Paused: paused},
+		}
+	}
+
+	tests := []struct {
+		name string
+		objs []runtime.Object
+		want CheckStatus
+	}{
+		{
+			name: "pass if there are no paused Clusters",
+			objs: []runtime.Object{cluster(false, nil)},
+			want: CheckPassed,
+		},
+		{
+			name: "warns if there are paused Clusters",
+			objs: []runtime.Object{cluster(true, nil)},
+			want: CheckWarning,
+		},
+		{
+			name: "fails if there are Clusters paused by an interrupted move",
+			objs: []runtime.Object{cluster(true, map[string]string{clusterctlv1.MoveProgressAnnotation: "Creating/2"})},
+			want: CheckFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().WithObjs(tt.objs...)
+			d := newDoctor(proxy, newInventoryClient(proxy, nil))
+
+			c, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := d.checkPausedClusters(c, &clusterctlv1.ProviderList{})
+			g.Expect(checkStatuses(got)).To(Equal([]CheckStatus{tt.want}))
+		})
+	}
+}
+
+func Test_doctor_checkWatchingNamespaces(t *testing.T) {
+	tests := []struct {
+		name  string
+		proxy *test.FakeProxy
+		want  []CheckStatus
+	}{
+		{
+			name: "pass if providers do not have overlapping watching namespaces",
+			proxy: test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system1", "ns1").
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system2", "ns2").
+				WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra-system1", "ns1").
+				WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra-system2", "ns2"),
+			want: []CheckStatus{CheckPassed},
+		},
+		{
+			name: "fails if instances of the same provider have overlapping watching namespaces",
+			proxy: test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system1", "").
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system2", "ns2"),
+			want: []CheckStatus{CheckFailed, CheckFailed},
+		},
+		{
+			name: "fails if providers can't be grouped in management groups",
+			proxy: test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system", "ns1").
+				WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra-system", "ns2"),
+			want: []CheckStatus{CheckFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			d := newDoctor(tt.proxy, newInventoryClient(tt.proxy, nil))
+			providers, err := d.providerInventory.List()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := d.checkWatchingNamespaces(nil, providers)
+			g.Expect(checkStatuses(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_doctor_Check(t *testing.T) {
+	g := NewWithT(t)
+
+	proxy := test.NewFakeProxy().
+		WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system", "").
+		WithObjs(fakeProviderDeployment("capi-system", "capi-controller-manager", "registry/cluster-api-controller:v1.0.0", 1))
+	d := newDoctor(proxy, newInventoryClient(proxy, nil))
+
+	got, err := d.Check()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	checks := map[string]CheckStatus{}
+	for _, r := range got {
+		g.Expect(r.Status).To(Equal(CheckPassed), r.Message)
+		checks[r.Check] = r.Status
+	}
+	g.Expect(checks).To(HaveLen(7))
+}
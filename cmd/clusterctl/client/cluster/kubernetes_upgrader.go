@@ -0,0 +1,432 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	waitKubernetesUpgradeInterval = 10 * time.Second
+	waitKubernetesUpgradeTimeout  = 30 * time.Minute
+)
+
+// machineHealthConditions are the Machine conditions that, when false, stop a Kubernetes upgrade.
+var machineHealthConditions = []clusterv1.ConditionType{
+	clusterv1.MachineHealthCheckSuccededCondition,
+	clusterv1.MachineOwnerRemediatedCondition,
+}
+
+// KubernetesUpgradeOptions carries the options supported by KubernetesUpgrader.
+type KubernetesUpgradeOptions struct {
+	// MachineDeploymentOrder defines the order in which MachineDeployments are upgraded; MachineDeployments
+	// not included in the list are upgraded afterwards, in alphabetical order.
+	MachineDeploymentOrder []string
+
+	// Timeout is the maximum time to wait for the control plane or for each MachineDeployment to be upgraded.
+	// If zero, a default timeout of 30 minutes is used.
+	Timeout time.Duration
+}
+
+// KubernetesUpgradePlan defines the steps required for upgrading the Kubernetes version of a workload cluster.
+type KubernetesUpgradePlan struct {
+	// ClusterNamespace is the namespace of the workload cluster.
+	ClusterNamespace string `json:"clusterNamespace"`
+
+	// ClusterName is the name of the workload cluster.
+	ClusterName string `json:"clusterName"`
+
+	// KubernetesVersion is the target Kubernetes version.
+	KubernetesVersion string `json:"kubernetesVersion"`
+
+	// Steps to be executed in order; the control plane is always upgraded first.
+	Steps []KubernetesUpgradeStep `json:"steps"`
+}
+
+// KubernetesUpgradeStep defines the upgrade of a control plane or of a MachineDeployment.
+type KubernetesUpgradeStep struct {
+	// Object is a reference to the control plane or to the MachineDeployment to be upgraded.
+	Object corev1.ObjectReference `json:"object"`
+
+	// CurrentVersion is the current Kubernetes version of the object.
+	CurrentVersion string `json:"currentVersion"`
+
+	// TargetVersion is the Kubernetes version the object will be upgraded to.
+	TargetVersion string `json:"targetVersion"`
+}
+
+// KubernetesUpgrader defines methods for upgrading the Kubernetes version of workload clusters.
+type KubernetesUpgrader interface {
+	// Plan returns the steps required for upgrading the Kubernetes version of a workload cluster,
+	// validating the upgrade is supported.
+	Plan(namespace, name, kubernetesVersion string, options KubernetesUpgradeOptions) (*KubernetesUpgradePlan, error)
+
+	// Apply executes an upgrade plan, upgrading the control plane first, and then the MachineDeployments one at time,
+	// each time waiting for the rollout to complete. The upgrade stops if any Machine of the workload cluster is not healthy.
+	Apply(plan *KubernetesUpgradePlan, options KubernetesUpgradeOptions) error
+}
+
+// kubernetesUpgrader implements KubernetesUpgrader.
+type kubernetesUpgrader struct {
+	proxy               Proxy
+	pollImmediateWaiter PollImmediateWaiter
+}
+
+// ensure kubernetesUpgrader implements KubernetesUpgrader.
+var _ KubernetesUpgrader = &kubernetesUpgrader{}
+
+func newKubernetesUpgrader(proxy Proxy, pollImmediateWaiter PollImmediateWaiter) *kubernetesUpgrader {
+	return &kubernetesUpgrader{
+		proxy:               proxy,
+		pollImmediateWaiter: pollImmediateWaiter,
+	}
+}
+
+func (u *kubernetesUpgrader) Plan(namespace, name, kubernetesVersion string, options KubernetesUpgradeOptions) (*KubernetesUpgradePlan, error) {
+	if _, err := version.ParseSemantic(kubernetesVersion); err != nil {
+		return nil, errors.Wrapf(err, "invalid Kubernetes version %q", kubernetesVersion)
+	}
+
+	c, err := u.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &clusterv1.Cluster{}
+	clusterKey := client.ObjectKey{Namespace: namespace, Name: name}
+	if err := c.Get(ctx, clusterKey, cluster); err != nil {
+		return nil, errors.Wrapf(err, "failed to get Cluster %s/%s", namespace, name)
+	}
+	if cluster.Spec.ControlPlaneRef == nil {
+		return nil, errors.Errorf("Cluster %s/%s does not have a control plane object; only clusters with a ControlPlaneRef can be upgraded", namespace, name)
+	}
+
+	plan := &KubernetesUpgradePlan{
+		ClusterNamespace:  namespace,
+		ClusterName:       name,
+		KubernetesVersion: kubernetesVersion,
+	}
+
+	// Gets the control plane step.
+	controlPlane, err := getControlPlane(c, cluster)
+	if err != nil {
+		return nil, err
+	}
+	controlPlaneVersion, _, err := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	if err != nil || controlPlaneVersion == "" {
+		return nil, errors.Errorf("failed to get spec.version from %s %s/%s", controlPlane.GetKind(), controlPlane.GetNamespace(), controlPlane.GetName())
+	}
+	if err := validateKubernetesUpgrade(controlPlaneVersion, kubernetesVersion); err != nil {
+		return nil, errors.Wrapf(err, "invalid upgrade for %s %s/%s", controlPlane.GetKind(), controlPlane.GetNamespace(), controlPlane.GetName())
+	}
+	if controlPlaneVersion != kubernetesVersion {
+		plan.Steps = append(plan.Steps, KubernetesUpgradeStep{
+			Object: corev1.ObjectReference{
+				APIVersion: controlPlane.GetAPIVersion(),
+				Kind:       controlPlane.GetKind(),
+				Namespace:  controlPlane.GetNamespace(),
+				Name:       controlPlane.GetName(),
+			},
+			CurrentVersion: controlPlaneVersion,
+			TargetVersion:  kubernetesVersion,
+		})
+	}
+
+	// Gets the MachineDeployment steps, in the requested order.
+	machineDeployments, err := getMachineDeploymentsForCluster(c, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := sortMachineDeployments(machineDeployments, options.MachineDeploymentOrder); err != nil {
+		return nil, err
+	}
+	for _, md := range machineDeployments {
+		currentVersion := ""
+		if md.Spec.Template.Spec.Version != nil {
+			currentVersion = *md.Spec.Template.Spec.Version
+		}
+		if currentVersion == kubernetesVersion {
+			continue
+		}
+		if currentVersion != "" {
+			if err := validateKubernetesUpgrade(currentVersion, kubernetesVersion); err != nil {
+				return nil, errors.Wrapf(err, "invalid upgrade for MachineDeployment %s/%s", md.Namespace, md.Name)
+			}
+		}
+		plan.Steps = append(plan.Steps, KubernetesUpgradeStep{
+			Object: corev1.ObjectReference{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "MachineDeployment",
+				Namespace:  md.Namespace,
+				Name:       md.Name,
+			},
+			CurrentVersion: currentVersion,
+			TargetVersion:  kubernetesVersion,
+		})
+	}
+
+	return plan, nil
+}
+
+// validateKubernetesUpgrade checks an upgrade from the current version to the target version does not
+// downgrade and does not skip minor versions.
+func validateKubernetesUpgrade(currentVersion, targetVersion string) error {
+	current, err := version.ParseSemantic(currentVersion)
+	if err != nil {
+		return errors.Wrapf(err, "invalid current Kubernetes version %q", currentVersion)
+	}
+	target, err := version.ParseSemantic(targetVersion)
+	if err != nil {
+		return errors.Wrapf(err, "invalid Kubernetes version %q", targetVersion)
+	}
+	if target.LessThan(current) {
+		return errors.Errorf("downgrading from %s to %s is not supported", currentVersion, targetVersion)
+	}
+	if target.Major() != current.Major() || target.Minor() > current.Minor()+1 {
+		return errors.Errorf("upgrading from %s to %s skips minor versions; please upgrade one minor version at a time", currentVersion, targetVersion)
+	}
+	return nil
+}
+
+// sortMachineDeployments sorts MachineDeployments according to the given order; MachineDeployments not included
+// in the list are sorted afterwards, in alphabetical order.
+func sortMachineDeployments(machineDeployments []*clusterv1.MachineDeployment, order []string) error {
+	position := map[string]int{}
+	for i, name := range order {
+		position[name] = i
+	}
+	found := map[string]bool{}
+	for _, md := range machineDeployments {
+		if _, ok := position[md.Name]; ok {
+			found[md.Name] = true
+		}
+	}
+	for _, name := range order {
+		if !found[name] {
+			return errors.Errorf("MachineDeployment %q does not exist or does not belong to the cluster", name)
+		}
+	}
+
+	sort.SliceStable(machineDeployments, func(i, j int) bool {
+		pi, iOrdered := position[machineDeployments[i].Name]
+		pj, jOrdered := position[machineDeployments[j].Name]
+		switch {
+		case iOrdered && jOrdered:
+			return pi < pj
+		case iOrdered != jOrdered:
+			return iOrdered
+		}
+		return machineDeployments[i].Name < machineDeployments[j].Name
+	})
+	return nil
+}
+
+func (u *kubernetesUpgrader) Apply(plan *KubernetesUpgradePlan, options KubernetesUpgradeOptions) error {
+	log := logf.Log
+
+	c, err := u.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	cluster := &clusterv1.Cluster{}
+	clusterKey := client.ObjectKey{Namespace: plan.ClusterNamespace, Name: plan.ClusterName}
+	if err := c.Get(ctx, clusterKey, cluster); err != nil {
+		return errors.Wrapf(err, "failed to get Cluster %s/%s", plan.ClusterNamespace, plan.ClusterName)
+	}
+
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = waitKubernetesUpgradeTimeout
+	}
+
+	for _, step := range plan.Steps {
+		// Stops before starting a new step if there are unhealthy Machines.
+		if err := checkMachinesHealth(c, cluster); err != nil {
+			return err
+		}
+
+		log.Info("Upgrading", "Kind", step.Object.Kind, "Namespace", step.Object.Namespace, "Name", step.Object.Name, "Version", step.TargetVersion)
+
+		var isUpgraded func() (bool, error)
+		switch step.Object.Kind {
+		case "MachineDeployment":
+			isUpgraded, err = u.upgradeMachineDeployment(c, step)
+		default:
+			isUpgraded, err = u.upgradeControlPlane(c, step)
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Info("Waiting for the rollout to complete", "Kind", step.Object.Kind, "Namespace", step.Object.Namespace, "Name", step.Object.Name)
+		if err := u.pollImmediateWaiter(waitKubernetesUpgradeInterval, timeout, func() (bool, error) {
+			if err := checkMachinesHealth(c, cluster); err != nil {
+				return false, err
+			}
+			upgraded, err := isUpgraded()
+			if err != nil {
+				//Nb. we are ignoring the error so the pollImmediateWaiter will execute another retry
+				log.V(5).Info("Failed to get the rollout status", "Error", err.Error())
+				return false, nil
+			}
+			return upgraded, nil
+		}); err != nil {
+			return errors.Wrapf(err, "failed to upgrade %s %s/%s to %s", step.Object.Kind, step.Object.Namespace, step.Object.Name, step.TargetVersion)
+		}
+	}
+	return nil
+}
+
+// upgradeControlPlane sets the version of the control plane, and returns a func checking if the rollout is completed.
+func (u *kubernetesUpgrader) upgradeControlPlane(c client.Client, step KubernetesUpgradeStep) (func() (bool, error), error) {
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetAPIVersion(step.Object.APIVersion)
+	controlPlane.SetKind(step.Object.Kind)
+	key := client.ObjectKey{Namespace: step.Object.Namespace, Name: step.Object.Name}
+
+	readBackoff := newReadBackoff()
+	if err := retryWithExponentialBackoff(readBackoff, func() error {
+		return c.Get(ctx, key, controlPlane)
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s %s/%s", step.Object.Kind, step.Object.Namespace, step.Object.Name)
+	}
+
+	patch := client.MergeFrom(controlPlane.DeepCopy())
+	if err := unstructured.SetNestedField(controlPlane.Object, step.TargetVersion, "spec", "version"); err != nil {
+		return nil, errors.Wrapf(err, "failed to set the version of %s %s/%s", step.Object.Kind, step.Object.Namespace, step.Object.Name)
+	}
+
+	patchBackoff := newWriteBackoff()
+	if err := retryWithExponentialBackoff(patchBackoff, func() error {
+		return c.Patch(ctx, controlPlane, patch)
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to set the version of %s %s/%s", step.Object.Kind, step.Object.Namespace, step.Object.Name)
+	}
+
+	return func() (bool, error) {
+		if err := c.Get(ctx, key, controlPlane); err != nil {
+			return false, err
+		}
+		return isControlPlaneUpgraded(controlPlane), nil
+	}, nil
+}
+
+// isControlPlaneUpgraded returns true if the control plane observed the latest spec and all its replicas are
+// updated and ready, as defined by the control plane provider contract.
+func isControlPlaneUpgraded(controlPlane *unstructured.Unstructured) bool {
+	observedGeneration, _, _ := unstructured.NestedInt64(controlPlane.Object, "status", "observedGeneration")
+	if observedGeneration < controlPlane.GetGeneration() {
+		return false
+	}
+
+	desired, found, _ := unstructured.NestedInt64(controlPlane.Object, "spec", "replicas")
+	replicas, _, _ := unstructured.NestedInt64(controlPlane.Object, "status", "replicas")
+	updated, _, _ := unstructured.NestedInt64(controlPlane.Object, "status", "updatedReplicas")
+	ready, _, _ := unstructured.NestedInt64(controlPlane.Object, "status", "readyReplicas")
+	unavailable, _, _ := unstructured.NestedInt64(controlPlane.Object, "status", "unavailableReplicas")
+	if found && desired != replicas {
+		return false
+	}
+	return replicas == updated && replicas == ready && unavailable == 0
+}
+
+// upgradeMachineDeployment sets the version of a MachineDeployment, and returns a func checking if the rollout is completed.
+func (u *kubernetesUpgrader) upgradeMachineDeployment(c client.Client, step KubernetesUpgradeStep) (func() (bool, error), error) {
+	md, err := getMachineDeployment(c, step.Object.Namespace, step.Object.Name)
+	if err != nil {
+		return nil, err
+	}
+	if md.Spec.Paused {
+		return nil, errors.Errorf("cannot upgrade the paused MachineDeployment %s/%s; resume it first", step.Object.Namespace, step.Object.Name)
+	}
+
+	patch := client.MergeFrom(md.DeepCopy())
+	md.Spec.Template.Spec.Version = &step.TargetVersion
+
+	patchBackoff := newWriteBackoff()
+	if err := retryWithExponentialBackoff(patchBackoff, func() error {
+		return c.Patch(ctx, md, patch)
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to set the version of MachineDeployment %s/%s", step.Object.Namespace, step.Object.Name)
+	}
+
+	return func() (bool, error) {
+		md, err := getMachineDeployment(c, step.Object.Namespace, step.Object.Name)
+		if err != nil {
+			return false, err
+		}
+		return getRolloutStatus(md).Complete, nil
+	}, nil
+}
+
+// checkMachinesHealth returns an error if any of the Machines of a Cluster has a false health condition.
+func checkMachinesHealth(c client.Client, cluster *clusterv1.Cluster) error {
+	machineList := &clusterv1.MachineList{}
+	if err := c.List(ctx, machineList, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return errors.Wrapf(err, "failed to list Machines for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	for i := range machineList.Items {
+		machine := &machineList.Items[i]
+		for _, conditionType := range machineHealthConditions {
+			if conditions.IsFalse(machine, conditionType) {
+				return errors.Errorf("stopping the upgrade because Machine %s/%s is not healthy: %s condition is false: %s",
+					machine.Namespace, machine.Name, conditionType, conditions.GetMessage(machine, conditionType))
+			}
+		}
+	}
+	return nil
+}
+
+func getControlPlane(c client.Client, cluster *clusterv1.Cluster) (*unstructured.Unstructured, error) {
+	ref := cluster.Spec.ControlPlaneRef
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetAPIVersion(ref.APIVersion)
+	controlPlane.SetKind(ref.Kind)
+	key := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = cluster.Namespace
+	}
+	if err := c.Get(ctx, key, controlPlane); err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s %s/%s", ref.Kind, key.Namespace, key.Name)
+	}
+	return controlPlane, nil
+}
+
+func getMachineDeploymentsForCluster(c client.Client, cluster *clusterv1.Cluster) ([]*clusterv1.MachineDeployment, error) {
+	machineDeploymentList := &clusterv1.MachineDeploymentList{}
+	if err := c.List(ctx, machineDeploymentList, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list MachineDeployments for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	machineDeployments := make([]*clusterv1.MachineDeployment, 0, len(machineDeploymentList.Items))
+	for i := range machineDeploymentList.Items {
+		machineDeployments = append(machineDeployments, &machineDeploymentList.Items[i])
+	}
+	return machineDeployments, nil
+}
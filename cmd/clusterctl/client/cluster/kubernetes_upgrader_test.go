@@ -0,0 +1,268 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	fakecontrolplane "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/controlplane"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func fakeClusterForKubernetesUpgrade(controlPlaneVersion string, machineDeploymentVersions map[string]string) []runtime.Object {
+	cluster := &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "cluster1",
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneRef: &corev1.ObjectReference{
+				APIVersion: fakecontrolplane.GroupVersion.String(),
+				Kind:       "GenericControlPlane",
+				Name:       "cp1",
+			},
+		},
+	}
+	controlPlane := &fakecontrolplane.GenericControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: fakecontrolplane.GroupVersion.String(),
+			Kind:       "GenericControlPlane",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "cp1",
+		},
+		Spec: fakecontrolplane.GenericControlPlaneSpec{
+			Version: controlPlaneVersion,
+		},
+		Status: fakecontrolplane.GenericControlPlaneStatus{
+			Replicas:        3,
+			UpdatedReplicas: 3,
+			ReadyReplicas:   3,
+		},
+	}
+
+	objs := []runtime.Object{cluster, controlPlane}
+	for name, version := range machineDeploymentVersions {
+		md := fakeMachineDeployment(name, version, clusterv1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3})
+		md.Labels = map[string]string{clusterv1.ClusterLabelName: "cluster1"}
+		objs = append(objs, md)
+	}
+	return objs
+}
+
+func fakeMachineForKubernetesUpgrade(name string, healthy bool) *clusterv1.Machine {
+	machine := &clusterv1.Machine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      name,
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "cluster1"},
+		},
+	}
+	if healthy {
+		conditions.MarkTrue(machine, clusterv1.MachineHealthCheckSuccededCondition)
+	} else {
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeCondition, clusterv1.ConditionSeverityWarning, "Node is unhealthy")
+	}
+	return machine
+}
+
+func Test_kubernetesUpgrader_Plan(t *testing.T) {
+	type args struct {
+		objs              []runtime.Object
+		kubernetesVersion string
+		options           KubernetesUpgradeOptions
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantSteps []string
+		wantErr   bool
+	}{
+		{
+			name: "upgrades the control plane and then the MachineDeployments in alphabetical order",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.17.3", map[string]string{"md-b": "v1.17.3", "md-a": "v1.17.0"}),
+				kubernetesVersion: "v1.18.2",
+			},
+			wantSteps: []string{"GenericControlPlane/cp1", "MachineDeployment/md-a", "MachineDeployment/md-b"},
+			wantErr:   false,
+		},
+		{
+			name: "upgrades MachineDeployments in the requested order",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.17.3", map[string]string{"md-a": "v1.17.3", "md-b": "v1.17.3", "md-c": "v1.17.3"}),
+				kubernetesVersion: "v1.18.2",
+				options:           KubernetesUpgradeOptions{MachineDeploymentOrder: []string{"md-c"}},
+			},
+			wantSteps: []string{"GenericControlPlane/cp1", "MachineDeployment/md-c", "MachineDeployment/md-a", "MachineDeployment/md-b"},
+			wantErr:   false,
+		},
+		{
+			name: "skips objects already at the target version",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.18.2", map[string]string{"md-a": "v1.18.2", "md-b": "v1.17.3"}),
+				kubernetesVersion: "v1.18.2",
+			},
+			wantSteps: []string{"MachineDeployment/md-b"},
+			wantErr:   false,
+		},
+		{
+			name: "fails if the upgrade skips a minor version",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.16.4", nil),
+				kubernetesVersion: "v1.18.2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the upgrade skips a minor version for a MachineDeployment",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.17.3", map[string]string{"md-a": "v1.16.4"}),
+				kubernetesVersion: "v1.18.2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the upgrade is a downgrade",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.18.2", nil),
+				kubernetesVersion: "v1.17.3",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if a MachineDeployment in the requested order does not exist",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.17.3", map[string]string{"md-a": "v1.17.3"}),
+				kubernetesVersion: "v1.18.2",
+				options:           KubernetesUpgradeOptions{MachineDeploymentOrder: []string{"md-x"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the version is not valid",
+			args: args{
+				objs:              fakeClusterForKubernetesUpgrade("v1.17.3", nil),
+				kubernetesVersion: "latest",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			u := newKubernetesUpgrader(test.NewFakeProxy().WithObjs(tt.args.objs...), nil)
+
+			got, err := u.Plan("ns1", "cluster1", tt.args.kubernetesVersion, tt.args.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			steps := []string{}
+			for _, s := range got.Steps {
+				steps = append(steps, s.Object.Kind+"/"+s.Object.Name)
+				g.Expect(s.TargetVersion).To(Equal(tt.args.kubernetesVersion))
+			}
+			g.Expect(steps).To(Equal(tt.wantSteps))
+		})
+	}
+}
+
+func Test_kubernetesUpgrader_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		healthy bool
+		wantErr bool
+	}{
+		{
+			name:    "upgrades all the objects",
+			healthy: true,
+			wantErr: false,
+		},
+		{
+			name:    "stops if a Machine is not healthy",
+			healthy: false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs := fakeClusterForKubernetesUpgrade("v1.17.3", map[string]string{"md-a": "v1.17.3"})
+			objs = append(objs, fakeMachineForKubernetesUpgrade("m1", tt.healthy))
+			proxy := test.NewFakeProxy().WithObjs(objs...)
+
+			pollImmediateWaiter := func(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+				done, err := condition()
+				if err != nil {
+					return err
+				}
+				if !done {
+					return wait.ErrWaitTimeout
+				}
+				return nil
+			}
+			u := newKubernetesUpgrader(proxy, pollImmediateWaiter)
+
+			plan, err := u.Plan("ns1", "cluster1", "v1.18.2", KubernetesUpgradeOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(plan.Steps).To(HaveLen(2))
+
+			err = u.Apply(plan, KubernetesUpgradeOptions{})
+
+			c, cerr := proxy.NewClient()
+			g.Expect(cerr).NotTo(HaveOccurred())
+			controlPlane := &fakecontrolplane.GenericControlPlane{}
+			g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "cp1"}, controlPlane)).To(Succeed())
+			md, merr := getMachineDeployment(c, "ns1", "md-a")
+			g.Expect(merr).NotTo(HaveOccurred())
+
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(controlPlane.Spec.Version).To(Equal("v1.17.3"))
+				g.Expect(md.Spec.Template.Spec.Version).To(Equal(pointer.StringPtr("v1.17.3")))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(controlPlane.Spec.Version).To(Equal("v1.18.2"))
+			g.Expect(md.Spec.Template.Spec.Version).To(Equal(pointer.StringPtr("v1.18.2")))
+		})
+	}
+}
@@ -17,7 +17,14 @@ limitations under the License.
 package cluster
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -28,48 +35,203 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ObjectMover defines methods for moving Cluster API objects to another management cluster.
 type ObjectMover interface {
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
-	Move(namespace string, toCluster Client) error
+	// If dryRun is set, the objects are discovered and the move sequence is computed and logged, but nothing is paused, created or deleted;
+	// in this case toCluster is optional, and if it is nil the checks on the providers in the target cluster are skipped.
+	// Move records its progress on the Cluster objects in the source management cluster, so if a previous move was interrupted
+	// it is resumed from the last completed step.
+	// Move returns the objects to be moved, grouped in the order they are created in the target cluster.
+	Move(namespace string, toCluster Client, dryRun bool) ([][]corev1.ObjectReference, error)
+
+	// Rollback reverts an interrupted move by deleting the objects already created in the target management cluster and
+	// by resuming the reconciliation of the Cluster objects in the source management cluster.
+	// Rollback is not possible if the interrupted move already started deleting objects from the source management cluster.
+	Rollback(namespace string, toCluster Client) error
+
+	// Backup saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target directory.
+	Backup(namespace string, directory string) error
+
+	// Restore restores all the Cluster API objects existing in a source directory to a target management cluster.
+	Restore(toCluster Client, directory string) error
+}
+
+// backupProvidersFile is the name of the file where backup saves the providers installed in the source cluster;
+// the file is not read as an object to restore given that the extension is not .yaml.
+const backupProvidersFile = "providers.json"
+
+// backupProvidersInfo defines the information about the providers in the source cluster that are saved by backup.
+type backupProvidersInfo struct {
+	Namespace string                  `json:"namespace"`
+	Providers []clusterctlv1.Provider `json:"providers"`
 }
 
 // objectMover implements the ObjectMover interface.
 type objectMover struct {
 	fromProxy             Proxy
 	fromProviderInventory InventoryClient
+
+	// writeBackoff overrides the backoff used when creating, deleting or saving objects; if not set, newWriteBackoff is used.
+	writeBackoff *wait.Backoff
 }
 
 // ensure objectMover implements the ObjectMover interface.
 var _ ObjectMover = &objectMover{}
 
-func (o *objectMover) Move(namespace string, toCluster Client) error {
+func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) ([][]corev1.ObjectReference, error) {
 	log := logf.Log
-	log.Info("Performing move...")
+	if dryRun {
+		log.Info("Performing move (dry run)...")
+	} else {
+		log.Info("Performing move...")
+	}
 
-	objectGraph := newObjectGraph(o.fromProxy)
+	if toCluster == nil && !dryRun {
+		return nil, errors.New("a target cluster is required for move")
+	}
 
 	// checks that all the required providers in place in the target cluster.
-	if err := o.checkTargetProviders(namespace, toCluster.ProviderInventory()); err != nil {
+	// Nb. In dry run mode the target cluster is optional.
+	if toCluster != nil {
+		fromProviders, err := o.fromProviderInventory.List()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get provider list from the source cluster")
+		}
+		if err := checkTargetProviders(namespace, fromProviders, toCluster.ProviderInventory()); err != nil {
+			return nil, err
+		}
+	}
+
+	objectGraph, err := o.getObjectGraph(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logs the move sequence without moving the objects.
+	if dryRun {
+		moveSequence := getMoveSequence(objectGraph)
+		logMoveSequence(moveSequence)
+		return moveSequence.objectReferences(), nil
+	}
+
+	// Move the objects to the target cluster.
+	if err := o.move(objectGraph, toCluster.Proxy()); err != nil {
+		return nil, err
+	}
+
+	return getMoveSequence(objectGraph).objectReferences(), nil
+}
+
+func (o *objectMover) Rollback(namespace string, toCluster Client) error {
+	log := logf.Log
+	log.Info("Performing rollback of move...")
+
+	objectGraph, err := o.getObjectGraph(namespace)
+	if err != nil {
+		return err
+	}
+
+	// Rollback the objects created in the target cluster.
+	if err := o.rollback(objectGraph, toCluster.Proxy()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *objectMover) Backup(namespace string, directory string) error {
+	log := logf.Log
+	log.Info("Performing backup...")
+
+	objectGraph, err := o.getObjectGraph(namespace)
+	if err != nil {
+		return err
+	}
+
+	// Save the providers watching the objects, so restore can check the same providers are installed in the target cluster.
+	if err := o.backupProviders(namespace, directory); err != nil {
+		return err
+	}
+
+	// Save the objects to the target directory.
+	if err := o.backup(objectGraph, directory); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *objectMover) Restore(toCluster Client, directory string) error {
+	log := logf.Log
+	log.Info("Performing restore...")
+
+	// Builds an empty object graph bound to the target cluster; the graph is going to be populated with the objects read from the source directory.
+	objectGraph := newObjectGraph(toCluster.Proxy())
+
+	// Checks that all the providers in place in the source cluster at the time of the backup are installed in the target cluster.
+	backup, err := readBackupProviders(directory)
+	if err != nil {
+		return err
+	}
+	if err := checkTargetProviders(backup.Namespace, &clusterctlv1.ProviderList{Items: backup.Providers}, toCluster.ProviderInventory()); err != nil {
+		return err
+	}
+
+	objs, err := readBackupFiles(directory)
+	if err != nil {
+		return err
+	}
+
+	// Builds the object graph from the objects read from the source directory:
+	// - Nodes are defined the Kubernetes objects (Clusters, Machines etc.) read from the backup files.
+	// - Edges are derived by the OwnerReferences between nodes, that are still referring to the UIDs at the time of the backup.
+	for i := range objs {
+		objectGraph.addRestoredObj(&objs[i])
+	}
+
+	// Completes the graph by searching for soft ownership relations such as secrets linked to the cluster
+	// by a naming convention (without any explicit OwnerReference).
+	objectGraph.setSoftOwnership()
+
+	// Completes the graph by setting for each node the list of Clusters the node belong to.
+	objectGraph.setClusterTenants()
+
+	// Completes the graph by setting for each node the list of ClusterResourceSet the node belong to.
+	objectGraph.setCRSTenants()
+
+	// Restore the objects to the target cluster.
+	if err := o.restore(objectGraph, toCluster.Proxy()); err != nil {
 		return err
 	}
 
+	return nil
+}
+
+// getObjectGraph returns the graph of the Cluster API objects existing in a namespace (or from all the namespaces if empty),
+// after checking if Cluster API has already completed the provisioning of the infrastructure for the objects in the graph.
+func (o *objectMover) getObjectGraph(namespace string) (*objectGraph, error) {
+	objectGraph := newObjectGraph(o.fromProxy)
+
 	// Gets all the types defines by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
 	types, err := objectGraph.getDiscoveryTypes()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Discovery the object graph for the selected types:
 	// - Nodes are defined the Kubernetes objects (Clusters, Machines etc.) identified during the discovery process.
 	// - Edges are derived by the OwnerReferences between nodes.
 	if err := objectGraph.Discovery(namespace, types); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move operation.
@@ -77,16 +239,11 @@ func (o *objectMover) Move(namespace string, toCluster Client) error {
 	// not currently waiting for long-running reconciliation loops, and so we can safely rely on the pause field on the Cluster object
 	// for blocking any further object reconciliation on the source objects.
 	if err := o.checkProvisioningCompleted(objectGraph); err != nil {
-		return err
+		return nil, err
 	}
 	//TODO: consider if to add additional preflight checks ensuring the object graph is complete (no virtual nodes left)
 
-	// Move the objects to the target cluster.
-	if err := o.move(objectGraph, toCluster.Proxy()); err != nil {
-		return err
-	}
-
-	return nil
+	return objectGraph, nil
 }
 
 func newObjectMover(fromProxy Proxy, fromProviderInventory InventoryClient) *objectMover {
@@ -167,6 +324,21 @@ func getClusterObj(proxy Proxy, cluster *node, clusterObj *clusterv1.Cluster) er
 	return nil
 }
 
+// getUnpausedClusters returns the nodes referring to Cluster objects without the pause field set.
+func getUnpausedClusters(proxy Proxy, clusters []*node) ([]*node, error) {
+	unpaused := []*node{}
+	for _, cluster := range clusters {
+		clusterObj := &clusterv1.Cluster{}
+		if err := getClusterObj(proxy, cluster, clusterObj); err != nil {
+			return nil, err
+		}
+		if !clusterObj.Spec.Paused {
+			unpaused = append(unpaused, cluster)
+		}
+	}
+	return unpaused, nil
+}
+
 // getMachineObj retrieves the the machineObj corresponding to a node with type Machine.
 func getMachineObj(proxy Proxy, machine *node, machineObj *clusterv1.Machine) error {
 	c, err := proxy.NewClient()
@@ -192,16 +364,27 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 	clusters := graph.getClusters()
 	log.Info("Moving Cluster API objects", "Clusters", len(clusters))
 
+	// Gets the progress of a previous move, if any, so the move can be resumed from the last completed step.
+	progress, err := getMoveProgress(o.fromProxy, clusters)
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		log.Info("Resuming a previously interrupted move", "Progress", progress.String())
+	}
+
 	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
 	log.V(1).Info("Pausing the source cluster")
 	if err := setClusterPause(o.fromProxy, clusters, true); err != nil {
 		return err
 	}
 
-	// Ensure all the expected target namespaces are in place before creating objects.
-	log.V(1).Info("Creating target namespaces, if missing")
-	if err := o.ensureNamespaces(graph, toProxy); err != nil {
-		return err
+	// Records the move is started.
+	if progress == nil {
+		progress = &moveProgress{}
+		if err := setMoveProgress(o.fromProxy, clusters, progress); err != nil {
+			return err
+		}
 	}
 
 	// Define the move sequence by processing the ownerReference chain, so we ensure that a Kubernetes object is moved only after its owners.
@@ -211,18 +394,49 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 	// - then all the MachineSets, then all the Machines, etc.
 	moveSequence := getMoveSequence(graph)
 
-	// Create all objects group by group, ensuring all the ownerReferences are re-created.
-	log.Info("Creating objects in the target cluster")
-	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
-		if err := o.createGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+	// If a previous move already started deleting objects from the source cluster, all the objects exist in the target cluster,
+	// so it is possible to skip directly to the deletion.
+	if !progress.deleting {
+		// Ensure all the expected target namespaces are in place before creating objects.
+		log.V(1).Info("Creating target namespaces, if missing")
+		if err := o.ensureNamespaces(graph, toProxy); err != nil {
+			return err
+		}
+
+		// Create all objects group by group, ensuring all the ownerReferences are re-created.
+		log.Info("Creating objects in the target cluster")
+		for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+			// If the group was already created by a previous move, gets the UID of the existing objects so the
+			// ownerReferences for the objects in the next groups can be re-created.
+			if groupIndex < progress.createdGroups {
+				if err := o.resumeGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := o.createGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+				return err
+			}
+
+			progress.createdGroups = groupIndex + 1
+			if err := setMoveProgress(o.fromProxy, clusters, progress); err != nil {
+				return err
+			}
+		}
+
+		// Records all the objects exist in the target cluster, and so it is safe to start deleting objects from the source cluster.
+		progress.deleting = true
+		if err := setMoveProgress(o.fromProxy, clusters, progress); err != nil {
 			return err
 		}
 	}
 
 	// Delete all objects group by group in reverse order.
+	// Nb. Cluster objects, and thus the move progress, are deleted last.
 	log.Info("Deleting objects from the source cluster")
 	for groupIndex := len(moveSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
-		if err := o.deleteGroup(moveSequence.getGroup(groupIndex)); err != nil {
+		if err := o.deleteGroup(moveSequence.getGroup(groupIndex), o.fromProxy); err != nil {
 			return err
 		}
 	}
@@ -236,6 +450,127 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 	return nil
 }
 
+// rollback reverts an interrupted move, deleting the objects created in the target management cluster and resuming the source management cluster.
+func (o *objectMover) rollback(graph *objectGraph, toProxy Proxy) error {
+	log := logf.Log
+
+	clusters := graph.getClusters()
+	log.Info("Rolling back the move of Cluster API objects", "Clusters", len(clusters))
+
+	progress, err := getMoveProgress(o.fromProxy, clusters)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		return errors.New("there is no interrupted move to roll back")
+	}
+	if progress.deleting {
+		return errors.New("the interrupted move already started deleting objects from the source cluster, so it cannot be rolled back; please run move again to complete it")
+	}
+
+	// Delete the objects created in the target cluster group by group in reverse order, including the group
+	// the interrupted move was creating, which could be partially created.
+	moveSequence := getMoveSequence(graph)
+	lastGroup := progress.createdGroups
+	if lastGroup > len(moveSequence.groups)-1 {
+		lastGroup = len(moveSequence.groups) - 1
+	}
+
+	log.Info("Deleting objects from the target cluster")
+	for groupIndex := lastGroup; groupIndex >= 0; groupIndex-- {
+		if err := o.deleteMovedGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+			return err
+		}
+	}
+
+	// Removes the move progress and resets the pause field on the Cluster object in the source management cluster, so the controllers start reconciling it again.
+	log.V(1).Info("Resuming the source cluster")
+	if err := setMoveProgress(o.fromProxy, clusters, nil); err != nil {
+		return err
+	}
+	if err := setClusterPause(o.fromProxy, clusters, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// backup saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target directory.
+func (o *objectMover) backup(graph *objectGraph, directory string) (reterr error) {
+	log := logf.Log
+
+	clusters := graph.getClusters()
+	log.Info("Saving Cluster API objects", "Clusters", len(clusters))
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create the backup directory %q", directory)
+	}
+
+	// Clusters already paused by the user are left as they are, so the backup does not resume them.
+	unpausedClusters, err := getUnpausedClusters(o.fromProxy, clusters)
+	if err != nil {
+		return err
+	}
+
+	// Reset the pause field on the Cluster objects paused by the backup on every exit path, including failures,
+	// so the controllers start reconciling them again.
+	defer func() {
+		log.V(1).Info("Resuming the source cluster")
+		if err := setClusterPause(o.fromProxy, unpausedClusters, false); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it
+	// and the saved objects are consistent; as a consequence, the Clusters are saved with the pause field set.
+	log.V(1).Info("Pausing the source cluster")
+	if err := setClusterPause(o.fromProxy, unpausedClusters, true); err != nil {
+		return err
+	}
+
+	// Save all objects following the same sequence used for move, so the backup is consistent with the move process.
+	moveSequence := getMoveSequence(graph)
+	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+		if err := o.backupGroup(moveSequence.getGroup(groupIndex), directory); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restore restores all the Cluster API objects read from a source directory to a target management cluster.
+func (o *objectMover) restore(graph *objectGraph, toProxy Proxy) error {
+	log := logf.Log
+
+	clusters := graph.getClusters()
+	log.Info("Restoring Cluster API objects", "Clusters", len(clusters))
+
+	// Ensure all the expected target namespaces are in place before creating objects.
+	log.V(1).Info("Creating target namespaces, if missing")
+	if err := o.ensureNamespaces(graph, toProxy); err != nil {
+		return err
+	}
+
+	// Create all objects group by group following the move sequence, ensuring all the ownerReferences are re-created.
+	moveSequence := getMoveSequence(graph)
+	log.Info("Creating objects in the target cluster")
+	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+		if err := o.createGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+			return err
+		}
+	}
+
+	// Reset the pause field on the Cluster object in the target management cluster, so the controllers start reconciling it.
+	// Nb. Clusters are saved with the pause field set during backup.
+	log.V(1).Info("Resuming the target cluster")
+	if err := setClusterPause(toProxy, clusters, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // moveSequence defines a list of group of moveGroups
 type moveSequence struct {
 	groups   []moveGroup
@@ -263,6 +598,20 @@ func (s *moveSequence) getGroup(i int) moveGroup {
 	return s.groups[i]
 }
 
+// objectReferences returns the identity of the objects in the move sequence, grouped in the same order
+// they are created in the target cluster and sorted by Kind, Namespace and Name within each group.
+func (s *moveSequence) objectReferences() [][]corev1.ObjectReference {
+	ret := make([][]corev1.ObjectReference, 0, len(s.groups))
+	for _, group := range s.groups {
+		refs := make([]corev1.ObjectReference, 0, len(group))
+		for _, n := range sortedGroup(group) {
+			refs = append(refs, n.identity)
+		}
+		ret = append(ret, refs)
+	}
+	return ret
+}
+
 // Define the move sequence by processing the ownerReference chain.
 func getMoveSequence(graph *objectGraph) *moveSequence {
 	moveSequence := &moveSequence{
@@ -310,6 +659,183 @@ func getMoveSequence(graph *objectGraph) *moveSequence {
 	return moveSequence
 }
 
+// logMoveSequence logs the objects that move would create in the target cluster and delete from the source cluster, group by group,
+// including for each object the list of owners and soft owners, so the user can review the move sequence before running move.
+func logMoveSequence(moveSequence *moveSequence) {
+	log := logf.Log
+
+	log.Info("Dry run: no objects will be paused, created or deleted")
+
+	log.Info("Objects to be created in the target cluster", "Groups", len(moveSequence.groups))
+	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+		for _, n := range sortedGroup(moveSequence.getGroup(groupIndex)) {
+			log.Info("Create", append(nodeToValues(n), "Group", groupIndex+1, "Owners", ownersToValues(n.owners), "SoftOwners", softOwnersToValues(n.softOwners))...)
+			if len(n.owners) == 0 && len(n.softOwners) > 0 {
+				log.Info("Object is only soft owned, it is moved because it is linked to a Cluster by a naming convention", nodeToValues(n)...)
+			}
+		}
+	}
+
+	log.Info("Objects to be deleted from the source cluster", "Groups", len(moveSequence.groups))
+	for groupIndex := len(moveSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
+		for _, n := range sortedGroup(moveSequence.getGroup(groupIndex)) {
+			log.Info("Delete", append(nodeToValues(n), "Group", groupIndex+1)...)
+		}
+	}
+}
+
+// sortedGroup returns a copy of a moveGroup sorted by Kind, Namespace and Name, so the move sequence is always logged in a consistent way.
+func sortedGroup(group moveGroup) moveGroup {
+	sorted := make(moveGroup, len(group))
+	copy(sorted, group)
+	sort.Slice(sorted, func(i, j int) bool {
+		return nodeToString(sorted[i]) < nodeToString(sorted[j])
+	})
+	return sorted
+}
+
+// nodeToValues returns the log values describing a node e.g. Cluster="foo" Namespace="ns1".
+func nodeToValues(n *node) []interface{} {
+	values := []interface{}{n.identity.Kind, n.identity.Name}
+	if n.identity.Namespace != "" {
+		values = append(values, "Namespace", n.identity.Namespace)
+	}
+	return values
+}
+
+// nodeToString returns a string describing a node e.g. Cluster/ns1/foo.
+func nodeToString(n *node) string {
+	return fmt.Sprintf("%s/%s/%s", n.identity.Kind, n.identity.Namespace, n.identity.Name)
+}
+
+// ownersToValues returns a sorted list of strings describing the owners of a node.
+func ownersToValues(owners map[*node]ownerReferenceAttributes) []string {
+	ret := []string{}
+	for owner := range owners {
+		ret = append(ret, fmt.Sprintf("%s/%s", owner.identity.Kind, owner.identity.Name))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// softOwnersToValues returns a sorted list of strings describing the soft owners of a node.
+func softOwnersToValues(softOwners map[*node]empty) []string {
+	ret := []string{}
+	for owner := range softOwners {
+		ret = append(ret, fmt.Sprintf("%s/%s", owner.identity.Kind, owner.identity.Name))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// moveProgress defines the progress of a move operation, as recorded on the Cluster objects in the source management cluster.
+type moveProgress struct {
+	// createdGroups is the number of groups in the move sequence already created in the target management cluster.
+	createdGroups int
+
+	// deleting is true if all the objects are created in the target management cluster and the deletion
+	// from the source management cluster is started.
+	deleting bool
+}
+
+const (
+	moveProgressCreating = "Creating"
+	moveProgressDeleting = "Deleting"
+)
+
+// String returns the value of the MoveProgressAnnotation for the moveProgress.
+func (p *moveProgress) String() string {
+	if p.deleting {
+		return moveProgressDeleting
+	}
+	return fmt.Sprintf("%s/%d", moveProgressCreating, p.createdGroups)
+}
+
+// before returns true if the moveProgress is before another moveProgress.
+func (p *moveProgress) before(other *moveProgress) bool {
+	if p.deleting != other.deleting {
+		return other.deleting
+	}
+	return p.createdGroups < other.createdGroups
+}
+
+// parseMoveProgress parses the value of the MoveProgressAnnotation.
+func parseMoveProgress(value string) (*moveProgress, error) {
+	if value == moveProgressDeleting {
+		return &moveProgress{deleting: true}, nil
+	}
+
+	if strings.HasPrefix(value, moveProgressCreating+"/") {
+		createdGroups, err := strconv.Atoi(strings.TrimPrefix(value, moveProgressCreating+"/"))
+		if err == nil && createdGroups >= 0 {
+			return &moveProgress{createdGroups: createdGroups}, nil
+		}
+	}
+
+	return nil, errors.Errorf("invalid value %q for the %s annotation", value, clusterctlv1.MoveProgressAnnotation)
+}
+
+// getMoveProgress returns the progress of a previous move as recorded on the Cluster objects in the source management cluster,
+// or nil if there is no move in progress. If the Cluster objects have different progress, the least advanced one is returned.
+func getMoveProgress(proxy Proxy, clusters []*node) (*moveProgress, error) {
+	var progress *moveProgress
+	missing := 0
+	for _, cluster := range clusters {
+		clusterObj := &clusterv1.Cluster{}
+		if err := getClusterObj(proxy, cluster, clusterObj); err != nil {
+			return nil, err
+		}
+
+		value, ok := clusterObj.Annotations[clusterctlv1.MoveProgressAnnotation]
+		if !ok {
+			missing++
+			continue
+		}
+
+		clusterProgress, err := parseMoveProgress(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the move progress for %q %s/%s",
+				clusterObj.GroupVersionKind(), clusterObj.GetNamespace(), clusterObj.GetName())
+		}
+		if progress == nil || clusterProgress.before(progress) {
+			progress = clusterProgress
+		}
+	}
+
+	// If the progress is recorded only on some of the Cluster objects, the previous move was interrupted while
+	// recording the move start, so it is required to start again from the beginning.
+	if progress != nil && missing > 0 {
+		return &moveProgress{}, nil
+	}
+	return progress, nil
+}
+
+// setMoveProgress records the progress of the move operation on the Cluster objects in the source management cluster;
+// if progress is nil, the move progress is removed.
+func setMoveProgress(proxy Proxy, clusters []*node, progress *moveProgress) error {
+	log := logf.Log
+
+	value := "null"
+	if progress != nil {
+		value = fmt.Sprintf("%q", progress.String())
+	}
+	patch := client.RawPatch(types.MergePatchType, []byte(fmt.Sprintf("{\"metadata\":{\"annotations\":{%q:%s}}}", clusterctlv1.MoveProgressAnnotation, value)))
+
+	setMoveProgressBackoff := newWriteBackoff()
+	for i := range clusters {
+		cluster := clusters[i]
+		log.V(5).Info("Set move progress", "Progress", value, "Cluster", cluster.identity.Name, "Namespace", cluster.identity.Namespace)
+
+		// Nb. The operation is wrapped in a retry loop to make setMoveProgress more resilient to unexpected conditions.
+		if err := retryWithExponentialBackoff(setMoveProgressBackoff, func() error {
+			return patchCluster(proxy, cluster, patch)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // setClusterPause sets the paused field on nodes referring to Cluster objects.
 func setClusterPause(proxy Proxy, clusters []*node, value bool) error {
 	log := logf.Log
@@ -349,7 +875,7 @@ func patchCluster(proxy Proxy, cluster *node, patch client.Patch) error {
 	}
 
 	if err := cFrom.Patch(ctx, clusterObj, patch); err != nil {
-		return errors.Wrapf(err, "error pausing reconciliation for %q %s/%s",
+		return errors.Wrapf(err, "error patching %q %s/%s",
 			clusterObj.GroupVersionKind(), clusterObj.GetNamespace(), clusterObj.GetName())
 	}
 
@@ -444,7 +970,7 @@ func (o *objectMover) ensureNamespace(toProxy Proxy, namespace string) error {
 
 // createGroup creates all the Kubernetes objects into the target management cluster corresponding to the object graph nodes in a moveGroup.
 func (o *objectMover) createGroup(group moveGroup, toProxy Proxy) error {
-	createTargetObjectBackoff := newWriteBackoff()
+	createTargetObjectBackoff := o.getWriteBackoff()
 	errList := []error{}
 	for i := range group {
 		nodeToCreate := group[i]
@@ -466,33 +992,84 @@ func (o *objectMover) createGroup(group moveGroup, toProxy Proxy) error {
 	return nil
 }
 
+// resumeGroup gets the newUID for all the Kubernetes objects corresponding to the object graph nodes in a moveGroup already created
+// in the target management cluster by a previous move; if an object does not exist, it is created.
+func (o *objectMover) resumeGroup(group moveGroup, toProxy Proxy) error {
+	cTo, err := toProxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	readTargetObjectBackoff := newReadBackoff()
+	errList := []error{}
+	for i := range group {
+		nodeToResume := group[i]
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(nodeToResume.identity.APIVersion)
+		obj.SetKind(nodeToResume.identity.Kind)
+		objKey := client.ObjectKey{
+			Namespace: nodeToResume.identity.Namespace,
+			Name:      nodeToResume.identity.Name,
+		}
+
+		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(readTargetObjectBackoff, func() error {
+			if err := cTo.Get(ctx, objKey, obj); err != nil {
+				if apierrors.IsNotFound(err) {
+					return o.createTargetObject(nodeToResume, toProxy)
+				}
+				return errors.Wrapf(err, "error reading %q %s/%s",
+					obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+			}
+			nodeToResume.newUID = obj.GetUID()
+			return nil
+		})
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// getWriteBackoff returns the backoff to be used when creating, deleting or saving objects.
+func (o *objectMover) getWriteBackoff() wait.Backoff {
+	if o.writeBackoff != nil {
+		return *o.writeBackoff
+	}
+	return newWriteBackoff()
+}
+
 // createTargetObject creates the Kubernetes object in the target Management cluster corresponding to the object graph node, taking care of restoring the OwnerReference with the owner nodes, if any.
 func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) error {
 	log := logf.Log
 	log.V(1).Info("Creating", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
 
-	cFrom, err := o.fromProxy.NewClient()
-	if err != nil {
-		return err
-	}
-
-	// Get the source object
-	obj := &unstructured.Unstructured{}
-	obj.SetAPIVersion(nodeToCreate.identity.APIVersion)
-	obj.SetKind(nodeToCreate.identity.Kind)
 	objKey := client.ObjectKey{
 		Namespace: nodeToCreate.identity.Namespace,
 		Name:      nodeToCreate.identity.Name,
 	}
 
-	if err := cFrom.Get(ctx, objKey, obj); err != nil {
-		return errors.Wrapf(err, "error reading %q %s/%s",
-			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	// Get the source object; if the node was read from a backup file, use the saved object, otherwise read it from the source cluster.
+	obj, err := o.getSourceObject(nodeToCreate)
+	if err != nil {
+		return err
 	}
 
 	// New objects cannot have a specified resource version. Clear it out.
 	obj.SetResourceVersion("")
 
+	// Removes the move progress, which applies only to the objects in the source management cluster, and
+	// marks the object as created by move, so it can be deleted by rollback.
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, clusterctlv1.MoveProgressAnnotation)
+	annotations[clusterctlv1.MovedFromAnnotation] = string(nodeToCreate.identity.UID)
+	obj.SetAnnotations(annotations)
+
 	// Removes current OwnerReferences
 	obj.SetOwnerReferences(nil)
 
@@ -544,6 +1121,14 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 				existingTargetObj.GroupVersionKind(), existingTargetObj.GetNamespace(), existingTargetObj.GetName())
 		}
 
+		// If the existing object was not created by a previous attempt of this move, do not mark it as created by move,
+		// so it is not deleted by rollback.
+		if !isMovedFrom(existingTargetObj, nodeToCreate) {
+			annotations := obj.GetAnnotations()
+			delete(annotations, clusterctlv1.MovedFromAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+
 		obj.SetUID(existingTargetObj.GetUID())
 		obj.SetResourceVersion(existingTargetObj.GetResourceVersion())
 		if err := cTo.Update(ctx, obj); err != nil {
@@ -558,17 +1143,169 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 	return nil
 }
 
-// deleteGroup deletes all the Kubernetes objects from the source management cluster corresponding to the object graph nodes in a moveGroup.
-func (o *objectMover) deleteGroup(group moveGroup) error {
-	deleteSourceObjectBackoff := newWriteBackoff()
+// isMovedFrom returns true if the object in the target management cluster was created by move from the object corresponding to the node.
+func isMovedFrom(obj *unstructured.Unstructured, n *node) bool {
+	movedFrom, ok := obj.GetAnnotations()[clusterctlv1.MovedFromAnnotation]
+	return ok && movedFrom == string(n.identity.UID)
+}
+
+// getSourceObject returns the object corresponding to a node, using the object read from a backup file if any, or reading it from the source cluster.
+func (o *objectMover) getSourceObject(n *node) (*unstructured.Unstructured, error) {
+	if n.restoreObject != nil {
+		return n.restoreObject.DeepCopy(), nil
+	}
+
+	cFrom, err := o.fromProxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(n.identity.APIVersion)
+	obj.SetKind(n.identity.Kind)
+	objKey := client.ObjectKey{
+		Namespace: n.identity.Namespace,
+		Name:      n.identity.Name,
+	}
+
+	if err := cFrom.Get(ctx, objKey, obj); err != nil {
+		return nil, errors.Wrapf(err, "error reading %q %s/%s",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return obj, nil
+}
+
+// backupGroup saves all the Kubernetes objects corresponding to the object graph nodes in a moveGroup to a target directory.
+func (o *objectMover) backupGroup(group moveGroup, directory string) error {
+	backupTargetObjectBackoff := o.getWriteBackoff()
+	errList := []error{}
+	for i := range group {
+		nodeToBackup := group[i]
+
+		// Save the Kubernetes object corresponding to the nodeToBackup.
+		// Nb. The operation is wrapped in a retry loop to make backup more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(backupTargetObjectBackoff, func() error {
+			return o.backupTargetObject(nodeToBackup, directory)
+		})
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// backupTargetObject saves the Kubernetes object corresponding to the object graph node to a file in the target directory.
+func (o *objectMover) backupTargetObject(nodeToBackup *node, directory string) error {
+	log := logf.Log
+	log.V(1).Info("Saving", nodeToBackup.identity.Kind, nodeToBackup.identity.Name, "Namespace", nodeToBackup.identity.Namespace)
+
+	obj, err := o.getSourceObject(nodeToBackup)
+	if err != nil {
+		return err
+	}
+
+	content, err := utilyaml.FromUnstructured([]unstructured.Unstructured{*obj})
+	if err != nil {
+		return errors.Wrapf(err, "error converting %q %s/%s to yaml",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+
+	path := filepath.Join(directory, backupFileName(nodeToBackup))
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return errors.Wrapf(err, "error writing %q", path)
+	}
+
+	return nil
+}
+
+// backupProviders saves to the target directory the providers installed in the source cluster, together with the namespace
+// being backed up.
+func (o *objectMover) backupProviders(namespace string, directory string) error {
+	providers, err := o.fromProviderInventory.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to get provider list from the source cluster")
+	}
+
+	content, err := json.MarshalIndent(backupProvidersInfo{Namespace: namespace, Providers: providers.Items}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the provider list")
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create the backup directory %q", directory)
+	}
+	path := filepath.Join(directory, backupProvidersFile)
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return errors.Wrapf(err, "error writing %q", path)
+	}
+	return nil
+}
+
+// readBackupProviders reads the providers installed in the source cluster at the time of the backup from a source directory.
+func readBackupProviders(directory string) (*backupProvidersInfo, error) {
+	path := filepath.Join(directory, backupProvidersFile)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the list of providers from the backup directory %q", directory)
+	}
+
+	info := &backupProvidersInfo{}
+	if err := json.Unmarshal(content, info); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %q", path)
+	}
+	return info, nil
+}
+
+// backupFileName returns the name of the backup file for the Kubernetes object corresponding to the object graph node.
+func backupFileName(n *node) string {
+	return fmt.Sprintf("%s_%s_%s.yaml", n.identity.Kind, n.identity.Namespace, n.identity.Name)
+}
+
+// readBackupFiles reads all the Kubernetes objects saved in the backup files existing in a source directory.
+func readBackupFiles(directory string) ([]unstructured.Unstructured, error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the backup directory %q", directory)
+	}
+
+	objs := []unstructured.Unstructured{}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".yaml" {
+			continue
+		}
+
+		path := filepath.Join(directory, f.Name())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading %q", path)
+		}
+
+		fileObjs, err := utilyaml.ToUnstructured(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing %q", path)
+		}
+		objs = append(objs, fileObjs...)
+	}
+
+	if len(objs) == 0 {
+		return nil, errors.Errorf("the backup directory %q does not contain any object to restore", directory)
+	}
+
+	return objs, nil
+}
+
+// deleteGroup deletes all the Kubernetes objects from a management cluster corresponding to the object graph nodes in a moveGroup.
+func (o *objectMover) deleteGroup(group moveGroup, proxy Proxy) error {
+	deleteObjectBackoff := o.getWriteBackoff()
 	errList := []error{}
 	for i := range group {
 		nodeToDelete := group[i]
 
 		// Delete the Kubernetes object corresponding to the current node.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
-		err := retryWithExponentialBackoff(deleteSourceObjectBackoff, func() error {
-			return o.deleteSourceObject(nodeToDelete)
+		err := retryWithExponentialBackoff(deleteObjectBackoff, func() error {
+			return o.deleteObject(nodeToDelete, proxy)
 		})
 
 		if err != nil {
@@ -579,63 +1316,113 @@ func (o *objectMover) deleteGroup(group moveGroup) error {
 	return kerrors.NewAggregate(errList)
 }
 
+// deleteMovedGroup deletes all the Kubernetes objects corresponding to the nodes in a moveGroup from the target management cluster,
+// skipping the objects that were not created by move.
+func (o *objectMover) deleteMovedGroup(group moveGroup, toProxy Proxy) error {
+	deleteObjectBackoff := o.getWriteBackoff()
+	errList := []error{}
+	for i := range group {
+		nodeToDelete := group[i]
+
+		// Nb. The operation is wrapped in a retry loop to make rollback more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(deleteObjectBackoff, func() error {
+			return o.deleteMovedObject(nodeToDelete, toProxy)
+		})
+
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// deleteMovedObject deletes the Kubernetes object corresponding to the node from the target management cluster, only if it was created by move.
+func (o *objectMover) deleteMovedObject(nodeToDelete *node, toProxy Proxy) error {
+	log := logf.Log
+
+	c, err := toProxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(nodeToDelete.identity.APIVersion)
+	obj.SetKind(nodeToDelete.identity.Kind)
+	objKey := client.ObjectKey{
+		Namespace: nodeToDelete.identity.Namespace,
+		Name:      nodeToDelete.identity.Name,
+	}
+
+	if err := c.Get(ctx, objKey, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			// If the object was not created or it is already deleted, move on.
+			return nil
+		}
+		return errors.Wrapf(err, "error reading %q %s/%s",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+
+	if !isMovedFrom(obj, nodeToDelete) {
+		log.V(1).Info("Object not created by move, skipping delete for", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
+		return nil
+	}
+
+	return o.deleteObject(nodeToDelete, toProxy)
+}
+
 var (
 	removeFinalizersPatch = client.RawPatch(types.MergePatchType, []byte("{\"metadata\":{\"finalizers\":[]}}"))
 )
 
-// deleteSourceObject deletes the Kubernetes object corresponding to the node from the source management cluster, taking care of removing all the finalizers so
+// deleteObject deletes the Kubernetes object corresponding to the node from a management cluster, taking care of removing all the finalizers so
 // the objects gets immediately deleted (force delete).
-func (o *objectMover) deleteSourceObject(nodeToDelete *node) error {
+func (o *objectMover) deleteObject(nodeToDelete *node, proxy Proxy) error {
 	log := logf.Log
 	log.V(1).Info("Deleting", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
 
-	cFrom, err := o.fromProxy.NewClient()
+	c, err := proxy.NewClient()
 	if err != nil {
 		return err
 	}
 
-	// Get the source object
-	sourceObj := &unstructured.Unstructured{}
-	sourceObj.SetAPIVersion(nodeToDelete.identity.APIVersion)
-	sourceObj.SetKind(nodeToDelete.identity.Kind)
-	sourceObjKey := client.ObjectKey{
+	// Get the object to be deleted
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(nodeToDelete.identity.APIVersion)
+	obj.SetKind(nodeToDelete.identity.Kind)
+	objKey := client.ObjectKey{
 		Namespace: nodeToDelete.identity.Namespace,
 		Name:      nodeToDelete.identity.Name,
 	}
 
-	if err := cFrom.Get(ctx, sourceObjKey, sourceObj); err != nil {
+	if err := c.Get(ctx, objKey, obj); err != nil {
 		if apierrors.IsNotFound(err) {
 			//If the object is already deleted, move on.
 			log.V(5).Info("Object already deleted, skipping delete for", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
 			return nil
 		}
 		return errors.Wrapf(err, "error reading %q %s/%s",
-			sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
 
-	if len(sourceObj.GetFinalizers()) > 0 {
-		if err := cFrom.Patch(ctx, sourceObj, removeFinalizersPatch); err != nil {
+	if len(obj.GetFinalizers()) > 0 {
+		if err := c.Patch(ctx, obj, removeFinalizersPatch); err != nil {
 			return errors.Wrapf(err, "error removing finalizers from %q %s/%s",
-				sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
+				obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 		}
 	}
 
-	if err := cFrom.Delete(ctx, sourceObj); err != nil {
+	if err := c.Delete(ctx, obj); err != nil {
 		return errors.Wrapf(err, "error deleting %q %s/%s",
-			sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
 
 	return nil
 }
 
 // checkTargetProviders checks that all the providers installed in the source cluster exists in the target cluster as well (with a version >= of the current version).
-func (o *objectMover) checkTargetProviders(namespace string, toInventory InventoryClient) error {
-	// Gets the list of providers in the source/target cluster.
-	fromProviders, err := o.fromProviderInventory.List()
-	if err != nil {
-		return errors.Wrapf(err, "failed to get provider list from the source cluster")
-	}
-
+func checkTargetProviders(namespace string, fromProviders *clusterctlv1.ProviderList, toInventory InventoryClient) error {
+	// Gets the list of providers in the target cluster.
 	toProviders, err := toInventory.List()
 	if err != nil {
 		return errors.Wrapf(err, "failed to get provider list from the target cluster")
@@ -17,14 +17,22 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
@@ -422,6 +430,16 @@ func Test_getMoveSequence(t *testing.T) {
 
 				g.Expect(gotNodes).To(ConsistOf(wantGroup))
 			}
+
+			gotRefs := moveSequence.objectReferences()
+			g.Expect(gotRefs).To(HaveLen(len(tt.wantMoveGroups)))
+			for i, gotGroup := range gotRefs {
+				gotUIDs := []string{}
+				for _, ref := range gotGroup {
+					gotUIDs = append(gotUIDs, string(ref.UID))
+				}
+				g.Expect(gotUIDs).To(ConsistOf(tt.wantMoveGroups[i]))
+			}
 		})
 	}
 }
@@ -500,6 +518,656 @@ func Test_objectMover_move(t *testing.T) {
 	}
 }
 
+func Test_objectMover_move_dryRun(t *testing.T) {
+	// NB. we are testing the dry run using the same set of moveTests used for move, checking that nothing changes in the source
+	// and in the target cluster.
+	for _, tt := range moveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test;
+			// Clusters and Machines are provisioned, so move can start.
+			graph := getObjectGraphWithObjs(withProvisioningCompleted(tt.fields.objs))
+
+			// gets a fakeProxy to an empty cluster with all the required CRDs
+			toProxy := getFakeProxyWithCRDs()
+			toCluster := newClusterClient(Kubeconfig{}, nil, InjectProxy(toProxy))
+
+			// Run move in dry run mode
+			mover := newObjectMover(graph.proxy, newInventoryClient(graph.proxy, nil))
+			moveGroups, err := mover.Move("ns1", toCluster, true)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(moveGroups).To(HaveLen(len(tt.wantMoveGroups)))
+
+			// check that the objects are still in the source cluster, that the clusters are not paused
+			// and that nothing is created in the target cluster
+			csFrom, err := graph.proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+			csTo, err := toProxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, group := range moveGroups {
+				for _, ref := range group {
+					key := client.ObjectKey{
+						Namespace: ref.Namespace,
+						Name:      ref.Name,
+					}
+
+					oFrom := &unstructured.Unstructured{}
+					oFrom.SetAPIVersion(ref.APIVersion)
+					oFrom.SetKind(ref.Kind)
+					g.Expect(csFrom.Get(ctx, key, oFrom)).To(Succeed(), "%v deleted from source cluster", key)
+
+					if oFrom.GroupVersionKind().GroupKind() == clusterv1.GroupVersion.WithKind("Cluster").GroupKind() {
+						paused, _, err := unstructured.NestedBool(oFrom.Object, "spec", "paused")
+						g.Expect(err).NotTo(HaveOccurred())
+						g.Expect(paused).To(BeFalse(), "%v paused in source cluster", key)
+					}
+
+					oTo := &unstructured.Unstructured{}
+					oTo.SetAPIVersion(ref.APIVersion)
+					oTo.SetKind(ref.Kind)
+					err := csTo.Get(ctx, key, oTo)
+					g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "%v created in target cluster", key)
+				}
+			}
+
+			// check that no namespace is created in the target cluster
+			namespaces := &corev1.NamespaceList{}
+			g.Expect(csTo.List(ctx, namespaces)).To(Succeed())
+			g.Expect(namespaces.Items).To(BeEmpty())
+		})
+	}
+}
+
+// withProvisioningCompleted returns a copy of the objects with the status of Clusters and Machines set as if provisioning
+// was completed.
+func withProvisioningCompleted(objs []runtime.Object) []runtime.Object {
+	ret := make([]runtime.Object, 0, len(objs))
+	for _, o := range objs {
+		o = o.DeepCopyObject()
+		switch obj := o.(type) {
+		case *clusterv1.Cluster:
+			obj.Status.InfrastructureReady = true
+			obj.Status.ControlPlaneInitialized = true
+			obj.Status.ControlPlaneReady = true
+		case *clusterv1.Machine:
+			obj.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: obj.Name}
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
+func Test_sortedGroup(t *testing.T) {
+	g := NewWithT(t)
+
+	newNode := func(kind, namespace, name string) *node {
+		return &node{identity: corev1.ObjectReference{Kind: kind, Namespace: namespace, Name: name}}
+	}
+	group := moveGroup{
+		newNode("Machine", "ns1", "m2"),
+		newNode("Cluster", "ns1", "cluster1"),
+		newNode("Machine", "ns1", "m1"),
+	}
+
+	got := sortedGroup(group)
+	g.Expect(got).To(HaveLen(3))
+	g.Expect(nodeToString(got[0])).To(Equal("Cluster/ns1/cluster1"))
+	g.Expect(nodeToString(got[1])).To(Equal("Machine/ns1/m1"))
+	g.Expect(nodeToString(got[2])).To(Equal("Machine/ns1/m2"))
+
+	// the original group is not changed
+	g.Expect(nodeToString(group[0])).To(Equal("Machine/ns1/m2"))
+}
+
+func Test_objectMover_move_resume(t *testing.T) {
+	objs := test.NewFakeCluster("ns1", "foo").
+		WithMachineDeployments(
+			test.NewFakeMachineDeployment("md1").
+				WithMachineSets(
+					test.NewFakeMachineSet("ms1").
+						WithMachines(
+							test.NewFakeMachine("m1"),
+						),
+				),
+		).Objs()
+
+	groups := len(getMoveSequence(discoverObjectGraph(t, getObjectGraphWithObjs(objs).proxy)).groups)
+
+	// Injects a failure while creating each group in the target cluster.
+	for failingGroup := 0; failingGroup < groups; failingGroup++ {
+		t.Run(fmt.Sprintf("resume after failing to create group %d", failingGroup), func(t *testing.T) {
+			g := NewWithT(t)
+
+			fromProxy := getObjectGraphWithObjs(objs).proxy
+			toProxy := &failingProxy{Proxy: getFakeProxyWithCRDs()}
+			mover := objectMover{
+				fromProxy:    fromProxy,
+				writeBackoff: &wait.Backoff{Steps: 1},
+			}
+
+			graph := discoverObjectGraph(t, fromProxy)
+			toProxy.failCreate = nodeKey(getMoveSequence(graph).getGroup(failingGroup)[0])
+			g.Expect(mover.move(graph, toProxy)).ToNot(Succeed())
+
+			// The source Clusters are paused and the progress of the move is recorded.
+			expectClusterMoveProgress(g, fromProxy, "foo", true, fmt.Sprintf("Creating/%d", failingGroup))
+
+			// Resume the move.
+			toProxy.failCreate = ""
+			g.Expect(mover.move(discoverObjectGraph(t, fromProxy), toProxy)).To(Succeed())
+
+			expectObjectsMoved(g, objs, fromProxy, toProxy)
+		})
+	}
+
+	// Injects a failure while deleting each group from the source cluster.
+	for failingGroup := 0; failingGroup < groups; failingGroup++ {
+		t.Run(fmt.Sprintf("resume after failing to delete group %d", failingGroup), func(t *testing.T) {
+			g := NewWithT(t)
+
+			fromProxy := &failingProxy{Proxy: getObjectGraphWithObjs(objs).proxy}
+			toProxy := getFakeProxyWithCRDs()
+			mover := objectMover{
+				fromProxy:    fromProxy,
+				writeBackoff: &wait.Backoff{Steps: 1},
+			}
+
+			graph := discoverObjectGraph(t, fromProxy)
+			fromProxy.failDelete = nodeKey(getMoveSequence(graph).getGroup(failingGroup)[0])
+			g.Expect(mover.move(graph, toProxy)).ToNot(Succeed())
+
+			// The source Clusters are paused and the progress of the move is recorded.
+			expectClusterMoveProgress(g, fromProxy, "foo", true, "Deleting")
+
+			// Rollback is not possible once deleting objects from the source cluster is started.
+			g.Expect(mover.rollback(discoverObjectGraph(t, fromProxy), toProxy)).ToNot(Succeed())
+
+			// Resume the move.
+			fromProxy.failDelete = ""
+			g.Expect(mover.move(discoverObjectGraph(t, fromProxy), toProxy)).To(Succeed())
+
+			expectObjectsMoved(g, objs, fromProxy, toProxy)
+		})
+	}
+}
+
+func Test_objectMover_rollback(t *testing.T) {
+	objs := test.NewFakeCluster("ns1", "foo").
+		WithMachineDeployments(
+			test.NewFakeMachineDeployment("md1").
+				WithMachineSets(
+					test.NewFakeMachineSet("ms1").
+						WithMachines(
+							test.NewFakeMachine("m1"),
+						),
+				),
+		).Objs()
+
+	groups := len(getMoveSequence(discoverObjectGraph(t, getObjectGraphWithObjs(objs).proxy)).groups)
+
+	// Injects a failure while creating each group in the target cluster.
+	for failingGroup := 0; failingGroup < groups; failingGroup++ {
+		t.Run(fmt.Sprintf("rollback after failing to create group %d", failingGroup), func(t *testing.T) {
+			g := NewWithT(t)
+
+			fromProxy := getObjectGraphWithObjs(objs).proxy
+			toProxy := &failingProxy{Proxy: getFakeProxyWithCRDs()}
+			mover := objectMover{
+				fromProxy:    fromProxy,
+				writeBackoff: &wait.Backoff{Steps: 1},
+			}
+
+			graph := discoverObjectGraph(t, fromProxy)
+			toProxy.failCreate = nodeKey(getMoveSequence(graph).getGroup(failingGroup)[0])
+			g.Expect(mover.move(graph, toProxy)).ToNot(Succeed())
+
+			// Rollback the move.
+			g.Expect(mover.rollback(discoverObjectGraph(t, fromProxy), toProxy)).To(Succeed())
+
+			// The source Clusters are not paused and the progress of the move is removed.
+			expectClusterMoveProgress(g, fromProxy, "foo", false, "")
+
+			// All the objects are still in the source cluster and none is left in the target cluster.
+			csFrom, err := fromProxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+			csTo, err := toProxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, o := range objs {
+				obj, key := objToUnstructuredKey(g, o)
+				g.Expect(csFrom.Get(ctx, key, obj)).To(Succeed(), "%v deleted from source cluster", key)
+				g.Expect(apierrors.IsNotFound(csTo.Get(ctx, key, obj))).To(BeTrue(), "%v not deleted from target cluster", key)
+			}
+
+			// There is nothing left to roll back.
+			g.Expect(mover.rollback(discoverObjectGraph(t, fromProxy), toProxy)).ToNot(Succeed())
+		})
+	}
+}
+
+func Test_objectMover_rollback_existingTargetObject(t *testing.T) {
+	g := NewWithT(t)
+
+	objs := test.NewFakeCluster("ns1", "foo").
+		WithMachines(
+			test.NewFakeMachine("m1"),
+		).Objs()
+
+	// The target cluster already has a Cluster with the same name, not created by move.
+	existingCluster := &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "foo",
+			UID:       "existing",
+		},
+	}
+
+	fromProxy := getObjectGraphWithObjs(objs).proxy
+	toProxy := &failingProxy{Proxy: getFakeProxyWithCRDs().WithObjs(existingCluster)}
+	mover := objectMover{
+		fromProxy:    fromProxy,
+		writeBackoff: &wait.Backoff{Steps: 1},
+	}
+
+	// Injects a failure while creating the last group in the target cluster.
+	graph := discoverObjectGraph(t, fromProxy)
+	moveSequence := getMoveSequence(graph)
+	toProxy.failCreate = nodeKey(moveSequence.getGroup(len(moveSequence.groups) - 1)[0])
+	g.Expect(mover.move(graph, toProxy)).ToNot(Succeed())
+
+	// Rollback the move.
+	g.Expect(mover.rollback(discoverObjectGraph(t, fromProxy), toProxy)).To(Succeed())
+
+	// The Cluster already existing in the target cluster is not deleted, while all the other objects are.
+	csTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	for _, o := range objs {
+		obj, key := objToUnstructuredKey(g, o)
+		if obj.GetKind() == "Cluster" {
+			g.Expect(csTo.Get(ctx, key, obj)).To(Succeed(), "%v deleted from target cluster", key)
+			g.Expect(obj.GetAnnotations()).ToNot(HaveKey(clusterctlv1.MovedFromAnnotation))
+			continue
+		}
+		g.Expect(apierrors.IsNotFound(csTo.Get(ctx, key, obj))).To(BeTrue(), "%v not deleted from target cluster", key)
+	}
+}
+
+func Test_parseMoveProgress(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    *moveProgress
+		wantErr bool
+	}{
+		{value: "Creating/0", want: &moveProgress{}},
+		{value: "Creating/3", want: &moveProgress{createdGroups: 3}},
+		{value: "Deleting", want: &moveProgress{deleting: true}},
+		{value: "Creating", wantErr: true},
+		{value: "Creating/-1", wantErr: true},
+		{value: "Creating/foo", wantErr: true},
+		{value: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseMoveProgress(tt.value)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+			g.Expect(got.String()).To(Equal(tt.value))
+		})
+	}
+}
+
+// failingProxy is a Proxy returning clients that fail when creating or deleting a given object.
+type failingProxy struct {
+	Proxy
+	failCreate string
+	failDelete string
+}
+
+func (p *failingProxy) NewClient() (client.Client, error) {
+	c, err := p.Proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return &failingClient{Client: c, proxy: p}, nil
+}
+
+type failingClient struct {
+	client.Client
+	proxy *failingProxy
+}
+
+func (c *failingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if key := objKey(obj); key == c.proxy.failCreate {
+		return errors.Errorf("injected failure creating %s", key)
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *failingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	if key := objKey(obj); key == c.proxy.failDelete {
+		return errors.Errorf("injected failure deleting %s", key)
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func objKey(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.GetObjectKind().GroupVersionKind().Kind, accessor.GetNamespace(), accessor.GetName())
+}
+
+func nodeKey(n *node) string {
+	return fmt.Sprintf("%s/%s/%s", n.identity.Kind, n.identity.Namespace, n.identity.Name)
+}
+
+// discoverObjectGraph returns an objectGraph with all the objects existing in the ns1 namespace of a cluster.
+func discoverObjectGraph(t *testing.T, proxy Proxy) *objectGraph {
+	g := NewWithT(t)
+
+	graph := newObjectGraph(proxy)
+
+	discoveryTypes, err := getFakeDiscoveryTypes(graph)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(graph.Discovery("ns1", discoveryTypes)).To(Succeed())
+	return graph
+}
+
+// expectClusterMoveProgress checks the pause field and the move progress annotation of a Cluster in the ns1 namespace.
+func expectClusterMoveProgress(g *WithT, proxy Proxy, name string, paused bool, progress string) {
+	c, err := proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cluster := &clusterv1.Cluster{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: name}, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Paused).To(Equal(paused))
+	g.Expect(cluster.Annotations[clusterctlv1.MoveProgressAnnotation]).To(Equal(progress))
+}
+
+// expectObjectsMoved checks that objects are removed from the source cluster and are created in the target cluster.
+func expectObjectsMoved(g *WithT, objs []runtime.Object, fromProxy, toProxy Proxy) {
+	csFrom, err := fromProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	csTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	for _, o := range objs {
+		obj, key := objToUnstructuredKey(g, o)
+		g.Expect(apierrors.IsNotFound(csFrom.Get(ctx, key, obj))).To(BeTrue(), "%v not deleted from source cluster", key)
+		g.Expect(csTo.Get(ctx, key, obj)).To(Succeed(), "%v not created in target cluster", key)
+		g.Expect(obj.GetAnnotations()).ToNot(HaveKey(clusterctlv1.MoveProgressAnnotation))
+	}
+
+	cluster := &clusterv1.Cluster{}
+	g.Expect(csTo.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo"}, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Paused).To(BeFalse())
+}
+
+func objToUnstructuredKey(g *WithT, o runtime.Object) (*unstructured.Unstructured, client.ObjectKey) {
+	accessor, err := meta.Accessor(o)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(o.GetObjectKind().GroupVersionKind())
+	return obj, client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+}
+
+func Test_objectMover_backup(t *testing.T) {
+	// NB. we are testing the backup using the same set of moveTests used for move, given that backup follows the same sequence.
+	for _, tt := range moveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(tt.fields.objs)
+
+			// Get all the types to be considered for discovery
+			discoveryTypes, err := getFakeDiscoveryTypes(graph)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("ns1", discoveryTypes)).To(Succeed())
+
+			dir, err := ioutil.TempDir("", "cluster-api-backup")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			// Run backup
+			mover := objectMover{
+				fromProxy: graph.proxy,
+			}
+
+			err = mover.backup(graph, dir)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// check that a file exists for each object in the move sequence, and that objects are still in the source cluster
+			csFrom, err := graph.proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range graph.getNodesWithTenants() {
+				_, err := os.Stat(filepath.Join(dir, backupFileName(node)))
+				g.Expect(err).NotTo(HaveOccurred(), "missing backup file for %s", node.identity.UID)
+
+				key := client.ObjectKey{
+					Namespace: node.identity.Namespace,
+					Name:      node.identity.Name,
+				}
+				oFrom := &unstructured.Unstructured{}
+				oFrom.SetAPIVersion(node.identity.APIVersion)
+				oFrom.SetKind(node.identity.Kind)
+				g.Expect(csFrom.Get(ctx, key, oFrom)).To(Succeed())
+			}
+
+			// check that the clusters in the source cluster are not left paused
+			for _, node := range graph.getClusters() {
+				clusterObj := &clusterv1.Cluster{}
+				g.Expect(getClusterObj(graph.proxy, node, clusterObj)).To(Succeed())
+				g.Expect(clusterObj.Spec.Paused).To(BeFalse())
+			}
+		})
+	}
+}
+
+func Test_objectMover_restore(t *testing.T) {
+	// NB. we are testing the restore using the same set of moveTests used for move, given that restore follows the same sequence.
+	for _, tt := range moveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(tt.fields.objs)
+
+			// Get all the types to be considered for discovery
+			discoveryTypes, err := getFakeDiscoveryTypes(graph)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("ns1", discoveryTypes)).To(Succeed())
+
+			dir, err := ioutil.TempDir("", "cluster-api-backup")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			// Run backup, so there is something to restore
+			mover := objectMover{
+				fromProxy:             graph.proxy,
+				fromProviderInventory: newInventoryClient(graph.proxy, nil),
+			}
+			g.Expect(mover.backupProviders("ns1", dir)).To(Succeed())
+			g.Expect(mover.backup(graph, dir)).To(Succeed())
+
+			// gets a fakeProxy to an empty cluster with all the required CRDs
+			toProxy := getFakeProxyWithCRDs()
+			toCluster := newClusterClient(Kubeconfig{}, nil, InjectProxy(toProxy))
+
+			// Run restore
+			restorer := newObjectMover(toProxy, nil)
+
+			err = restorer.Restore(toCluster, dir)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// check that the objects are created in the target cluster
+			csTo, err := toProxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range graph.getNodesWithTenants() {
+				key := client.ObjectKey{
+					Namespace: node.identity.Namespace,
+					Name:      node.identity.Name,
+				}
+
+				oTo := &unstructured.Unstructured{}
+				oTo.SetAPIVersion(node.identity.APIVersion)
+				oTo.SetKind(node.identity.Kind)
+
+				if err := csTo.Get(ctx, key, oTo); err != nil {
+					t.Errorf("error = %v when checking for %v created in target cluster", err, key)
+					continue
+				}
+			}
+
+			// check that the clusters in the target cluster are not paused
+			for _, node := range graph.getClusters() {
+				clusterObj := &clusterv1.Cluster{}
+				g.Expect(getClusterObj(toProxy, node, clusterObj)).To(Succeed())
+				g.Expect(clusterObj.Spec.Paused).To(BeFalse())
+			}
+		})
+	}
+}
+
+func Test_objectMover_backup_failure(t *testing.T) {
+	g := NewWithT(t)
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	discoveryTypes, err := getFakeDiscoveryTypes(graph)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(graph.Discovery("ns1", discoveryTypes)).To(Succeed())
+
+	dir, err := ioutil.TempDir("", "cluster-api-backup")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	// A directory with the name of the backup file for the Cluster makes the backup fail.
+	clusters := graph.getClusters()
+	g.Expect(clusters).To(HaveLen(1))
+	g.Expect(os.Mkdir(filepath.Join(dir, backupFileName(clusters[0])), 0755)).To(Succeed())
+
+	mover := objectMover{
+		fromProxy:    graph.proxy,
+		writeBackoff: &wait.Backoff{Steps: 1},
+	}
+	g.Expect(mover.backup(graph, dir)).ToNot(Succeed())
+
+	// check that the clusters in the source cluster are not left paused
+	clusterObj := &clusterv1.Cluster{}
+	g.Expect(getClusterObj(graph.proxy, clusters[0], clusterObj)).To(Succeed())
+	g.Expect(clusterObj.Spec.Paused).To(BeFalse())
+}
+
+func Test_objectMover_backup_pausedCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	// Create a source cluster with a Cluster paused by the user and a Cluster not paused.
+	pausedObjs := test.NewFakeCluster("ns1", "paused").Objs()
+	for _, o := range pausedObjs {
+		if c, ok := o.(*clusterv1.Cluster); ok {
+			c.Spec.Paused = true
+		}
+	}
+	graph := getObjectGraphWithObjs(append(pausedObjs, test.NewFakeCluster("ns1", "foo").Objs()...))
+	discoveryTypes, err := getFakeDiscoveryTypes(graph)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(graph.Discovery("ns1", discoveryTypes)).To(Succeed())
+
+	dir, err := ioutil.TempDir("", "cluster-api-backup")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+	g.Expect(mover.backup(graph, dir)).To(Succeed())
+
+	// check that the backup resumes only the clusters it paused
+	paused := map[string]bool{}
+	for _, node := range graph.getClusters() {
+		clusterObj := &clusterv1.Cluster{}
+		g.Expect(getClusterObj(graph.proxy, node, clusterObj)).To(Succeed())
+		paused[clusterObj.Name] = clusterObj.Spec.Paused
+	}
+	g.Expect(paused).To(Equal(map[string]bool{"paused": true, "foo": false}))
+}
+
+func Test_objectMover_Restore_checkTargetProviders(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "cluster-api-backup")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	// Run backup from a cluster with the core provider installed.
+	fromProxy := test.NewFakeProxy().
+		WithObjs(test.NewFakeCluster("ns1", "foo").Objs()...).
+		WithProviderInventory("capi", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system", "")
+	g.Expect(newObjectMover(fromProxy, newInventoryClient(fromProxy, nil)).backupProviders("ns1", dir)).To(Succeed())
+
+	// Restore to a cluster without the core provider fails.
+	toProxy := getFakeProxyWithCRDs()
+	toCluster := newClusterClient(Kubeconfig{}, nil, InjectProxy(toProxy))
+	err = newObjectMover(toProxy, nil).Restore(toCluster, dir)
+	g.Expect(err).To(MatchError(ContainSubstring("not found in the target cluster")))
+
+	// Restore to a cluster with an older version of the core provider fails.
+	toProxy = getFakeProxyWithCRDs().
+		WithProviderInventory("capi", clusterctlv1.CoreProviderType, "v0.9.0", "capi-system", "")
+	toCluster = newClusterClient(Kubeconfig{}, nil, InjectProxy(toProxy))
+	err = newObjectMover(toProxy, nil).Restore(toCluster, dir)
+	g.Expect(err).To(MatchError(ContainSubstring("older than in the source cluster")))
+}
+
+func Test_objectMover_Restore_emptyDirectory(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "cluster-api-backup")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	toProxy := getFakeProxyWithCRDs()
+	toCluster := newClusterClient(Kubeconfig{}, nil, InjectProxy(toProxy))
+
+	g.Expect(newObjectMover(toProxy, nil).Restore(toCluster, dir)).ToNot(Succeed())
+}
+
 func Test_objectMover_checkProvisioningCompleted(t *testing.T) {
 	type fields struct {
 		objs []runtime.Object
@@ -812,10 +1480,10 @@ func Test_objectsMoverService_checkTargetProviders(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
-			o := &objectMover{
-				fromProviderInventory: newInventoryClient(tt.fields.fromProxy, nil),
-			}
-			err := o.checkTargetProviders(tt.args.namespace, newInventoryClient(tt.args.toProxy, nil))
+			fromProviders, err := newInventoryClient(tt.fields.fromProxy, nil).List()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			err = checkTargetProviders(tt.args.namespace, fromProviders, newInventoryClient(tt.args.toProxy, nil))
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -63,6 +63,9 @@ type node struct {
 	// tenantCRSs define the list of ClusterResourceSet which are tenant for the node, no matter if the node has a direct OwnerReference to the ClusterResourceSet or if
 	// the node is linked to a ClusterResourceSet indirectly in the OwnerReference chain.
 	tenantCRSs map[*node]empty
+
+	// restoreObject holds the object read from a backup file, if the node was added to the graph during restore.
+	restoreObject *unstructured.Unstructured
 }
 
 // markObserved marks the fact that a node was observed as a concrete object.
@@ -121,6 +124,14 @@ func (o *objectGraph) addObj(obj *unstructured.Unstructured) {
 	}
 }
 
+// addRestoredObj adds a Kubernetes object read from a backup file to the object graph that is generated during restore.
+// During add, OwnerReferences are processed in order to create the dependency graph, and the object is stored in the node
+// so it can be used later for creating the object in the target cluster.
+func (o *objectGraph) addRestoredObj(obj *unstructured.Unstructured) {
+	o.addObj(obj)
+	o.uidToNode[obj.GetUID()].restoreObject = obj
+}
+
 // ownerToVirtualNode creates a virtual node as a placeholder for the Kubernetes owner object received in input.
 // The virtual node will be eventually converted to an actual node when the node will be visited during discovery.
 func (o *objectGraph) ownerToVirtualNode(owner metav1.OwnerReference, namespace string) *node {
@@ -145,22 +156,25 @@ func (o *objectGraph) ownerToVirtualNode(owner metav1.OwnerReference, namespace
 
 // objToNode creates a node for the Kubernetes object received in input.
 // If the node corresponding to the Kubernetes object already exists as a virtual node detected when processing OwnerReferences,
-// the node is marked as Observed.
+// the node is marked as Observed and its identity is updated with the data read from the actual object.
 func (o *objectGraph) objToNode(obj *unstructured.Unstructured) *node {
+	identity := corev1.ObjectReference{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		UID:        obj.GetUID(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+	}
+
 	existingNode, found := o.uidToNode[obj.GetUID()]
 	if found {
 		existingNode.markObserved()
+		existingNode.identity = identity
 		return existingNode
 	}
 
 	newNode := &node{
-		identity: corev1.ObjectReference{
-			APIVersion: obj.GetAPIVersion(),
-			Kind:       obj.GetKind(),
-			UID:        obj.GetUID(),
-			Name:       obj.GetName(),
-			Namespace:  obj.GetNamespace(),
-		},
+		identity:       identity,
 		owners:         make(map[*node]ownerReferenceAttributes),
 		softOwners:     make(map[*node]empty),
 		tenantClusters: make(map[*node]empty),
@@ -273,8 +287,9 @@ func getObjList(proxy Proxy, typeMeta metav1.TypeMeta, selectors []client.ListOp
 		return err
 	}
 
+	// Nb. The List suffix is required by the fake client, and it is removed by the real client.
 	objList.SetAPIVersion(typeMeta.APIVersion)
-	objList.SetKind(typeMeta.Kind)
+	objList.SetKind(typeMeta.Kind + "List")
 
 	if err := c.List(ctx, objList, selectors...); err != nil {
 		if apierrors.IsNotFound(err) {
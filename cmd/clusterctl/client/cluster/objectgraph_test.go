@@ -17,7 +17,6 @@ limitations under the License.
 package cluster
 
 import (
-	"fmt"
 	"sort"
 	"testing"
 
@@ -1034,16 +1033,7 @@ func getFakeProxyWithCRDs() *test.FakeProxy {
 }
 
 func getFakeDiscoveryTypes(graph *objectGraph) ([]metav1.TypeMeta, error) {
-	discoveryTypes, err := graph.getDiscoveryTypes()
-	if err != nil {
-		return nil, err
-	}
-
-	// Given that the Fake client behaves in a different way than real client, for this test we are required to add the List suffix to all the types.
-	for i := range discoveryTypes {
-		discoveryTypes[i].Kind = fmt.Sprintf("%sList", discoveryTypes[i].Kind)
-	}
-	return discoveryTypes, nil
+	return graph.getDiscoveryTypes()
 }
 
 func TestObjectGraph_Discovery(t *testing.T) {
@@ -0,0 +1,404 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	waitRolloutInterval = 5 * time.Second
+)
+
+// RolloutStatusOptions carries the options supported by RolloutClient.Status.
+type RolloutStatusOptions struct {
+	// Wait blocks until the rollout is complete, or until the MachineDeployment does not progress
+	// for more than ProgressDeadlineSeconds.
+	Wait bool
+
+	// Timeout is the maximum time to wait for the rollout to complete. If zero, there is no timeout
+	// other than the progress deadline of the MachineDeployment.
+	Timeout time.Duration
+}
+
+// RolloutStatus defines the status of the rollout of a MachineDeployment.
+type RolloutStatus struct {
+	// Revision is the current revision of the MachineDeployment.
+	Revision int64 `json:"revision"`
+
+	// Replicas is the number of desired Machines.
+	Replicas int32 `json:"replicas"`
+
+	// UpdatedReplicas is the number of Machines with the desired machine template.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// AvailableReplicas is the number of available Machines.
+	AvailableReplicas int32 `json:"availableReplicas"`
+
+	// Paused is true if the MachineDeployment is paused.
+	Paused bool `json:"paused"`
+
+	// Complete is true if the rollout is complete.
+	Complete bool `json:"complete"`
+
+	// Message is a human readable description of the rollout status.
+	Message string `json:"message"`
+}
+
+// RolloutRevision defines a revision of a MachineDeployment, as recorded by its MachineSets.
+type RolloutRevision struct {
+	// Revision number.
+	Revision int64 `json:"revision"`
+
+	// MachineSet hosting the revision.
+	MachineSet string `json:"machineSet"`
+
+	// Replicas is the number of Machines in the MachineSet.
+	Replicas int32 `json:"replicas"`
+
+	// Template is the machine template of the revision.
+	Template clusterv1.MachineTemplateSpec `json:"template"`
+}
+
+// RolloutClient has methods to manage the rollout of MachineDeployments.
+type RolloutClient interface {
+	// Status returns the rollout status of a MachineDeployment, eventually waiting for the rollout to complete.
+	Status(namespace, name string, options RolloutStatusOptions) (*RolloutStatus, error)
+
+	// History returns the revisions of a MachineDeployment, sorted by revision number.
+	History(namespace, name string) ([]RolloutRevision, error)
+
+	// Undo restores the machine template of a previous revision of a MachineDeployment.
+	// If toRevision is zero, the revision before the current one is restored.
+	Undo(namespace, name string, toRevision int64) error
+
+	// Pause pauses the rollout of a MachineDeployment.
+	Pause(namespace, name string) error
+
+	// Resume resumes the rollout of a paused MachineDeployment.
+	Resume(namespace, name string) error
+
+	// Restart triggers the rollout of new Machines for a MachineDeployment, without changing its spec.
+	Restart(namespace, name string) error
+}
+
+// rolloutClient implements RolloutClient.
+type rolloutClient struct {
+	proxy               Proxy
+	pollImmediateWaiter PollImmediateWaiter
+	now                 func() time.Time
+}
+
+// ensure rolloutClient implements RolloutClient.
+var _ RolloutClient = &rolloutClient{}
+
+func newRolloutClient(proxy Proxy, pollImmediateWaiter PollImmediateWaiter) *rolloutClient {
+	return &rolloutClient{
+		proxy:               proxy,
+		pollImmediateWaiter: pollImmediateWaiter,
+		now:                 time.Now,
+	}
+}
+
+func (r *rolloutClient) Status(namespace, name string, options RolloutStatusOptions) (*RolloutStatus, error) {
+	log := logf.Log
+
+	c, err := r.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := getMachineDeployment(c, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	status := getRolloutStatus(md)
+	if !options.Wait || status.Complete {
+		return status, nil
+	}
+
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = time.Duration(math.MaxInt64)
+	}
+
+	// Nb. besides relying on the Progressing condition set by the controller, the progress deadline is checked client
+	// side, considering any change in the MachineDeployment status as a progress of the rollout.
+	lastStatus := md.Status
+	lastProgress := r.now()
+	if err := r.pollImmediateWaiter(waitRolloutInterval, timeout, func() (bool, error) {
+		md, err = getMachineDeployment(c, namespace, name)
+		if err != nil {
+			//Nb. we are ignoring the error so the pollImmediateWaiter will execute another retry
+			return false, nil
+		}
+
+		status = getRolloutStatus(md)
+		log.Info(status.Message)
+		if status.Complete {
+			return true, nil
+		}
+		if status.Paused {
+			return false, errors.Errorf("the rollout of MachineDeployment %s/%s is paused; resume it before waiting for the rollout to complete", namespace, name)
+		}
+
+		if conditions.GetReason(md, clusterv1.MachineDeploymentProgressingCondition) == clusterv1.ProgressDeadlineExceededReason {
+			return false, errors.Errorf("the rollout of MachineDeployment %s/%s exceeded its progress deadline: %s",
+				namespace, name, conditions.GetMessage(md, clusterv1.MachineDeploymentProgressingCondition))
+		}
+		if !apiequality.Semantic.DeepEqual(md.Status, lastStatus) {
+			lastStatus = md.Status
+			lastProgress = r.now()
+			return false, nil
+		}
+		if md.Spec.ProgressDeadlineSeconds != nil {
+			deadline := time.Duration(*md.Spec.ProgressDeadlineSeconds) * time.Second
+			if r.now().Sub(lastProgress) > deadline {
+				return false, errors.Errorf("the rollout of MachineDeployment %s/%s exceeded its progress deadline of %s", namespace, name, deadline)
+			}
+		}
+		return false, nil
+	}); err != nil {
+		return status, errors.Wrapf(err, "failed to wait for the rollout of MachineDeployment %s/%s", namespace, name)
+	}
+	return status, nil
+}
+
+// getRolloutStatus computes the rollout status of a MachineDeployment, using the same rules of kubectl rollout status.
+func getRolloutStatus(md *clusterv1.MachineDeployment) *RolloutStatus {
+	revision, _ := mdutil.Revision(md)
+	status := &RolloutStatus{
+		Revision:          revision,
+		UpdatedReplicas:   md.Status.UpdatedReplicas,
+		AvailableReplicas: md.Status.AvailableReplicas,
+		Paused:            md.Spec.Paused,
+	}
+	if md.Spec.Replicas != nil {
+		status.Replicas = *md.Spec.Replicas
+	}
+
+	switch {
+	case md.Generation > md.Status.ObservedGeneration:
+		status.Message = fmt.Sprintf("Waiting for MachineDeployment %q spec update to be observed...", md.Name)
+	case md.Spec.Replicas != nil && md.Status.UpdatedReplicas < *md.Spec.Replicas:
+		status.Message = fmt.Sprintf("Waiting for MachineDeployment %q rollout to finish: %d out of %d new machines have been updated...", md.Name, md.Status.UpdatedReplicas, *md.Spec.Replicas)
+	case md.Status.Replicas > md.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for MachineDeployment %q rollout to finish: %d old machines are pending termination...", md.Name, md.Status.Replicas-md.Status.UpdatedReplicas)
+	case md.Status.AvailableReplicas < md.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for MachineDeployment %q rollout to finish: %d of %d updated machines are available...", md.Name, md.Status.AvailableReplicas, md.Status.UpdatedReplicas)
+	default:
+		status.Complete = true
+		status.Message = fmt.Sprintf("MachineDeployment %q successfully rolled out", md.Name)
+	}
+	return status
+}
+
+func (r *rolloutClient) History(namespace, name string) ([]RolloutRevision, error) {
+	c, err := r.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := getMachineDeployment(c, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	machineSets, err := getMachineSetsForDeployment(c, md)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]RolloutRevision, 0, len(machineSets))
+	for _, ms := range machineSets {
+		revision, err := mdutil.Revision(ms)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the revision of MachineSet %s/%s", ms.Namespace, ms.Name)
+		}
+		rolloutRevision := RolloutRevision{
+			Revision:   revision,
+			MachineSet: ms.Name,
+			Template:   ms.Spec.Template,
+		}
+		if ms.Spec.Replicas != nil {
+			rolloutRevision.Replicas = *ms.Spec.Replicas
+		}
+		revisions = append(revisions, rolloutRevision)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+func (r *rolloutClient) Undo(namespace, name string, toRevision int64) error {
+	log := logf.Log
+
+	revisions, err := r.History(namespace, name)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		return errors.Errorf("no revisions found for MachineDeployment %s/%s", namespace, name)
+	}
+
+	// If the revision is not specified, restore the revision before the current one.
+	var target *RolloutRevision
+	if toRevision == 0 {
+		if len(revisions) < 2 {
+			return errors.Errorf("no previous revisions found for MachineDeployment %s/%s", namespace, name)
+		}
+		target = &revisions[len(revisions)-2]
+	} else {
+		for i := range revisions {
+			if revisions[i].Revision == toRevision {
+				target = &revisions[i]
+				break
+			}
+		}
+		if target == nil {
+			return errors.Errorf("revision %d not found for MachineDeployment %s/%s", toRevision, namespace, name)
+		}
+	}
+
+	log.Info("Rolling back MachineDeployment", "Namespace", namespace, "Name", name, "Revision", target.Revision)
+
+	return r.patchMachineDeployment(namespace, name, func(md *clusterv1.MachineDeployment) error {
+		if md.Spec.Paused {
+			return errors.Errorf("cannot rollback the paused MachineDeployment %s/%s; resume it first", namespace, name)
+		}
+
+		// Restores the machine template, dropping the label used by the controller for identifying the MachineSet.
+		template := target.Template.DeepCopy()
+		delete(template.Labels, mdutil.DefaultMachineDeploymentUniqueLabelKey)
+		md.Spec.Template = *template
+		return nil
+	})
+}
+
+func (r *rolloutClient) Pause(namespace, name string) error {
+	return r.patchMachineDeployment(namespace, name, func(md *clusterv1.MachineDeployment) error {
+		if md.Spec.Paused {
+			return errors.Errorf("MachineDeployment %s/%s is already paused", namespace, name)
+		}
+		md.Spec.Paused = true
+		return nil
+	})
+}
+
+func (r *rolloutClient) Resume(namespace, name string) error {
+	return r.patchMachineDeployment(namespace, name, func(md *clusterv1.MachineDeployment) error {
+		if !md.Spec.Paused {
+			return errors.Errorf("MachineDeployment %s/%s is not paused", namespace, name)
+		}
+		md.Spec.Paused = false
+		return nil
+	})
+}
+
+func (r *rolloutClient) Restart(namespace, name string) error {
+	return r.patchMachineDeployment(namespace, name, func(md *clusterv1.MachineDeployment) error {
+		if md.Spec.Paused {
+			return errors.Errorf("cannot restart the paused MachineDeployment %s/%s; resume it first", namespace, name)
+		}
+
+		// Changing an annotation in the machine template forces the controller to create a new MachineSet,
+		// and thus to replace all the Machines.
+		if md.Spec.Template.Annotations == nil {
+			md.Spec.Template.Annotations = map[string]string{}
+		}
+		md.Spec.Template.Annotations[clusterctlv1.RestartedAtAnnotation] = r.now().Format(time.RFC3339)
+		return nil
+	})
+}
+
+// patchMachineDeployment applies a change to a MachineDeployment using a merge patch.
+func (r *rolloutClient) patchMachineDeployment(namespace, name string, mutate func(md *clusterv1.MachineDeployment) error) error {
+	c, err := r.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	md, err := getMachineDeployment(c, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(md.DeepCopy())
+	if err := mutate(md); err != nil {
+		return err
+	}
+
+	patchBackoff := newWriteBackoff()
+	if err := retryWithExponentialBackoff(patchBackoff, func() error {
+		return c.Patch(ctx, md, patch)
+	}); err != nil {
+		return errors.Wrapf(err, "failed to patch MachineDeployment %s/%s", namespace, name)
+	}
+	return nil
+}
+
+func getMachineDeployment(c client.Client, namespace, name string) (*clusterv1.MachineDeployment, error) {
+	md := &clusterv1.MachineDeployment{}
+	key := client.ObjectKey{
+		Namespace: namespace,
+		Name:      name,
+	}
+
+	readBackoff := newReadBackoff()
+	if err := retryWithExponentialBackoff(readBackoff, func() error {
+		return c.Get(ctx, key, md)
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get MachineDeployment %s/%s", namespace, name)
+	}
+	return md, nil
+}
+
+// getMachineSetsForDeployment returns the MachineSets controlled by a MachineDeployment.
+func getMachineSetsForDeployment(c client.Client, md *clusterv1.MachineDeployment) ([]*clusterv1.MachineSet, error) {
+	machineSetList := &clusterv1.MachineSetList{}
+
+	readBackoff := newReadBackoff()
+	if err := retryWithExponentialBackoff(readBackoff, func() error {
+		return c.List(ctx, machineSetList, client.InNamespace(md.Namespace))
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list MachineSets in namespace %q", md.Namespace)
+	}
+
+	var machineSets []*clusterv1.MachineSet
+	for i := range machineSetList.Items {
+		ms := &machineSetList.Items[i]
+		if metav1.IsControlledBy(ms, md) {
+			machineSets = append(machineSets, ms)
+		}
+	}
+	return machineSets, nil
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+)
+
+func fakeMachineDeployment(name string, version string, status clusterv1.MachineDeploymentStatus) *clusterv1.MachineDeployment {
+	return &clusterv1.MachineDeployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "MachineDeployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      name,
+			UID:       types.UID(name),
+			Annotations: map[string]string{
+				clusterv1.RevisionAnnotation: "2",
+			},
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			Replicas:                pointer.Int32Ptr(3),
+			ProgressDeadlineSeconds: pointer.Int32Ptr(600),
+			Template:                fakeMachineTemplate(version),
+		},
+		Status: status,
+	}
+}
+
+func fakeMachineTemplate(version string) clusterv1.MachineTemplateSpec {
+	return clusterv1.MachineTemplateSpec{
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "cluster1",
+			Version:     &version,
+			InfrastructureRef: corev1.ObjectReference{
+				Kind: "GenericInfrastructureMachineTemplate",
+				Name: "infra-" + version,
+			},
+		},
+	}
+}
+
+func fakeMachineSet(md *clusterv1.MachineDeployment, name, revision, version string) *clusterv1.MachineSet {
+	template := fakeMachineTemplate(version)
+	template.Labels = map[string]string{mdutil.DefaultMachineDeploymentUniqueLabelKey: name}
+	return &clusterv1.MachineSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "MachineSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: md.Namespace,
+			Name:      name,
+			Annotations: map[string]string{
+				clusterv1.RevisionAnnotation: revision,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(md, clusterv1.GroupVersion.WithKind("MachineDeployment")),
+			},
+		},
+		Spec: clusterv1.MachineSetSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Template: template,
+		},
+	}
+}
+
+func Test_getRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       clusterv1.MachineDeploymentStatus
+		generation   int64
+		wantComplete bool
+	}{
+		{
+			name:         "spec update not observed",
+			generation:   2,
+			status:       clusterv1.MachineDeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			wantComplete: false,
+		},
+		{
+			name:         "machines not updated",
+			status:       clusterv1.MachineDeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3},
+			wantComplete: false,
+		},
+		{
+			name:         "old machines pending termination",
+			status:       clusterv1.MachineDeploymentStatus{Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3},
+			wantComplete: false,
+		},
+		{
+			name:         "updated machines not available",
+			status:       clusterv1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2},
+			wantComplete: false,
+		},
+		{
+			name:         "rollout complete",
+			status:       clusterv1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			wantComplete: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			md := fakeMachineDeployment("md1", "v1.18.0", tt.status)
+			md.Generation = tt.generation
+
+			got := getRolloutStatus(md)
+			g.Expect(got.Complete).To(Equal(tt.wantComplete))
+			g.Expect(got.Revision).To(Equal(int64(2)))
+			g.Expect(got.Message).ToNot(BeEmpty())
+		})
+	}
+}
+
+func Test_rolloutClient_Status(t *testing.T) {
+	inProgress := clusterv1.MachineDeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3}
+
+	tests := []struct {
+		name    string
+		md      *clusterv1.MachineDeployment
+		options RolloutStatusOptions
+		wantErr bool
+	}{
+		{
+			name:    "does not wait if not required",
+			md:      fakeMachineDeployment("md1", "v1.18.0", inProgress),
+			options: RolloutStatusOptions{},
+			wantErr: false,
+		},
+		{
+			name:    "does not wait if the rollout is complete",
+			md:      fakeMachineDeployment("md1", "v1.18.0", clusterv1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			options: RolloutStatusOptions{Wait: true},
+			wantErr: false,
+		},
+		{
+			name:    "fails if the progress deadline is exceeded",
+			md:      fakeMachineDeployment("md1", "v1.18.0", inProgress),
+			options: RolloutStatusOptions{Wait: true},
+			wantErr: true,
+		},
+		{
+			name: "fails if the MachineDeployment is paused",
+			md: func() *clusterv1.MachineDeployment {
+				md := fakeMachineDeployment("md1", "v1.18.0", inProgress)
+				md.Spec.Paused = true
+				return md
+			}(),
+			options: RolloutStatusOptions{Wait: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// The waiter simulates the passing of the time, one minute for each poll.
+			now := time.Now()
+			pollImmediateWaiter := func(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+				for i := 0; i < 20; i++ {
+					done, err := condition()
+					if err != nil {
+						return err
+					}
+					if done {
+						return nil
+					}
+					now = now.Add(time.Minute)
+				}
+				return wait.ErrWaitTimeout
+			}
+
+			r := newRolloutClient(test.NewFakeProxy().WithObjs(tt.md), pollImmediateWaiter)
+			r.now = func() time.Time { return now }
+
+			got, err := r.Status("ns1", "md1", tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got.Complete).To(Equal(apiequality.Semantic.DeepEqual(tt.md.Status, clusterv1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3})))
+		})
+	}
+}
+
+func Test_rolloutClient_HistoryAndUndo(t *testing.T) {
+	md := fakeMachineDeployment("md1", "v1.18.0", clusterv1.MachineDeploymentStatus{})
+	objs := []runtime.Object{
+		md,
+		fakeMachineSet(md, "ms1", "1", "v1.17.0"),
+		fakeMachineSet(md, "ms2", "2", "v1.18.0"),
+		fakeMachineSet(fakeMachineDeployment("other", "v1.16.0", clusterv1.MachineDeploymentStatus{}), "ms3", "1", "v1.16.0"),
+	}
+
+	tests := []struct {
+		name        string
+		toRevision  int64
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:        "rollback to the previous revision",
+			toRevision:  0,
+			wantVersion: "v1.17.0",
+			wantErr:     false,
+		},
+		{
+			name:        "rollback to a specific revision",
+			toRevision:  1,
+			wantVersion: "v1.17.0",
+			wantErr:     false,
+		},
+		{
+			name:       "fails if the revision does not exist",
+			toRevision: 3,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().WithObjs(objs...)
+			r := newRolloutClient(proxy, nil)
+
+			revisions, err := r.History("ns1", "md1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(revisions).To(HaveLen(2))
+			g.Expect(revisions[0].Revision).To(Equal(int64(1)))
+			g.Expect(revisions[0].MachineSet).To(Equal("ms1"))
+			g.Expect(revisions[1].Revision).To(Equal(int64(2)))
+			g.Expect(revisions[1].MachineSet).To(Equal("ms2"))
+
+			err = r.Undo("ns1", "md1", tt.toRevision)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c, err := proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+			got, err := getMachineDeployment(c, "ns1", "md1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(*got.Spec.Template.Spec.Version).To(Equal(tt.wantVersion))
+			g.Expect(got.Spec.Template.Spec.InfrastructureRef.Name).To(Equal("infra-" + tt.wantVersion))
+			g.Expect(got.Spec.Template.Labels).ToNot(HaveKey(mdutil.DefaultMachineDeploymentUniqueLabelKey))
+		})
+	}
+}
+
+func Test_rolloutClient_PauseResumeRestart(t *testing.T) {
+	g := NewWithT(t)
+
+	proxy := test.NewFakeProxy().WithObjs(fakeMachineDeployment("md1", "v1.18.0", clusterv1.MachineDeploymentStatus{}))
+	r := newRolloutClient(proxy, nil)
+	now := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	c, err := proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	getMachineDeployment := func() *clusterv1.MachineDeployment {
+		md, err := getMachineDeployment(c, "ns1", "md1")
+		g.Expect(err).NotTo(HaveOccurred())
+		return md
+	}
+
+	// Resume fails if the MachineDeployment is not paused.
+	g.Expect(r.Resume("ns1", "md1")).ToNot(Succeed())
+
+	g.Expect(r.Pause("ns1", "md1")).To(Succeed())
+	g.Expect(getMachineDeployment().Spec.Paused).To(BeTrue())
+
+	// Restart and pause fail if the MachineDeployment is already paused.
+	g.Expect(r.Restart("ns1", "md1")).ToNot(Succeed())
+	g.Expect(r.Pause("ns1", "md1")).ToNot(Succeed())
+
+	g.Expect(r.Resume("ns1", "md1")).To(Succeed())
+	g.Expect(getMachineDeployment().Spec.Paused).To(BeFalse())
+
+	// Restart changes the machine template only by adding the restartedAt annotation.
+	g.Expect(r.Restart("ns1", "md1")).To(Succeed())
+	md := getMachineDeployment()
+	g.Expect(md.Spec.Template.Annotations).To(HaveKeyWithValue(clusterctlv1.RestartedAtAnnotation, "2020-07-01T10:00:00Z"))
+	g.Expect(*md.Spec.Template.Spec.Version).To(Equal("v1.18.0"))
+}
@@ -17,30 +17,54 @@ limitations under the License.
 package cluster
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"strings"
 
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	ociScheme       = "oci"
+	gitSchemePrefix = "git+"
+)
+
+var (
+	// gitTransports are the transports supported for reading templates from Git repositories; other transports, e.g.
+	// ext::, are rejected because they can run arbitrary commands.
+	gitTransports = []string{"https", "http", "ssh", "file"}
+
+	// minGitVersionForCredentials is the minimum git version supporting configuration from environment variables,
+	// which is used for passing the credentials to git.
+	minGitVersionForCredentials = version.MustParseGeneric("2.31.0")
+)
+
 // TemplateClient has methods to work with templates stored in the cluster/out of the provider repository.
 type TemplateClient interface {
 	// GetFromConfigMap returns a workload cluster template from the given ConfigMap.
 	GetFromConfigMap(namespace, name, dataKey, targetNamespace string, listVariablesOnly bool) (repository.Template, error)
 
-	// GetFromURL returns a workload cluster template from the given URL.
+	// GetFromURL returns a workload cluster template from the given URL; supported URLs are:
+	// - GitHub blob URLs, e.g. https://github.com/{owner}/{repository}/blob/{branch}/{path-to-file}
+	// - local files, e.g. /path/to/file or file:///path/to/file
+	// - OCI artifacts, e.g. oci://{registry}/{repository}:{tag}[/{file}]
+	// - files in Git repositories, e.g. git+https://{host}/{repository}@{ref}#{path-to-file}
 	GetFromURL(templateURL, targetNamespace string, listVariablesOnly bool) (repository.Template, error)
 }
 
@@ -49,6 +73,7 @@ type templateClient struct {
 	proxy               Proxy
 	configClient        config.Client
 	gitHubClientFactory func(configVariablesClient config.VariablesClient) (*github.Client, error)
+	ociHTTPClient       *http.Client
 	processor           yaml.Processor
 }
 
@@ -141,7 +166,15 @@ func (t *templateClient) getURLContent(templateURL string) ([]byte, error) {
 		return t.getLocalFileContent(rURL)
 	}
 
-	return nil, errors.Errorf("unable to read content from %q. Only reading from GitHub and local file system is supported", templateURL)
+	if rURL.Scheme == ociScheme {
+		return repository.GetOCIArtifactFile(templateURL, t.configClient.Variables(), t.ociHTTPClient)
+	}
+
+	if strings.HasPrefix(rURL.Scheme, gitSchemePrefix) {
+		return t.getGitFileContent(rURL)
+	}
+
+	return nil, errors.Errorf("unable to read content from %q. Only reading from GitHub, local file system, OCI registries and Git repositories is supported", templateURL)
 }
 
 func (t *templateClient) getLocalFileContent(rURL *url.URL) ([]byte, error) {
@@ -200,6 +233,140 @@ func (t *templateClient) getGitHubFileContent(rURL *url.URL) ([]byte, error) {
 	return content, nil
 }
 
+// getGitFileContent returns the content of a file in a Git repository at a given ref, using the git command line.
+// The URL must be in the form git+{https|http|ssh|file}://{host}/{repository}@{ref}#{path-to-file}, e.g.
+// git+https://github.com/foo-org/foo-templates@v1.0.0#aws/cluster-template.yaml.
+//
+// If the repository requires authentication over HTTPS, the credentials can be provided using the git-username and
+// git-password variables; for SSH, the credentials are managed by the user SSH configuration.
+func (t *templateClient) getGitFileContent(rURL *url.URL) ([]byte, error) {
+	log := logf.Log
+
+	// Check if the URL is in the expected format.
+	refIndex := strings.LastIndex(rURL.Path, "@")
+	if refIndex < 0 || rURL.Fragment == "" {
+		return nil, errors.Errorf(
+			"invalid Git url %q: a Git url should be in the form git+https://{host}/{repository}@{ref}#{path-to-file}", rURL,
+		)
+	}
+	ref := rURL.Path[refIndex+1:]
+	path := strings.TrimPrefix(rURL.Fragment, "/")
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, errors.Errorf("invalid Git url %q: invalid ref %q", rURL, ref)
+	}
+
+	remoteURL := *rURL
+	remoteURL.Scheme = strings.TrimPrefix(rURL.Scheme, gitSchemePrefix)
+	if !isSupportedGitTransport(remoteURL.Scheme) {
+		return nil, errors.Errorf("invalid Git url %q: unsupported transport %q, supported transports are %s", rURL, remoteURL.Scheme, strings.Join(gitTransports, ", "))
+	}
+	remoteURL.Path = rURL.Path[:refIndex]
+	remoteURL.Fragment = ""
+
+	// If credentials are defined, they are passed to git as an HTTP header set via environment variables, so they
+	// are not visible in the process list nor stored in the local repository configuration. Nb. the environment of
+	// the git process is still readable by the same user and by root.
+	username, _ := t.configClient.Variables().Get(config.GitUsernameVariable)
+	password, _ := t.configClient.Variables().Get(config.GitPasswordVariable)
+	gitEnv := gitCredentialsEnv(username, password)
+	if gitEnv != nil {
+		if err := checkGitVersion(minGitVersionForCredentials); err != nil {
+			return nil, errors.Wrapf(err, "failed to use the %s and %s variables", config.GitUsernameVariable, config.GitPasswordVariable)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "clusterctl-template")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	log.V(1).Info("Fetching the template from the Git repository", "Repository", remoteURL.String(), "Ref", ref)
+	if _, err := runGit(dir, nil, "init", "-q"); err != nil {
+		return nil, err
+	}
+	if _, err := runGit(dir, gitEnv, "fetch", "-q", "--depth", "1", "--", remoteURL.String(), ref); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch %q from the Git repository %q", ref, remoteURL.String())
+	}
+	content, err := runGit(dir, nil, "show", fmt.Sprintf("FETCH_HEAD:%s", path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read file %q at %q from the Git repository %q", path, ref, remoteURL.String())
+	}
+	return content, nil
+}
+
+// gitCredentialsEnv returns the environment variables setting the http.extraHeader git configuration to the basic
+// authentication header for the given credentials; it returns nil if no credentials are defined.
+// Nb. configuration from environment variables requires git 2.31 or later.
+func gitCredentialsEnv(username, password string) []string {
+	if username == "" && password == "" {
+		return nil
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		fmt.Sprintf("GIT_CONFIG_VALUE_0=Authorization: Basic %s", credentials),
+	}
+}
+
+// isSupportedGitTransport returns true if the transport is supported for reading templates from Git repositories.
+func isSupportedGitTransport(transport string) bool {
+	for _, t := range gitTransports {
+		if transport == t {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGitVersion returns an error if the installed git version is older than the given minimum version.
+func checkGitVersion(minVersion *version.Version) error {
+	out, err := runGit("", nil, "version")
+	if err != nil {
+		return err
+	}
+	gitVersion, err := parseGitVersion(string(out))
+	if err != nil {
+		return err
+	}
+	if gitVersion.LessThan(minVersion) {
+		return errors.Errorf("git version %s is not supported, git %s or later is required", gitVersion, minVersion)
+	}
+	return nil
+}
+
+// parseGitVersion parses the output of the git version command, e.g. "git version 2.31.1" or
+// "git version 2.31.1.windows.1".
+func parseGitVersion(out string) (*version.Version, error) {
+	fields := strings.Fields(out)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return nil, errors.Errorf("failed to parse the git version from %q", strings.TrimSpace(out))
+	}
+	gitVersion, err := version.ParseGeneric(fields[2])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the git version from %q", strings.TrimSpace(out))
+	}
+	return gitVersion, nil
+}
+
+// runGit runs a git command in the given directory with the given additional environment variables,
+// returning the command output.
+func runGit(dir string, env []string, command string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{command}, args...)...)
+	cmd.Dir = dir
+	// Never prompt for credentials, because clusterctl is not interactive.
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "git %s failed: %s", command, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
 func getGitHubClient(configVariablesClient config.VariablesClient) (*github.Client, error) {
 	var authenticatingHTTPClient *http.Client
 	if token, err := configVariablesClient.Get(config.GitHubTokenVariable); err == nil {
@@ -17,13 +17,18 @@ limitations under the License.
 package cluster
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -308,6 +313,9 @@ func Test_templateClient_GetFromURL(t *testing.T) {
 	path := filepath.Join(tmpDir, "cluster-template.yaml")
 	g.Expect(ioutil.WriteFile(path, []byte(template), 0600)).To(Succeed())
 
+	registry := newFakeTemplateRegistry(t)
+	defer registry.Close()
+
 	type args struct {
 		templateURL       string
 		targetNamespace   string
@@ -339,6 +347,25 @@ func Test_templateClient_GetFromURL(t *testing.T) {
 			want:    template,
 			wantErr: false,
 		},
+		{
+			name: "Get from OCI registry",
+			args: args{
+				templateURL:       "oci://" + strings.TrimPrefix(registry.URL, "https://") + "/templates:v1.0.0",
+				targetNamespace:   "",
+				listVariablesOnly: false,
+			},
+			want:    template,
+			wantErr: false,
+		},
+		{
+			name: "Fails for unsupported schemes",
+			args: args{
+				templateURL:       "ftp://example.com/cluster-template.yaml",
+				targetNamespace:   "",
+				listVariablesOnly: false,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -351,6 +378,8 @@ func Test_templateClient_GetFromURL(t *testing.T) {
 			c := newTemplateClient(TemplateClientInput{nil, configClient, processor})
 			// override the github client factory
 			c.gitHubClientFactory = gitHubClientFactory
+			// override the HTTP client used for OCI registries, so it trusts the fake registry
+			c.ociHTTPClient = registry.Client()
 
 			got, err := c.GetFromURL(tt.args.templateURL, tt.args.targetNamespace, tt.args.listVariablesOnly)
 			if tt.wantErr {
@@ -373,6 +402,197 @@ func Test_templateClient_GetFromURL(t *testing.T) {
 	}
 }
 
+// newFakeTemplateRegistry returns a fake OCI registry hosting the templates repository, with the v1.0.0 tag
+// containing a single file.
+func newFakeTemplateRegistry(t *testing.T) *httptest.Server {
+	sum := sha256.Sum256([]byte(template))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Request method: %v, want %v", r.Method, http.MethodGet)
+		}
+
+		switch r.URL.Path {
+		case "/v2/templates/manifests/v1.0.0":
+			fmt.Fprintf(w, `{"schemaVersion": 2, "layers": [{"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": "%s", "annotations": {"org.opencontainers.image.title": "cluster-template.yaml"}}]}`, digest)
+		case "/v2/templates/blobs/" + digest:
+			fmt.Fprint(w, template)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func Test_templateClient_getGitFileContent(t *testing.T) {
+	g := NewWithT(t)
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	// Creates a local bare git repository with a template in the v1.0.0 tag and a different template in the master branch.
+	bareDir := filepath.Join(tmpDir, "templates.git")
+	workDir := filepath.Join(tmpDir, "work")
+	g.Expect(os.MkdirAll(filepath.Join(workDir, "aws"), 0755)).To(Succeed())
+	mustRunGit(t, tmpDir, "init", "-q", "--bare", bareDir)
+	mustRunGit(t, workDir, "init", "-q")
+	g.Expect(ioutil.WriteFile(filepath.Join(workDir, "aws", "cluster-template.yaml"), []byte(template), 0600)).To(Succeed())
+	mustRunGit(t, workDir, "add", ".")
+	mustRunGit(t, workDir, "commit", "-q", "-m", "v1.0.0")
+	mustRunGit(t, workDir, "tag", "v1.0.0")
+	g.Expect(ioutil.WriteFile(filepath.Join(workDir, "aws", "cluster-template.yaml"), []byte("changed"), 0600)).To(Succeed())
+	mustRunGit(t, workDir, "commit", "-q", "-a", "-m", "changed")
+	mustRunGit(t, workDir, "push", "-q", bareDir, "HEAD:refs/heads/master", "v1.0.0")
+
+	configClient, err := config.New("", config.InjectReader(test.NewFakeReader()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Get file from tag",
+			rawURL:  "git+file://" + bareDir + "@v1.0.0#aws/cluster-template.yaml",
+			want:    template,
+			wantErr: false,
+		},
+		{
+			name:    "Get file from branch",
+			rawURL:  "git+file://" + bareDir + "@master#/aws/cluster-template.yaml",
+			want:    "changed",
+			wantErr: false,
+		},
+		{
+			name:    "Fails if ref does not exist",
+			rawURL:  "git+file://" + bareDir + "@v2.0.0#aws/cluster-template.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "Fails if file does not exist",
+			rawURL:  "git+file://" + bareDir + "@v1.0.0#gcp/cluster-template.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "Fails if ref is missing",
+			rawURL:  "git+file://" + bareDir + "#aws/cluster-template.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "Fails if path is missing",
+			rawURL:  "git+file://" + bareDir + "@v1.0.0",
+			wantErr: true,
+		},
+		{
+			name:    "Fails if transport is not supported",
+			rawURL:  "git+ext://" + bareDir + "@v1.0.0#aws/cluster-template.yaml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &templateClient{
+				configClient: configClient,
+			}
+			got, err := c.getGitFileContent(mustParseURL(tt.rawURL))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_templateClient_getGitFileContent_credentials(t *testing.T) {
+	g := NewWithT(t)
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// Records the authorization headers sent by git to a fake Git server, which rejects all the requests.
+	authorizations := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	configClient, err := config.New("", config.InjectReader(test.NewFakeReader().
+		WithVar(config.GitUsernameVariable, "foo").
+		WithVar(config.GitPasswordVariable, "bar")))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	c := &templateClient{
+		configClient: configClient,
+	}
+	_, err = c.getGitFileContent(mustParseURL("git+" + srv.URL + "/templates.git@v1.0.0#aws/cluster-template.yaml"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).NotTo(ContainSubstring(base64.StdEncoding.EncodeToString([]byte("foo:bar"))))
+
+	g.Expect(authorizations).To(Receive(Equal("Basic " + base64.StdEncoding.EncodeToString([]byte("foo:bar")))))
+}
+
+func Test_gitCredentialsEnv(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(gitCredentialsEnv("", "")).To(BeEmpty())
+	g.Expect(gitCredentialsEnv("foo", "bar")).To(Equal([]string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("foo:bar")),
+	}))
+}
+
+func Test_parseGitVersion(t *testing.T) {
+	tests := []struct {
+		out     string
+		want    string
+		wantErr bool
+	}{
+		{out: "git version 2.31.1\n", want: "2.31.1"},
+		{out: "git version 2.30.0.windows.1", want: "2.30.0"},
+		{out: "git version 2.39.5 (Apple Git-143)", want: "2.39.5"},
+		{out: "git 2.31.1", wantErr: true},
+		{out: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseGitVersion(tt.out)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got.String()).To(Equal(tt.want))
+		})
+	}
+}
+
+func mustRunGit(t *testing.T, dir string, args ...string) {
+	env := []string{
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	}
+	if _, err := runGit(dir, env, args[0], args[1:]...); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func mustParseURL(rawURL string) *url.URL {
 	rURL, err := url.Parse(rawURL)
 	if err != nil {
@@ -35,6 +35,10 @@ type ProviderUpgrader interface {
 	//   - Upgrade to the latest version in the the v1alpha3 series: ....
 	Plan() ([]UpgradePlan, error)
 
+	// DiffPlan returns the changes to the provider components resulting from executing an upgrade following an UpgradePlan
+	// generated by clusterctl, comparing the components of the current and of the target version of each provider.
+	DiffPlan(coreProvider clusterctlv1.Provider, contract string) ([]ComponentsDiff, error)
+
 	// ApplyPlan executes an upgrade following an UpgradePlan generated by clusterctl.
 	ApplyPlan(coreProvider clusterctlv1.Provider, clusterAPIVersion string) error
 
@@ -69,6 +73,9 @@ func (u *UpgradePlan) isPartialUpgrade() bool {
 type UpgradeItem struct {
 	clusterctlv1.Provider
 	NextVersion string
+
+	// VersionConstraint pinning the provider to a range of versions, if defined in the clusterctl configuration.
+	VersionConstraint string
 }
 
 // UpgradeRef returns a string identifying the upgrade item; this string is derived by the provider.
@@ -189,10 +196,14 @@ func (u *providerUpgrader) getUpgradePlan(managementGroup ManagementGroup, contr
 		nextVersion := providerUpgradeInfo.getLatestNextVersion(contract)
 
 		// Append the upgrade item for the provider/with the target contract.
-		upgradeItems = append(upgradeItems, UpgradeItem{
+		upgradeItem := UpgradeItem{
 			Provider:    provider,
 			NextVersion: versionTag(nextVersion),
-		})
+		}
+		if providerUpgradeInfo.versionConstraint != nil {
+			upgradeItem.VersionConstraint = providerUpgradeInfo.versionConstraint.String()
+		}
+		upgradeItems = append(upgradeItems, upgradeItem)
 	}
 
 	return &UpgradePlan{
@@ -265,6 +276,11 @@ func (u *providerUpgrader) createCustomPlan(coreProvider clusterctlv1.Provider,
 			return nil, err
 		}
 
+		// Checks the target version of the provider is allowed by the version constraint, if any.
+		if err := u.checkVersionConstraint(*provider, upgradeItem.NextVersion); err != nil {
+			return nil, err
+		}
+
 		if contract != targetContract {
 			return nil, errors.Errorf("unable to complete that upgrade: the target version for the provider %s supports the %s API Version of Cluster API (contract), while the management group is using %s", upgradeItem.InstanceName(), contract, targetContract)
 		}
@@ -313,8 +329,32 @@ func (u *providerUpgrader) getProviderContractByVersion(provider clusterctlv1.Pr
 	return releaseSeries.Contract, nil
 }
 
+// checkVersionConstraint checks the target version of a provider is allowed by the version constraint pinning the provider
+// to a range of versions, if defined in the clusterctl configuration.
+func (u *providerUpgrader) checkVersionConstraint(provider clusterctlv1.Provider, targetVersion string) error {
+	versionConstraint, err := u.configClient.VersionConstraints().Get(provider.ManifestLabel())
+	if err != nil {
+		return err
+	}
+	if versionConstraint == nil {
+		return nil
+	}
+
+	// we are ignoring the conversion error here because the target version was already parsed by getProviderContractByVersion.
+	targetSemVersion, _ := version.ParseSemantic(targetVersion)
+	if !versionConstraint.Allows(targetSemVersion) {
+		return errors.Errorf("unable to complete that upgrade: the target version %s for the provider %s is not allowed by the %q version constraint defined in the clusterctl configuration", targetVersion, provider.InstanceName(), versionConstraint)
+	}
+	return nil
+}
+
 // getUpgradeComponents returns the provider components for the selected target version.
 func (u *providerUpgrader) getUpgradeComponents(provider UpgradeItem) (repository.Components, error) {
+	return u.getComponents(provider.Provider, provider.NextVersion)
+}
+
+// getComponents returns the provider components for the given version.
+func (u *providerUpgrader) getComponents(provider clusterctlv1.Provider, version string) (repository.Components, error) {
 	configRepository, err := u.configClient.Providers().Get(provider.ProviderName, provider.GetProviderType())
 	if err != nil {
 		return nil, err
@@ -326,7 +366,7 @@ func (u *providerUpgrader) getUpgradeComponents(provider UpgradeItem) (repositor
 	}
 
 	options := repository.ComponentsOptions{
-		Version:           provider.NextVersion,
+		Version:           version,
 		TargetNamespace:   provider.Namespace,
 		WatchingNamespace: provider.WatchedNamespace,
 	}
@@ -0,0 +1,226 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/yaml"
+)
+
+// ObjectDiffType defines the type of change to a provider component.
+type ObjectDiffType string
+
+const (
+	// ObjectAdded is used for components existing only in the target version of the provider.
+	ObjectAdded = ObjectDiffType("Added")
+
+	// ObjectRemoved is used for components existing only in the current version of the provider.
+	ObjectRemoved = ObjectDiffType("Removed")
+
+	// ObjectChanged is used for components existing in both the versions of the provider, but with different content.
+	ObjectChanged = ObjectDiffType("Changed")
+)
+
+// ObjectDiff defines a change to a provider component resulting from an upgrade.
+type ObjectDiff struct {
+	// Object is the component added, removed or changed.
+	Object corev1.ObjectReference
+
+	// Type of the change.
+	Type ObjectDiffType
+
+	// Diff is a unified diff of the YAML of the component in the current and in the target version;
+	// it is empty for added or removed components.
+	Diff string
+}
+
+// ComponentsDiff defines the changes to the components of a provider resulting from an upgrade.
+type ComponentsDiff struct {
+	clusterctlv1.Provider
+
+	// NextVersion is the version the provider is going to be upgraded to.
+	NextVersion string
+
+	// Objects lists the components added, removed or changed; unchanged components are not included.
+	Objects []ObjectDiff
+}
+
+func (u *providerUpgrader) DiffPlan(coreProvider clusterctlv1.Provider, contract string) ([]ComponentsDiff, error) {
+	// Retrieves the management group.
+	managementGroup, err := u.getManagementGroup(coreProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gets the upgrade plan for the selected management group/API Version of Cluster API (contract).
+	upgradePlan, err := u.getUpgradePlan(*managementGroup, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []ComponentsDiff{}
+	for _, upgradeItem := range upgradePlan.Providers {
+		// If there is not a specified next version, skip it (we are already up-to-date).
+		if upgradeItem.NextVersion == "" {
+			continue
+		}
+
+		diff, err := u.diffUpgradeItem(upgradeItem)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, *diff)
+	}
+	return ret, nil
+}
+
+// diffUpgradeItem compares the components of the current and of the target version of a provider.
+// Nb. both the versions are read from the provider repository, so the comparison is not affected by changes
+// applied by the controllers or by the users to the objects in the cluster.
+func (u *providerUpgrader) diffUpgradeItem(upgradeItem UpgradeItem) (*ComponentsDiff, error) {
+	currentComponents, err := u.getComponents(upgradeItem.Provider, upgradeItem.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the components for the current version of the %s provider", upgradeItem.InstanceName())
+	}
+
+	nextComponents, err := u.getUpgradeComponents(upgradeItem)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the components for the target version of the %s provider", upgradeItem.InstanceName())
+	}
+
+	objs, err := diffObjects(componentsObjs(currentComponents), componentsObjs(nextComponents))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compare the components of the %s provider", upgradeItem.InstanceName())
+	}
+
+	return &ComponentsDiff{
+		Provider:    upgradeItem.Provider,
+		NextVersion: upgradeItem.NextVersion,
+		Objects:     objs,
+	}, nil
+}
+
+// componentsObjs returns all the objects in a provider components.
+func componentsObjs(components repository.Components) []unstructured.Unstructured {
+	objs := []unstructured.Unstructured{}
+	objs = append(objs, components.SharedObjs()...)
+	objs = append(objs, components.InstanceObjs()...)
+	return objs
+}
+
+// diffKey identifies an object across versions of a provider; the API version is not included, so an object
+// moving to a new API version, e.g. a CRD moving from apiextensions.k8s.io/v1beta1 to apiextensions.k8s.io/v1,
+// is reported as changed.
+type diffKey struct {
+	group     string
+	kind      string
+	namespace string
+	name      string
+}
+
+func newDiffKey(obj unstructured.Unstructured) diffKey {
+	return diffKey{
+		group:     obj.GroupVersionKind().Group,
+		kind:      obj.GetKind(),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+}
+
+// diffObjects returns the objects added, removed or changed between the current and the next list of objects,
+// sorted by kind, namespace and name.
+func diffObjects(current, next []unstructured.Unstructured) ([]ObjectDiff, error) {
+	currentObjs := map[diffKey]unstructured.Unstructured{}
+	for _, obj := range current {
+		currentObjs[newDiffKey(obj)] = obj
+	}
+
+	ret := []ObjectDiff{}
+	for _, nextObj := range next {
+		key := newDiffKey(nextObj)
+		currentObj, ok := currentObjs[key]
+		if !ok {
+			ret = append(ret, ObjectDiff{Object: objectReference(nextObj), Type: ObjectAdded})
+			continue
+		}
+		delete(currentObjs, key)
+
+		diff, err := diffYAML(currentObj, nextObj)
+		if err != nil {
+			return nil, err
+		}
+		if diff != "" {
+			ret = append(ret, ObjectDiff{Object: objectReference(nextObj), Type: ObjectChanged, Diff: diff})
+		}
+	}
+
+	for _, currentObj := range currentObjs {
+		ret = append(ret, ObjectDiff{Object: objectReference(currentObj), Type: ObjectRemoved})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Object.Kind != ret[j].Object.Kind {
+			return ret[i].Object.Kind < ret[j].Object.Kind
+		}
+		if ret[i].Object.Namespace != ret[j].Object.Namespace {
+			return ret[i].Object.Namespace < ret[j].Object.Namespace
+		}
+		return ret[i].Object.Name < ret[j].Object.Name
+	})
+	return ret, nil
+}
+
+// diffYAML returns a unified diff of the YAML of the current and of the next version of an object;
+// the diff is empty if the two versions are equal.
+func diffYAML(current, next unstructured.Unstructured) (string, error) {
+	currentYAML, err := yaml.Marshal(current.Object)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal %s %s/%s to YAML", current.GetKind(), current.GetNamespace(), current.GetName())
+	}
+	nextYAML, err := yaml.Marshal(next.Object)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal %s %s/%s to YAML", next.GetKind(), next.GetNamespace(), next.GetName())
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(currentYAML)),
+		B:        difflib.SplitLines(string(nextYAML)),
+		FromFile: "current",
+		ToFile:   "next",
+		Context:  3,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to diff %s %s/%s", next.GetKind(), next.GetNamespace(), next.GetName())
+	}
+	return diff, nil
+}
+
+func objectReference(obj unstructured.Unstructured) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+var (
+	diffComponentsV1 = []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.infra.cluster.x-k8s.io
+spec:
+  group: infra.cluster.x-k8s.io
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: manager-role
+rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["get"]
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: infra-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: infra-controller:v1.0.0
+`)
+
+	diffComponentsV2 = []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.infra.cluster.x-k8s.io
+spec:
+  group: infra.cluster.x-k8s.io
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: bars.infra.cluster.x-k8s.io
+spec:
+  group: infra.cluster.x-k8s.io
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: infra-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: infra-controller:v1.0.1
+`)
+)
+
+func Test_providerUpgrader_DiffPlan(t *testing.T) {
+	g := NewWithT(t)
+
+	reader := test.NewFakeReader().
+		WithProvider("cluster-api", clusterctlv1.CoreProviderType, "https://somewhere.com").
+		WithProvider("infra", clusterctlv1.InfrastructureProviderType, "https://somewhere.com")
+
+	repositories := map[string]repository.Repository{
+		// no new versions for the core provider, so it is not included in the diff
+		"cluster-api": test.NewFakeRepository().
+			WithVersions("v1.0.0").
+			WithMetadata("v1.0.0", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 1, Minor: 0, Contract: "v1alpha3"},
+				},
+			}),
+		"infrastructure-infra": test.NewFakeRepository().
+			WithPaths("root", "components.yaml").
+			WithDefaultVersion("v1.0.1").
+			WithFile("v1.0.0", "components.yaml", diffComponentsV1).
+			WithFile("v1.0.1", "components.yaml", diffComponentsV2).
+			WithMetadata("v1.0.1", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 1, Minor: 0, Contract: "v1alpha3"},
+				},
+			}),
+	}
+
+	proxy := test.NewFakeProxy().
+		WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system", "").
+		WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra-system", "")
+
+	configClient, _ := config.New("", config.InjectReader(reader))
+
+	u := &providerUpgrader{
+		configClient: configClient,
+		repositoryClientFactory: func(provider config.Provider, configClient config.Client, options ...repository.Option) (repository.Client, error) {
+			return repository.New(provider, configClient, repository.InjectRepository(repositories[provider.ManifestLabel()]))
+		},
+		providerInventory: newInventoryClient(proxy, nil),
+	}
+
+	got, err := u.DiffPlan(fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "", "cluster-api-system", ""), "v1alpha3")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(HaveLen(1))
+
+	g.Expect(got[0].InstanceName()).To(Equal("infra-system/infrastructure-infra"))
+	g.Expect(got[0].Version).To(Equal("v1.0.0"))
+	g.Expect(got[0].NextVersion).To(Equal("v1.0.1"))
+
+	var changes []string
+	for _, o := range got[0].Objects {
+		changes = append(changes, string(o.Type)+" "+o.Object.Kind+" "+o.Object.Name)
+		if o.Type == ObjectChanged {
+			g.Expect(o.Diff).To(HavePrefix("--- current\n+++ next\n"))
+			g.Expect(o.Diff).To(ContainSubstring("\n-      - image: infra-controller:v1.0.0\n+      - image: infra-controller:v1.0.1\n"))
+		} else {
+			g.Expect(o.Diff).To(BeEmpty())
+		}
+	}
+	g.Expect(changes).To(Equal([]string{
+		"Removed ClusterRole infra-system-manager-role", // NB. cluster-wide RBAC is prefixed with the target namespace
+		"Added CustomResourceDefinition bars.infra.cluster.x-k8s.io",
+		"Changed Deployment controller-manager",
+	}))
+}
@@ -24,6 +24,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 )
 
 // upgradeInfo holds all the information required for taking upgrade decisions for a provider
@@ -41,10 +43,16 @@ type upgradeInfo struct {
 	// nextVersions return the list of versions available for upgrades, defined as the list of version available in the provider repository
 	// greater than the currentVersion.
 	nextVersions []version.Version
+
+	// versionConstraint pinning the provider to a range of versions, if defined in the clusterctl configuration.
+	// Nb. nextVersions includes only versions satisfying the version constraint.
+	versionConstraint *config.VersionConstraint
 }
 
 // getUpgradeInfo returns all the info required for taking upgrade decisions for a provider.
 func (u *providerUpgrader) getUpgradeInfo(provider clusterctlv1.Provider) (*upgradeInfo, error) {
+	log := logf.Log
+
 	// Gets the list of versions available in the provider repository.
 	configRepository, err := u.configClient.Providers().Get(provider.ProviderName, provider.GetProviderType())
 	if err != nil {
@@ -92,6 +100,12 @@ func (u *providerUpgrader) getUpgradeInfo(provider clusterctlv1.Provider) (*upgr
 		return nil, errors.Errorf("invalid provider metadata: version %s (the current version) for the provider %s does not match any release series", provider.Version, provider.InstanceName())
 	}
 
+	// Gets the version constraint pinning the provider to a range of versions, if any.
+	versionConstraint, err := u.configClient.VersionConstraints().Get(provider.ManifestLabel())
+	if err != nil {
+		return nil, err
+	}
+
 	// Filters the versions to be considered for upgrading the provider (next versions) and checks if the releaseSeries defined in metadata includes all of them.
 	nextVersions := []version.Version{}
 	for _, repositoryVersion := range repositoryVersions {
@@ -108,10 +122,18 @@ func (u *providerUpgrader) getUpgradeInfo(provider clusterctlv1.Provider) (*upgr
 			return nil, errors.Errorf("invalid provider metadata: version %s (one of the available versions) for the provider %s does not match any release series", repositoryVersion, provider.InstanceName())
 		}
 
+		// Drop the nextVersion if not allowed by the version constraint.
+		if versionConstraint != nil && !versionConstraint.Allows(repositorySemVersion) {
+			log.V(1).Info("Skipping version not allowed by the version constraint", "Provider", provider.InstanceName(), "Version", repositoryVersion, "VersionConstraint", versionConstraint.String())
+			continue
+		}
+
 		nextVersions = append(nextVersions, *repositorySemVersion)
 	}
 
-	return newUpgradeInfo(latestMetadata, currentVersion, nextVersions), nil
+	upgradeInfo := newUpgradeInfo(latestMetadata, currentVersion, nextVersions)
+	upgradeInfo.versionConstraint = versionConstraint
+	return upgradeInfo, nil
 }
 
 func newUpgradeInfo(metadata *clusterctlv1.Metadata, currentVersion *version.Version, nextVersions []version.Version) *upgradeInfo {
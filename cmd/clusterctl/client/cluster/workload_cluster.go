@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	utilkubeconfig "sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	waitClustersReadyInterval = 10 * time.Second
+	waitClustersReadyTimeout  = 10 * time.Minute
+)
+
+// WorkloadCluster has methods for accessing workload clusters managed by the management cluster.
+type WorkloadCluster interface {
+	// GetKubeconfig returns the admin kubeconfig of a workload cluster, as stored in the <cluster>-kubeconfig secret.
+	GetKubeconfig(namespace, name string) ([]byte, error)
+
+	// GenerateKubeconfig returns a kubeconfig for a workload cluster with a new client certificate
+	// signed by the cluster CA and valid for the given duration.
+	GenerateKubeconfig(namespace, name string, certDuration time.Duration) ([]byte, error)
+
+	// WaitForClustersReady waits for all the Clusters in a namespace to be reconciled by the controllers running
+	// in the management cluster and to be ready. If timeout is zero, a default timeout of 10 minutes is used.
+	WaitForClustersReady(namespace string, timeout time.Duration) error
+
+	// ListClusters returns the namespace/name of the Clusters in a namespace, or in all the namespaces
+	// if namespace is empty.
+	ListClusters(namespace string) ([]string, error)
+}
+
+// workloadCluster implements WorkloadCluster.
+type workloadCluster struct {
+	proxy               Proxy
+	pollImmediateWaiter PollImmediateWaiter
+}
+
+// ensure workloadCluster implements WorkloadCluster.
+var _ WorkloadCluster = &workloadCluster{}
+
+func newWorkloadCluster(proxy Proxy, pollImmediateWaiter PollImmediateWaiter) *workloadCluster {
+	return &workloadCluster{
+		proxy:               proxy,
+		pollImmediateWaiter: pollImmediateWaiter,
+	}
+}
+
+func (w *workloadCluster) GetKubeconfig(namespace, name string) ([]byte, error) {
+	c, err := w.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	readBackoff := newReadBackoff()
+	if err := retryWithExponentialBackoff(readBackoff, func() error {
+		data, err = utilkubeconfig.FromSecret(ctx, c, client.ObjectKey{Namespace: namespace, Name: name})
+		return err
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the kubeconfig for Cluster %s/%s", namespace, name)
+	}
+	return data, nil
+}
+
+func (w *workloadCluster) GenerateKubeconfig(namespace, name string, certDuration time.Duration) ([]byte, error) {
+	c, err := w.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &clusterv1.Cluster{}
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	readBackoff := newReadBackoff()
+	if err := retryWithExponentialBackoff(readBackoff, func() error {
+		return c.Get(ctx, key, cluster)
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get Cluster %s/%s", namespace, name)
+	}
+	if cluster.Spec.ControlPlaneEndpoint.IsZero() {
+		return nil, errors.Errorf("the control plane endpoint for Cluster %s/%s is not yet set", namespace, name)
+	}
+
+	server := fmt.Sprintf("https://%s", cluster.Spec.ControlPlaneEndpoint.String())
+	data, err := utilkubeconfig.FromCA(ctx, c, key, server, certDuration)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate the kubeconfig for Cluster %s/%s", namespace, name)
+	}
+	return data, nil
+}
+
+func (w *workloadCluster) WaitForClustersReady(namespace string, timeout time.Duration) error {
+	log := logf.Log
+
+	c, err := w.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	if timeout == 0 {
+		timeout = waitClustersReadyTimeout
+	}
+
+	log.Info("Waiting for the Clusters to be reconciled", "Namespace", namespace)
+	var notReady []string
+	if err := w.pollImmediateWaiter(waitClustersReadyInterval, timeout, func() (bool, error) {
+		clusters := &clusterv1.ClusterList{}
+		if err := c.List(ctx, clusters, client.InNamespace(namespace)); err != nil {
+			//Nb. we are ignoring the error so the pollImmediateWaiter will execute another retry
+			log.V(5).Info("Failed to list the Clusters", "Error", err.Error())
+			return false, nil
+		}
+
+		// A Cluster is considered reconciled when the controllers observed its latest generation and the Cluster is ready;
+		// given that the status is not preserved when creating objects, this ensures the controllers in the management
+		// cluster are actually reconciling the Cluster.
+		notReady = nil
+		for i := range clusters.Items {
+			cluster := &clusters.Items[i]
+			if cluster.Status.ObservedGeneration < cluster.Generation || !conditions.IsTrue(cluster, clusterv1.ReadyCondition) {
+				notReady = append(notReady, cluster.Name)
+			}
+		}
+		return len(notReady) == 0, nil
+	}); err != nil {
+		return errors.Wrapf(err, "failed to wait for the Clusters in namespace %s to be reconciled (not ready: %s)", namespace, strings.Join(notReady, ", "))
+	}
+	return nil
+}
+
+func (w *workloadCluster) ListClusters(namespace string) ([]string, error) {
+	c, err := w.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := &clusterv1.ClusterList{}
+	readBackoff := newReadBackoff()
+	if err := retryWithExponentialBackoff(readBackoff, func() error {
+		return c.List(ctx, clusters, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to list Clusters")
+	}
+
+	names := make([]string, 0, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		names = append(names, fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name))
+	}
+	sort.Strings(names)
+	return names, nil
+}
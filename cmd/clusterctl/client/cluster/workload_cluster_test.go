@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func Test_workloadCluster_GetKubeconfig(t *testing.T) {
+	g := NewWithT(t)
+
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      secret.Name("cluster1", secret.Kubeconfig),
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: []byte("kubeconfig"),
+		},
+	}
+
+	w := newWorkloadCluster(test.NewFakeProxy().WithObjs(kubeconfigSecret), nil)
+
+	got, err := w.GetKubeconfig("ns1", "cluster1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(got)).To(Equal("kubeconfig"))
+}
+
+func Test_workloadCluster_GenerateKubeconfig(t *testing.T) {
+	g := NewWithT(t)
+
+	// Nb. the generation of the kubeconfig is tested in util/kubeconfig, so only the validation of the Cluster is tested here.
+	cluster := &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "cluster1",
+		},
+	}
+
+	w := newWorkloadCluster(test.NewFakeProxy().WithObjs(cluster), nil)
+
+	_, err := w.GenerateKubeconfig("ns1", "cluster1", time.Hour)
+	g.Expect(err).To(MatchError(ContainSubstring("control plane endpoint")))
+}
+
+func Test_workloadCluster_WaitForClustersReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		ready   bool
+		wantErr bool
+	}{
+		{
+			name:    "succeeds if all the Clusters are ready",
+			ready:   true,
+			wantErr: false,
+		},
+		{
+			name:    "fails if a Cluster is not ready before the timeout",
+			ready:   false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns1",
+					Name:      "cluster1",
+				},
+			}
+			if tt.ready {
+				conditions.MarkTrue(cluster, clusterv1.ReadyCondition)
+			}
+
+			pollImmediateWaiter := func(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+				done, err := condition()
+				if err != nil {
+					return err
+				}
+				if !done {
+					return wait.ErrWaitTimeout
+				}
+				return nil
+			}
+
+			w := newWorkloadCluster(test.NewFakeProxy().WithObjs(cluster), pollImmediateWaiter)
+
+			err := w.WaitForClustersReady("ns1", 0)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(ContainSubstring("cluster1")))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func Test_workloadCluster_ListClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	w := newWorkloadCluster(test.NewFakeProxy().WithObjs(
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "cluster2"}},
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster1"}},
+	), nil)
+
+	got, err := w.ListClusters("")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]string{"ns1/cluster1", "ns2/cluster2"}))
+
+	got, err = w.ListClusters("ns2")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]string{"ns2/cluster2"}))
+}
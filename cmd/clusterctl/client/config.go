@@ -190,7 +190,9 @@ type ProviderRepositorySourceOptions struct {
 
 // URLSourceOptions defines the options to be used when reading a workload cluster template from an URL.
 type URLSourceOptions struct {
-	// URL to read the workload cluster template from.
+	// URL to read the workload cluster template from; GitHub blob URLs, local files, OCI artifacts
+	// (oci://{registry}/{repository}:{tag}[/{file}]) and files in Git repositories
+	// (git+https://{host}/{repository}@{ref}#{path-to-file}) are supported.
 	URL string
 }
 
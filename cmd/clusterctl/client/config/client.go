@@ -25,6 +25,8 @@ import (
 // 1. The configuration of the providers (name, type and URL of the provider repository)
 // 2. Variables used when installing providers/creating clusters. Variables can be read from the environment or from the config file
 // 3. The configuration about image overrides
+// 4. The configuration about verification of provider artifacts
+// 5. The configuration about version constraints pinning providers to a range of versions
 type Client interface {
 	// Providers provide access to provider configurations.
 	Providers() ProvidersClient
@@ -34,6 +36,12 @@ type Client interface {
 
 	// ImageMeta provide access to to image meta configurations.
 	ImageMeta() ImageMetaClient
+
+	// Verification provide access to the configuration for verifying provider artifacts.
+	Verification() VerificationClient
+
+	// VersionConstraints provide access to the configuration pinning providers to a range of versions.
+	VersionConstraints() VersionConstraintsClient
 }
 
 // configClient implements Client.
@@ -56,6 +64,14 @@ func (c *configClient) ImageMeta() ImageMetaClient {
 	return newImageMetaClient(c.reader)
 }
 
+func (c *configClient) Verification() VerificationClient {
+	return newVerificationClient(c.reader)
+}
+
+func (c *configClient) VersionConstraints() VersionConstraintsClient {
+	return newVersionConstraintsClient(c.reader)
+}
+
 // Option is a configuration option supplied to New
 type Option func(*configClient)
 
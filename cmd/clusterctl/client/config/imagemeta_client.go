@@ -24,7 +24,8 @@ import (
 )
 
 const (
-	imagesConfigKey = "images"
+	// ImagesConfigKey is the key of the image override configurations in the clusterctl config file.
+	ImagesConfigKey = "images"
 	allImageConfig  = "all"
 )
 
@@ -32,12 +33,16 @@ const (
 type ImageMetaClient interface {
 	// AlterImage alters an image name according to the current image override configurations.
 	AlterImage(component, image string) (string, error)
+
+	// Overrides returns the image override configurations, by component; the "all" key defines the override
+	// applying to all the components.
+	Overrides() (map[string]ImageMeta, error)
 }
 
 // imageMetaClient implements ImageMetaClient.
 type imageMetaClient struct {
 	reader         Reader
-	imageMetaCache map[string]*imageMeta
+	imageMetaCache map[string]*ImageMeta
 }
 
 // ensure imageMetaClient implements ImageMetaClient.
@@ -46,7 +51,7 @@ var _ ImageMetaClient = &imageMetaClient{}
 func newImageMetaClient(reader Reader) *imageMetaClient {
 	return &imageMetaClient{
 		reader:         reader,
-		imageMetaCache: map[string]*imageMeta{},
+		imageMetaCache: map[string]*ImageMeta{},
 	}
 }
 
@@ -64,16 +69,24 @@ func (p *imageMetaClient) AlterImage(component, image string) (string, error) {
 	return meta.ApplyToImage(image)
 }
 
+func (p *imageMetaClient) Overrides() (map[string]ImageMeta, error) {
+	var meta map[string]ImageMeta
+	if err := p.reader.UnmarshalKey(ImagesConfigKey, &meta); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal image override configurations")
+	}
+	return meta, nil
+}
+
 // getImageMetaByComponent returns the image meta that applies to the selected component
-func (p *imageMetaClient) getImageMetaByComponent(component string) (*imageMeta, error) {
+func (p *imageMetaClient) getImageMetaByComponent(component string) (*ImageMeta, error) {
 	// if the image meta for the component is already known, return it
 	if im, ok := p.imageMetaCache[component]; ok {
 		return im, nil
 	}
 
 	// Otherwise read the image override configurations.
-	var meta map[string]imageMeta
-	if err := p.reader.UnmarshalKey(imagesConfigKey, &meta); err != nil {
+	var meta map[string]ImageMeta
+	if err := p.reader.UnmarshalKey(ImagesConfigKey, &meta); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal image override configurations")
 	}
 
@@ -84,7 +97,7 @@ func (p *imageMetaClient) getImageMetaByComponent(component string) (*imageMeta,
 	}
 
 	// Gets the image configuration and to the specific component, and returns the union of the two.
-	m := &imageMeta{}
+	m := &ImageMeta{}
 	if allMeta, ok := meta[allImageConfig]; ok {
 		m.Union(&allMeta)
 	}
@@ -96,8 +109,8 @@ func (p *imageMetaClient) getImageMetaByComponent(component string) (*imageMeta,
 	return m, nil
 }
 
-// imageMeta allows to define transformations to apply to the image contained in the YAML manifests.
-type imageMeta struct {
+// ImageMeta allows to define transformations to apply to the image contained in the YAML manifests.
+type ImageMeta struct {
 	// repository sets the container registry to pull images from.
 	Repository string `json:"repository,omitempty"`
 
@@ -105,9 +118,9 @@ type imageMeta struct {
 	Tag string `json:"tag,omitempty"`
 }
 
-// Union allows to merge two imageMeta transformation; in case both the imageMeta defines new values for the same field,
+// Union allows to merge two ImageMeta transformation; in case both the ImageMeta defines new values for the same field,
 // the other transformation takes precedence on the existing one.
-func (i *imageMeta) Union(other *imageMeta) {
+func (i *ImageMeta) Union(other *ImageMeta) {
 	if other.Repository != "" {
 		i.Repository = other.Repository
 	}
@@ -116,8 +129,8 @@ func (i *imageMeta) Union(other *imageMeta) {
 	}
 }
 
-// ApplyToImage changes an image name applying the transformations defined in the current imageMeta.
-func (i *imageMeta) ApplyToImage(image string) (string, error) {
+// ApplyToImage changes an image name applying the transformations defined in the current ImageMeta.
+func (i *ImageMeta) ApplyToImage(image string) (string, error) {
 
 	newImage, err := container.ImageFromString(image)
 	if err != nil {
@@ -106,7 +106,7 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 		{
 			name: "fails if wrong image config",
 			fields: fields{
-				reader: test.NewFakeReader().WithVar(imagesConfigKey, "invalid"),
+				reader: test.NewFakeReader().WithVar(ImagesConfigKey, "invalid"),
 			},
 			args: args{
 				component: "any",
@@ -145,3 +145,26 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 		})
 	}
 }
+
+func Test_imageMetaClient_Overrides(t *testing.T) {
+	g := NewWithT(t)
+
+	p := newImageMetaClient(test.NewFakeReader())
+	got, err := p.Overrides()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(BeEmpty())
+
+	p = newImageMetaClient(test.NewFakeReader().
+		WithImageMeta(allImageConfig, "foo-repository.io", "").
+		WithImageMeta("cert-manager", "", "bar-tag"))
+	got, err = p.Overrides()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal(map[string]ImageMeta{
+		allImageConfig: {Repository: "foo-repository.io"},
+		"cert-manager": {Tag: "bar-tag"},
+	}))
+
+	p = newImageMetaClient(test.NewFakeReader().WithVar(ImagesConfigKey, "invalid"))
+	_, err = p.Overrides()
+	g.Expect(err).To(HaveOccurred())
+}
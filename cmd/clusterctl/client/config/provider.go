@@ -46,13 +46,26 @@ type Provider interface {
 
 	// Less func can be used to ensure a consist order of provider lists.
 	Less(other Provider) bool
+
+	// TemplateProcessor returns the name of the yaml processor to be used for the provider's cluster templates.
+	// If empty, the SimpleTemplateProcessor is used.
+	TemplateProcessor() string
 }
 
+const (
+	// SimpleTemplateProcessor identifies the yaml processor substituting variables in the ${VAR} format.
+	SimpleTemplateProcessor = "simple"
+
+	// GoTemplateProcessor identifies the yaml processor using Go text/template, supporting conditionals and loops.
+	GoTemplateProcessor = "gotemplate"
+)
+
 // provider implements provider
 type provider struct {
-	name         string
-	url          string
-	providerType clusterctlv1.ProviderType
+	name              string
+	url               string
+	providerType      clusterctlv1.ProviderType
+	templateProcessor string
 }
 
 // ensure provider implements provider
@@ -70,6 +83,10 @@ func (p *provider) Type() clusterctlv1.ProviderType {
 	return p.providerType
 }
 
+func (p *provider) TemplateProcessor() string {
+	return p.templateProcessor
+}
+
 func (p *provider) SameAs(other Provider) bool {
 	return p.name == other.Name() && p.providerType == other.Type()
 }
@@ -91,18 +108,30 @@ func NewProvider(name string, url string, ttype clusterctlv1.ProviderType) Provi
 	}
 }
 
+// NewProviderWithTemplateProcessor returns a provider configuration using a specific yaml processor for cluster templates.
+func NewProviderWithTemplateProcessor(name string, url string, ttype clusterctlv1.ProviderType, templateProcessor string) Provider {
+	return &provider{
+		name:              name,
+		url:               url,
+		providerType:      ttype,
+		templateProcessor: templateProcessor,
+	}
+}
+
 func (p provider) MarshalJSON() ([]byte, error) {
 	dir, file := filepath.Split(p.url)
 	j, err := json.Marshal(struct {
-		Name         string
-		ProviderType clusterctlv1.ProviderType
-		URL          string
-		File         string
+		Name              string
+		ProviderType      clusterctlv1.ProviderType
+		URL               string
+		File              string
+		TemplateProcessor string `json:",omitempty"`
 	}{
-		Name:         p.name,
-		ProviderType: p.providerType,
-		URL:          dir,
-		File:         file,
+		Name:              p.name,
+		ProviderType:      p.providerType,
+		URL:               dir,
+		File:              file,
+		TemplateProcessor: p.templateProcessor,
 	})
 	if err != nil {
 		return nil, err
@@ -152,9 +152,10 @@ func (p *providersClient) defaults() []Provider {
 
 // configProvider mirrors config.Provider interface and allows serialization of the corresponding info
 type configProvider struct {
-	Name string                    `json:"name,omitempty"`
-	URL  string                    `json:"url,omitempty"`
-	Type clusterctlv1.ProviderType `json:"type,omitempty"`
+	Name              string                    `json:"name,omitempty"`
+	URL               string                    `json:"url,omitempty"`
+	Type              clusterctlv1.ProviderType `json:"type,omitempty"`
+	TemplateProcessor string                    `json:"templateProcessor,omitempty"`
 }
 
 func (p *providersClient) List() ([]Provider, error) {
@@ -170,7 +171,7 @@ func (p *providersClient) List() ([]Provider, error) {
 	}
 
 	for _, u := range userDefinedProviders {
-		provider := NewProvider(u.Name, u.URL, u.Type)
+		provider := NewProviderWithTemplateProcessor(u.Name, u.URL, u.Type, u.TemplateProcessor)
 		if err := validateProvider(provider); err != nil {
 			return nil, errors.Wrapf(err, "error validating configuration for the %s with name %s. Please fix the providers value in clusterctl configuration file", provider.Type(), provider.Name())
 		}
@@ -247,5 +248,12 @@ func validateProvider(r Provider) error {
 			clusterctlv1.InfrastructureProviderType,
 			clusterctlv1.ControlPlaneProviderType)
 	}
+
+	switch r.TemplateProcessor() {
+	case "", SimpleTemplateProcessor, GoTemplateProcessor:
+		break
+	default:
+		return errors.Errorf("invalid template processor. Allowed values are [%s, %s]", SimpleTemplateProcessor, GoTemplateProcessor)
+	}
 	return nil
 }
@@ -41,6 +41,8 @@ func Test_providers_List(t *testing.T) {
 
 	defaultsAndZZZ := append(defaults, NewProvider("zzz", "https://zzz/infrastructure-components.yaml", "InfrastructureProvider"))
 
+	defaultsAndZZZWithGoTemplate := append(append([]Provider{}, defaults...), NewProviderWithTemplateProcessor("zzz", "https://zzz/infrastructure-components.yaml", "InfrastructureProvider", GoTemplateProcessor))
+
 	defaultsWithOverride := append([]Provider{}, defaults...)
 	defaultsWithOverride[0] = NewProvider(defaults[0].Name(), "https://zzz/infrastructure-components.yaml", defaults[0].Type())
 
@@ -75,6 +77,21 @@ func Test_providers_List(t *testing.T) {
 			want:    defaultsAndZZZ,
 			wantErr: false,
 		},
+		{
+			name: "Returns user defined provider configurations with template processor",
+			fields: fields{
+				configGetter: test.NewFakeReader().
+					WithVar(
+						ProvidersConfigKey,
+						"- name: \"zzz\"\n"+
+							"  url: \"https://zzz/infrastructure-components.yaml\"\n"+
+							"  type: \"InfrastructureProvider\"\n"+
+							"  templateProcessor: \"gotemplate\"\n",
+					),
+			},
+			want:    defaultsAndZZZWithGoTemplate,
+			wantErr: false,
+		},
 		{
 			name: "User defined provider configurations override defaults",
 			fields: fields{
@@ -214,6 +231,20 @@ func Test_validateProvider(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Pass with the go template processor",
+			args: args{
+				r: NewProviderWithTemplateProcessor("foo", "https://something.com", clusterctlv1.InfrastructureProviderType, GoTemplateProcessor),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fails if template processor is not valid",
+			args: args{
+				r: NewProviderWithTemplateProcessor("foo", "https://something.com", clusterctlv1.InfrastructureProviderType, "bar"),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
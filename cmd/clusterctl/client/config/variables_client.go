@@ -19,6 +19,25 @@ package config
 const (
 	// GitHubTokenVariable defines a variable hosting the GitHub access token
 	GitHubTokenVariable = "github-token"
+
+	// GitLabTokenVariable defines a variable hosting the GitLab access token
+	GitLabTokenVariable = "gitlab-token"
+
+	// OCIUsernameVariable defines a variable hosting the username for accessing an OCI registry
+	OCIUsernameVariable = "oci-username"
+
+	// OCIPasswordVariable defines a variable hosting the password for accessing an OCI registry
+	OCIPasswordVariable = "oci-password"
+
+	// GitUsernameVariable defines a variable hosting the username for accessing a Git repository over HTTPS
+	GitUsernameVariable = "git-username"
+
+	// GitPasswordVariable defines a variable hosting the password, or the access token, for accessing a Git repository over HTTPS
+	GitPasswordVariable = "git-password"
+
+	// OfflineVariable defines a variable that, when set to true, instructs clusterctl to serve provider repository
+	// artifacts only from the local cache
+	OfflineVariable = "CLUSTERCTL_OFFLINE"
 )
 
 // VariablesClient has methods to work with environment variables and with variables defined in the clusterctl configuration file.
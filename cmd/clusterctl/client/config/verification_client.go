@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	verificationConfigKey = "verification"
+)
+
+// VerificationClient has methods to work with the configuration for verifying the provider artifacts
+// read from provider repositories.
+type VerificationClient interface {
+	// Strict returns true if clusterctl should refuse providers that do not publish signed checksums.
+	Strict() (bool, error)
+
+	// TrustedKeys returns the public keys trusted for verifying the signature of provider checksums.
+	TrustedKeys() ([]TrustedKey, error)
+}
+
+// TrustedKey defines a public key trusted for verifying the signature of provider checksums.
+type TrustedKey struct {
+	// Name of the key, used for logging purposes.
+	Name string `json:"name,omitempty"`
+
+	// Key is the PEM encoded public key; RSA, ECDSA and Ed25519 keys are supported.
+	Key string `json:"key,omitempty"`
+}
+
+// verification defines the verification configuration in the clusterctl config file.
+type verification struct {
+	Strict      bool         `json:"strict,omitempty"`
+	TrustedKeys []TrustedKey `json:"trustedKeys,omitempty"`
+}
+
+// verificationClient implements VerificationClient.
+type verificationClient struct {
+	reader Reader
+}
+
+// ensure verificationClient implements VerificationClient.
+var _ VerificationClient = &verificationClient{}
+
+func newVerificationClient(reader Reader) *verificationClient {
+	return &verificationClient{
+		reader: reader,
+	}
+}
+
+func (p *verificationClient) Strict() (bool, error) {
+	v, err := p.get()
+	if err != nil {
+		return false, err
+	}
+	return v.Strict, nil
+}
+
+func (p *verificationClient) TrustedKeys() ([]TrustedKey, error) {
+	v, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range v.TrustedKeys {
+		if k.Key == "" {
+			return nil, errors.Errorf("invalid trusted key %q: key value cannot be empty", k.Name)
+		}
+	}
+	return v.TrustedKeys, nil
+}
+
+func (p *verificationClient) get() (*verification, error) {
+	v := &verification{}
+	if err := p.reader.UnmarshalKey(verificationConfigKey, v); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the verification configuration")
+	}
+	return v, nil
+}
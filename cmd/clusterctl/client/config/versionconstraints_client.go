@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	versionConstraintsConfigKey = "versionConstraints"
+)
+
+// versionPrefixRegexp matches the "v" prefix of the versions in a version constraint, e.g. ">=v0.3.0" or "v0.3.x".
+var versionPrefixRegexp = regexp.MustCompile(`(^|[\s<>=!]+)v(\d)`)
+
+// VersionConstraintsClient has methods to work with the version constraints pinning providers to a range of versions.
+type VersionConstraintsClient interface {
+	// Get returns the version constraint for a provider, or nil if the provider is not pinned.
+	// Providers are identified by their manifest label, e.g. cluster-api or infrastructure-aws.
+	Get(provider string) (*VersionConstraint, error)
+}
+
+// VersionConstraint defines a range of versions a provider is pinned to.
+type VersionConstraint struct {
+	raw      string
+	semRange semver.Range
+}
+
+// NewVersionConstraint returns a VersionConstraint for a range of versions, e.g. "v0.3.x" or ">=v0.3.0 <v0.3.10".
+func NewVersionConstraint(constraint string) (*VersionConstraint, error) {
+	semRange, err := semver.ParseRange(versionPrefixRegexp.ReplaceAllString(strings.TrimSpace(constraint), "$1$2"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version constraint %q", constraint)
+	}
+	return &VersionConstraint{
+		raw:      constraint,
+		semRange: semRange,
+	}, nil
+}
+
+// Allows returns true if the version is in the range defined by the version constraint.
+func (v *VersionConstraint) Allows(ver *version.Version) bool {
+	semVer, err := semver.Parse(ver.String())
+	if err != nil {
+		return false
+	}
+	return v.semRange(semVer)
+}
+
+// String returns the version constraint as defined in the clusterctl configuration.
+func (v *VersionConstraint) String() string {
+	return v.raw
+}
+
+// versionConstraintsClient implements VersionConstraintsClient.
+type versionConstraintsClient struct {
+	reader Reader
+}
+
+// ensure versionConstraintsClient implements VersionConstraintsClient.
+var _ VersionConstraintsClient = &versionConstraintsClient{}
+
+func newVersionConstraintsClient(reader Reader) *versionConstraintsClient {
+	return &versionConstraintsClient{
+		reader: reader,
+	}
+}
+
+func (p *versionConstraintsClient) Get(provider string) (*VersionConstraint, error) {
+	var constraints map[string]string
+	if err := p.reader.UnmarshalKey(versionConstraintsConfigKey, &constraints); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the version constraints configuration")
+	}
+
+	constraint, ok := constraints[provider]
+	if !ok {
+		return nil, nil
+	}
+
+	v, err := NewVersionConstraint(constraint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the version constraint for the %s provider", provider)
+	}
+	return v, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_versionConstraintsClient_Get(t *testing.T) {
+	tests := []struct {
+		name        string
+		reader      Reader
+		provider    string
+		wantNil     bool
+		wantAllowed []string
+		wantDenied  []string
+		wantErr     bool
+	}{
+		{
+			name:     "no version constraints",
+			reader:   test.NewFakeReader(),
+			provider: "cluster-api",
+			wantNil:  true,
+		},
+		{
+			name:     "no version constraint for the provider",
+			reader:   test.NewFakeReader().WithVersionConstraint("infrastructure-aws", "v0.5.x"),
+			provider: "cluster-api",
+			wantNil:  true,
+		},
+		{
+			name:        "wildcard version constraint",
+			reader:      test.NewFakeReader().WithVersionConstraint("cluster-api", "v0.3.x"),
+			provider:    "cluster-api",
+			wantAllowed: []string{"v0.3.0", "v0.3.9"},
+			wantDenied:  []string{"v0.2.9", "v0.4.0", "v1.0.0"},
+		},
+		{
+			name:        "range version constraint",
+			reader:      test.NewFakeReader().WithVersionConstraint("cluster-api", ">=v0.3.2 <v0.3.5"),
+			provider:    "cluster-api",
+			wantAllowed: []string{"v0.3.2", "v0.3.4"},
+			wantDenied:  []string{"v0.3.1", "v0.3.5"},
+		},
+		{
+			name:        "range version constraint without v prefix",
+			reader:      test.NewFakeReader().WithVersionConstraint("cluster-api", ">=0.3.0 <0.4.0"),
+			provider:    "cluster-api",
+			wantAllowed: []string{"v0.3.0", "v0.3.9"},
+			wantDenied:  []string{"v0.4.0"},
+		},
+		{
+			name:     "invalid version constraint",
+			reader:   test.NewFakeReader().WithVersionConstraint("cluster-api", "foo"),
+			provider: "cluster-api",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			p := newVersionConstraintsClient(tt.reader)
+			got, err := p.Get(tt.provider)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			if tt.wantNil {
+				g.Expect(got).To(BeNil())
+				return
+			}
+			g.Expect(got).NotTo(BeNil())
+
+			for _, v := range tt.wantAllowed {
+				g.Expect(got.Allows(version.MustParseSemantic(v))).To(BeTrue(), v)
+			}
+			for _, v := range tt.wantDenied {
+				g.Expect(got.Allows(version.MustParseSemantic(v))).To(BeFalse(), v)
+			}
+		})
+	}
+}
@@ -58,22 +58,30 @@ type DeleteOptions struct {
 	// IncludeCRDs forces the deletion of the provider's CRDs (and of all the related objects).
 	// By Extension, this forces the deletion of all the resources shared among provider instances, like e.g. web-hooks.
 	IncludeCRDs bool
+
+	// IncludeCertManager forces the deletion of the cert-manager installed by clusterctl, including its CRDs.
+	// This option can be used only in combination with DeleteAll. An externally managed cert-manager is never deleted.
+	IncludeCertManager bool
 }
 
-func (c *clusterctlClient) Delete(options DeleteOptions) error {
+func (c *clusterctlClient) Delete(options DeleteOptions) ([]clusterctlv1.Provider, error) {
+	if options.IncludeCertManager && !options.DeleteAll {
+		return nil, errors.New("cert-manager can be deleted only when deleting all the providers")
+	}
+
 	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Get the list of installed providers.
 	installedProviders, err := clusterClient.ProviderInventory().List()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Prepare the list of providers to delete.
@@ -93,7 +101,7 @@ func (c *clusterctlClient) Delete(options DeleteOptions) error {
 			// Parse the abbreviated syntax for name[:version]
 			name, _, err := parseProviderName(provider.Name)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			// If the namespace where the provider is installed is not provided, try to detect it
@@ -101,13 +109,13 @@ func (c *clusterctlClient) Delete(options DeleteOptions) error {
 			if provider.Namespace == "" {
 				provider.Namespace, err = clusterClient.ProviderInventory().GetDefaultProviderNamespace(provider.ProviderName, provider.GetProviderType())
 				if err != nil {
-					return err
+					return nil, err
 				}
 
 				// if there are more instance of a providers, it is not possible to get a default namespace for the provider,
 				// so we should return and ask for it.
 				if provider.Namespace == "" {
-					return errors.Errorf("Unable to find default namespace for the %q provider. Please specify the provider's namespace", name)
+					return nil, errors.Errorf("Unable to find default namespace for the %q provider. Please specify the provider's namespace", name)
 				}
 			}
 
@@ -133,11 +141,18 @@ func (c *clusterctlClient) Delete(options DeleteOptions) error {
 	// Delete the selected providers
 	for _, provider := range providersToDelete {
 		if err := clusterClient.ProviderComponents().Delete(cluster.DeleteOptions{Provider: provider, IncludeNamespace: options.IncludeNamespace, IncludeCRDs: options.IncludeCRDs}); err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	// Delete cert-manager, if required.
+	if options.IncludeCertManager {
+		if err := clusterClient.CertManager().Delete(); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	return providersToDelete, nil
 }
 
 func appendProviders(list []clusterctlv1.Provider, providerType clusterctlv1.ProviderType, names ...string) []clusterctlv1.Provider {
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// DeleteClusterOptions carries the options supported by DeleteCluster.
+type DeleteClusterOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload cluster is located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// ClusterName is the name of the workload cluster to delete.
+	ClusterName string
+
+	// Wait for all the objects the workload cluster is composed of to be deleted.
+	Wait bool
+
+	// Timeout is the maximum time to wait for the objects to be deleted.
+	// If zero, a default timeout is used.
+	Timeout time.Duration
+}
+
+func (c *clusterctlClient) DeleteCluster(options DeleteClusterOptions) ([]RemainingObject, error) {
+	if options.ClusterName == "" {
+		return nil, errors.New("the name of the cluster to delete must be specified")
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	remaining, err := clusterClient.ClusterDeleter().Delete(options.Namespace, options.ClusterName, cluster.ClusterDeleteOptions{
+		Wait:    options.Wait,
+		Timeout: options.Timeout,
+	})
+
+	// RemainingObject is an alias for cluster.RemainingObject; this makes the conversion
+	aliasRemaining := make([]RemainingObject, len(remaining))
+	for i, r := range remaining {
+		aliasRemaining[i] = RemainingObject(r)
+	}
+	return aliasRemaining, err
+}
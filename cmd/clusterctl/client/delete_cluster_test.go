@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_clusterctlClient_DeleteCluster(t *testing.T) {
+	tests := []struct {
+		name    string
+		options DeleteClusterOptions
+		wantErr bool
+	}{
+		{
+			name: "returns an error if the cluster name is not specified",
+			options: DeleteClusterOptions{
+				Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if the cluster client is not found",
+			options: DeleteClusterOptions{
+				Kubeconfig:  Kubeconfig{Path: "kubeconfig", Context: "does-not-exist"},
+				ClusterName: "cluster1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if the cluster does not exist",
+			options: DeleteClusterOptions{
+				Kubeconfig:  Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Namespace:   "ns1",
+				ClusterName: "does-not-exist",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := fakeClientForMove().DeleteCluster(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
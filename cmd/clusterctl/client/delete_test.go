@@ -35,11 +35,12 @@ func Test_clusterctlClient_Delete(t *testing.T) {
 		options DeleteOptions
 	}
 	tests := []struct {
-		name          string
-		fields        fields
-		args          args
-		wantProviders sets.String
-		wantErr       bool
+		name                   string
+		fields                 fields
+		args                   args
+		wantProviders          sets.String
+		wantCertManagerDeleted bool
+		wantErr                bool
 	}{
 		{
 			name: "Delete all the providers",
@@ -104,12 +105,43 @@ func Test_clusterctlClient_Delete(t *testing.T) {
 			wantProviders: sets.NewString(capiProviderConfig.Name()),
 			wantErr:       false,
 		},
+		{
+			name: "Delete all the providers and cert-manager",
+			fields: fields{
+				client: fakeClusterForDelete(),
+			},
+			args: args{
+				options: DeleteOptions{
+					Kubeconfig:         Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					DeleteAll:          true,
+					IncludeCertManager: true,
+				},
+			},
+			wantProviders:          sets.NewString(),
+			wantCertManagerDeleted: true,
+			wantErr:                false,
+		},
+		{
+			name: "Fails to delete cert-manager if not deleting all the providers",
+			fields: fields{
+				client: fakeClusterForDelete(),
+			},
+			args: args{
+				options: DeleteOptions{
+					Kubeconfig:         Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					Namespace:          "capbpk-system",
+					BootstrapProviders: []string{bootstrapProviderConfig.Name()},
+					IncludeCertManager: true,
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
-			err := tt.fields.client.Delete(tt.args.options)
+			deletedProviders, err := tt.fields.client.Delete(tt.args.options)
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 				return
@@ -130,6 +162,15 @@ func Test_clusterctlClient_Delete(t *testing.T) {
 			}
 
 			g.Expect(gotProvidersSet).To(Equal(tt.wantProviders))
+
+			// Deleted providers are returned to the caller.
+			g.Expect(deletedProviders).To(HaveLen(2 - tt.wantProviders.Len()))
+			for _, deletedProvider := range deletedProviders {
+				g.Expect(gotProvidersSet.Has(deletedProvider.Name)).To(BeFalse())
+			}
+
+			certManager := tt.fields.client.clusters[input].CertManager().(*fakeCertManagerClient)
+			g.Expect(certManager.deleted).To(Equal(tt.wantCertManagerDeleted))
 		})
 	}
 }
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// DescribeOptions carries the options supported by Describe.
+type DescribeOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload cluster is located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// ClusterName to be used for the workload cluster.
+	ClusterName string
+
+	// ShowOtherConditions adds to each object all the conditions, not only the Ready condition.
+	ShowOtherConditions bool
+
+	// DisableGrouping disables grouping Machines with the same Ready condition status, severity and reason.
+	DisableGrouping bool
+}
+
+func (c *clusterctlClient) Describe(options DescribeOptions) (*ObjectTree, error) {
+	if options.ClusterName == "" {
+		return nil, errors.New("the name of the cluster to describe must be specified")
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	tree, err := clusterClient.ClusterDescriber().Describe(options.Namespace, options.ClusterName, cluster.DescribeOptions{
+		ShowOtherConditions: options.ShowOtherConditions,
+		DisableGrouping:     options.DisableGrouping,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*ObjectTree)(tree), nil
+}
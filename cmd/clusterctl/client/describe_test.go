@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_clusterctlClient_Describe(t *testing.T) {
+	type fields struct {
+		client *fakeClient
+	}
+	type args struct {
+		options DescribeOptions
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "returns an error if the cluster name is not specified",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0, infra v2.0.0
+			},
+			args: args{
+				options: DescribeOptions{
+					Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if the cluster client is not found",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0, infra v2.0.0
+			},
+			args: args{
+				options: DescribeOptions{
+					Kubeconfig:  Kubeconfig{Path: "kubeconfig", Context: "does-not-exist"},
+					ClusterName: "cluster1",
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := tt.fields.client.Describe(tt.args.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
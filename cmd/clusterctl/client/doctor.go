@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+// DoctorOptions carries the options supported by Doctor.
+type DoctorOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+}
+
+func (c *clusterctlClient) Doctor(options DoctorOptions) ([]CheckResult, error) {
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Nb. the doctor does not ensure the clusterctl CRDs are in place, because checks should never change the management cluster.
+	results, err := clusterClient.Doctor().Check()
+	if err != nil {
+		return nil, err
+	}
+
+	// CheckResult is an alias for cluster.CheckResult; this makes the conversion
+	aliasResults := make([]CheckResult, len(results))
+	for i, r := range results {
+		aliasResults[i] = CheckResult(r)
+	}
+	return aliasResults, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+func Test_clusterctlClient_Doctor(t *testing.T) {
+	tests := []struct {
+		name       string
+		kubeconfig Kubeconfig
+		wantChecks []string
+		wantErr    bool
+	}{
+		{
+			name:       "returns an error if the cluster client is not found",
+			kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "does-not-exist"},
+			wantErr:    true,
+		},
+		{
+			name:       "runs all the checks",
+			kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+			wantChecks: []string{"Inventory", "Controllers", "Webhooks", "CRDs", "Orphaned objects", "Paused Clusters", "Watching namespaces"},
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
+			config1 := newFakeConfig().
+				WithProvider(core)
+			cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+				WithProviderInventory(core.Name(), core.Type(), "v1.0.0", "cluster-api-system", "")
+			client := newFakeClient(config1).
+				WithCluster(cluster1)
+
+			got, err := client.Doctor(DoctorOptions{Kubeconfig: tt.kubeconfig})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			var gotChecks []string
+			for _, r := range got {
+				if len(gotChecks) == 0 || gotChecks[len(gotChecks)-1] != r.Check {
+					gotChecks = append(gotChecks, r.Check)
+				}
+			}
+			g.Expect(gotChecks).To(Equal(tt.wantChecks))
+		})
+	}
+}
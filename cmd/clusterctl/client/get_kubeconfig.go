@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// GetKubeconfigOptions carries all the options supported by GetKubeconfig.
+type GetKubeconfigOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload cluster is located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// WorkloadClusterName is the name of the workload cluster.
+	WorkloadClusterName string
+
+	// CertificateDuration, if set, is the lifespan of a new client certificate signed by the cluster CA;
+	// by default, the admin kubeconfig stored in the management cluster is returned.
+	CertificateDuration time.Duration
+
+	// ContextName, if set, is used for renaming the context, the cluster and the user in the kubeconfig.
+	ContextName string
+
+	// File, if set, is the path of the file where the kubeconfig is written.
+	File string
+
+	// Merge merges the kubeconfig into File, replacing any context, cluster or user with the same name,
+	// instead of overwriting it. If File is empty, the kubeconfig is merged into the default kubeconfig file.
+	Merge bool
+}
+
+func (c *clusterctlClient) GetKubeconfig(options GetKubeconfigOptions) ([]byte, error) {
+	if options.WorkloadClusterName == "" {
+		return nil, errors.New("the name of the workload cluster must be specified")
+	}
+	if options.CertificateDuration < 0 {
+		return nil, errors.New("the certificate duration must be a positive duration")
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	var data []byte
+	if options.CertificateDuration > 0 {
+		data, err = clusterClient.WorkloadCluster().GenerateKubeconfig(options.Namespace, options.WorkloadClusterName, options.CertificateDuration)
+	} else {
+		data, err = clusterClient.WorkloadCluster().GetKubeconfig(options.Namespace, options.WorkloadClusterName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the kubeconfig for Cluster %s/%s", options.Namespace, options.WorkloadClusterName)
+	}
+
+	if options.ContextName != "" {
+		config, err = renameKubeconfig(config, options.ContextName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Merge {
+		file := options.File
+		if file == "" {
+			file = clientcmd.NewDefaultPathOptions().GetDefaultFilename()
+		}
+		if err := mergeKubeconfig(config, file); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err = clientcmd.Write(*config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize the kubeconfig")
+	}
+
+	if options.File != "" && !options.Merge {
+		if err := os.MkdirAll(filepath.Dir(options.File), 0755); err != nil {
+			return nil, errors.Wrapf(err, "failed to create the folder for %q", options.File)
+		}
+		if err := ioutil.WriteFile(options.File, data, 0600); err != nil {
+			return nil, errors.Wrapf(err, "failed to write %q", options.File)
+		}
+	}
+	return data, nil
+}
+
+// renameKubeconfig renames the context of a kubeconfig with a single context, and the cluster and the user
+// referenced by the context.
+func renameKubeconfig(config *api.Config, name string) (*api.Config, error) {
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok || len(config.Contexts) != 1 {
+		return nil, errors.New("failed to rename the kubeconfig: expected a kubeconfig with a single context")
+	}
+
+	cluster, ok := config.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, errors.Errorf("failed to rename the kubeconfig: cluster %q not found", kubeContext.Cluster)
+	}
+	authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return nil, errors.Errorf("failed to rename the kubeconfig: user %q not found", kubeContext.AuthInfo)
+	}
+
+	renamed := api.NewConfig()
+	renamed.Clusters[name] = cluster
+	renamed.AuthInfos[name] = authInfo
+	renamed.Contexts[name] = &api.Context{
+		Cluster:   name,
+		AuthInfo:  name,
+		Namespace: kubeContext.Namespace,
+	}
+	renamed.CurrentContext = name
+	return renamed, nil
+}
+
+// mergeKubeconfig merges a kubeconfig into a kubeconfig file, replacing any context, cluster or user with the same name.
+// The current context of the kubeconfig file is preserved, if any.
+func mergeKubeconfig(config *api.Config, file string) error {
+	existing := api.NewConfig()
+	if _, err := os.Stat(file); err == nil {
+		existing, err = clientcmd.LoadFromFile(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q", file)
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to read %q", file)
+	}
+
+	for name, cluster := range config.Clusters {
+		existing.Clusters[name] = cluster
+	}
+	for name, authInfo := range config.AuthInfos {
+		existing.AuthInfos[name] = authInfo
+	}
+	for name, kubeContext := range config.Contexts {
+		existing.Contexts[name] = kubeContext
+	}
+	if existing.CurrentContext == "" {
+		existing.CurrentContext = config.CurrentContext
+	}
+
+	if err := clientcmd.WriteToFile(*existing, file); err != nil {
+		return errors.Wrapf(err, "failed to write %q", file)
+	}
+	return nil
+}
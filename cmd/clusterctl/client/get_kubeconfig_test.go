@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func fakeClientForGetKubeconfig(t *testing.T) *fakeClient {
+	g := NewWithT(t)
+
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(0),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, caKey.Public(), caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	caCert, err := x509.ParseCertificate(der)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	workloadCluster := &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "cluster1",
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443},
+		},
+	}
+
+	adminConfig, err := kubeconfig.New("cluster1", "https://10.0.0.1:6443", caCert, caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	adminKubeconfig, err := clientcmd.Write(*adminConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      secret.Name("cluster1", secret.ClusterCA),
+		},
+		Data: map[string][]byte{
+			secret.TLSKeyDataName: certs.EncodePrivateKeyPEM(caKey),
+			secret.TLSCrtDataName: certs.EncodeCertPEM(caCert),
+		},
+	}
+
+	core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
+
+	config1 := newFakeConfig().
+		WithProvider(core)
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+		WithProviderInventory(core.Name(), core.Type(), "v1.0.0", "cluster-api-system", "").
+		WithObjs(workloadCluster, caSecret, kubeconfig.GenerateSecret(workloadCluster, adminKubeconfig))
+
+	return newFakeClient(config1).
+		WithCluster(cluster1)
+}
+
+func Test_clusterctlClient_GetKubeconfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        GetKubeconfigOptions
+		wantContext    string
+		wantCertExpiry time.Duration
+		wantErr        bool
+	}{
+		{
+			name: "returns an error if the workload cluster name is not specified",
+			options: GetKubeconfigOptions{
+				Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns the admin kubeconfig",
+			options: GetKubeconfigOptions{
+				Kubeconfig:          Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Namespace:           "ns1",
+				WorkloadClusterName: "cluster1",
+			},
+			wantContext:    "cluster1-admin@cluster1",
+			wantCertExpiry: certs.DefaultCertDuration,
+			wantErr:        false,
+		},
+		{
+			name: "returns the admin kubeconfig with a custom context name",
+			options: GetKubeconfigOptions{
+				Kubeconfig:          Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Namespace:           "ns1",
+				WorkloadClusterName: "cluster1",
+				ContextName:         "my-cluster",
+			},
+			wantContext:    "my-cluster",
+			wantCertExpiry: certs.DefaultCertDuration,
+			wantErr:        false,
+		},
+		{
+			name: "returns a kubeconfig with a short-lived certificate",
+			options: GetKubeconfigOptions{
+				Kubeconfig:          Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Namespace:           "ns1",
+				WorkloadClusterName: "cluster1",
+				CertificateDuration: time.Hour,
+			},
+			wantContext:    "cluster1-admin@cluster1",
+			wantCertExpiry: time.Hour,
+			wantErr:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			data, err := fakeClientForGetKubeconfig(t).GetKubeconfig(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			config, err := clientcmd.Load(data)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(config.CurrentContext).To(Equal(tt.wantContext))
+			g.Expect(config.Contexts).To(HaveLen(1))
+
+			kubeContext := config.Contexts[tt.wantContext]
+			g.Expect(kubeContext).ToNot(BeNil())
+			g.Expect(config.Clusters[kubeContext.Cluster].Server).To(Equal("https://10.0.0.1:6443"))
+
+			cert, err := certs.DecodeCertPEM(config.AuthInfos[kubeContext.AuthInfo].ClientCertificateData)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(tt.wantCertExpiry), time.Minute))
+		})
+	}
+}
+
+func Test_clusterctlClient_GetKubeconfig_WriteAndMerge(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	client := fakeClientForGetKubeconfig(t)
+	options := GetKubeconfigOptions{
+		Kubeconfig:          Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+		Namespace:           "ns1",
+		WorkloadClusterName: "cluster1",
+	}
+
+	// Write the kubeconfig to a new file.
+	options.File = filepath.Join(tmpDir, "kubeconfig")
+	_, err = client.GetKubeconfig(options)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	written, err := clientcmd.LoadFromFile(options.File)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(written.Contexts).To(HaveKey("cluster1-admin@cluster1"))
+
+	// Merge the kubeconfig into an existing file, preserving the existing contexts and the current context.
+	existing := api.NewConfig()
+	existing.Clusters["mgmt"] = &api.Cluster{Server: "https://mgmt:6443"}
+	existing.AuthInfos["mgmt"] = &api.AuthInfo{Token: "token"}
+	existing.AuthInfos["cluster1"] = &api.AuthInfo{Token: "stale"}
+	existing.Contexts["mgmt"] = &api.Context{Cluster: "mgmt", AuthInfo: "mgmt"}
+	existing.CurrentContext = "mgmt"
+	options.File = filepath.Join(tmpDir, "existing")
+	g.Expect(clientcmd.WriteToFile(*existing, options.File)).To(Succeed())
+
+	options.Merge = true
+	options.ContextName = "cluster1"
+	_, err = client.GetKubeconfig(options)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	merged, err := clientcmd.LoadFromFile(options.File)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(merged.CurrentContext).To(Equal("mgmt"))
+	g.Expect(merged.Contexts).To(HaveKey("mgmt"))
+	g.Expect(merged.Contexts).To(HaveKey("cluster1"))
+	g.Expect(merged.Clusters["cluster1"].Server).To(Equal("https://10.0.0.1:6443"))
+	g.Expect(merged.AuthInfos["cluster1"].Token).To(BeEmpty())
+	g.Expect(merged.AuthInfos["cluster1"].ClientCertificateData).ToNot(BeEmpty())
+}
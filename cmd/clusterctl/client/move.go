@@ -16,6 +16,12 @@ limitations under the License.
 
 package client
 
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
 // MoveOptions carries the options supported by move.
 type MoveOptions struct {
 	// FromKubeconfig defines the kubeconfig to use for accessing the source management cluster. If empty,
@@ -29,28 +35,96 @@ type MoveOptions struct {
 	// Namespace where the objects describing the workload cluster exists. If unspecified, the current
 	// namespace will be used.
 	Namespace string
+
+	// DryRun means the move action is only logged, without pausing, creating or deleting any object in the source
+	// and in the target management clusters.
+	// In dry run mode ToKubeconfig is optional; if it is not set, the checks on the target cluster are skipped.
+	DryRun bool
+
+	// Rollback reverts an interrupted move, deleting the objects already created in the target management cluster and
+	// resuming the reconciliation of the Cluster objects in the source management cluster.
+	Rollback bool
 }
 
-func (c *clusterctlClient) Move(options MoveOptions) error {
+func (c *clusterctlClient) Move(options MoveOptions) ([][]corev1.ObjectReference, error) {
+	if options.DryRun && options.Rollback {
+		return nil, errors.New("dry run and rollback cannot be used together")
+	}
+
 	// Get the client for interacting with the source management cluster.
 	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.FromKubeconfig})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Ensures the custom resource definitions required by clusterctl are in place.
-	if err := fromCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
-		return err
+	// Nb. In dry run mode nothing is written to the management clusters.
+	if !options.DryRun {
+		if err := fromCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+			return nil, err
+		}
 	}
 
 	// Get the client for interacting with the target management cluster.
-	toCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.ToKubeconfig})
+	// Nb. In dry run mode the target management cluster is optional.
+	var toCluster cluster.Client
+	if !options.DryRun || options.ToKubeconfig != (Kubeconfig{}) {
+		toCluster, err = c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.ToKubeconfig})
+		if err != nil {
+			return nil, err
+		}
+
+		// Ensures the custom resource definitions required by clusterctl are in place
+		if !options.DryRun {
+			if err := toCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := fromCluster.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	if options.Rollback {
+		return nil, fromCluster.ObjectMover().Rollback(options.Namespace, toCluster)
+	}
+
+	return fromCluster.ObjectMover().Move(options.Namespace, toCluster, options.DryRun)
+}
+
+// BackupOptions holds options supported by backup.
+type BackupOptions struct {
+	// FromKubeconfig defines the kubeconfig to use for accessing the source management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	FromKubeconfig Kubeconfig
+
+	// Namespace where the objects describing the workload cluster exists. If unspecified, the current
+	// namespace will be used.
+	Namespace string
+
+	// Directory defines the local directory to store the cluster objects
+	Directory string
+}
+
+func (c *clusterctlClient) Backup(options BackupOptions) error {
+	if options.Directory == "" {
+		return errors.New("the directory to store the cluster objects must be specified")
+	}
+
+	// Get the client for interacting with the source management cluster.
+	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.FromKubeconfig})
 	if err != nil {
 		return err
 	}
 
-	// Ensures the custom resource definitions required by clusterctl are in place
-	if err := toCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := fromCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
 		return err
 	}
 
@@ -63,7 +137,40 @@ func (c *clusterctlClient) Move(options MoveOptions) error {
 		options.Namespace = currentNamespace
 	}
 
-	if err := fromCluster.ObjectMover().Move(options.Namespace, toCluster); err != nil {
+	if err := fromCluster.ObjectMover().Backup(options.Namespace, options.Directory); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreOptions holds options supported by restore.
+type RestoreOptions struct {
+	// ToKubeconfig defines the kubeconfig to use for accessing the target management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	ToKubeconfig Kubeconfig
+
+	// Directory defines the local directory to restore the cluster objects from
+	Directory string
+}
+
+func (c *clusterctlClient) Restore(options RestoreOptions) error {
+	if options.Directory == "" {
+		return errors.New("the directory to restore the cluster objects from must be specified")
+	}
+
+	// Get the client for interacting with the target management cluster.
+	toCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.ToKubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := toCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return err
+	}
+
+	if err := toCluster.ObjectMover().Restore(toCluster, options.Directory); err != nil {
 		return err
 	}
 
@@ -0,0 +1,232 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// PivotOptions carries the options supported by Pivot.
+type PivotOptions struct {
+	// FromKubeconfig defines the kubeconfig to use for accessing the source management cluster, usually a temporary
+	// bootstrap cluster. If empty, default rules for kubeconfig discovery will be used.
+	FromKubeconfig Kubeconfig
+
+	// ToKubeconfig defines the kubeconfig to use for accessing the target cluster, that is the workload cluster
+	// that is going to manage itself.
+	ToKubeconfig Kubeconfig
+
+	// Namespace where the objects describing the workload cluster exists. If unspecified, the current
+	// namespace will be used.
+	Namespace string
+
+	// Timeout is the maximum time to wait for the moved Clusters to be reconciled in the target cluster.
+	// If zero, a default timeout is used.
+	Timeout time.Duration
+
+	// DeleteSource deletes all the providers, their namespaces and CRDs, and the cert-manager installed by clusterctl
+	// from the source management cluster once the moved Clusters are reconciled in the target cluster.
+	// Pivot refuses to delete the source management cluster if it hosts Clusters in other namespaces, or providers
+	// watching other namespaces.
+	DeleteSource bool
+}
+
+// Pivot makes the target cluster a management cluster hosting the providers and the Cluster API objects
+// currently in the source management cluster.
+func (c *clusterctlClient) Pivot(options PivotOptions) error {
+	log := logf.Log
+
+	if options.ToKubeconfig == (Kubeconfig{}) {
+		return errors.New("the target cluster must be specified")
+	}
+
+	// Get the client for interacting with the source management cluster.
+	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.FromKubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := fromCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return err
+	}
+
+	// Get the client for interacting with the target cluster.
+	toCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.ToKubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := toCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := fromCluster.Proxy().CurrentNamespace()
+		if err != nil {
+			return err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	// Before doing any change, ensures the source management cluster can be deleted once the pivot is completed.
+	if options.DeleteSource {
+		if err := checkSourceCanBeDeleted(fromCluster, options.Namespace); err != nil {
+			return err
+		}
+	}
+
+	// Installs in the target cluster the same providers and versions installed in the source management cluster.
+	if err := c.installSourceProviders(fromCluster, toCluster, options.Namespace); err != nil {
+		return err
+	}
+
+	// Moves the Cluster API objects to the target cluster.
+	// Nb. before moving objects, move checks all the providers in the source management cluster are installed in
+	// the target cluster, with the same or a newer version.
+	if _, err := fromCluster.ObjectMover().Move(options.Namespace, toCluster, false); err != nil {
+		return err
+	}
+
+	// Verifies the moved Clusters are reconciled by the providers running in the target cluster.
+	if err := toCluster.WorkloadCluster().WaitForClustersReady(options.Namespace, options.Timeout); err != nil {
+		return err
+	}
+
+	if !options.DeleteSource {
+		return nil
+	}
+
+	log.Info("Deleting the providers from the source management cluster")
+	_, err = c.Delete(DeleteOptions{
+		Kubeconfig:         options.FromKubeconfig,
+		DeleteAll:          true,
+		IncludeNamespace:   true,
+		IncludeCRDs:        true,
+		IncludeCertManager: true,
+	})
+	return err
+}
+
+// checkSourceCanBeDeleted returns an error if the source management cluster hosts Clusters in namespaces other than
+// the pivoted one, or providers watching other namespaces; in both cases deleting the providers and their CRDs would
+// break the Clusters left in the source management cluster.
+func checkSourceCanBeDeleted(fromCluster cluster.Client, namespace string) error {
+	providers, err := fromCluster.ProviderInventory().List()
+	if err != nil {
+		return errors.Wrap(err, "failed to get provider list from the source management cluster")
+	}
+	for _, provider := range providers.Items {
+		if provider.WatchedNamespace != "" && provider.WatchedNamespace != namespace {
+			return errors.Errorf("the source management cluster can't be deleted because the provider %q is watching the namespace %q; pivot without deleting the source management cluster", provider.InstanceName(), provider.WatchedNamespace)
+		}
+	}
+
+	clusters, err := fromCluster.WorkloadCluster().ListClusters("")
+	if err != nil {
+		return errors.Wrap(err, "failed to get the Clusters in the source management cluster")
+	}
+	var others []string
+	for _, c := range clusters {
+		if !strings.HasPrefix(c, namespace+"/") {
+			others = append(others, c)
+		}
+	}
+	if len(others) > 0 {
+		return errors.Errorf("the source management cluster can't be deleted because it hosts Clusters in other namespaces (%s); pivot without deleting the source management cluster", strings.Join(others, ", "))
+	}
+	return nil
+}
+
+// installSourceProviders installs in the target cluster the providers installed in the source management cluster
+// watching the given namespace, using the same version, target namespace and watching namespace;
+// providers already installed in the target cluster are skipped.
+func (c *clusterctlClient) installSourceProviders(fromCluster, toCluster cluster.Client, namespace string) error {
+	log := logf.Log
+
+	fromProviders, err := fromCluster.ProviderInventory().List()
+	if err != nil {
+		return errors.Wrap(err, "failed to get provider list from the source management cluster")
+	}
+
+	toProviders, err := toCluster.ProviderInventory().List()
+	if err != nil {
+		return errors.Wrap(err, "failed to get provider list from the target cluster")
+	}
+
+	installer := toCluster.ProviderInstaller()
+	installRequired := false
+	for _, provider := range fromProviders.Items {
+		// Skips the providers not watching the namespace to be moved.
+		if provider.WatchedNamespace != "" && provider.WatchedNamespace != namespace {
+			continue
+		}
+
+		if isProviderInstalled(toProviders, provider) {
+			log.V(1).Info("Provider already installed in the target cluster", "Provider", provider.InstanceName())
+			continue
+		}
+
+		addOptions := addToInstallerOptions{
+			installer:         installer,
+			targetNamespace:   provider.Namespace,
+			watchingNamespace: provider.WatchedNamespace,
+		}
+		if err := c.addToInstaller(addOptions, provider.GetProviderType(), fmt.Sprintf("%s:%s", provider.ProviderName, provider.Version)); err != nil {
+			return err
+		}
+		installRequired = true
+	}
+
+	if !installRequired {
+		log.Info("All the providers are already installed in the target cluster")
+		return nil
+	}
+
+	// Before installing the providers, validates the target cluster resulting by the planned installation.
+	if err := installer.Validate(); err != nil {
+		return err
+	}
+
+	// Before installing the providers, ensure the cert-manager Webhook is in place.
+	if err := toCluster.CertManager().EnsureWebhook(); err != nil {
+		return err
+	}
+
+	_, err = installer.Install()
+	return err
+}
+
+// isProviderInstalled returns true if the list of providers includes an instance of the given provider
+// with the same watching namespace.
+func isProviderInstalled(providers *clusterctlv1.ProviderList, provider clusterctlv1.Provider) bool {
+	for _, p := range providers.Items {
+		if p.SameAs(provider) && p.WatchedNamespace == provider.WatchedNamespace {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+func Test_clusterctlClient_Pivot(t *testing.T) {
+	tests := []struct {
+		name                string
+		options             PivotOptions
+		moveErr             error
+		watchingNamespace   string
+		sourceObjs          []runtime.Object
+		wantProviders       []string
+		wantSourceProviders []string
+		wantErr             bool
+	}{
+		{
+			name: "returns an error if the target cluster is not specified",
+			options: PivotOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "installs the source providers in the target cluster and moves the objects",
+			options: PivotOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+				Namespace:      "default",
+			},
+			wantProviders: []string{"ns1/cluster-api:v1.0.0", "ns4/infrastructure-infra:v3.0.0"},
+			wantErr:       false,
+		},
+		{
+			name: "returns an error if move fails",
+			options: PivotOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+				Namespace:      "default",
+			},
+			moveErr:       errors.New("failed to move"),
+			wantProviders: []string{"ns1/cluster-api:v1.0.0", "ns4/infrastructure-infra:v3.0.0"},
+			wantErr:       true,
+		},
+		{
+			name: "deletes the source management cluster",
+			options: PivotOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+				Namespace:      "default",
+				DeleteSource:   true,
+			},
+			wantProviders:       []string{"ns1/cluster-api:v1.0.0", "ns4/infrastructure-infra:v3.0.0"},
+			wantSourceProviders: nil,
+			wantErr:             false,
+		},
+		{
+			name: "refuses to delete the source management cluster hosting Clusters in other namespaces",
+			options: PivotOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+				Namespace:      "default",
+				DeleteSource:   true,
+			},
+			sourceObjs: []runtime.Object{
+				&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster1"}},
+				&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "cluster2"}},
+			},
+			wantProviders:       nil,
+			wantSourceProviders: []string{"ns1/cluster-api:v1.0.0", "ns4/infrastructure-infra:v3.0.0"},
+			wantErr:             true,
+		},
+		{
+			name: "refuses to delete the source management cluster with providers watching other namespaces",
+			options: PivotOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+				Namespace:      "default",
+				DeleteSource:   true,
+			},
+			watchingNamespace:   "other",
+			wantProviders:       nil,
+			wantSourceProviders: []string{"ns1/cluster-api:v1.0.0", "ns4/infrastructure-infra:v3.0.0"},
+			wantErr:             true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			client, fromCluster, toCluster := fakeClientForPivot(tt.moveErr, tt.watchingNamespace, tt.sourceObjs...)
+
+			err := client.Pivot(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+
+			toProviders, err := toCluster.ProviderInventory().List()
+			g.Expect(err).NotTo(HaveOccurred())
+			var got []string
+			for _, p := range toProviders.Items {
+				got = append(got, p.InstanceName()+":"+p.Version)
+			}
+			g.Expect(got).To(ConsistOf(tt.wantProviders))
+
+			if !tt.options.DeleteSource {
+				return
+			}
+			fromProviders, err := fromCluster.ProviderInventory().List()
+			g.Expect(err).NotTo(HaveOccurred())
+			got = nil
+			for _, p := range fromProviders.Items {
+				got = append(got, p.InstanceName()+":"+p.Version)
+			}
+			g.Expect(got).To(ConsistOf(tt.wantSourceProviders))
+		})
+	}
+}
+
+// fakeClientForPivot returns a clusterctl client with a source management cluster with the core and the infra
+// providers installed, the infra provider watching the given namespace, and an empty target cluster.
+func fakeClientForPivot(moveErr error, watchingNamespace string, sourceObjs ...runtime.Object) (*fakeClient, *fakeClusterClient, *fakeClusterClient) {
+	config1 := fakeConfig(
+		[]config.Provider{capiProviderConfig, infraProviderConfig},
+		map[string]string{"SOME_VARIABLE": "value"},
+	)
+	repositories := fakeRepositories(config1, nil)
+
+	fromCluster := fakeCluster(config1, repositories, newFakeCertManagerClient(nil, nil)).
+		WithProviderInventory(capiProviderConfig.Name(), capiProviderConfig.Type(), "v1.0.0", "ns1", "").
+		WithProviderInventory(infraProviderConfig.Name(), infraProviderConfig.Type(), "v3.0.0", "ns4", watchingNamespace).
+		WithObjs(sourceObjs...).
+		WithObjectMover(&fakeObjectMover{moveErr: moveErr})
+
+	toCluster := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "worker-context"}, config1).
+		WithCertManagerClient(newFakeCertManagerClient(nil, nil))
+	for _, r := range repositories {
+		toCluster.WithRepository(r)
+	}
+
+	client := fakeClusterCtlClient(config1, repositories, []*fakeClusterClient{fromCluster, toCluster})
+	return client, fromCluster, toCluster
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	cacheFolder    = "cache"
+	cacheFolderKey = "cacheFolder"
+	cacheTTLKey    = "cacheTTL"
+
+	defaultCacheTTL = 1 * time.Hour
+
+	cacheRepositoryFile = "repository.yaml"
+	cacheVersionsFile   = "versions.yaml"
+	cacheFilesFolder    = "files"
+)
+
+// cachedRepositoryInfo defines the information about a repository that are stored in the cache, so it is possible
+// to use the repository in offline mode.
+type cachedRepositoryInfo struct {
+	DefaultVersion string `json:"defaultVersion"`
+	RootPath       string `json:"rootPath"`
+	ComponentsPath string `json:"componentsPath"`
+}
+
+// cachedRepository is a Repository that stores the results of the repository it wraps in a local folder,
+// by default $HOME/.cluster-api/cache.
+//
+// The list of versions is cached for a limited amount of time (the cache TTL), while files for released versions,
+// i.e. versions that are valid semantic versions, are cached forever given that released artifacts should never change.
+// The wrapped repository is created only when a result is not available in the cache, so no calls to the remote
+// repository happen while the cache is fresh.
+// When running in offline mode, the wrapped repository is never used and all the results are served from the cache.
+type cachedRepository struct {
+	newRepository func() (Repository, error)
+	repository    Repository
+	basepath      string
+	ttl           time.Duration
+	offline       bool
+	info          cachedRepositoryInfo
+	now           func() time.Time
+}
+
+var _ Repository = &cachedRepository{}
+
+// DefaultVersion returns the default version of the cached repository.
+func (c *cachedRepository) DefaultVersion() string {
+	return c.info.DefaultVersion
+}
+
+// RootPath returns the root path of the cached repository.
+func (c *cachedRepository) RootPath() string {
+	return c.info.RootPath
+}
+
+// ComponentsPath returns the components path of the cached repository.
+func (c *cachedRepository) ComponentsPath() string {
+	return c.info.ComponentsPath
+}
+
+// GetVersions returns the list of versions that are available in the cached repository.
+func (c *cachedRepository) GetVersions() ([]string, error) {
+	versionsPath := filepath.Join(c.basepath, cacheVersionsFile)
+
+	content, fresh, err := c.read(versionsPath)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil || (!fresh && !c.offline) {
+		if c.offline {
+			return nil, errors.New("the list of versions is not available in the local cache")
+		}
+
+		repository, err := c.getRepository()
+		if err != nil {
+			return nil, err
+		}
+		versions, err := repository.GetVersions()
+		if err != nil {
+			return nil, err
+		}
+
+		content, err = yaml.Marshal(versions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal the list of versions")
+		}
+		if err := c.write(versionsPath, content); err != nil {
+			return nil, err
+		}
+		return versions, nil
+	}
+
+	versions := []string{}
+	if err := yaml.Unmarshal(content, &versions); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the list of versions cached in %q", versionsPath)
+	}
+	return versions, nil
+}
+
+// GetFile returns a file for a given provider version from the cached repository.
+func (c *cachedRepository) GetFile(version, path string) ([]byte, error) {
+	filePath := filepath.Join(c.basepath, cacheFilesFolder, version, path)
+
+	content, fresh, err := c.read(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if content != nil && (fresh || c.offline || isReleasedVersion(version)) {
+		return content, nil
+	}
+
+	if c.offline {
+		return nil, errors.Errorf("file %q for version %q is not available in the local cache", path, version)
+	}
+
+	repository, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+	content, err = repository.GetFile(version, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.write(filePath, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// getRepository returns the wrapped repository, creating it on first use.
+func (c *cachedRepository) getRepository() (Repository, error) {
+	if c.repository == nil {
+		repository, err := c.newRepository()
+		if err != nil {
+			return nil, err
+		}
+		c.repository = repository
+	}
+	return c.repository, nil
+}
+
+// read returns the content of a file in the cache, if any, and whether it is still fresh according to the cache TTL.
+func (c *cachedRepository) read(path string) ([]byte, bool, error) {
+	f, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to read %q from the local cache", path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to read %q from the local cache", path)
+	}
+
+	return content, c.now().Before(f.ModTime().Add(c.ttl)), nil
+}
+
+// write stores a file in the cache.
+func (c *cachedRepository) write(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create the local cache folder %q", filepath.Dir(path))
+	}
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %q to the local cache", path)
+	}
+	return nil
+}
+
+// newCachedRepository returns a cachedRepository wrapping the repository returned by the newRepository func.
+// The repository information are read from the cache while fresh, or always in offline mode; otherwise
+// newRepository is called and the repository information in the cache are refreshed.
+func newCachedRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, newRepository func() (Repository, error)) (*cachedRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	c := &cachedRepository{
+		newRepository: newRepository,
+		basepath:      filepath.Join(cachePath(configVariablesClient), providerConfig.ManifestLabel(), urlHash(providerConfig.URL())),
+		ttl:           defaultCacheTTL,
+		offline:       isOffline(configVariablesClient),
+		now:           time.Now,
+	}
+
+	if ttl, err := configVariablesClient.Get(cacheTTLKey); err == nil && strings.TrimSpace(ttl) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(ttl))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s value %q", cacheTTLKey, ttl)
+		}
+		c.ttl = d
+	}
+
+	infoPath := filepath.Join(c.basepath, cacheRepositoryFile)
+
+	content, fresh, err := c.read(infoPath)
+	if err != nil {
+		return nil, err
+	}
+	if content != nil && (fresh || c.offline) {
+		if err := yaml.Unmarshal(content, &c.info); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the repository information cached in %q", infoPath)
+		}
+		return c, nil
+	}
+
+	if c.offline {
+		return nil, errors.Errorf("the repository %q is not available in the local cache; it is required to run clusterctl at least once without offline mode in order to populate the cache", providerConfig.URL())
+	}
+
+	repository, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+	c.info = cachedRepositoryInfo{
+		DefaultVersion: repository.DefaultVersion(),
+		RootPath:       repository.RootPath(),
+		ComponentsPath: repository.ComponentsPath(),
+	}
+
+	content, err = yaml.Marshal(c.info)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the repository information")
+	}
+	if err := c.write(infoPath, content); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// cachedRepositoryFactory returns the repository implementation corresponding to the provider URL; remote repositories
+// are wrapped by a cachedRepository, while local filesystem repositories are used as is.
+func cachedRepositoryFactory(providerConfig config.Provider, configVariablesClient config.VariablesClient) (Repository, error) {
+	newRepository := func() (Repository, error) {
+		return repositoryFactory(providerConfig, configVariablesClient)
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Errorf("failed to parse repository url %q", providerConfig.URL())
+	}
+	if rURL.Scheme == "file" || rURL.Scheme == "" {
+		return newRepository()
+	}
+
+	return newCachedRepository(providerConfig, configVariablesClient, newRepository)
+}
+
+// cachePath returns the path of the local cache folder.
+func cachePath(configVariablesClient config.VariablesClient) string {
+	basepath := filepath.Join(homedir.HomeDir(), config.ConfigFolder, cacheFolder)
+	f, err := configVariablesClient.Get(cacheFolderKey)
+	if err == nil && len(strings.TrimSpace(f)) != 0 {
+		basepath = f
+	}
+	return basepath
+}
+
+// isOffline returns true if clusterctl should serve repository artifacts only from the local cache.
+func isOffline(configVariablesClient config.VariablesClient) bool {
+	v, err := configVariablesClient.Get(config.OfflineVariable)
+	if err != nil {
+		return false
+	}
+	offline, err := strconv.ParseBool(strings.TrimSpace(v))
+	return err == nil && offline
+}
+
+// isReleasedVersion returns true if the version is a valid semantic version; artifacts for released
+// versions are never expected to change.
+func isReleasedVersion(v string) bool {
+	_, err := version.ParseSemantic(v)
+	return err == nil
+}
+
+// urlHash returns a short hash of the provider URL, used as a key for the cache folder.
+func urlHash(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return hex.EncodeToString(sum[:])[:16]
+}
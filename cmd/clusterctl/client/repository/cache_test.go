@@ -0,0 +1,194 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// countingRepository is a Repository that counts the calls to the wrapped repository.
+type countingRepository struct {
+	Repository
+	created     int
+	getVersions int
+	getFile     int
+}
+
+func (c *countingRepository) GetVersions() ([]string, error) {
+	c.getVersions++
+	return c.Repository.GetVersions()
+}
+
+func (c *countingRepository) GetFile(version, path string) ([]byte, error) {
+	c.getFile++
+	return c.Repository.GetFile(version, path)
+}
+
+func newTestCachedRepository(t *testing.T, cacheDir string, offline bool, repository *countingRepository) (*cachedRepository, error) {
+	providerConfig := config.NewProvider("foo", "https://example.com/infrastructure-foo/v1.0.0/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+	configVariablesClient := test.NewFakeVariableClient().WithVar(cacheFolderKey, cacheDir)
+	if offline {
+		configVariablesClient.WithVar(config.OfflineVariable, "true")
+	}
+
+	return newCachedRepository(providerConfig, configVariablesClient, func() (Repository, error) {
+		if offline {
+			t.Fatal("the repository should not be created in offline mode")
+		}
+		repository.created++
+		return repository, nil
+	})
+}
+
+func Test_cachedRepository(t *testing.T) {
+	g := NewWithT(t)
+
+	cacheDir := createTempDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	repository := &countingRepository{
+		Repository: test.NewFakeRepository().
+			WithPaths("root", "infrastructure-components.yaml").
+			WithDefaultVersion("v1.0.0").
+			WithFile("v1.0.0", "infrastructure-components.yaml", []byte("v1.0.0 content")).
+			WithFile("main", "infrastructure-components.yaml", []byte("main content")),
+	}
+
+	c, err := newTestCachedRepository(t, cacheDir, false, repository)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.DefaultVersion()).To(Equal("v1.0.0"))
+	g.Expect(c.RootPath()).To(Equal("root"))
+	g.Expect(c.ComponentsPath()).To(Equal("infrastructure-components.yaml"))
+	g.Expect(repository.created).To(Equal(1))
+
+	// The first calls go to the repository.
+	versions, err := c.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v1.0.0", "main"))
+	g.Expect(repository.getVersions).To(Equal(1))
+
+	for _, v := range []string{"v1.0.0", "main"} {
+		_, err := c.GetFile(v, "infrastructure-components.yaml")
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(repository.getFile).To(Equal(2))
+
+	// Within the TTL, all the calls are served from the cache.
+	versions, err = c.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v1.0.0", "main"))
+	g.Expect(repository.getVersions).To(Equal(1))
+
+	content, err := c.GetFile("main", "infrastructure-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(Equal([]byte("main content")))
+	g.Expect(repository.getFile).To(Equal(2))
+
+	// Within the TTL, a new cached repository is created without creating the repository it wraps.
+	cached, err := newTestCachedRepository(t, cacheDir, false, repository)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cached.DefaultVersion()).To(Equal("v1.0.0"))
+	versions, err = cached.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v1.0.0", "main"))
+	g.Expect(repository.created).To(Equal(1))
+	g.Expect(repository.getVersions).To(Equal(1))
+
+	// The repository is created when a result is not available in the cache.
+	_, err = cached.GetFile("v1.0.0", "metadata.yaml")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(repository.created).To(Equal(2))
+	g.Expect(repository.getFile).To(Equal(3))
+
+	// After the TTL, versions and files for versions that are not released are read again from the repository,
+	// while files for released versions are still served from the cache.
+	c.now = func() time.Time { return time.Now().Add(2 * defaultCacheTTL) }
+
+	_, err = c.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repository.getVersions).To(Equal(2))
+
+	_, err = c.GetFile("main", "infrastructure-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repository.getFile).To(Equal(4))
+
+	content, err = c.GetFile("v1.0.0", "infrastructure-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(Equal([]byte("v1.0.0 content")))
+	g.Expect(repository.getFile).To(Equal(4))
+
+	// Errors from the repository are not cached.
+	_, err = c.GetFile("v1.0.0", "not-a-file.yaml")
+	g.Expect(err).To(HaveOccurred())
+
+	// In offline mode, everything is served from the cache, regardless of the TTL.
+	offline, err := newTestCachedRepository(t, cacheDir, true, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	offline.now = c.now
+	g.Expect(offline.DefaultVersion()).To(Equal("v1.0.0"))
+	g.Expect(offline.RootPath()).To(Equal("root"))
+	g.Expect(offline.ComponentsPath()).To(Equal("infrastructure-components.yaml"))
+
+	versions, err = offline.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v1.0.0", "main"))
+
+	content, err = offline.GetFile("main", "infrastructure-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(Equal([]byte("main content")))
+
+	_, err = offline.GetFile("v1.0.0", "not-a-file.yaml")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_cachedRepository_OfflineEmptyCache(t *testing.T) {
+	g := NewWithT(t)
+
+	cacheDir := createTempDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	_, err := newTestCachedRepository(t, cacheDir, true, nil)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_cachedRepositoryFactory(t *testing.T) {
+	g := NewWithT(t)
+
+	cacheDir := createTempDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	configVariablesClient := test.NewFakeVariableClient().WithVar(cacheFolderKey, cacheDir)
+
+	// Local filesystem repositories are not cached.
+	dst := createLocalTestProviderFile(t, cacheDir, "bootstrap-foo/v1.0.0/bootstrap-components.yaml", "")
+	repo, err := cachedRepositoryFactory(config.NewProvider("foo", dst, clusterctlv1.BootstrapProviderType), configVariablesClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repo).To(BeAssignableToTypeOf(&localRepository{}))
+
+	// Remote repositories are cached.
+	repo, err = cachedRepositoryFactory(config.NewProvider("foo", "https://example.com/v1.0.0/bootstrap-components.yaml", clusterctlv1.BootstrapProviderType), configVariablesClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repo).To(BeAssignableToTypeOf(&cachedRepository{}))
+}
@@ -18,6 +18,7 @@ package repository
 
 import (
 	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
@@ -35,6 +36,16 @@ type Client interface {
 	// GetVersion return the list of versions that are available in a provider repository
 	GetVersions() ([]string, error)
 
+	// DefaultVersion returns the default provider version returned by the provider repository.
+	DefaultVersion() string
+
+	// ComponentsPath returns the path of the YAML file for creating provider components.
+	ComponentsPath() string
+
+	// GetFile returns the raw content of a file for a given provider version, without any processing;
+	// the local override file is returned if it exists.
+	GetFile(version, path string) ([]byte, error)
+
 	// Components provide access to YAML file for creating provider components.
 	Components() ComponentsClient
 
@@ -61,6 +72,35 @@ func (c *repositoryClient) GetVersions() ([]string, error) {
 	return c.repository.GetVersions()
 }
 
+func (c *repositoryClient) DefaultVersion() string {
+	return c.repository.DefaultVersion()
+}
+
+func (c *repositoryClient) ComponentsPath() string {
+	return c.repository.ComponentsPath()
+}
+
+func (c *repositoryClient) GetFile(version, path string) ([]byte, error) {
+	file, err := getLocalOverride(&newOverrideInput{
+		configVariablesClient: c.configClient.Variables(),
+		provider:              c.Provider,
+		version:               version,
+		filePath:              path,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		return file, nil
+	}
+
+	file, err = c.repository.GetFile(version, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q from provider's repository %q", path, c.Provider.ManifestLabel())
+	}
+	return file, nil
+}
+
 func (c *repositoryClient) Components() ComponentsClient {
 	return newComponentsClient(c.Provider, c.repository, c.configClient)
 }
@@ -70,7 +110,7 @@ func (c *repositoryClient) Templates(version string) TemplateClient {
 }
 
 func (c *repositoryClient) Metadata(version string) MetadataClient {
-	return newMetadataClient(c.Provider, version, c.repository, c.configClient.Variables())
+	return newMetadataClient(c.Provider, version, c.repository, c.configClient)
 }
 
 // Option is a configuration option supplied to New
@@ -86,7 +126,8 @@ func InjectRepository(repository Repository) Option {
 }
 
 // InjectYamlProcessor allows you to override the yaml processor that the
-// repository client uses. By default, the SimpleProcessor is used. This is
+// repository client uses. By default, the processor defined in the provider
+// configuration is used, or the SimpleProcessor if not defined. This is
 // true even if a nil processor is injected.
 func InjectYamlProcessor(p yaml.Processor) Option {
 	return func(c *repositoryClient) {
@@ -105,15 +146,15 @@ func newRepositoryClient(provider config.Provider, configClient config.Client, o
 	client := &repositoryClient{
 		Provider:     provider,
 		configClient: configClient,
-		processor:    yaml.NewSimpleProcessor(),
+		processor:    newYamlProcessor(provider),
 	}
 	for _, o := range options {
 		o(client)
 	}
 
-	// if there is an injected repository, use it, otherwise use a default one
+	// if there is an injected repository, use it, otherwise use a default one backed by the local cache
 	if client.repository == nil {
-		r, err := repositoryFactory(provider, configClient.Variables())
+		r, err := cachedRepositoryFactory(provider, configClient.Variables())
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to get repository client for the %s with name %s", provider.Type(), provider.Name())
 		}
@@ -123,6 +164,14 @@ func newRepositoryClient(provider config.Provider, configClient config.Client, o
 	return client, nil
 }
 
+// newYamlProcessor returns the yaml processor for the provider's cluster templates, according to the provider configuration.
+func newYamlProcessor(provider config.Provider) yaml.Processor {
+	if provider.TemplateProcessor() == config.GoTemplateProcessor {
+		return yaml.NewGoTemplateProcessor()
+	}
+	return yaml.NewSimpleProcessor()
+}
+
 // Repository defines the behavior of a repository implementation.
 // clusterctl is designed to support different repository types; each repository implementation should be aware of
 // the provider version they are hosting, and possibly to host more than one version.
@@ -150,7 +199,7 @@ type Repository interface {
 
 var _ Repository = &test.FakeRepository{}
 
-//repositoryFactory returns the repository implementation corresponding to the provider URL.
+// repositoryFactory returns the repository implementation corresponding to the provider URL.
 func repositoryFactory(providerConfig config.Provider, configVariablesClient config.VariablesClient) (Repository, error) {
 	// parse the repository url
 	rURL, err := url.Parse(providerConfig.URL())
@@ -167,6 +216,33 @@ func repositoryFactory(providerConfig config.Provider, configVariablesClient con
 		return repo, err
 	}
 
+	// if the url is a GitLab repository
+	if rURL.Scheme == httpsScheme && strings.Contains(rURL.Path, gitlabReleasesSeparator) {
+		repo, err := newGitLabRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the GitLab repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is a generic HTTPS repository
+	if rURL.Scheme == httpsScheme {
+		repo, err := newHTTPRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the HTTPS repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is an OCI repository
+	if rURL.Scheme == ociScheme {
+		repo, err := newOCIRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the OCI repository client")
+		}
+		return repo, err
+	}
+
 	// if the url is a local filesystem repository
 	if rURL.Scheme == "file" || rURL.Scheme == "" {
 		repo, err := newLocalRepository(providerConfig, configVariablesClient)
@@ -73,6 +73,55 @@ func Test_newRepositoryClient_LocalFileSystemRepository(t *testing.T) {
 	}
 }
 
+func Test_repositoryFactory(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected Repository
+		wantErr  bool
+	}{
+		{
+			name:     "GitHub repository",
+			url:      "https://github.com/o/r/releases/v0.4.1/components.yaml",
+			expected: &gitHubRepository{},
+		},
+		{
+			name:     "GitLab repository",
+			url:      "https://gitlab.example.com/group/project/-/releases/v0.4.1/components.yaml",
+			expected: &gitLabRepository{},
+		},
+		{
+			name:     "HTTPS repository",
+			url:      "https://example.com/providers/bootstrap-foo/v0.4.1/components.yaml",
+			expected: &httpRepository{},
+		},
+		{
+			name:     "OCI repository",
+			url:      "oci://registry.example.com/providers/bootstrap-foo:v0.4.1/components.yaml",
+			expected: &ociRepository{},
+		},
+		{
+			name:    "unknown scheme",
+			url:     "ftp://example.com/providers/bootstrap-foo/v0.4.1/components.yaml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := repositoryFactory(config.NewProvider("foo", tt.url, clusterctlv1.BootstrapProviderType), test.NewFakeVariableClient())
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo).To(BeAssignableToTypeOf(tt.expected))
+		})
+	}
+}
+
 func Test_newRepositoryClient_YamlProcesor(t *testing.T) {
 	tests := []struct {
 		name   string
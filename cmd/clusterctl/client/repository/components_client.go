@@ -79,6 +79,18 @@ func (f *componentsClient) Get(options ComponentsOptions) (Components, error) {
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read %q from provider's repository %q", path, f.provider.ManifestLabel())
 		}
+
+		// Verify the components YAML before using it, given that it is going to be applied to the management cluster.
+		if err := verifyFile(verifyFileInput{
+			provider:           f.provider,
+			repository:         f.repository,
+			verificationClient: f.configClient.Verification(),
+			version:            options.Version,
+			path:               path,
+			content:            file,
+		}); err != nil {
+			return nil, err
+		}
 	} else {
 		log.Info("Using", "Override", path, "Provider", f.provider.ManifestLabel(), "Version", options.Version)
 	}
@@ -256,6 +256,23 @@ func Test_componentsClient_Get(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Fails if the components checksum does not match",
+			fields: fields{
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "components.yaml").
+					WithDefaultVersion("v1.0.0").
+					WithFile("v1.0.0", "components.yaml", utilyaml.JoinYaml(namespaceYaml, controllerYaml, configMapYaml)).
+					WithFile("v1.0.0", checksumsFile, []byte("0000000000000000000000000000000000000000000000000000000000000000  components.yaml\n")),
+			},
+			args: args{
+				version:           "v1.0.0",
+				targetNamespace:   "",
+				watchingNamespace: "",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Fails if yaml processor cannot get Variables",
 			fields: fields{
@@ -36,22 +36,24 @@ type MetadataClient interface {
 
 // metadataClient implements MetadataClient.
 type metadataClient struct {
-	configVarClient config.VariablesClient
-	provider        config.Provider
-	version         string
-	repository      Repository
+	configVarClient    config.VariablesClient
+	verificationClient config.VerificationClient
+	provider           config.Provider
+	version            string
+	repository         Repository
 }
 
 // ensure metadataClient implements MetadataClient.
 var _ MetadataClient = &metadataClient{}
 
 // newMetadataClient returns a metadataClient.
-func newMetadataClient(provider config.Provider, version string, repository Repository, config config.VariablesClient) *metadataClient {
+func newMetadataClient(provider config.Provider, version string, repository Repository, config config.Client) *metadataClient {
 	return &metadataClient{
-		configVarClient: config,
-		provider:        provider,
-		version:         version,
-		repository:      repository,
+		configVarClient:    config.Variables(),
+		verificationClient: config.Verification(),
+		provider:           provider,
+		version:            version,
+		repository:         repository,
 	}
 }
 
@@ -82,6 +84,18 @@ func (f *metadataClient) Get() (*clusterctlv1.Metadata, error) {
 
 			return nil, errors.Wrapf(err, "failed to read %q from the repository for provider %q", name, f.provider.ManifestLabel())
 		}
+
+		// Verify the metadata before using it, given that it drives the provider upgrades.
+		if err := verifyFile(verifyFileInput{
+			provider:           f.provider,
+			repository:         f.repository,
+			verificationClient: f.verificationClient,
+			version:            version,
+			path:               name,
+			content:            file,
+		}); err != nil {
+			return nil, err
+		}
 	} else {
 		log.V(1).Info("Using", "Override", name, "Provider", f.provider.ManifestLabel(), "Version", version)
 	}
@@ -136,11 +136,15 @@ func Test_metadataClient_Get(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
+			configClient, err := config.New("", config.InjectReader(test.NewFakeReader()))
+			g.Expect(err).NotTo(HaveOccurred())
+
 			f := &metadataClient{
-				configVarClient: test.NewFakeVariableClient(),
-				provider:        tt.fields.provider,
-				version:         tt.fields.version,
-				repository:      tt.fields.repository,
+				configVarClient:    test.NewFakeVariableClient(),
+				verificationClient: configClient.Verification(),
+				provider:           tt.fields.provider,
+				version:            tt.fields.version,
+				repository:         tt.fields.repository,
 			}
 			got, err := f.Get()
 			if tt.wantErr {
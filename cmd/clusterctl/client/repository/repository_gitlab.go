@@ -0,0 +1,306 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	gitlabReleasesSeparator  = "/-/releases/"
+	gitlabLatestReleaseLabel = "latest"
+	gitlabTokenHeader        = "PRIVATE-TOKEN"
+	gitlabReleasesPerPage    = 100
+)
+
+var (
+	// Cache used to limit the number of GitLab API calls; versions and files are cached in the caches shared with gitHubRepository.
+	cacheGitLabReleases = map[string]*gitLabRelease{}
+)
+
+// gitLabRelease defines the subset of the GitLab release API object used by gitLabRepository.
+type gitLabRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  struct {
+		Links []gitLabReleaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+// gitLabReleaseLink defines the subset of the GitLab release link API object used by gitLabRepository.
+type gitLabReleaseLink struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DirectAssetURL string `json:"direct_asset_url"`
+}
+
+// gitLabRepository provides support for providers hosted on GitLab, including self-managed GitLab instances.
+//
+// We support GitLab projects that use the release feature to publish artifacts and versions; artifacts must be
+// attached to the release as links, with the link name matching the file name.
+// The provider URL must be in the form https://{host}/{project-path}/-/releases/{latest|version-tag}/{components.yaml},
+// where project-path is the full path of the project including all the groups and subgroups.
+type gitLabRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	baseURL               string
+	projectPath           string
+	token                 string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+	injectClient          *http.Client
+}
+
+var _ Repository = &gitLabRepository{}
+
+// DefaultVersion returns defaultVersion field of gitLabRepository struct
+func (g *gitLabRepository) DefaultVersion() string {
+	return g.defaultVersion
+}
+
+// GetVersions returns the list of versions that are available in a provider repository
+func (g *gitLabRepository) GetVersions() ([]string, error) {
+	versions, err := g.getVersions()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repository versions")
+	}
+	return versions, nil
+}
+
+// RootPath returns rootPath field of gitLabRepository struct
+func (g *gitLabRepository) RootPath() string {
+	return g.rootPath
+}
+
+// ComponentsPath returns componentsPath field of gitLabRepository struct
+func (g *gitLabRepository) ComponentsPath() string {
+	return g.componentsPath
+}
+
+// GetFile returns a file for a given provider version
+func (g *gitLabRepository) GetFile(version, path string) ([]byte, error) {
+	release, err := g.getReleaseByTag(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get GitLab release %s", version)
+	}
+
+	// download files from the release
+	files, err := g.downloadFilesFromRelease(release, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download files from GitLab release %s", version)
+	}
+
+	return files, nil
+}
+
+// newGitLabRepository returns a gitLabRepository implementation
+func newGitLabRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...gitLabRepositoryOption) (*gitLabRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != httpsScheme {
+		return nil, errors.New("invalid url: a GitLab repository url should start with https://")
+	}
+
+	// Check if the path is in the expected format, {project-path}/-/releases/{version}/{components.yaml}.
+	pathSplit := strings.SplitN(rURL.Path, gitlabReleasesSeparator, 2)
+	if len(pathSplit) != 2 {
+		return nil, errors.Errorf("invalid url: a GitLab repository url should be in the form https://{host}/{project-path}%s{latest|version-tag}/{components.yaml}", gitlabReleasesSeparator)
+	}
+	projectPath := strings.Trim(pathSplit[0], "/")
+	releaseSplit := strings.Split(strings.Trim(pathSplit[1], "/"), "/")
+	if projectPath == "" || len(releaseSplit) < 2 || releaseSplit[0] == "" {
+		return nil, errors.Errorf("invalid url: a GitLab repository url should be in the form https://{host}/{project-path}%s{latest|version-tag}/{components.yaml}", gitlabReleasesSeparator)
+	}
+
+	defaultVersion := releaseSplit[0]
+	path := strings.Join(releaseSplit[1:], "/")
+
+	// use path's directory as a rootPath
+	rootPath := filepath.Dir(path)
+	// use the file name (if any) as componentsPath
+	componentsPath := getComponentsPath(path, rootPath)
+
+	repo := &gitLabRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		baseURL:               fmt.Sprintf("%s://%s", rURL.Scheme, rURL.Host),
+		projectPath:           projectPath,
+		defaultVersion:        defaultVersion,
+		rootPath:              rootPath,
+		componentsPath:        componentsPath,
+	}
+
+	if token, err := configVariablesClient.Get(config.GitLabTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if defaultVersion == gitlabLatestReleaseLabel {
+		versions, err := repo.getVersions()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab latest version")
+		}
+		repo.defaultVersion, err = latestSemanticVersion(versions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// gitLabRepositoryOption is an option for creating a gitLabRepository.
+type gitLabRepositoryOption func(*gitLabRepository)
+
+// injectGitLabRepositoryClient allows to override the HTTP client used by a gitLabRepository.
+func injectGitLabRepositoryClient(client *http.Client) gitLabRepositoryOption {
+	return func(g *gitLabRepository) {
+		g.injectClient = client
+	}
+}
+
+// getClient returns the HTTP client to be used for accessing the GitLab API.
+func (g *gitLabRepository) getClient() *http.Client {
+	if g.injectClient != nil {
+		return g.injectClient
+	}
+	return http.DefaultClient
+}
+
+// get returns the content of a GitLab resource, using the GitLab token for authentication if available.
+// Nb. The token is sent only to the GitLab instance hosting the project, and not to e.g. external links attached to a release.
+func (g *gitLabRepository) get(resourceURL string) ([]byte, error) {
+	header := http.Header{}
+	if g.token != "" && strings.HasPrefix(resourceURL, g.baseURL+"/") {
+		header.Set(gitlabTokenHeader, g.token)
+	}
+	return httpGet(g.getClient(), resourceURL, header)
+}
+
+// projectURL returns the GitLab API URL for the project.
+func (g *gitLabRepository) projectURL() string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", g.baseURL, url.PathEscape(g.projectPath))
+}
+
+// getVersions returns all the release versions for a GitLab project
+func (g *gitLabRepository) getVersions() ([]string, error) {
+	cacheID := fmt.Sprintf("%s/%s", g.baseURL, g.projectPath)
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	versions := []string{}
+	for page := 1; ; page++ {
+		content, err := g.get(fmt.Sprintf("%s/releases?per_page=%d&page=%d", g.projectURL(), gitlabReleasesPerPage, page))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the list of releases")
+		}
+
+		releases := []gitLabRelease{}
+		if err := json.Unmarshal(content, &releases); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the list of releases")
+		}
+
+		for _, r := range releases {
+			if _, err := version.ParseSemantic(r.TagName); err != nil {
+				// Discard releases with tags that are not a valid semantic versions (the user can point explicitly to such releases).
+				continue
+			}
+			versions = append(versions, r.TagName)
+		}
+
+		if len(releases) < gitlabReleasesPerPage {
+			break
+		}
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
+
+// getReleaseByTag returns the GitLab project release with a specific tag name.
+func (g *gitLabRepository) getReleaseByTag(tag string) (*gitLabRelease, error) {
+	cacheID := fmt.Sprintf("%s/%s:%s", g.baseURL, g.projectPath, tag)
+	if release, ok := cacheGitLabReleases[cacheID]; ok {
+		return release, nil
+	}
+
+	content, err := g.get(fmt.Sprintf("%s/releases/%s", g.projectURL(), url.PathEscape(tag)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read release %q", tag)
+	}
+
+	release := &gitLabRelease{}
+	if err := json.Unmarshal(content, release); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse release %q", tag)
+	}
+
+	cacheGitLabReleases[cacheID] = release
+	return release, nil
+}
+
+// downloadFilesFromRelease download a file from release.
+func (g *gitLabRepository) downloadFilesFromRelease(release *gitLabRelease, fileName string) ([]byte, error) {
+	cacheID := fmt.Sprintf("%s/%s:%s:%s", g.baseURL, g.projectPath, release.TagName, fileName)
+	if content, ok := cacheFiles[cacheID]; ok {
+		return content, nil
+	}
+
+	absoluteFileName := filepath.Join(g.rootPath, fileName)
+
+	// search for the file into the release links, retrieving the download url
+	var downloadURL string
+	for _, l := range release.Assets.Links {
+		if l.Name == absoluteFileName {
+			downloadURL = l.DirectAssetURL
+			if downloadURL == "" {
+				downloadURL = l.URL
+			}
+			break
+		}
+	}
+	if downloadURL == "" {
+		return nil, errors.Errorf("failed to get file %q from %q release", fileName, release.TagName)
+	}
+
+	content, err := g.get(downloadURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q from %q release", fileName, release.TagName)
+	}
+
+	cacheFiles[cacheID] = content
+	return content, nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_gitLabRepository_newGitLabRepository(t *testing.T) {
+	type field struct {
+		providerConfig config.Provider
+		variableClient config.VariablesClient
+	}
+	tests := []struct {
+		name    string
+		field   field
+		want    *gitLabRepository
+		wantErr bool
+	}{
+		{
+			name: "can create a new GitLab repo",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://gitlab.example.com/group/subgroup/project/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			want: &gitLabRepository{
+				providerConfig:        config.NewProvider("test", "https://gitlab.example.com/group/subgroup/project/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				configVariablesClient: test.NewFakeVariableClient(),
+				baseURL:               "https://gitlab.example.com",
+				projectPath:           "group/subgroup/project",
+				defaultVersion:        "v0.4.1",
+				rootPath:              ".",
+				componentsPath:        "path",
+			},
+			wantErr: false,
+		},
+		{
+			name: "can create a new GitLab repo with a token",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://gitlab.example.com/group/project/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient().WithVar(config.GitLabTokenVariable, "token"),
+			},
+			want: &gitLabRepository{
+				providerConfig:        config.NewProvider("test", "https://gitlab.example.com/group/project/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				configVariablesClient: test.NewFakeVariableClient().WithVar(config.GitLabTokenVariable, "token"),
+				baseURL:               "https://gitlab.example.com",
+				projectPath:           "group/project",
+				token:                 "token",
+				defaultVersion:        "v0.4.1",
+				rootPath:              ".",
+				componentsPath:        "path",
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing variableClient",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://gitlab.example.com/group/project/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url is not https",
+			field: field{
+				providerConfig: config.NewProvider("test", "http://gitlab.example.com/group/project/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url is not a release url",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://gitlab.example.com/group/project/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url does not have a file",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://gitlab.example.com/group/project/-/releases/v0.4.1", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := newGitLabRepository(tt.field.providerConfig, tt.field.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+// newFakeGitLab returns a fake GitLab server for the group/project project.
+// Nb. The project path is URL encoded in the GitLab API, so the handler matches the escaped path.
+func newFakeGitLab(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.Header.Get(gitlabTokenHeader) != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fproject/releases":
+			if r.URL.Query().Get("page") != "1" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `[{"tag_name": "v0.4.0"}, {"tag_name": "v0.4.2"}, {"tag_name": "v0.4.1"}, {"tag_name": "foo"}]`)
+		case "/api/v4/projects/group%2Fproject/releases/v0.4.1":
+			fmt.Fprintf(w, `{"tag_name": "v0.4.1", "assets": {"links": [{"name": "file.yaml", "url": "%[1]s/file", "direct_asset_url": "%[1]s/group/project/-/releases/v0.4.1/downloads/file.yaml"}]}}`, server.URL)
+		case "/group/project/-/releases/v0.4.1/downloads/file.yaml":
+			fmt.Fprint(w, "content")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func Test_gitLabRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newFakeGitLab(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", server.URL+"/group/project/-/releases/latest/file.yaml", clusterctlv1.CoreProviderType)
+	configVariablesClient := test.NewFakeVariableClient().WithVar(config.GitLabTokenVariable, "token")
+
+	repo, err := newGitLabRepository(providerConfig, configVariablesClient, injectGitLabRepositoryClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// latest is resolved to the latest release in semantic version order.
+	g.Expect(repo.DefaultVersion()).To(Equal("v0.4.2"))
+
+	got, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]string{"v0.4.0", "v0.4.2", "v0.4.1"}))
+}
+
+func Test_gitLabRepository_GetFile(t *testing.T) {
+	server := newFakeGitLab(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", server.URL+"/group/project/-/releases/v0.4.1/file.yaml", clusterctlv1.CoreProviderType)
+
+	tests := []struct {
+		name     string
+		token    string
+		release  string
+		fileName string
+		want     []byte
+		wantErr  bool
+	}{
+		// Nb. this test case runs first, because successfully read releases and downloaded files are cached.
+		{
+			name:     "Invalid token",
+			token:    "invalid",
+			release:  "v0.4.1",
+			fileName: "file.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "Release and file exist",
+			token:    "token",
+			release:  "v0.4.1",
+			fileName: "file.yaml",
+			want:     []byte("content"),
+			wantErr:  false,
+		},
+		{
+			name:     "Release does not exist",
+			token:    "token",
+			release:  "not-a-release",
+			fileName: "file.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "File does not exist",
+			token:    "token",
+			release:  "v0.4.1",
+			fileName: "404.file",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configVariablesClient := test.NewFakeVariableClient().WithVar(config.GitLabTokenVariable, tt.token)
+
+			repo, err := newGitLabRepository(providerConfig, configVariablesClient, injectGitLabRepositoryClient(server.Client()))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.release, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	httpVersionsFile = "versions.txt"
+	httpLatestLabel  = "latest"
+)
+
+// httpRepository provides support for providers hosted on a generic HTTPS server.
+//
+// The HTTPS server must expose provider artifacts using the following directory layout:
+// https://{host}/{basepath}/{version}/{components.yaml}
+//
+// In order to support version listing, the server must expose a {basepath}/versions.txt file
+// with the list of the available versions, one per line; empty lines and lines starting with # are ignored.
+// The provider URL can use "latest" as a version, which resolves to the latest version listed in the versions.txt file
+// according to semantic version order.
+//
+// Concrete example:
+// https://artifacts.example.com/providers/infrastructure-foo/v0.3.0/infrastructure-components.yaml
+// basepath: providers/infrastructure-foo
+// version: v0.3.0
+// components.yaml: infrastructure-components.yaml
+type httpRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	baseURL               string
+	defaultVersion        string
+	componentsPath        string
+	injectClient          *http.Client
+}
+
+var _ Repository = &httpRepository{}
+
+// DefaultVersion returns the default version for the HTTPS repository.
+func (h *httpRepository) DefaultVersion() string {
+	return h.defaultVersion
+}
+
+// RootPath returns the empty string as all the files are expected to be in the version directory.
+func (h *httpRepository) RootPath() string {
+	return ""
+}
+
+// ComponentsPath returns the path to the components file for the HTTPS repository.
+func (h *httpRepository) ComponentsPath() string {
+	return h.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (h *httpRepository) GetFile(version, path string) ([]byte, error) {
+	fileURL := fmt.Sprintf("%s/%s/%s", h.baseURL, version, strings.TrimPrefix(path, "/"))
+	if content, ok := cacheFiles[fileURL]; ok {
+		return content, nil
+	}
+
+	content, err := httpGet(h.getClient(), fileURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %q for version %q", path, version)
+	}
+
+	cacheFiles[fileURL] = content
+	return content, nil
+}
+
+// GetVersions returns the list of versions that are available in the HTTPS repository, as listed in the versions.txt file.
+func (h *httpRepository) GetVersions() ([]string, error) {
+	versionsURL := fmt.Sprintf("%s/%s", h.baseURL, httpVersionsFile)
+	if versions, ok := cacheVersions[versionsURL]; ok {
+		return versions, nil
+	}
+
+	content, err := httpGet(h.getClient(), versionsURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repository versions")
+	}
+
+	versions := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := version.ParseSemantic(line); err != nil {
+			// Discard versions that are not valid semantic versions (the user can point explicitly to such versions).
+			continue
+		}
+		versions = append(versions, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", versionsURL)
+	}
+
+	cacheVersions[versionsURL] = versions
+	return versions, nil
+}
+
+// newHTTPRepository returns a httpRepository implementation.
+func newHTTPRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...httpRepositoryOption) (*httpRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != httpsScheme {
+		return nil, errors.New("invalid url: a HTTPS repository url should start with https://")
+	}
+
+	// Check if the path is in the expected format, {basepath}/{version}/{components.yaml}.
+	urlSplit := strings.Split(strings.Trim(rURL.Path, "/"), "/")
+	if len(urlSplit) < 2 || urlSplit[len(urlSplit)-1] == "" || urlSplit[len(urlSplit)-2] == "" {
+		return nil, errors.New("invalid url: a HTTPS repository url should be in the form https://{host}/{basepath}/{latest|version}/{components.yaml}")
+	}
+
+	baseURL := url.URL{
+		Scheme: rURL.Scheme,
+		User:   rURL.User,
+		Host:   rURL.Host,
+		Path:   "/" + strings.Join(urlSplit[:len(urlSplit)-2], "/"),
+	}
+
+	repo := &httpRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		baseURL:               strings.TrimSuffix(baseURL.String(), "/"),
+		defaultVersion:        urlSplit[len(urlSplit)-2],
+		componentsPath:        urlSplit[len(urlSplit)-1],
+	}
+
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if repo.defaultVersion == httpLatestLabel {
+		versions, err := repo.GetVersions()
+		if err != nil {
+			return nil, err
+		}
+		repo.defaultVersion, err = latestSemanticVersion(versions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// httpRepositoryOption is an option for creating a httpRepository.
+type httpRepositoryOption func(*httpRepository)
+
+// injectHTTPRepositoryClient allows to override the HTTP client used by a httpRepository.
+func injectHTTPRepositoryClient(client *http.Client) httpRepositoryOption {
+	return func(h *httpRepository) {
+		h.injectClient = client
+	}
+}
+
+// getClient returns the HTTP client to be used for accessing the repository.
+func (h *httpRepository) getClient() *http.Client {
+	if h.injectClient != nil {
+		return h.injectClient
+	}
+	return http.DefaultClient
+}
+
+// httpGet returns the content of an HTTP resource, failing if the response status code is not 200.
+func httpGet(client *http.Client, resourceURL string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create a request for %q", resourceURL)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %q", resourceURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to get %q: unexpected status code %d", resourceURL, resp.StatusCode)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", resourceURL)
+	}
+	return content, nil
+}
+
+// latestSemanticVersion returns the latest version in a list according to semantic version order.
+// Versions that are not in semver format are ignored.
+func latestSemanticVersion(versions []string) (string, error) {
+	var latestTag string
+	var latestVersion *version.Version
+	for _, v := range versions {
+		sv, err := version.ParseSemantic(v)
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || latestVersion.LessThan(sv) {
+			latestTag = v
+			latestVersion = sv
+		}
+	}
+
+	if latestTag == "" {
+		return "", errors.New("failed to find versions with a valid semantic version number")
+	}
+	return latestTag, nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_httpRepository_newHTTPRepository(t *testing.T) {
+	type field struct {
+		providerConfig config.Provider
+		variableClient config.VariablesClient
+	}
+	tests := []struct {
+		name               string
+		field              field
+		wantBaseURL        string
+		wantDefaultVersion string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name: "can create a new HTTPS repo",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://example.com/providers/infrastructure-foo/v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantBaseURL:        "https://example.com/providers/infrastructure-foo",
+			wantDefaultVersion: "v0.4.1",
+			wantComponentsPath: "infrastructure-components.yaml",
+			wantErr:            false,
+		},
+		{
+			name: "can create a new HTTPS repo with the version at the root of the server",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://example.com/v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantBaseURL:        "https://example.com",
+			wantDefaultVersion: "v0.4.1",
+			wantComponentsPath: "infrastructure-components.yaml",
+			wantErr:            false,
+		},
+		{
+			name: "missing variableClient",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://example.com/v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url is not https",
+			field: field{
+				providerConfig: config.NewProvider("test", "http://example.com/v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url does not have a version",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://example.com/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := newHTTPRepository(tt.field.providerConfig, tt.field.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got.baseURL).To(Equal(tt.wantBaseURL))
+			g.Expect(got.DefaultVersion()).To(Equal(tt.wantDefaultVersion))
+			g.Expect(got.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+			g.Expect(got.RootPath()).To(Equal(""))
+		})
+	}
+}
+
+func Test_httpRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/providers/infrastructure-foo/versions.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, "# available versions\nv0.4.0\n\nv0.4.2\nv0.4.1\nnot-a-version\n")
+	})
+
+	providerConfig := config.NewProvider("test", server.URL+"/providers/infrastructure-foo/latest/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+
+	repo, err := newHTTPRepository(providerConfig, test.NewFakeVariableClient(), injectHTTPRepositoryClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// latest is resolved to the latest version in semantic version order.
+	g.Expect(repo.DefaultVersion()).To(Equal("v0.4.2"))
+
+	got, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]string{"v0.4.0", "v0.4.2", "v0.4.1"}))
+}
+
+func Test_httpRepository_GetFile(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/providers/infrastructure-foo/v0.4.1/metadata.yaml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, "content")
+	})
+
+	providerConfig := config.NewProvider("test", server.URL+"/providers/infrastructure-foo/v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+
+	tests := []struct {
+		name     string
+		version  string
+		fileName string
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "Version and file exist",
+			version:  "v0.4.1",
+			fileName: "metadata.yaml",
+			want:     []byte("content"),
+			wantErr:  false,
+		},
+		{
+			name:     "Version does not exist",
+			version:  "v0.4.2",
+			fileName: "metadata.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "File does not exist",
+			version:  "v0.4.1",
+			fileName: "404.file",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := newHTTPRepository(providerConfig, test.NewFakeVariableClient(), injectHTTPRepositoryClient(server.Client()))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_latestSemanticVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "Get latest version",
+			versions: []string{"v0.4.0", "v0.4.2", "v0.4.1"},
+			want:     "v0.4.2",
+		},
+		{
+			name:     "Versions that are not semver are ignored",
+			versions: []string{"v0.4.0", "foo"},
+			want:     "v0.4.0",
+		},
+		{
+			name:     "No valid versions",
+			versions: []string{"foo"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := latestSemanticVersion(tt.versions)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,494 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	ociScheme          = "oci"
+	ociLatestLabel     = "latest"
+	ociManifestType    = "application/vnd.oci.image.manifest.v1+json"
+	ociTitleAnnotation = "org.opencontainers.image.title"
+)
+
+// ociManifest defines the subset of the OCI image manifest used by ociRepository.
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor defines the subset of the OCI content descriptor used by ociRepository.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociTagList defines the response of the OCI distribution API for listing tags.
+type ociTagList struct {
+	Tags []string `json:"tags"`
+}
+
+// ociRepository provides support for providers published as OCI artifacts in an OCI registry.
+//
+// Each provider version is an OCI artifact tagged with the version; each file is stored in a layer of the artifact,
+// with the file name in the "org.opencontainers.image.title" annotation, as e.g. done by "oras push".
+// The provider URL must be in the form oci://{registry}/{repository}:{latest|version-tag}/{components.yaml}.
+//
+// Concrete example:
+// oci://registry.example.com/providers/infrastructure-foo:v0.3.0/infrastructure-components.yaml
+// registry: registry.example.com
+// repository: providers/infrastructure-foo
+// version: v0.3.0
+// components.yaml: infrastructure-components.yaml
+//
+// If the registry requires authentication, the credentials can be provided using the oci-username and oci-password variables.
+type ociRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	registry              string
+	repository            string
+	username              string
+	password              string
+	token                 string
+	defaultVersion        string
+	componentsPath        string
+	injectClient          *http.Client
+}
+
+var _ Repository = &ociRepository{}
+
+// DefaultVersion returns the default version for the OCI repository.
+func (o *ociRepository) DefaultVersion() string {
+	return o.defaultVersion
+}
+
+// RootPath returns the empty string as all the files are stored in the layers of the OCI artifact.
+func (o *ociRepository) RootPath() string {
+	return ""
+}
+
+// ComponentsPath returns the path to the components file for the OCI repository.
+func (o *ociRepository) ComponentsPath() string {
+	return o.componentsPath
+}
+
+// GetFile returns a file for a given provider version, reading it from the layer of the OCI artifact with a matching title.
+func (o *ociRepository) GetFile(version, path string) ([]byte, error) {
+	cacheID := fmt.Sprintf("%s/%s:%s:%s", o.registry, o.repository, version, path)
+	if content, ok := cacheFiles[cacheID]; ok {
+		return content, nil
+	}
+
+	content, err := o.getArtifactFile(version, path)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheFiles[cacheID] = content
+	return content, nil
+}
+
+// getArtifactFile returns a file reading it from the layer of the OCI artifact with a matching title; if the path
+// is empty, the OCI artifact is expected to have a single layer.
+func (o *ociRepository) getArtifactFile(version, path string) ([]byte, error) {
+	header := http.Header{}
+	header.Set("Accept", ociManifestType)
+	content, err := o.get(fmt.Sprintf("%s/manifests/%s", o.repositoryURL(), url.PathEscape(version)), header)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the OCI artifact for version %q", version)
+	}
+
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the OCI artifact for version %q", version)
+	}
+
+	var digest string
+	switch {
+	case path != "":
+		for _, l := range manifest.Layers {
+			if l.Annotations[ociTitleAnnotation] == path {
+				digest = l.Digest
+				break
+			}
+		}
+		if digest == "" {
+			return nil, errors.Errorf("failed to get file %q from the OCI artifact for version %q", path, version)
+		}
+	case len(manifest.Layers) == 1:
+		digest = manifest.Layers[0].Digest
+		path = manifest.Layers[0].Annotations[ociTitleAnnotation]
+	default:
+		return nil, errors.Errorf("the OCI artifact for version %q contains %d files, please specify the file to read", version, len(manifest.Layers))
+	}
+
+	content, err = o.get(fmt.Sprintf("%s/blobs/%s", o.repositoryURL(), digest), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %q from the OCI artifact for version %q", path, version)
+	}
+	if err := verifyDigest(digest, content); err != nil {
+		return nil, errors.Wrapf(err, "failed to verify file %q from the OCI artifact for version %q", path, version)
+	}
+	return content, nil
+}
+
+// GetVersions returns the list of versions that are available in the OCI repository, as defined by the artifact tags.
+func (o *ociRepository) GetVersions() ([]string, error) {
+	cacheID := fmt.Sprintf("%s/%s", o.registry, o.repository)
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	content, err := o.get(fmt.Sprintf("%s/tags/list", o.repositoryURL()), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get repository versions")
+	}
+
+	tagList := &ociTagList{}
+	if err := json.Unmarshal(content, tagList); err != nil {
+		return nil, errors.Wrap(err, "failed to parse repository versions")
+	}
+
+	versions := []string{}
+	for _, t := range tagList.Tags {
+		if _, err := version.ParseSemantic(t); err != nil {
+			// Discard tags that are not valid semantic versions (the user can point explicitly to such tags).
+			continue
+		}
+		versions = append(versions, t)
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
+
+// newOCIRepository returns an ociRepository implementation.
+func newOCIRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...ociRepositoryOption) (*ociRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != ociScheme {
+		return nil, errors.New("invalid url: an OCI repository url should start with oci://")
+	}
+
+	// Check if the path is in the expected format, {repository}:{version}/{components.yaml}.
+	path := strings.TrimPrefix(rURL.Path, "/")
+	tagIndex := strings.Index(path, ":")
+	if rURL.Host == "" || tagIndex <= 0 {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version-tag}/{components.yaml}")
+	}
+	tagSplit := strings.SplitN(path[tagIndex+1:], "/", 2)
+	if len(tagSplit) != 2 || tagSplit[0] == "" || tagSplit[1] == "" {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version-tag}/{components.yaml}")
+	}
+
+	repo := &ociRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		registry:              rURL.Host,
+		repository:            path[:tagIndex],
+		defaultVersion:        tagSplit[0],
+		componentsPath:        tagSplit[1],
+	}
+
+	if username, err := configVariablesClient.Get(config.OCIUsernameVariable); err == nil {
+		repo.username = username
+	}
+	if password, err := configVariablesClient.Get(config.OCIPasswordVariable); err == nil {
+		repo.password = password
+	}
+
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if repo.defaultVersion == ociLatestLabel {
+		versions, err := repo.GetVersions()
+		if err != nil {
+			return nil, err
+		}
+		repo.defaultVersion, err = latestSemanticVersion(versions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// GetOCIArtifactFile returns a file stored in an OCI artifact, e.g. a workload cluster template pushed to an OCI registry
+// using "oras push". The artifact URL must be in the form oci://{registry}/{repository}:{tag}[/{file}]; if the file is
+// not specified, the OCI artifact is expected to contain a single file.
+//
+// If the registry requires authentication, the credentials can be provided using the oci-username and oci-password variables.
+// If client is nil, the default HTTP client is used.
+func GetOCIArtifactFile(artifactURL string, configVariablesClient config.VariablesClient, client *http.Client) ([]byte, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(artifactURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != ociScheme {
+		return nil, errors.New("invalid url: an OCI artifact url should start with oci://")
+	}
+
+	// Check if the path is in the expected format, {repository}:{tag}[/{file}].
+	path := strings.TrimPrefix(rURL.Path, "/")
+	tagIndex := strings.Index(path, ":")
+	if rURL.Host == "" || tagIndex <= 0 {
+		return nil, errors.New("invalid url: an OCI artifact url should be in the form oci://{registry}/{repository}:{tag}[/{file}]")
+	}
+	tagSplit := strings.SplitN(path[tagIndex+1:], "/", 2)
+	if tagSplit[0] == "" {
+		return nil, errors.New("invalid url: an OCI artifact url should be in the form oci://{registry}/{repository}:{tag}[/{file}]")
+	}
+	file := ""
+	if len(tagSplit) == 2 {
+		file = tagSplit[1]
+	}
+
+	repo := &ociRepository{
+		configVariablesClient: configVariablesClient,
+		registry:              rURL.Host,
+		repository:            path[:tagIndex],
+		injectClient:          client,
+	}
+	if username, err := configVariablesClient.Get(config.OCIUsernameVariable); err == nil {
+		repo.username = username
+	}
+	if password, err := configVariablesClient.Get(config.OCIPasswordVariable); err == nil {
+		repo.password = password
+	}
+
+	return repo.getArtifactFile(tagSplit[0], file)
+}
+
+// ociRepositoryOption is an option for creating an ociRepository.
+type ociRepositoryOption func(*ociRepository)
+
+// injectOCIRepositoryClient allows to override the HTTP client used by an ociRepository.
+func injectOCIRepositoryClient(client *http.Client) ociRepositoryOption {
+	return func(o *ociRepository) {
+		o.injectClient = client
+	}
+}
+
+// getClient returns the HTTP client to be used for accessing the OCI registry.
+func (o *ociRepository) getClient() *http.Client {
+	if o.injectClient != nil {
+		return o.injectClient
+	}
+	return http.DefaultClient
+}
+
+// repositoryURL returns the OCI distribution API URL for the repository.
+func (o *ociRepository) repositoryURL() string {
+	return fmt.Sprintf("%s://%s/v2/%s", httpsScheme, o.registry, o.repository)
+}
+
+// get returns the content of a resource in the OCI registry; if the registry requires authentication,
+// the authentication challenge is handled using either basic or bearer token authentication.
+func (o *ociRepository) get(resourceURL string, header http.Header) ([]byte, error) {
+	resp, err := o.do(resourceURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized && o.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		if err := o.authenticate(challenge); err != nil {
+			return nil, errors.Wrapf(err, "failed to authenticate to the OCI registry %q", o.registry)
+		}
+
+		resp, err = o.do(resourceURL, header)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to get %q: unexpected status code %d", resourceURL, resp.StatusCode)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", resourceURL)
+	}
+	return content, nil
+}
+
+// do sends a GET request to the OCI registry, using the current credentials if any.
+func (o *ociRepository) do(resourceURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create a request for %q", resourceURL)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	switch {
+	case o.token != "":
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	case o.username != "" || o.password != "":
+		req.SetBasicAuth(o.username, o.password)
+	}
+
+	resp, err := o.getClient().Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %q", resourceURL)
+	}
+	return resp, nil
+}
+
+// authenticate gets a bearer token from the authorization service defined in a Bearer authentication challenge,
+// using the basic credentials if any. Nb. Basic authentication challenges are already handled by the do method.
+func (o *ociRepository) authenticate(challenge string) error {
+	scheme, params := parseAuthenticateChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") {
+		return errors.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme == "" || realm.Host == "" {
+		return errors.Errorf("invalid realm in the authentication challenge %q", challenge)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if value, ok := params[key]; ok {
+			query.Set(key, value)
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a request for %q", realm.String())
+	}
+	if o.username != "" || o.password != "" {
+		req.SetBasicAuth(o.username, o.password)
+	}
+
+	resp, err := o.getClient().Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get a token from %q", realm.String())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to get a token from %q: unexpected status code %d", realm.String(), resp.StatusCode)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return errors.Wrapf(err, "failed to parse the token from %q", realm.String())
+	}
+
+	o.token = token.Token
+	if o.token == "" {
+		o.token = token.AccessToken
+	}
+	if o.token == "" {
+		return errors.Errorf("failed to get a token from %q: empty token", realm.String())
+	}
+	return nil
+}
+
+// parseAuthenticateChallenge parses a WWW-Authenticate header value, e.g. Bearer realm="https://auth.example.com/token",service="registry.example.com".
+func parseAuthenticateChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	challenge = strings.TrimSpace(challenge)
+	schemeIndex := strings.Index(challenge, " ")
+	if schemeIndex < 0 {
+		return challenge, params
+	}
+
+	scheme := challenge[:schemeIndex]
+	rest := challenge[schemeIndex+1:]
+	for rest != "" {
+		eqIndex := strings.Index(rest, "=")
+		if eqIndex < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eqIndex]))
+		rest = strings.TrimSpace(rest[eqIndex+1:])
+
+		var value string
+		if strings.HasPrefix(rest, "\"") {
+			endIndex := strings.Index(rest[1:], "\"")
+			if endIndex < 0 {
+				break
+			}
+			value = rest[1 : endIndex+1]
+			rest = rest[endIndex+2:]
+		} else {
+			endIndex := strings.Index(rest, ",")
+			if endIndex < 0 {
+				endIndex = len(rest)
+			}
+			value = strings.TrimSpace(rest[:endIndex])
+			rest = rest[endIndex:]
+		}
+		params[key] = value
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+	return scheme, params
+}
+
+// verifyDigest verifies that the content matches a sha256 digest, e.g. sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03.
+func verifyDigest(digest string, content []byte) error {
+	digestSplit := strings.SplitN(digest, ":", 2)
+	if len(digestSplit) != 2 || digestSplit[0] != "sha256" {
+		return errors.Errorf("unsupported digest %q", digest)
+	}
+
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != digestSplit[1] {
+		return errors.Errorf("digest mismatch, expected %q", digest)
+	}
+	return nil
+}
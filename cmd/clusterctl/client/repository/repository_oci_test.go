@@ -0,0 +1,342 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_ociRepository_newOCIRepository(t *testing.T) {
+	type field struct {
+		providerConfig config.Provider
+		variableClient config.VariablesClient
+	}
+	tests := []struct {
+		name    string
+		field   field
+		want    *ociRepository
+		wantErr bool
+	}{
+		{
+			name: "can create a new OCI repo",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://registry.example.com/providers/infrastructure-foo:v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			want: &ociRepository{
+				providerConfig:        config.NewProvider("test", "oci://registry.example.com/providers/infrastructure-foo:v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				configVariablesClient: test.NewFakeVariableClient(),
+				registry:              "registry.example.com",
+				repository:            "providers/infrastructure-foo",
+				defaultVersion:        "v0.4.1",
+				componentsPath:        "infrastructure-components.yaml",
+			},
+			wantErr: false,
+		},
+		{
+			name: "can create a new OCI repo with credentials and a registry port",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://registry.example.com:5000/infrastructure-foo:v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password"),
+			},
+			want: &ociRepository{
+				providerConfig:        config.NewProvider("test", "oci://registry.example.com:5000/infrastructure-foo:v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				configVariablesClient: test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password"),
+				registry:              "registry.example.com:5000",
+				repository:            "infrastructure-foo",
+				username:              "user",
+				password:              "password",
+				defaultVersion:        "v0.4.1",
+				componentsPath:        "infrastructure-components.yaml",
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing variableClient",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://registry.example.com/infrastructure-foo:v0.4.1/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url does not have a tag",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://registry.example.com/infrastructure-foo/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url does not have a file",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://registry.example.com/infrastructure-foo:v0.4.1", clusterctlv1.InfrastructureProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := newOCIRepository(tt.field.providerConfig, tt.field.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+// newFakeRegistry returns a fake OCI registry hosting the infrastructure-foo repository; the registry requires a bearer token,
+// which is issued by the /token endpoint to the user with username user and password password.
+func newFakeRegistry(t *testing.T) *httptest.Server {
+	content := "content"
+	sum := sha256.Sum256([]byte(content))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+
+		if r.URL.Path == "/token" {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("service") != "registry" || r.URL.Query().Get("scope") != "repository:infrastructure-foo:pull" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "token"}`)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:infrastructure-foo:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/infrastructure-foo/tags/list":
+			fmt.Fprint(w, `{"name": "infrastructure-foo", "tags": ["v0.4.0", "v0.4.2", "v0.4.1", "foo"]}`)
+		case "/v2/infrastructure-foo/manifests/v0.4.1":
+			if !strings.Contains(r.Header.Get("Accept"), ociManifestType) {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			fmt.Fprintf(w, `{"schemaVersion": 2, "layers": [{"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": "%s", "annotations": {"org.opencontainers.image.title": "file.yaml"}}]}`, digest)
+		case "/v2/infrastructure-foo/blobs/" + digest:
+			fmt.Fprint(w, content)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func Test_ociRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newFakeRegistry(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", "oci://"+strings.TrimPrefix(server.URL, "https://")+"/infrastructure-foo:latest/file.yaml", clusterctlv1.InfrastructureProviderType)
+	configVariablesClient := test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password")
+
+	repo, err := newOCIRepository(providerConfig, configVariablesClient, injectOCIRepositoryClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// latest is resolved to the latest tag in semantic version order.
+	g.Expect(repo.DefaultVersion()).To(Equal("v0.4.2"))
+
+	got, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]string{"v0.4.0", "v0.4.2", "v0.4.1"}))
+}
+
+func Test_ociRepository_GetFile(t *testing.T) {
+	server := newFakeRegistry(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", "oci://"+strings.TrimPrefix(server.URL, "https://")+"/infrastructure-foo:v0.4.1/file.yaml", clusterctlv1.InfrastructureProviderType)
+
+	tests := []struct {
+		name     string
+		password string
+		version  string
+		fileName string
+		want     []byte
+		wantErr  bool
+	}{
+		// Nb. this test case runs first, because successfully downloaded files are cached.
+		{
+			name:     "Invalid credentials",
+			password: "invalid",
+			version:  "v0.4.1",
+			fileName: "file.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "Version and file exist",
+			password: "password",
+			version:  "v0.4.1",
+			fileName: "file.yaml",
+			want:     []byte("content"),
+			wantErr:  false,
+		},
+		{
+			name:     "Version does not exist",
+			password: "password",
+			version:  "v0.4.2",
+			fileName: "file.yaml",
+			wantErr:  true,
+		},
+		{
+			name:     "File does not exist",
+			password: "password",
+			version:  "v0.4.1",
+			fileName: "404.file",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configVariablesClient := test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, tt.password)
+
+			repo, err := newOCIRepository(providerConfig, configVariablesClient, injectOCIRepositoryClient(server.Client()))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_GetOCIArtifactFile(t *testing.T) {
+	server := newFakeRegistry(t)
+	defer server.Close()
+
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	tests := []struct {
+		name        string
+		artifactURL string
+		want        []byte
+		wantErr     bool
+	}{
+		{
+			name:        "Tag and file exist",
+			artifactURL: "oci://" + registry + "/infrastructure-foo:v0.4.1/file.yaml",
+			want:        []byte("content"),
+			wantErr:     false,
+		},
+		{
+			name:        "Tag exists, file not specified and the artifact contains a single file",
+			artifactURL: "oci://" + registry + "/infrastructure-foo:v0.4.1",
+			want:        []byte("content"),
+			wantErr:     false,
+		},
+		{
+			name:        "Tag does not exist",
+			artifactURL: "oci://" + registry + "/infrastructure-foo:v0.4.2",
+			wantErr:     true,
+		},
+		{
+			name:        "File does not exist",
+			artifactURL: "oci://" + registry + "/infrastructure-foo:v0.4.1/404.file",
+			wantErr:     true,
+		},
+		{
+			name:        "Tag not specified",
+			artifactURL: "oci://" + registry + "/infrastructure-foo",
+			wantErr:     true,
+		},
+		{
+			name:        "Not an OCI url",
+			artifactURL: "https://" + registry + "/infrastructure-foo:v0.4.1",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configVariablesClient := test.NewFakeVariableClient().WithVar(config.OCIUsernameVariable, "user").WithVar(config.OCIPasswordVariable, "password")
+
+			got, err := GetOCIArtifactFile(tt.artifactURL, configVariablesClient, server.Client())
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_parseAuthenticateChallenge(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, params := parseAuthenticateChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:foo:pull,push"`)
+	g.Expect(scheme).To(Equal("Bearer"))
+	g.Expect(params).To(Equal(map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:foo:pull,push",
+	}))
+}
+
+func Test_verifyDigest(t *testing.T) {
+	g := NewWithT(t)
+
+	sum := sha256.Sum256([]byte("content"))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	g.Expect(verifyDigest(digest, []byte("content"))).To(Succeed())
+	g.Expect(verifyDigest(digest, []byte("corrupted"))).ToNot(Succeed())
+	g.Expect(verifyDigest("sha512:foo", []byte("content"))).ToNot(Succeed())
+}
@@ -34,6 +34,10 @@ type Template interface {
 	// This value is derived by the template YAML.
 	Variables() []string
 
+	// VariablesSchema describes the variables required by the template, if a schema is provided
+	// along with the template; otherwise nil is returned.
+	VariablesSchema() *yaml.VariablesSchema
+
 	// TargetNamespace where the template objects will be installed.
 	TargetNamespace() string
 
@@ -47,6 +51,7 @@ type Template interface {
 // template implements Template.
 type template struct {
 	variables       []string
+	variablesSchema *yaml.VariablesSchema
 	targetNamespace string
 	objs            []unstructured.Unstructured
 }
@@ -58,6 +63,10 @@ func (t *template) Variables() []string {
 	return t.variables
 }
 
+func (t *template) VariablesSchema() *yaml.VariablesSchema {
+	return t.variablesSchema
+}
+
 func (t *template) TargetNamespace() string {
 	return t.targetNamespace
 }
@@ -76,6 +85,10 @@ type TemplateInput struct {
 	Processor             yaml.Processor
 	TargetNamespace       string
 	ListVariablesOnly     bool
+
+	// VariablesSchema describes the template variables; if set, variable values are validated
+	// against the schema before processing the template and the schema defaults are applied.
+	VariablesSchema *yaml.VariablesSchema
 }
 
 // NewTemplate returns a new objects embedding a cluster template YAML file.
@@ -88,11 +101,21 @@ func NewTemplate(input TemplateInput) (*template, error) {
 	if input.ListVariablesOnly {
 		return &template{
 			variables:       variables,
+			variablesSchema: input.VariablesSchema,
 			targetNamespace: input.TargetNamespace,
 		}, nil
 	}
 
-	processedYaml, err := input.Processor.Process(input.RawArtifact, input.ConfigVariablesClient.Get)
+	// Validates the variable values against the schema, if any, before processing the template.
+	variablesGetter := input.ConfigVariablesClient.Get
+	if input.VariablesSchema != nil {
+		if err := input.VariablesSchema.Validate(variables, variablesGetter); err != nil {
+			return nil, err
+		}
+		variablesGetter = input.VariablesSchema.WithDefaults(variablesGetter)
+	}
+
+	processedYaml, err := input.Processor.Process(input.RawArtifact, variablesGetter)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +133,7 @@ func NewTemplate(input TemplateInput) (*template, error) {
 
 	return &template{
 		variables:       variables,
+		variablesSchema: input.VariablesSchema,
 		targetNamespace: input.TargetNamespace,
 		objs:            objs,
 	}, nil
@@ -95,5 +95,50 @@ func (c *templateClient) Get(flavor, targetNamespace string, listVariablesOnly b
 		log.V(1).Info("Using", "Override", name, "Provider", c.provider.ManifestLabel(), "Version", version)
 	}
 
-	return NewTemplate(TemplateInput{rawArtifact, c.configVariablesClient, c.processor, targetNamespace, listVariablesOnly})
+	variablesSchema, err := c.getVariablesSchema(version, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTemplate(TemplateInput{
+		RawArtifact:           rawArtifact,
+		ConfigVariablesClient: c.configVariablesClient,
+		Processor:             c.processor,
+		TargetNamespace:       targetNamespace,
+		ListVariablesOnly:     listVariablesOnly,
+		VariablesSchema:       variablesSchema,
+	})
+}
+
+// getVariablesSchema returns the variables schema shipped next to a template, if any.
+// The schema is optional, so failures in reading the schema file are treated as if the schema does not exist,
+// while an invalid schema is reported as an error.
+func (c *templateClient) getVariablesSchema(version, templateName string) (*yaml.VariablesSchema, error) {
+	log := logf.Log
+
+	name := yaml.VariablesSchemaName(templateName)
+
+	rawSchema, err := getLocalOverride(&newOverrideInput{
+		configVariablesClient: c.configVariablesClient,
+		provider:              c.provider,
+		version:               version,
+		filePath:              name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if rawSchema == nil {
+		rawSchema, err = c.repository.GetFile(version, name)
+		if err != nil {
+			log.V(5).Info("Variables schema not available", "File", name, "Provider", c.provider.ManifestLabel(), "Version", version)
+			return nil, nil
+		}
+	}
+
+	schema, err := yaml.ParseVariablesSchema(rawSchema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q from provider's repository %q", name, c.provider.ManifestLabel())
+	}
+	return schema, nil
 }
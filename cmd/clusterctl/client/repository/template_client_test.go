@@ -161,6 +161,70 @@ func Test_templates_Get(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "pass if variables does not exists but the variables schema provides a default",
+			fields: fields{
+				version:  "v1.0",
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "").
+					WithDefaultVersion("v1.0").
+					WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+					WithFile("v1.0", "cluster-template.variables.yaml", []byte(fmt.Sprintf("variables:\n  %s:\n    default: %s\n", variableName, variableValue))),
+				configVariablesClient: test.NewFakeVariableClient(),
+				processor:             yaml.NewSimpleProcessor(),
+			},
+			args: args{
+				flavor:            "",
+				targetNamespace:   "ns1",
+				listVariablesOnly: false,
+			},
+			want: want{
+				variables:       []string{variableName},
+				targetNamespace: "ns1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "fails if the variable value does not match the variables schema",
+			fields: fields{
+				version:  "v1.0",
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "").
+					WithDefaultVersion("v1.0").
+					WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+					WithFile("v1.0", "cluster-template.variables.yaml", []byte(fmt.Sprintf("variables:\n  %s:\n    type: int\n", variableName))),
+				configVariablesClient: test.NewFakeVariableClient().WithVar(variableName, variableValue),
+				processor:             yaml.NewSimpleProcessor(),
+			},
+			args: args{
+				flavor:            "",
+				targetNamespace:   "ns1",
+				listVariablesOnly: false,
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the variables schema is not valid",
+			fields: fields{
+				version:  "v1.0",
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "").
+					WithDefaultVersion("v1.0").
+					WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+					WithFile("v1.0", "cluster-template.variables.yaml", []byte(fmt.Sprintf("variables:\n  %s:\n    type: float\n", variableName))),
+				configVariablesClient: test.NewFakeVariableClient().WithVar(variableName, variableValue),
+				processor:             yaml.NewSimpleProcessor(),
+			},
+			args: args{
+				flavor:            "",
+				targetNamespace:   "ns1",
+				listVariablesOnly: true,
+			},
+			wantErr: true,
+		},
 		{
 			name: "returns error if processor is unable to get variables",
 			fields: fields{
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+const (
+	// checksumsFile is the name of the file, published in the provider repository next to the components YAML,
+	// listing the SHA256 checksums of the provider artifacts in the sha256sum format.
+	checksumsFile = "checksums.txt"
+
+	// checksumsSignatureFile is the name of the file hosting the detached signature of the checksums file.
+	checksumsSignatureFile = "checksums.txt.sig"
+)
+
+type verifyFileInput struct {
+	provider           config.Provider
+	repository         Repository
+	verificationClient config.VerificationClient
+	version            string
+	path               string
+	content            []byte
+}
+
+// verifyFile verifies a file read from a provider repository against the checksums file published in the same
+// repository, and the checksums file against its detached signature, using the trusted keys defined in the
+// clusterctl config file.
+//
+// Providers not publishing checksums or signatures are accepted unless the verification is configured as strict;
+// instead, invalid checksums or signatures are always reported as an error.
+func verifyFile(input verifyFileInput) error {
+	log := logf.Log
+
+	strict, err := input.verificationClient.Strict()
+	if err != nil {
+		return err
+	}
+
+	checksums, err := input.repository.GetFile(input.version, checksumsFile)
+	if err != nil {
+		if strict {
+			return errors.Wrapf(err, "failed to read %q from the repository for provider %q; unverified providers are not allowed in strict mode", checksumsFile, input.provider.ManifestLabel())
+		}
+		log.V(1).Info("Skipping verification, the provider does not publish checksums", "File", input.path, "Provider", input.provider.ManifestLabel(), "Version", input.version)
+		return nil
+	}
+
+	if err := verifyChecksumsSignature(input, checksums, strict); err != nil {
+		return err
+	}
+
+	want, err := getChecksum(checksums, input.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify %q for provider %q", input.path, input.provider.ManifestLabel())
+	}
+	sum := sha256.Sum256(input.content)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return errors.Errorf("failed to verify %q for provider %q: checksum %s does not match the expected checksum %s", input.path, input.provider.ManifestLabel(), got, want)
+	}
+
+	log.V(1).Info("Verified", "File", input.path, "Provider", input.provider.ManifestLabel(), "Version", input.version)
+	return nil
+}
+
+// verifyChecksumsSignature verifies the detached signature of the checksums file using the trusted keys.
+func verifyChecksumsSignature(input verifyFileInput, checksums []byte, strict bool) error {
+	log := logf.Log
+
+	signature, err := input.repository.GetFile(input.version, checksumsSignatureFile)
+	if err != nil {
+		if strict {
+			return errors.Wrapf(err, "failed to read %q from the repository for provider %q; unsigned providers are not allowed in strict mode", checksumsSignatureFile, input.provider.ManifestLabel())
+		}
+		log.V(1).Info("Skipping signature verification, the provider does not publish a signature", "Provider", input.provider.ManifestLabel(), "Version", input.version)
+		return nil
+	}
+
+	keys, err := input.verificationClient.TrustedKeys()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		if strict {
+			return errors.Errorf("failed to verify the signature for provider %q: there are no trusted keys in the clusterctl config file", input.provider.ManifestLabel())
+		}
+		log.V(1).Info("Skipping signature verification, there are no trusted keys", "Provider", input.provider.ManifestLabel(), "Version", input.version)
+		return nil
+	}
+
+	key, err := verifySignature(keys, checksums, signature)
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify the signature of %q for provider %q", checksumsFile, input.provider.ManifestLabel())
+	}
+
+	log.V(5).Info("Verified signature", "Provider", input.provider.ManifestLabel(), "Version", input.version, "Key", key)
+	return nil
+}
+
+// getChecksum returns the checksum for a file from a checksums file in the sha256sum format.
+func getChecksum(checksums []byte, file string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// Nb. sha256sum prefixes file names with "*" when computing checksums in binary mode.
+		if path.Clean(strings.TrimPrefix(fields[1], "*")) == path.Clean(file) {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", errors.Wrapf(err, "failed to read %q", checksumsFile)
+	}
+	return "", errors.Errorf("%q is not listed in %q", file, checksumsFile)
+}
+
+// verifySignature verifies a detached signature with a set of trusted keys, returning the name of the key that
+// verified the signature. Signatures can be either raw or base64 encoded.
+func verifySignature(keys []config.TrustedKey, content, signature []byte) (string, error) {
+	signatures := [][]byte{signature}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signatures = append(signatures, decoded)
+	}
+
+	for _, k := range keys {
+		publicKey, err := parsePublicKey(k.Key)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid trusted key %q", k.Name)
+		}
+		for _, s := range signatures {
+			if verifyWithKey(publicKey, content, s) {
+				return k.Name, nil
+			}
+		}
+	}
+	return "", errors.New("the signature does not match any of the trusted keys")
+}
+
+// parsePublicKey parses a PEM encoded public key.
+func parsePublicKey(key string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("failed to decode the PEM block")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the public key")
+	}
+	return publicKey, nil
+}
+
+// verifyWithKey verifies a signature with a public key; RSA and ECDSA signatures are expected to be computed on the
+// SHA256 digest of the content.
+func verifyWithKey(publicKey crypto.PublicKey, content, signature []byte) bool {
+	digest := sha256.Sum256(content)
+
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		var s struct {
+			R, S *big.Int
+		}
+		if rest, err := asn1.Unmarshal(signature, &s); err != nil || len(rest) != 0 {
+			return false
+		}
+		return ecdsa.Verify(k, digest[:], s.R, s.S)
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, content, signature)
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// testSigner holds a key pair used for signing checksums in tests.
+type testSigner struct {
+	publicKey string
+	sign      func(content []byte) []byte
+}
+
+func newTestSigners(t *testing.T) map[string]testSigner {
+	g := NewWithT(t)
+
+	encode := func(publicKey crypto.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(publicKey)
+		g.Expect(err).NotTo(HaveOccurred())
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	g.Expect(err).NotTo(HaveOccurred())
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	ed25519PublicKey, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return map[string]testSigner{
+		"rsa": {
+			publicKey: encode(&rsaKey.PublicKey),
+			sign: func(content []byte) []byte {
+				digest := sha256.Sum256(content)
+				s, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+				g.Expect(err).NotTo(HaveOccurred())
+				return s
+			},
+		},
+		"ecdsa": {
+			publicKey: encode(&ecdsaKey.PublicKey),
+			sign: func(content []byte) []byte {
+				digest := sha256.Sum256(content)
+				s, err := ecdsaKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+				g.Expect(err).NotTo(HaveOccurred())
+				return s
+			},
+		},
+		"ed25519": {
+			publicKey: encode(ed25519PublicKey),
+			sign: func(content []byte) []byte {
+				return ed25519.Sign(ed25519Key, content)
+			},
+		},
+	}
+}
+
+func Test_verifyFile(t *testing.T) {
+	signers := newTestSigners(t)
+
+	content := []byte("components")
+	sum := sha256.Sum256(content)
+	checksums := []byte(fmt.Sprintf("%s  components.yaml\n%s  metadata.yaml\n", hex.EncodeToString(sum[:]), strings.Repeat("0", 64)))
+
+	verificationConfig := func(strict bool, keys ...string) string {
+		c := fmt.Sprintf("strict: %t\ntrustedKeys:\n", strict)
+		for _, k := range keys {
+			c += fmt.Sprintf("- name: %s\n  key: %q\n", k, signers[k].publicKey)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name         string
+		repository   Repository
+		verification string
+		path         string
+		wantErr      bool
+	}{
+		{
+			name:         "provider without checksums is accepted",
+			repository:   test.NewFakeRepository().WithVersions("v1.0.0"),
+			verification: verificationConfig(false),
+			path:         "components.yaml",
+			wantErr:      false,
+		},
+		{
+			name:         "provider without checksums is refused in strict mode",
+			repository:   test.NewFakeRepository().WithVersions("v1.0.0"),
+			verification: verificationConfig(true, "rsa"),
+			path:         "components.yaml",
+			wantErr:      true,
+		},
+		{
+			name:         "provider with checksums and without signature is accepted",
+			repository:   test.NewFakeRepository().WithFile("v1.0.0", checksumsFile, checksums),
+			verification: verificationConfig(false, "rsa"),
+			path:         "components.yaml",
+			wantErr:      false,
+		},
+		{
+			name:         "provider with checksums and without signature is refused in strict mode",
+			repository:   test.NewFakeRepository().WithFile("v1.0.0", checksumsFile, checksums),
+			verification: verificationConfig(true, "rsa"),
+			path:         "components.yaml",
+			wantErr:      true,
+		},
+		{
+			name:         "provider with invalid checksum is refused",
+			repository:   test.NewFakeRepository().WithFile("v1.0.0", checksumsFile, checksums),
+			verification: verificationConfig(false),
+			path:         "metadata.yaml",
+			wantErr:      true,
+		},
+		{
+			name:         "file not listed in checksums is refused",
+			repository:   test.NewFakeRepository().WithFile("v1.0.0", checksumsFile, checksums),
+			verification: verificationConfig(false),
+			path:         "other.yaml",
+			wantErr:      true,
+		},
+		{
+			name: "provider with a signature is accepted if there are no trusted keys",
+			repository: test.NewFakeRepository().
+				WithFile("v1.0.0", checksumsFile, checksums).
+				WithFile("v1.0.0", checksumsSignatureFile, signers["rsa"].sign(checksums)),
+			verification: verificationConfig(false),
+			path:         "components.yaml",
+			wantErr:      false,
+		},
+		{
+			name: "provider with a signature is refused in strict mode if there are no trusted keys",
+			repository: test.NewFakeRepository().
+				WithFile("v1.0.0", checksumsFile, checksums).
+				WithFile("v1.0.0", checksumsSignatureFile, signers["rsa"].sign(checksums)),
+			verification: verificationConfig(true),
+			path:         "components.yaml",
+			wantErr:      true,
+		},
+		{
+			name: "provider signed with a RSA key is accepted in strict mode",
+			repository: test.NewFakeRepository().
+				WithFile("v1.0.0", checksumsFile, checksums).
+				WithFile("v1.0.0", checksumsSignatureFile, signers["rsa"].sign(checksums)),
+			verification: verificationConfig(true, "ed25519", "rsa"),
+			path:         "components.yaml",
+			wantErr:      false,
+		},
+		{
+			name: "provider signed with an ECDSA key and a base64 encoded signature is accepted in strict mode",
+			repository: test.NewFakeRepository().
+				WithFile("v1.0.0", checksumsFile, checksums).
+				WithFile("v1.0.0", checksumsSignatureFile, []byte(base64.StdEncoding.EncodeToString(signers["ecdsa"].sign(checksums))+"\n")),
+			verification: verificationConfig(true, "ecdsa"),
+			path:         "components.yaml",
+			wantErr:      false,
+		},
+		{
+			name: "provider signed with an Ed25519 key is accepted in strict mode",
+			repository: test.NewFakeRepository().
+				WithFile("v1.0.0", checksumsFile, checksums).
+				WithFile("v1.0.0", checksumsSignatureFile, signers["ed25519"].sign(checksums)),
+			verification: verificationConfig(true, "ed25519"),
+			path:         "components.yaml",
+			wantErr:      false,
+		},
+		{
+			name: "provider signed with an untrusted key is refused",
+			repository: test.NewFakeRepository().
+				WithFile("v1.0.0", checksumsFile, checksums).
+				WithFile("v1.0.0", checksumsSignatureFile, signers["ecdsa"].sign(checksums)),
+			verification: verificationConfig(false, "rsa", "ed25519"),
+			path:         "components.yaml",
+			wantErr:      true,
+		},
+		{
+			name: "provider with tampered checksums is refused",
+			repository: test.NewFakeRepository().
+				WithFile("v1.0.0", checksumsFile, append(checksums, []byte("tampered")...)).
+				WithFile("v1.0.0", checksumsSignatureFile, signers["rsa"].sign(checksums)),
+			verification: verificationConfig(false, "rsa"),
+			path:         "components.yaml",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configClient, err := config.New("", config.InjectReader(test.NewFakeReader().WithVar("verification", tt.verification)))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			err = verifyFile(verifyFileInput{
+				provider:           config.NewProvider("p1", "", clusterctlv1.CoreProviderType),
+				repository:         tt.repository,
+				verificationClient: configClient.Verification(),
+				version:            "v1.0.0",
+				path:               tt.path,
+				content:            content,
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func Test_getChecksum(t *testing.T) {
+	g := NewWithT(t)
+
+	checksums := []byte("ABCD  components.yaml\n1234 *./metadata.yaml\ninvalid line\n")
+
+	got, err := getChecksum(checksums, "components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal("abcd"))
+
+	got, err = getChecksum(checksums, "metadata.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal("1234"))
+
+	_, err = getChecksum(checksums, "other.yaml")
+	g.Expect(err).To(HaveOccurred())
+}
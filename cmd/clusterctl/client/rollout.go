@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// RolloutOptions carries the options supported by RolloutHistory, RolloutPause, RolloutResume and RolloutRestart.
+type RolloutOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the MachineDeployment is located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// MachineDeployment is the name of the MachineDeployment.
+	MachineDeployment string
+}
+
+// RolloutStatusOptions carries the options supported by RolloutStatus.
+type RolloutStatusOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the MachineDeployment is located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// MachineDeployment is the name of the MachineDeployment.
+	MachineDeployment string
+
+	// Wait blocks until the rollout is complete, or until the MachineDeployment does not progress
+	// for more than its ProgressDeadlineSeconds.
+	Wait bool
+
+	// Timeout is the maximum time to wait for the rollout to complete. If zero, there is no timeout
+	// other than the progress deadline of the MachineDeployment.
+	Timeout time.Duration
+}
+
+// RolloutUndoOptions carries the options supported by RolloutUndo.
+type RolloutUndoOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the MachineDeployment is located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// MachineDeployment is the name of the MachineDeployment.
+	MachineDeployment string
+
+	// ToRevision is the revision to rollback to. If zero, the revision before the current one is restored.
+	ToRevision int64
+}
+
+func (c *clusterctlClient) RolloutStatus(options RolloutStatusOptions) (*RolloutStatus, error) {
+	rolloutClient, namespace, err := c.getRolloutClient(options.Kubeconfig, options.Namespace, options.MachineDeployment)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := rolloutClient.Status(namespace, options.MachineDeployment, cluster.RolloutStatusOptions{
+		Wait:    options.Wait,
+		Timeout: options.Timeout,
+	})
+	// Nb. the status is returned also in case of errors, so the caller can report the last known status of the rollout.
+	return (*RolloutStatus)(status), err
+}
+
+func (c *clusterctlClient) RolloutHistory(options RolloutOptions) ([]RolloutRevision, error) {
+	rolloutClient, namespace, err := c.getRolloutClient(options.Kubeconfig, options.Namespace, options.MachineDeployment)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := rolloutClient.History(namespace, options.MachineDeployment)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]RolloutRevision, 0, len(revisions))
+	for _, r := range revisions {
+		ret = append(ret, RolloutRevision(r))
+	}
+	return ret, nil
+}
+
+func (c *clusterctlClient) RolloutUndo(options RolloutUndoOptions) error {
+	if options.ToRevision < 0 {
+		return errors.New("the revision to rollback to must be a positive number")
+	}
+
+	rolloutClient, namespace, err := c.getRolloutClient(options.Kubeconfig, options.Namespace, options.MachineDeployment)
+	if err != nil {
+		return err
+	}
+
+	return rolloutClient.Undo(namespace, options.MachineDeployment, options.ToRevision)
+}
+
+func (c *clusterctlClient) RolloutPause(options RolloutOptions) error {
+	rolloutClient, namespace, err := c.getRolloutClient(options.Kubeconfig, options.Namespace, options.MachineDeployment)
+	if err != nil {
+		return err
+	}
+
+	return rolloutClient.Pause(namespace, options.MachineDeployment)
+}
+
+func (c *clusterctlClient) RolloutResume(options RolloutOptions) error {
+	rolloutClient, namespace, err := c.getRolloutClient(options.Kubeconfig, options.Namespace, options.MachineDeployment)
+	if err != nil {
+		return err
+	}
+
+	return rolloutClient.Resume(namespace, options.MachineDeployment)
+}
+
+func (c *clusterctlClient) RolloutRestart(options RolloutOptions) error {
+	rolloutClient, namespace, err := c.getRolloutClient(options.Kubeconfig, options.Namespace, options.MachineDeployment)
+	if err != nil {
+		return err
+	}
+
+	return rolloutClient.Restart(namespace, options.MachineDeployment)
+}
+
+// getRolloutClient returns the RolloutClient for the management cluster, and the namespace where the MachineDeployment is located.
+func (c *clusterctlClient) getRolloutClient(kubeconfig Kubeconfig, namespace, machineDeployment string) (cluster.RolloutClient, string, error) {
+	if machineDeployment == "" {
+		return nil, "", errors.New("the name of the MachineDeployment must be specified")
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: kubeconfig})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, "", err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, "", err
+		}
+		namespace = currentNamespace
+	}
+
+	return clusterClient.Rollout(), namespace, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_clusterctlClient_RolloutPause(t *testing.T) {
+	tests := []struct {
+		name    string
+		options RolloutOptions
+		wantErr bool
+	}{
+		{
+			name: "returns an error if the MachineDeployment name is not specified",
+			options: RolloutOptions{
+				Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if the cluster client is not found",
+			options: RolloutOptions{
+				Kubeconfig:        Kubeconfig{Path: "kubeconfig", Context: "does-not-exist"},
+				MachineDeployment: "md1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "pauses the MachineDeployment",
+			options: RolloutOptions{
+				Kubeconfig:        Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Namespace:         "ns1",
+				MachineDeployment: "md1",
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := fakeClientForRollout().RolloutPause(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func fakeClientForRollout() *fakeClient {
+	core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
+
+	config1 := newFakeConfig().
+		WithProvider(core)
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+		WithProviderInventory(core.Name(), core.Type(), "v1.0.0", "cluster-api-system", "").
+		WithObjs(test.NewFakeCluster("ns1", "cluster1").
+			WithMachineDeployments(test.NewFakeMachineDeployment("md1")).
+			Objs()...)
+
+	return newFakeClient(config1).
+		WithCluster(cluster1)
+}
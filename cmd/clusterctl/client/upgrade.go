@@ -61,6 +61,61 @@ func (c *clusterctlClient) PlanUpgrade(options PlanUpgradeOptions) ([]UpgradePla
 	return aliasUpgradePlan, nil
 }
 
+func (c *clusterctlClient) PlanCertManagerUpgrade(options PlanUpgradeOptions) (CertManagerUpgradePlan, error) {
+	// Get the client for interacting with the management cluster.
+	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return CertManagerUpgradePlan{}, err
+	}
+
+	plan, err := cluster.CertManager().PlanUpgrade()
+	return CertManagerUpgradePlan(plan), err
+}
+
+// DiffUpgradeOptions carries the options supported by upgrade plan --diff.
+type DiffUpgradeOptions struct {
+	// Kubeconfig to use for accessing the management cluster. If empty, default discovery rules apply.
+	Kubeconfig Kubeconfig
+
+	// ManagementGroup whose upgrade plan should be compared (e.g. capi-system/cluster-api).
+	ManagementGroup string
+
+	// Contract defines the API Version of Cluster API (contract e.g. v1alpha3) of the upgrade plan to be compared.
+	Contract string
+}
+
+func (c *clusterctlClient) DiffUpgrade(options DiffUpgradeOptions) ([]ComponentsDiff, error) {
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, err
+	}
+
+	// The management group name is derived from the core provider name, so now
+	// convert the reference back into a coreProvider.
+	coreUpgradeItem, err := parseUpgradeItem(options.ManagementGroup, clusterctlv1.CoreProviderType)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := clusterClient.ProviderUpgrader().DiffPlan(coreUpgradeItem.Provider, options.Contract)
+	if err != nil {
+		return nil, err
+	}
+
+	// ComponentsDiff is an alias for cluster.ComponentsDiff; this makes the conversion
+	aliasDiffs := make([]ComponentsDiff, len(diffs))
+	for i, diff := range diffs {
+		aliasDiffs[i] = ComponentsDiff(diff)
+	}
+	return aliasDiffs, nil
+}
+
 // ApplyUpgradeOptions carries the options supported by upgrade apply.
 type ApplyUpgradeOptions struct {
 	// Kubeconfig to use for accessing the management cluster. If empty, default discovery rules apply.
@@ -107,6 +162,13 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 	}
 	coreProvider := coreUpgradeItem.Provider
 
+	// Ensures the latest version of cert-manager is installed before upgrading the providers,
+	// because new provider versions might rely on it.
+	// Nb. this is a no-op if cert-manager is externally managed or already up to date.
+	if err := clusterClient.CertManager().EnsureLatestVersion(); err != nil {
+		return err
+	}
+
 	// Check if the user want a custom upgrade
 	isCustomUpgrade := options.CoreProvider != "" ||
 		len(options.BootstrapProviders) > 0 ||
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// ClusterUpgradeOptions carries the options supported by PlanClusterUpgrade and ApplyClusterUpgrade.
+type ClusterUpgradeOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload cluster is located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// ClusterName is the name of the workload cluster to upgrade.
+	ClusterName string
+
+	// KubernetesVersion is the target Kubernetes version.
+	KubernetesVersion string
+
+	// MachineDeploymentOrder defines the order in which MachineDeployments are upgraded; MachineDeployments
+	// not included in the list are upgraded afterwards, in alphabetical order.
+	MachineDeploymentOrder []string
+
+	// Timeout is the maximum time to wait for the control plane or for each MachineDeployment to be upgraded.
+	// If zero, a default timeout is used.
+	Timeout time.Duration
+}
+
+func (c *clusterctlClient) PlanClusterUpgrade(options ClusterUpgradeOptions) (*KubernetesUpgradePlan, error) {
+	upgrader, err := c.getKubernetesUpgrader(&options)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := upgrader.Plan(options.Namespace, options.ClusterName, options.KubernetesVersion, cluster.KubernetesUpgradeOptions{
+		MachineDeploymentOrder: options.MachineDeploymentOrder,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*KubernetesUpgradePlan)(plan), nil
+}
+
+func (c *clusterctlClient) ApplyClusterUpgrade(options ClusterUpgradeOptions) error {
+	upgrader, err := c.getKubernetesUpgrader(&options)
+	if err != nil {
+		return err
+	}
+
+	upgradeOptions := cluster.KubernetesUpgradeOptions{
+		MachineDeploymentOrder: options.MachineDeploymentOrder,
+		Timeout:                options.Timeout,
+	}
+
+	// Always re-compute the plan, so the upgrade is validated against the current state of the cluster.
+	plan, err := upgrader.Plan(options.Namespace, options.ClusterName, options.KubernetesVersion, upgradeOptions)
+	if err != nil {
+		return err
+	}
+	return upgrader.Apply(plan, upgradeOptions)
+}
+
+// getKubernetesUpgrader returns the KubernetesUpgrader for the management cluster, defaulting the namespace if not specified.
+func (c *clusterctlClient) getKubernetesUpgrader(options *ClusterUpgradeOptions) (cluster.KubernetesUpgrader, error) {
+	if options.ClusterName == "" {
+		return nil, errors.New("the name of the cluster to upgrade must be specified")
+	}
+	if options.KubernetesVersion == "" {
+		return nil, errors.New("the target Kubernetes version must be specified")
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	return clusterClient.KubernetesUpgrader(), nil
+}
@@ -249,6 +249,47 @@ func Test_clusterctlClient_ApplyUpgrade(t *testing.T) {
 	}
 }
 
+func Test_clusterctlClient_CertManagerUpgrade(t *testing.T) {
+	tests := []struct {
+		name         string
+		plan         cluster.CertManagerUpgradePlan
+		wantUpgraded bool
+	}{
+		{
+			name:         "upgrades an outdated cert-manager",
+			plan:         cluster.CertManagerUpgradePlan{Installed: true, From: "v0.10.0", To: "v0.11.0", ShouldUpgrade: true},
+			wantUpgraded: true,
+		},
+		{
+			name:         "does not upgrade an externally managed cert-manager",
+			plan:         cluster.CertManagerUpgradePlan{Installed: true, ExternallyManaged: true, To: "v0.11.0"},
+			wantUpgraded: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			client := fakeClientForUpgrade()
+			kubeconfig := Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}
+			certManager := newFakeCertManagerClient(nil, nil).WithUpgradePlan(tt.plan)
+			client.clusters[cluster.Kubeconfig(kubeconfig)].(*fakeClusterClient).WithCertManagerClient(certManager)
+
+			gotPlan, err := client.PlanCertManagerUpgrade(PlanUpgradeOptions{Kubeconfig: kubeconfig})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gotPlan).To(Equal(CertManagerUpgradePlan(tt.plan)))
+
+			err = client.ApplyUpgrade(ApplyUpgradeOptions{
+				Kubeconfig:      kubeconfig,
+				ManagementGroup: "cluster-api-system/cluster-api",
+				Contract:        "v1alpha3",
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(certManager.upgraded).To(Equal(tt.wantUpgraded))
+		})
+	}
+}
+
 func fakeClientForUpgrade() *fakeClient {
 	core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
 	infra := config.NewProvider("infra", "https://somewhere.com", clusterctlv1.InfrastructureProviderType)
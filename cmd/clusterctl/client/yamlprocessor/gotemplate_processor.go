@@ -0,0 +1,304 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// GoTemplateProcessor is a yaml processor that uses Go text/template for processing templates;
+// template variables are accessed as fields of the template data, e.g. {{ .CLUSTER_NAME }}, or
+// {{ $.CLUSTER_NAME }} when the dot is changed by range or with actions.
+// Variable values are always strings.
+//
+// Conditionals and loops are supported using the text/template actions, e.g.
+//
+//	{{ if .ENABLE_BASTION }}...{{ end }}
+//	{{ range $i, $fd := split "," .FAILURE_DOMAINS }}...{{ end }}
+//
+// Templates can use only the functions built into text/template and the following functions:
+// default, required, toYaml, indent, nindent, b64enc, split and until.
+// See https://golang.org/pkg/text/template/ for more details.
+type GoTemplateProcessor struct{}
+
+var _ Processor = &GoTemplateProcessor{}
+
+// maxUntilCount is the maximum count accepted by the until function, so a wrong variable value cannot make
+// clusterctl allocate an unbounded amount of memory.
+const maxUntilCount = 10000
+
+func NewGoTemplateProcessor() *GoTemplateProcessor {
+	return &GoTemplateProcessor{}
+}
+
+// GetTemplateName returns the name of the template that the go template processor
+// uses. It follows the cluster template naming convention of
+// "cluster-template<-flavor>.yaml".
+func (tp *GoTemplateProcessor) GetTemplateName(_, flavor string) string {
+	name := "cluster-template"
+	if flavor != "" {
+		name = fmt.Sprintf("%s-%s", name, flavor)
+	}
+	name = fmt.Sprintf("%s.yaml", name)
+
+	return name
+}
+
+// GetVariables returns a list of the variables referenced in the template.
+func (tp *GoTemplateProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
+	t, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := inspectGoTemplateVariables(t)
+
+	varNames := make([]string, 0, len(variables))
+	for k := range variables {
+		varNames = append(varNames, k)
+	}
+	sort.Strings(varNames)
+	return varNames, nil
+}
+
+// Process returns the final yaml obtained by executing the template with the variable values.
+// Variables used only as an argument of the default and required functions, or as the condition
+// of if and with actions are optional; if there are other variables without corresponding values,
+// it will return the raw yaml along with an error.
+func (tp *GoTemplateProcessor) Process(rawArtifact []byte, variablesClient func(string) (string, error)) ([]byte, error) {
+	t, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return rawArtifact, err
+	}
+
+	data := map[string]interface{}{}
+	var missingVariables []string
+	for name, optional := range inspectGoTemplateVariables(t) {
+		v, err := variablesClient(name)
+		if err != nil {
+			// add to missingVariables list if the variable does not exist in the
+			// variablesClient AND it is not optional; optional variables default to empty.
+			if !optional {
+				missingVariables = append(missingVariables, name)
+			}
+			data[name] = ""
+			continue
+		}
+		data[name] = v
+	}
+
+	if len(missingVariables) > 0 {
+		return rawArtifact, &errMissingVariables{missingVariables}
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return rawArtifact, errors.Wrap(err, "failed to execute the template")
+	}
+	return out.Bytes(), nil
+}
+
+// parseGoTemplate parses a template, with missing keys treated as errors at execution time.
+func parseGoTemplate(rawArtifact []byte) (*template.Template, error) {
+	t, err := template.New("template").Option("missingkey=error").Funcs(goTemplateFuncs()).Parse(string(rawArtifact))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the template")
+	}
+	return t, nil
+}
+
+// inspectGoTemplateVariables walks through the parse trees of a template and returns a map of the variable
+// names and if they are optional, that is if all the references to the variable are guarded by the default
+// or required functions, or by if or with actions.
+func inspectGoTemplateVariables(t *template.Template) map[string]bool {
+	variables := map[string]bool{}
+	for _, tt := range t.Templates() {
+		if tt.Tree == nil || tt.Tree.Root == nil {
+			continue
+		}
+		traverseGoTemplate(tt.Tree.Root, true, false, variables)
+	}
+	return variables
+}
+
+// traverseGoTemplate recursively walks down a node and tracks the variables.
+// rootDot is true if the dot refers to the template data (it changes inside range and with actions),
+// guarded is true if the node is an argument of the default or required functions, or the condition of
+// if and with actions.
+func traverseGoTemplate(node parse.Node, rootDot, guarded bool, variables map[string]bool) {
+	addVariable := func(name string) {
+		if optional, ok := variables[name]; ok {
+			variables[name] = optional && guarded
+			return
+		}
+		variables[name] = guarded
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, ln := range n.Nodes {
+			traverseGoTemplate(ln, rootDot, guarded, variables)
+		}
+	case *parse.ActionNode:
+		traverseGoTemplate(n.Pipe, rootDot, guarded, variables)
+	case *parse.IfNode:
+		traverseGoTemplate(n.Pipe, rootDot, true, variables)
+		traverseGoTemplate(n.List, rootDot, guarded, variables)
+		traverseGoTemplate(n.ElseList, rootDot, guarded, variables)
+	case *parse.WithNode:
+		traverseGoTemplate(n.Pipe, rootDot, true, variables)
+		traverseGoTemplate(n.List, false, guarded, variables)
+		traverseGoTemplate(n.ElseList, rootDot, guarded, variables)
+	case *parse.RangeNode:
+		traverseGoTemplate(n.Pipe, rootDot, guarded, variables)
+		traverseGoTemplate(n.List, false, guarded, variables)
+		traverseGoTemplate(n.ElseList, rootDot, guarded, variables)
+	case *parse.TemplateNode:
+		traverseGoTemplate(n.Pipe, rootDot, guarded, variables)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		// If any command in the pipeline is a call to default or required, all the variables in the pipeline are guarded.
+		pipeGuarded := guarded
+		for _, c := range n.Cmds {
+			if len(c.Args) > 0 {
+				if id, ok := c.Args[0].(*parse.IdentifierNode); ok && (id.Ident == "default" || id.Ident == "required") {
+					pipeGuarded = true
+				}
+			}
+		}
+		for _, c := range n.Cmds {
+			traverseGoTemplate(c, rootDot, pipeGuarded, variables)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			traverseGoTemplate(a, rootDot, guarded, variables)
+		}
+	case *parse.ChainNode:
+		traverseGoTemplate(n.Node, rootDot, guarded, variables)
+	case *parse.FieldNode:
+		// e.g. .CLUSTER_NAME
+		if rootDot && len(n.Ident) > 0 {
+			addVariable(n.Ident[0])
+		}
+	case *parse.VariableNode:
+		// e.g. $.CLUSTER_NAME
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			addVariable(n.Ident[1])
+		}
+	}
+}
+
+// goTemplateFuncs returns the functions available in templates processed by the GoTemplateProcessor.
+// Only functions without side effects are allowed.
+func goTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// default returns the default value if the given value is empty, e.g. {{ .REPLICAS | default "1" }}.
+		"default": func(defaultValue interface{}, value ...interface{}) interface{} {
+			if len(value) == 0 || isEmptyValue(value[0]) {
+				return defaultValue
+			}
+			return value[0]
+		},
+		// required fails with the given message if the value is empty, e.g. {{ required "REGION is required" .REGION }}.
+		"required": func(message string, value interface{}) (interface{}, error) {
+			if isEmptyValue(value) {
+				return nil, errors.New(message)
+			}
+			return value, nil
+		},
+		// toYaml returns the yaml representation of a value.
+		"toYaml": func(value interface{}) (string, error) {
+			b, err := yaml.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(string(b), "\n"), nil
+		},
+		// indent indents each line of a string by the given number of spaces.
+		"indent": indent,
+		// nindent is like indent, but it prepends a new line.
+		"nindent": func(spaces int, s string) string {
+			return "\n" + indent(spaces, s)
+		},
+		// b64enc returns the base64 encoding of a string.
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		// split returns the list of non empty items in a string separated by sep, e.g. {{ range split "," .FAILURE_DOMAINS }}.
+		"split": func(sep, s string) []string {
+			ret := []string{}
+			for _, item := range strings.Split(s, sep) {
+				if item = strings.TrimSpace(item); item != "" {
+					ret = append(ret, item)
+				}
+			}
+			return ret
+		},
+		// until returns the list of integers from 0 to count-1, e.g. {{ range $i := until .MACHINE_DEPLOYMENT_COUNT }};
+		// count cannot be greater than maxUntilCount.
+		"until": func(count interface{}) ([]int, error) {
+			n, err := strconv.Atoi(fmt.Sprint(count))
+			if err != nil {
+				return nil, errors.Errorf("invalid count %q", count)
+			}
+			if n > maxUntilCount {
+				return nil, errors.Errorf("invalid count %d: count must not be greater than %d", n, maxUntilCount)
+			}
+			ret := []int{}
+			for i := 0; i < n; i++ {
+				ret = append(ret, i)
+			}
+			return ret, nil
+		},
+	}
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.Replace(s, "\n", "\n"+pad, -1)
+}
+
+// isEmptyValue returns true if a value is nil or the zero value for its type.
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func TestGoTemplateProcessor_GetTemplateName(t *testing.T) {
+	g := NewWithT(t)
+	p := NewGoTemplateProcessor()
+	g.Expect(p.GetTemplateName("some-version", "some-flavor")).To(Equal("cluster-template-some-flavor.yaml"))
+	g.Expect(p.GetTemplateName("", "")).To(Equal("cluster-template.yaml"))
+}
+
+func TestGoTemplateProcessor_GetVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "variable with different spacing around the name",
+			data: "yaml with {{.A}} {{ .B}} {{ .C }} {{ .D }}",
+			want: []string{"A", "B", "C", "D"},
+		},
+		{
+			name: "variables used in functions, conditionals and loops",
+			data: `{{ if .ENABLED }}{{ .A | default "a" }}{{ end }}` +
+				`{{ range $i, $fd := split "," .FAILURE_DOMAINS }}{{ $.CLUSTER_NAME }}-{{ $fd }}{{ .IGNORED }}{{ end }}` +
+				`{{ with .WITH }}{{ .IGNORED }}{{ end }}{{ b64enc (required "B is required" .B) }}`,
+			want: []string{"A", "B", "CLUSTER_NAME", "ENABLED", "FAILURE_DOMAINS", "WITH"},
+		},
+		{
+			name:    "returns error for invalid templates",
+			data:    "yaml with {{ .A ",
+			wantErr: true,
+		},
+		{
+			name:    "returns error for unknown functions",
+			data:    "yaml with {{ exec .A }}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+
+			actual, err := p.GetVariables([]byte(tt.data))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestGoTemplateProcessor_Process(t *testing.T) {
+	type args struct {
+		yaml                  []byte
+		configVariablesClient config.VariablesClient
+	}
+	tests := []struct {
+		name             string
+		args             args
+		want             []byte
+		wantErr          bool
+		missingVariables []string
+	}{
+		{
+			name: "replaces variables",
+			args: args{
+				yaml: []byte("foo {{ .BAR }}, {{ $.BAR }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "ba$r"),
+			},
+			want:    []byte("foo ba$r, ba$r"),
+			wantErr: false,
+		},
+		{
+			name: "uses default values if variable doesn't exist in variables client or it is empty",
+			args: args{
+				yaml: []byte(`foo {{ .BAR | default "default_bar" }} {{ default "default_baz" .BAZ }} {{ .CAZ | default "default_caz" }}`),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "bar").WithVar("CAZ", ""),
+			},
+			want:    []byte("foo bar default_baz default_caz"),
+			wantErr: false,
+		},
+		{
+			name: "supports conditionals",
+			args: args{
+				yaml: []byte("{{ if .BAR }}bar{{ else }}no bar{{ end }}, {{ if .BAZ }}baz{{ else }}no baz{{ end }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "true"),
+			},
+			want:    []byte("bar, no baz"),
+			wantErr: false,
+		},
+		{
+			name: "supports loops",
+			args: args{
+				yaml: []byte(`{{ range $i, $fd := split "," .FAILURE_DOMAINS }}- name: {{ $.CLUSTER_NAME }}-md-{{ $i }}` + "\n" + `  failureDomain: {{ $fd }}` + "\n" + `{{ end }}` +
+					`{{ range until .COUNT }}{{ . }}{{ end }}`),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("CLUSTER_NAME", "foo").WithVar("FAILURE_DOMAINS", "fd1, fd2").WithVar("COUNT", "3"),
+			},
+			want:    []byte("- name: foo-md-0\n  failureDomain: fd1\n- name: foo-md-1\n  failureDomain: fd2\n012"),
+			wantErr: false,
+		},
+		{
+			name: "returns error if the until count is too big",
+			args: args{
+				yaml: []byte(`{{ range until .COUNT }}{{ . }}{{ end }}`),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("COUNT", "10001"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "supports yaml and encoding functions",
+			args: args{
+				yaml: []byte("data:{{ .FOO | toYaml | nindent 2 }}\nvalue: {{ b64enc .FOO }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("FOO", "foo: bar"),
+			},
+			want:    []byte("data:\n  'foo: bar'\nvalue: Zm9vOiBiYXI="),
+			wantErr: false,
+		},
+		{
+			name: "returns error with missing template variables listed (for better ux)",
+			args: args{
+				yaml: []byte("foo {{ .BAR }} {{ .BAZ }} {{ .CAR }} {{ if .DAR }}{{ end }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("CAR", "car"),
+			},
+			want:             nil,
+			wantErr:          true,
+			missingVariables: []string{"BAR", "BAZ"},
+		},
+		{
+			name: "returns error with the required message",
+			args: args{
+				yaml:                  []byte(`foo {{ required "BAR must be set" .BAR }}`),
+				configVariablesClient: test.NewFakeVariableClient(),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+
+			got, err := p.Process(tt.args.yaml, tt.args.configVariablesClient.Get)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				if len(tt.missingVariables) != 0 {
+					e, ok := err.(*errMissingVariables)
+					g.Expect(ok).To(BeTrue())
+					g.Expect(e.Missing).To(ConsistOf(tt.missingVariables))
+				}
+				// we want to ensure that we keep returning the original yaml
+				// as per the intended behavior of Process
+				g.Expect(got).To(Equal(tt.args.yaml))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(string(got)).To(Equal(string(tt.want)))
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+// VariableType defines the type of the values accepted by a template variable.
+type VariableType string
+
+const (
+	// StringVariableType accepts any value.
+	StringVariableType = VariableType("string")
+
+	// IntVariableType accepts integer values, e.g. 3.
+	IntVariableType = VariableType("int")
+
+	// CIDRVariableType accepts IP addresses ranges in CIDR notation, e.g. 192.168.0.0/16.
+	CIDRVariableType = VariableType("cidr")
+
+	// KubernetesVersionVariableType accepts Kubernetes versions in semantic version format, e.g. v1.18.2.
+	KubernetesVersionVariableType = VariableType("kubernetesVersion")
+)
+
+// VariableSpec describes a template variable.
+type VariableSpec struct {
+	// Description explains the meaning of the variable.
+	Description string `json:"description,omitempty"`
+
+	// Type of the values accepted by the variable. If empty, string is assumed.
+	Type VariableType `json:"type,omitempty"`
+
+	// Default value for the variable, used when the value is not set in the environment or in the
+	// clusterctl config file. The default defined in the schema takes precedence over default values
+	// defined in the template using the ${VAR:=default} syntax.
+	Default *string `json:"default,omitempty"`
+
+	// Enum lists the values accepted by the variable. If empty, any value of the given type is accepted.
+	Enum []string `json:"enum,omitempty"`
+
+	// Required variables must be set in the environment or in the clusterctl config file; defaults
+	// are ignored for required variables.
+	Required bool `json:"required,omitempty"`
+}
+
+// VariablesSchema describes the variables of a template.
+type VariablesSchema struct {
+	// Variables defines the spec for each template variable, indexed by the variable name.
+	// Template variables not included in the schema are treated as variables of type string.
+	Variables map[string]VariableSpec `json:"variables"`
+}
+
+// VariablesSchemaName returns the name of the file defining the variables schema for a template,
+// derived by the name of the template file, e.g. cluster-template-dev.variables.yaml for cluster-template-dev.yaml.
+func VariablesSchemaName(templateName string) string {
+	return fmt.Sprintf("%s.variables.yaml", strings.TrimSuffix(templateName, filepath.Ext(templateName)))
+}
+
+// ParseVariablesSchema parses and validates a variables schema.
+func ParseVariablesSchema(rawSchema []byte) (*VariablesSchema, error) {
+	schema := &VariablesSchema{}
+	if err := yaml.UnmarshalStrict(rawSchema, schema); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the variables schema")
+	}
+
+	var errList []error
+	for _, name := range schema.names() {
+		spec := schema.Variables[name]
+		switch spec.Type {
+		case "", StringVariableType, IntVariableType, CIDRVariableType, KubernetesVersionVariableType:
+		default:
+			errList = append(errList, errors.Errorf("invalid type %q for variable %s", spec.Type, name))
+			continue
+		}
+		for _, e := range spec.Enum {
+			if err := spec.validateType(e); err != nil {
+				errList = append(errList, errors.Wrapf(err, "invalid enum value for variable %s", name))
+			}
+		}
+		if spec.Default != nil {
+			if err := spec.validate(*spec.Default); err != nil {
+				errList = append(errList, errors.Wrapf(err, "invalid default value for variable %s", name))
+			}
+		}
+	}
+	if len(errList) > 0 {
+		return nil, errors.Wrap(kerrors.NewAggregate(errList), "invalid variables schema")
+	}
+	return schema, nil
+}
+
+// Get returns the spec for a variable, if defined in the schema.
+func (s *VariablesSchema) Get(name string) (VariableSpec, bool) {
+	if s == nil {
+		return VariableSpec{}, false
+	}
+	spec, ok := s.Variables[name]
+	return spec, ok
+}
+
+// WithDefaults wraps a function returning variable values, so the default values defined in the schema
+// are returned for variables without a value.
+func (s *VariablesSchema) WithDefaults(values func(string) (string, error)) func(string) (string, error) {
+	return func(name string) (string, error) {
+		v, err := values(name)
+		if err == nil {
+			return v, nil
+		}
+		if spec, ok := s.Get(name); ok && spec.Default != nil && !spec.Required {
+			return *spec.Default, nil
+		}
+		return v, err
+	}
+}
+
+// Validate checks the values of the given variables against the schema; variables without a value
+// or not included in the schema are ignored, except for required variables that must have a value.
+func (s *VariablesSchema) Validate(variables []string, values func(string) (string, error)) error {
+	if s == nil {
+		return nil
+	}
+
+	var errList []error
+	for _, name := range variables {
+		spec, ok := s.Get(name)
+		if !ok {
+			continue
+		}
+
+		v, err := values(name)
+		if err != nil {
+			if spec.Required {
+				errList = append(errList, errors.Errorf("value for variable %s is required", name))
+			}
+			continue
+		}
+
+		if err := spec.validate(v); err != nil {
+			errList = append(errList, errors.Wrapf(err, "invalid value for variable %s", name))
+		}
+	}
+	if len(errList) > 0 {
+		return errors.Wrap(kerrors.NewAggregate(errList), "invalid template variables")
+	}
+	return nil
+}
+
+// names returns the names of the variables in the schema, sorted alphabetically.
+func (s *VariablesSchema) names() []string {
+	names := make([]string, 0, len(s.Variables))
+	for name := range s.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validate checks a value against the variable type and enum.
+func (v VariableSpec) validate(value string) error {
+	if err := v.validateType(value); err != nil {
+		return err
+	}
+	if len(v.Enum) == 0 {
+		return nil
+	}
+	for _, e := range v.Enum {
+		if value == e {
+			return nil
+		}
+	}
+	return errors.Errorf("%q is not one of the allowed values [%s]", value, strings.Join(v.Enum, ", "))
+}
+
+// validateType checks a value against the variable type.
+func (v VariableSpec) validateType(value string) error {
+	switch v.Type {
+	case IntVariableType:
+		if _, err := strconv.Atoi(value); err != nil {
+			return errors.Errorf("%q is not an integer", value)
+		}
+	case CIDRVariableType:
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return errors.Errorf("%q is not a valid CIDR", value)
+		}
+	case KubernetesVersionVariableType:
+		if _, err := version.ParseSemantic(value); err != nil || !strings.HasPrefix(value, "v") {
+			return errors.Errorf("%q is not a valid Kubernetes version, e.g. v1.18.2", value)
+		}
+	}
+	return nil
+}
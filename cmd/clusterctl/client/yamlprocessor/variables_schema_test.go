@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/utils/pointer"
+)
+
+func TestVariablesSchemaName(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(VariablesSchemaName("cluster-template.yaml")).To(Equal("cluster-template.variables.yaml"))
+	g.Expect(VariablesSchemaName("cluster-template-prod.yaml")).To(Equal("cluster-template-prod.variables.yaml"))
+}
+
+func TestParseVariablesSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		want    map[string]VariableSpec
+		wantErr bool
+	}{
+		{
+			name: "valid schema",
+			schema: `variables:
+  KUBERNETES_VERSION:
+    description: The Kubernetes version.
+    type: kubernetesVersion
+    required: true
+  POD_CIDR:
+    type: cidr
+    default: 192.168.0.0/16
+  SIZE:
+    enum: [small, large]
+    default: small
+`,
+			want: map[string]VariableSpec{
+				"KUBERNETES_VERSION": {Description: "The Kubernetes version.", Type: KubernetesVersionVariableType, Required: true},
+				"POD_CIDR":           {Type: CIDRVariableType, Default: pointer.StringPtr("192.168.0.0/16")},
+				"SIZE":               {Enum: []string{"small", "large"}, Default: pointer.StringPtr("small")},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "fails for unknown fields",
+			schema:  "variables:\n  FOO:\n    format: bar\n",
+			wantErr: true,
+		},
+		{
+			name:    "fails for unknown types",
+			schema:  "variables:\n  FOO:\n    type: float\n",
+			wantErr: true,
+		},
+		{
+			name:    "fails for default values not matching the type",
+			schema:  "variables:\n  FOO:\n    type: int\n    default: bar\n",
+			wantErr: true,
+		},
+		{
+			name:    "fails for default values not included in the enum",
+			schema:  "variables:\n  FOO:\n    enum: [bar]\n    default: baz\n",
+			wantErr: true,
+		},
+		{
+			name:    "fails for enum values not matching the type",
+			schema:  "variables:\n  FOO:\n    type: int\n    enum: [bar]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := ParseVariablesSchema([]byte(tt.schema))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got.Variables).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestVariablesSchema_Validate(t *testing.T) {
+	schema := &VariablesSchema{
+		Variables: map[string]VariableSpec{
+			"KUBERNETES_VERSION": {Type: KubernetesVersionVariableType, Required: true, Default: pointer.StringPtr("v1.18.2")},
+			"REPLICAS":           {Type: IntVariableType, Default: pointer.StringPtr("1")},
+			"POD_CIDR":           {Type: CIDRVariableType},
+			"SIZE":               {Enum: []string{"small", "large"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "pass with valid values",
+			values:  map[string]string{"KUBERNETES_VERSION": "v1.18.2", "REPLICAS": "3", "POD_CIDR": "192.168.0.0/16", "SIZE": "large"},
+			wantErr: false,
+		},
+		{
+			name:    "pass if only required values are set",
+			values:  map[string]string{"KUBERNETES_VERSION": "v1.18.2"},
+			wantErr: false,
+		},
+		{
+			name:    "fails if required values are missing, even if there is a default",
+			values:  map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "fails for invalid int",
+			values:  map[string]string{"KUBERNETES_VERSION": "v1.18.2", "REPLICAS": "three"},
+			wantErr: true,
+		},
+		{
+			name:    "fails for invalid CIDR",
+			values:  map[string]string{"KUBERNETES_VERSION": "v1.18.2", "POD_CIDR": "192.168.0.0"},
+			wantErr: true,
+		},
+		{
+			name:    "fails for invalid Kubernetes version",
+			values:  map[string]string{"KUBERNETES_VERSION": "1.18"},
+			wantErr: true,
+		},
+		{
+			name:    "fails for values not included in the enum",
+			values:  map[string]string{"KUBERNETES_VERSION": "v1.18.2", "SIZE": "medium"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			values := func(name string) (string, error) {
+				if v, ok := tt.values[name]; ok {
+					return v, nil
+				}
+				return "", errors.Errorf("value for variable %q is not set", name)
+			}
+
+			err := schema.Validate([]string{"KUBERNETES_VERSION", "REPLICAS", "POD_CIDR", "SIZE", "NOT_IN_SCHEMA"}, values)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func TestVariablesSchema_WithDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	schema := &VariablesSchema{
+		Variables: map[string]VariableSpec{
+			"KUBERNETES_VERSION": {Required: true, Default: pointer.StringPtr("v1.18.2")},
+			"REPLICAS":           {Default: pointer.StringPtr("1")},
+		},
+	}
+	values := schema.WithDefaults(func(name string) (string, error) {
+		if name == "CLUSTER_NAME" {
+			return "foo", nil
+		}
+		return "", errors.Errorf("value for variable %q is not set", name)
+	})
+
+	g.Expect(values("CLUSTER_NAME")).To(Equal("foo"))
+	g.Expect(values("REPLICAS")).To(Equal("1"))
+
+	// Defaults are ignored for required variables.
+	_, err := values("KUBERNETES_VERSION")
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = values("NOT_IN_SCHEMA")
+	g.Expect(err).To(HaveOccurred())
+}
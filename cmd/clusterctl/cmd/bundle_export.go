@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type bundleExportOptions struct {
+	coreProvider            string
+	bootstrapProviders      []string
+	controlPlaneProviders   []string
+	infrastructureProviders []string
+	flavors                 []string
+	file                    string
+}
+
+var beo = &bundleExportOptions{}
+
+var bundleExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a bundle with all the artifacts required for installing providers in air-gapped environments.",
+	Long: LongDesc(`
+		Export a bundle with all the artifacts required for installing providers in air-gapped environments.
+
+		The bundle is a tarball containing the components YAML, the metadata, the cluster templates and, if published,
+		the signed checksums for the selected providers, the cert-manager manifest and the list of the container images
+		required for installing them.
+
+		Image overrides defined in the clusterctl configuration file are applied to the list of images, and they are
+		registered in the clusterctl configuration file when importing the bundle.`),
+
+	Example: Examples(`
+		# Export a bundle with the latest version of the Cluster API core provider, the kubeadm bootstrap and
+		# control-plane providers and the given infrastructure provider.
+		clusterctl bundle export --infrastructure=aws --file=bundle.tar.gz
+
+		# Export a bundle with specific versions of the providers, including the cluster templates for the given flavors.
+		clusterctl bundle export --core=cluster-api:v0.3.0 --infrastructure=aws:v0.5.0 --flavor=eks --file=bundle.tar.gz`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundleExport()
+	},
+}
+
+func init() {
+	bundleExportCmd.Flags().StringVar(&beo.coreProvider, "core", "",
+		"Core provider version (e.g. cluster-api:v0.3.0) to add to the bundle. If unspecified, Cluster API's latest release is used.")
+	bundleExportCmd.Flags().StringSliceVarP(&beo.infrastructureProviders, "infrastructure", "i", nil,
+		"Infrastructure providers and versions (e.g. aws:v0.5.0) to add to the bundle.")
+	bundleExportCmd.Flags().StringSliceVarP(&beo.bootstrapProviders, "bootstrap", "b", nil,
+		"Bootstrap providers and versions (e.g. kubeadm:v0.3.0) to add to the bundle. If unspecified, Kubeadm bootstrap provider's latest release is used.")
+	bundleExportCmd.Flags().StringSliceVarP(&beo.controlPlaneProviders, "control-plane", "c", nil,
+		"Control plane providers and versions (e.g. kubeadm:v0.3.0) to add to the bundle. If unspecified, the Kubeadm control plane provider's latest release is used.")
+	bundleExportCmd.Flags().StringSliceVarP(&beo.flavors, "flavor", "f", nil,
+		"Cluster template flavors to add to the bundle for each infrastructure provider, in addition to the default cluster template.")
+	bundleExportCmd.Flags().StringVar(&beo.file, "file", "",
+		"The path of the bundle tarball to be created.")
+
+	bundleCmd.AddCommand(bundleExportCmd)
+}
+
+func runBundleExport() error {
+	if beo.file == "" {
+		return errors.New("please specify a bundle file using the --file flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.BundleExport(client.BundleExportOptions{
+		CoreProvider:            beo.coreProvider,
+		BootstrapProviders:      beo.bootstrapProviders,
+		ControlPlaneProviders:   beo.controlPlaneProviders,
+		InfrastructureProviders: beo.infrastructureProviders,
+		Flavors:                 beo.flavors,
+		File:                    beo.file,
+	})
+}
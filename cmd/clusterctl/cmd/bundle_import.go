@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type bundleImportOptions struct {
+	file      string
+	directory string
+}
+
+var bio = &bundleImportOptions{}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a bundle, writing a clusterctl configuration file with the providers included in the bundle as local repositories.",
+	Long: LongDesc(`
+		Import a bundle created with clusterctl bundle export.
+
+		The bundle is extracted in a local directory, and the providers included in the bundle are
+		registered as local repositories in the clusterctl.yaml file in the same directory, together with
+		the image overrides stored in the bundle. Use this file with the --config flag, or copy its content
+		to your clusterctl configuration file, so clusterctl init and clusterctl config cluster can use the
+		providers without accessing the network.`),
+
+	Example: Examples(`
+		# Import a bundle in the default directory ($HOME/.cluster-api/bundles/bundle).
+		clusterctl bundle import --file=bundle.tar.gz
+
+		# Import a bundle in a custom directory.
+		clusterctl bundle import --file=bundle.tar.gz --directory=/opt/cluster-api/bundle
+
+		# Install the providers included in the bundle.
+		clusterctl init --config=/opt/cluster-api/bundle/clusterctl.yaml --infrastructure aws`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundleImport()
+	},
+}
+
+func init() {
+	bundleImportCmd.Flags().StringVar(&bio.file, "file", "",
+		"The path of the bundle tarball to import.")
+	bundleImportCmd.Flags().StringVar(&bio.directory, "directory", "",
+		"The directory where the bundle should be extracted. If unspecified, the bundle is extracted in $HOME/.cluster-api/bundles.")
+
+	bundleCmd.AddCommand(bundleImportCmd)
+}
+
+func runBundleImport() error {
+	if bio.file == "" {
+		return errors.New("please specify a bundle file using the --file flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	providers, err := c.BundleImport(client.BundleImportOptions{
+		File:      bio.file,
+		Directory: bio.directory,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range providers {
+		fmt.Printf("Registered %s %q: %s\n", p.Type(), p.Name(), p.URL())
+	}
+	return nil
+}
@@ -18,11 +18,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 )
 
 type configClusterOptions struct {
@@ -42,6 +46,7 @@ type configClusterOptions struct {
 	configMapDataKey   string
 
 	listVariables bool
+	output        string
 }
 
 var cc = &configClusterOptions{}
@@ -84,7 +89,16 @@ var configClusterClusterCmd = &cobra.Command{
 		clusterctl config cluster my-cluster --from https://github.com/foo-org/foo-repository/blob/master/cluster-template.yaml
 
 		# Generates a configuration file for creating workload clusters using a template stored locally.
-		clusterctl config cluster my-cluster --from ~/workspace/cluster-template.yaml`),
+		clusterctl config cluster my-cluster --from ~/workspace/cluster-template.yaml
+
+		# Generates a configuration file for creating workload clusters using a template published in an OCI registry.
+		clusterctl config cluster my-cluster --from oci://registry.example.com/templates/aws:v1.0.0
+
+		# Generates a configuration file for creating workload clusters using a template stored in a Git repository at a given ref.
+		clusterctl config cluster my-cluster --from git+https://git.example.com/platform/templates@v1.0.0#aws/cluster-template.yaml
+
+		# Prints the list of variables required by the template in yaml format.
+		clusterctl config cluster my-cluster --list-variables -o yaml`),
 
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -116,7 +130,7 @@ func init() {
 
 	// flags for the url source
 	configClusterClusterCmd.Flags().StringVar(&cc.url, "from", "",
-		"The URL to read the workload cluster template from. If unspecified, the infrastructure provider repository URL will be used")
+		"The URL to read the workload cluster template from, e.g. a GitHub blob URL, a local file, an oci:// or a git+https:// URL. If unspecified, the infrastructure provider repository URL will be used")
 
 	// flags for the config map source
 	configClusterClusterCmd.Flags().StringVar(&cc.configMapName, "from-config-map", "",
@@ -129,11 +143,20 @@ func init() {
 	// other flags
 	configClusterClusterCmd.Flags().BoolVar(&cc.listVariables, "list-variables", false,
 		"Returns the list of variables expected by the template instead of the template yaml")
+	configClusterClusterCmd.Flags().StringVarP(&cc.output, "output", "o", OutputText,
+		fmt.Sprintf("Output format for the list of variables, used in combination with --list-variables. Valid values: %v.", Outputs))
 
 	configCmd.AddCommand(configClusterClusterCmd)
 }
 
 func runGetClusterTemplate(cmd *cobra.Command, name string) error {
+	if err := validateOutput(cc.output); err != nil {
+		return err
+	}
+	if cc.output != OutputText && !cc.listVariables {
+		return errors.New("the --output flag can only be used in combination with --list-variables")
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -181,20 +204,48 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 	}
 
 	if cc.listVariables {
-		return templateListVariablesOutput(template)
+		return templateListVariablesOutput(template, cc.output, os.Stdout)
 	}
 
 	return templateYAMLOutput(template)
 }
 
-func templateListVariablesOutput(template client.Template) error {
+func templateListVariablesOutput(template client.Template, output string, out io.Writer) error {
+	if isStructuredOutput(output) {
+		return printStructuredOutput(out, output, toOutputVariableList(template.Variables(), template.VariablesSchema()))
+	}
+
+	// If the template ships a variables schema, print the details of each variable.
+	if schema := template.VariablesSchema(); schema != nil && len(template.Variables()) > 0 {
+		w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tREQUIRED\tDEFAULT\tDESCRIPTION")
+		for _, v := range template.Variables() {
+			spec, _ := schema.Get(v)
+			varType := spec.Type
+			if varType == "" {
+				varType = yamlprocessor.StringVariableType
+			}
+			defaultValue := ""
+			if spec.Default != nil {
+				defaultValue = *spec.Default
+			}
+			description := spec.Description
+			if len(spec.Enum) > 0 {
+				description = strings.TrimSpace(fmt.Sprintf("%s Allowed values: %s.", description, strings.Join(spec.Enum, ", ")))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n", v, varType, spec.Required, defaultValue, description)
+		}
+		w.Flush()
+		return nil
+	}
+
 	if len(template.Variables()) > 0 {
-		fmt.Println("Variables:")
+		fmt.Fprintln(out, "Variables:")
 		for _, v := range template.Variables() {
-			fmt.Printf("  - %s\n", v)
+			fmt.Fprintf(out, "  - %s\n", v)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 	return nil
 }
 
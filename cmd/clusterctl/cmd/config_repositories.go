@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -34,11 +35,13 @@ const (
 	RepositoriesOutputYaml = "yaml"
 	// RepositoriesOutputText is an option used to print the repository list in text format.
 	RepositoriesOutputText = "text"
+	// RepositoriesOutputJSON is an option used to print the repository list in json format.
+	RepositoriesOutputJSON = "json"
 )
 
 var (
 	// RepositoriesOutputs is a list of valid repository list outputs.
-	RepositoriesOutputs = []string{RepositoriesOutputYaml, RepositoriesOutputText}
+	RepositoriesOutputs = []string{RepositoriesOutputYaml, RepositoriesOutputText, RepositoriesOutputJSON}
 )
 
 type configRepositoriesOptions struct {
@@ -62,7 +65,10 @@ var configRepositoryCmd = &cobra.Command{
 		clusterctl config repositories
 		
 		# Print the list of available providers in yaml format.
-		clusterctl config repositories -o yaml`),
+		clusterctl config repositories -o yaml
+
+		# Print the list of available providers in json format.
+		clusterctl config repositories -o json`),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runGetRepositories(cfgFile, os.Stdout)
@@ -76,7 +82,7 @@ func init() {
 }
 
 func runGetRepositories(cfgFile string, out io.Writer) error {
-	if cro.output != RepositoriesOutputText && cro.output != RepositoriesOutputYaml {
+	if cro.output != RepositoriesOutputText && cro.output != RepositoriesOutputYaml && cro.output != RepositoriesOutputJSON {
 		return errors.Errorf("Invalid output format %q. Valid values: %v.", cro.output, RepositoriesOutputs)
 	}
 
@@ -109,6 +115,12 @@ func runGetRepositories(cfgFile string, out io.Writer) error {
 			return err
 		}
 		fmt.Fprintf(w, string(y))
+	case RepositoriesOutputJSON:
+		j, err := json.MarshalIndent(repositoryList, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(j))
 	}
 	w.Flush()
 	return nil
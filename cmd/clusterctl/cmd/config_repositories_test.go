@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -48,6 +49,9 @@ func Test_runGetRepositories(t *testing.T) {
 				g.Expect(string(out)).To(Equal(expectedOutputText))
 			} else if val == RepositoriesOutputYaml {
 				g.Expect(string(out)).To(Equal(expectedOutputYaml))
+			} else if val == RepositoriesOutputJSON {
+				g.Expect(json.Valid(out)).To(BeTrue())
+				g.Expect(string(out)).To(ContainSubstring(`"Name": "cluster-api"`))
 			}
 		}
 	})
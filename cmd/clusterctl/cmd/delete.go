@@ -17,6 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"io"
+	"os"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
@@ -32,7 +35,9 @@ type deleteOptions struct {
 	infrastructureProviders []string
 	includeNamespace        bool
 	includeCRDs             bool
+	includeCertManager      bool
 	deleteAll               bool
+	output                  string
 }
 
 var dd = &deleteOptions{}
@@ -83,10 +88,17 @@ var deleteCmd = &cobra.Command{
 		# Reset the management cluster to its original state
 		# Important! As a consequence of this operation all the corresponding resources on target clouds
 		# are "orphaned" and thus there may be ongoing costs incurred as a result of this.
-		clusterctl delete --all --include-crd  --include-namespace`),
+		clusterctl delete --all --include-crd  --include-namespace
+
+		# Delete all the providers and the cert-manager installed by clusterctl, including the cert-manager CRDs.
+		# Please note that this forces deletion of all the cert-manager objects (e.g. Certificates, Issuers).
+		clusterctl delete --all --include-cert-manager
+
+		# Delete all the providers and print the deleted providers in json format.
+		clusterctl delete --all -o json`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runDelete()
+		return runDelete(os.Stdout)
 	},
 }
 
@@ -101,6 +113,8 @@ func init() {
 		"Forces the deletion of the namespace where the providers are hosted (and of all the contained objects)")
 	deleteCmd.Flags().BoolVar(&dd.includeCRDs, "include-crd", false,
 		"Forces the deletion of the provider's CRDs (and of all the related objects)")
+	deleteCmd.Flags().BoolVar(&dd.includeCertManager, "include-cert-manager", false,
+		"Forces the deletion of the cert-manager installed by clusterctl (and of all the related objects). Valid only in combination with --all")
 
 	deleteCmd.Flags().StringVar(&dd.coreProvider, "core", "",
 		"Core provider version (e.g. cluster-api:v0.3.0) to delete from the management cluster")
@@ -113,11 +127,16 @@ func init() {
 
 	deleteCmd.Flags().BoolVar(&dd.deleteAll, "all", false,
 		"Force deletion of all the providers")
+	addOutputFlag(deleteCmd.Flags(), &dd.output)
 
 	RootCmd.AddCommand(deleteCmd)
 }
 
-func runDelete() error {
+func runDelete(out io.Writer) error {
+	if err := validateOutput(dd.output); err != nil {
+		return err
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -136,7 +155,11 @@ func runDelete() error {
 		return errors.New("At least one of --core, --bootstrap, --control-plane, --infrastructure should be specified or the --all flag should be set")
 	}
 
-	if err := c.Delete(client.DeleteOptions{
+	if dd.includeCertManager && !dd.deleteAll {
+		return errors.New("The --include-cert-manager flag can be used only in combination with --all")
+	}
+
+	deletedProviders, err := c.Delete(client.DeleteOptions{
 		Kubeconfig:              client.Kubeconfig{Path: dd.kubeconfig, Context: dd.kubeconfigContext},
 		IncludeNamespace:        dd.includeNamespace,
 		IncludeCRDs:             dd.includeCRDs,
@@ -146,9 +169,14 @@ func runDelete() error {
 		InfrastructureProviders: dd.infrastructureProviders,
 		ControlPlaneProviders:   dd.controlPlaneProviders,
 		DeleteAll:               dd.deleteAll,
-	}); err != nil {
+		IncludeCertManager:      dd.includeCertManager,
+	})
+	if err != nil {
 		return err
 	}
 
+	if isStructuredOutput(dd.output) {
+		return printStructuredOutput(out, dd.output, toOutputProviderList(deletedProviders))
+	}
 	return nil
 }
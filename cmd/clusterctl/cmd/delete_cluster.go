@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type deleteClusterOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	wait              bool
+	timeout           time.Duration
+}
+
+var dco = &deleteClusterOptions{}
+
+var deleteClusterCmd = &cobra.Command{
+	Use:   "cluster NAME",
+	Short: "Delete a workload cluster.",
+	Long: LongDesc(`
+		Delete a workload cluster.
+
+		The Cluster object is deleted, and then the command waits for the Cluster API controllers to delete all the
+		objects the workload cluster is composed of, reporting progress as Machines are drained, the infrastructure
+		is released and finalizers are removed.
+
+		If the objects are not deleted before the timeout, the objects still existing are listed together with
+		their finalizers and failure messages, so it is possible to investigate what is blocking the deletion.`),
+
+	Example: Examples(`
+		# Delete the workload cluster named test-1 and wait for all its objects to be deleted.
+		clusterctl delete cluster test-1
+
+		# Delete the workload cluster named test-1, waiting at most 10 minutes.
+		clusterctl delete cluster test-1 --timeout=10m
+
+		# Delete the workload cluster named test-1 without waiting.
+		clusterctl delete cluster test-1 --wait=false`),
+
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("please specify a cluster name")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeleteCluster(args[0], os.Stdout)
+	},
+}
+
+func init() {
+	deleteClusterCmd.Flags().StringVar(&dco.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	deleteClusterCmd.Flags().StringVar(&dco.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	deleteClusterCmd.Flags().StringVarP(&dco.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is located. If unspecified, the current namespace will be used.")
+
+	deleteClusterCmd.Flags().BoolVar(&dco.wait, "wait", true,
+		"Wait for all the objects the workload cluster is composed of to be deleted.")
+	deleteClusterCmd.Flags().DurationVar(&dco.timeout, "timeout", 0,
+		"The maximum time to wait for the objects to be deleted. If zero, defaults to 30 minutes.")
+
+	deleteCmd.AddCommand(deleteClusterCmd)
+}
+
+func runDeleteCluster(clusterName string, out io.Writer) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	remaining, err := c.DeleteCluster(client.DeleteClusterOptions{
+		Kubeconfig:  client.Kubeconfig{Path: dco.kubeconfig, Context: dco.kubeconfigContext},
+		Namespace:   dco.namespace,
+		ClusterName: clusterName,
+		Wait:        dco.wait,
+		Timeout:     dco.timeout,
+	})
+	if len(remaining) > 0 {
+		fmt.Fprintf(out, "The following objects of Cluster %q were not deleted:\n\n", clusterName)
+		printRemainingObjects(out, remaining)
+		fmt.Fprintln(out, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	if dco.wait {
+		fmt.Fprintf(out, "Cluster %q deleted\n", clusterName)
+	} else {
+		fmt.Fprintf(out, "Cluster %q is being deleted\n", clusterName)
+	}
+	return nil
+}
+
+// printRemainingObjects prints the objects of a workload cluster still existing after the deletion timed out.
+func printRemainingObjects(out io.Writer, remaining []client.RemainingObject) {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tDELETING SINCE\tFINALIZERS\tMESSAGE")
+	for _, r := range remaining {
+		deletingSince := "-"
+		if r.DeletionTimestamp != nil {
+			deletingSince = duration.HumanDuration(time.Since(r.DeletionTimestamp.Time))
+		}
+		finalizers := "-"
+		if len(r.Finalizers) > 0 {
+			finalizers = strings.Join(r.Finalizers, ",")
+		}
+		message := "-"
+		if len(r.FailureMessages) > 0 {
+			message = strings.Join(r.FailureMessages, "; ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Object.Kind, r.Object.Name, deletingSince, finalizers, message)
+	}
+	w.Flush()
+}
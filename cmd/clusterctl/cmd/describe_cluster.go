@@ -0,0 +1,196 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DescribeOutputText is an option used to print the object tree in text format.
+	DescribeOutputText = "text"
+	// DescribeOutputJSON is an option used to print the object tree in json format.
+	DescribeOutputJSON = "json"
+	// DescribeOutputYaml is an option used to print the object tree in yaml format.
+	DescribeOutputYaml = "yaml"
+)
+
+var (
+	// DescribeOutputs is a list of valid describe outputs.
+	DescribeOutputs = []string{DescribeOutputText, DescribeOutputJSON, DescribeOutputYaml}
+)
+
+type describeClusterOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+
+	namespace           string
+	showOtherConditions bool
+	disableGrouping     bool
+	output              string
+}
+
+var dc = &describeClusterOptions{}
+
+var describeClusterCmd = &cobra.Command{
+	Use:   "cluster NAME",
+	Short: "Describe workload clusters.",
+	Long: LongDesc(`
+		Provide an "at glance" view of a Cluster API cluster designed to help the user in quickly
+		understanding if there are problems and where.
+
+		The view is a tree of the objects composing the workload cluster, built following the
+		OwnerReference chain starting from the Cluster object; for each object, the Ready condition
+		is shown with its severity, reason and the object's age.`),
+
+	Example: Examples(`
+		# Describe the cluster named test-1.
+		clusterctl describe cluster test-1
+
+		# Describe the cluster named test-1 showing all the conditions for each object.
+		clusterctl describe cluster test-1 --show-conditions
+
+		# Describe the cluster named test-1 without grouping Machines with the same state.
+		clusterctl describe cluster test-1 --disable-grouping
+
+		# Describe the cluster named test-1 in yaml format.
+		clusterctl describe cluster test-1 -o yaml`),
+
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("please specify a cluster name")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDescribeCluster(args[0], os.Stdout)
+	},
+}
+
+func init() {
+	describeClusterCmd.Flags().StringVar(&dc.kubeconfig, "kubeconfig", "",
+		"Path to a kubeconfig file to use for the management cluster. If empty, default discovery rules apply.")
+	describeClusterCmd.Flags().StringVar(&dc.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+
+	describeClusterCmd.Flags().StringVarP(&dc.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is located. If unspecified, the current namespace will be used.")
+
+	describeClusterCmd.Flags().BoolVar(&dc.showOtherConditions, "show-conditions", false,
+		"Show all the conditions for each object, not only the Ready condition.")
+	describeClusterCmd.Flags().BoolVar(&dc.disableGrouping, "disable-grouping", false,
+		"Disable grouping Machines with the same Ready condition status, severity and reason.")
+	describeClusterCmd.Flags().StringVarP(&dc.output, "output", "o", DescribeOutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", DescribeOutputs))
+
+	describeCmd.AddCommand(describeClusterCmd)
+}
+
+func runDescribeCluster(name string, out io.Writer) error {
+	if dc.output != DescribeOutputText && dc.output != DescribeOutputJSON && dc.output != DescribeOutputYaml {
+		return errors.Errorf("Invalid output format %q. Valid values: %v.", dc.output, DescribeOutputs)
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	tree, err := c.Describe(client.DescribeOptions{
+		Kubeconfig:          client.Kubeconfig{Path: dc.kubeconfig, Context: dc.kubeconfigContext},
+		Namespace:           dc.namespace,
+		ClusterName:         name,
+		ShowOtherConditions: dc.showOtherConditions,
+		DisableGrouping:     dc.disableGrouping,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch dc.output {
+	case DescribeOutputJSON:
+		j, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(j))
+	case DescribeOutputYaml:
+		y, err := yaml.Marshal(tree)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(out, string(y))
+	default:
+		printObjectTree(out, tree, time.Now())
+	}
+	return nil
+}
+
+// printObjectTree prints the object tree in text format.
+func printObjectTree(out io.Writer, tree *client.ObjectTree, now time.Time) {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREADY\tSEVERITY\tREASON\tAGE\tMESSAGE")
+	printObjectTreeNode(w, tree.Root, "", "", now)
+	w.Flush()
+}
+
+// printObjectTreeNode prints a node of the object tree and all its children, using prefix for the current node
+// and childPrefix for the rows nested under the current node.
+func printObjectTreeNode(w io.Writer, n *cluster.ObjectTreeNode, prefix, childPrefix string, now time.Time) {
+	name := fmt.Sprintf("%s/%s", n.Object.Kind, n.Object.Name)
+	if n.IsGroup() {
+		name = fmt.Sprintf("%d %ss...", len(n.GroupItems), n.Object.Kind)
+	}
+	age := duration.HumanDuration(now.Sub(n.CreationTimestamp.Time))
+	status, severity, reason, message := conditionColumns(n.ReadyCondition)
+	fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n", prefix, name, status, severity, reason, age, message)
+
+	for i := range n.OtherConditions {
+		condition := n.OtherConditions[i]
+		status, severity, reason, message := conditionColumns(&condition)
+		fmt.Fprintf(w, "%s  %s\t%s\t%s\t%s\t\t%s\n", childPrefix, condition.Type, status, severity, reason, message)
+	}
+
+	for i, child := range n.Children {
+		if i == len(n.Children)-1 {
+			printObjectTreeNode(w, child, childPrefix+"└─", childPrefix+"  ", now)
+			continue
+		}
+		printObjectTreeNode(w, child, childPrefix+"├─", childPrefix+"│ ", now)
+	}
+}
+
+// conditionColumns returns the values for the READY, SEVERITY, REASON and MESSAGE columns for a condition.
+func conditionColumns(c *clusterv1.Condition) (string, string, string, string) {
+	if c == nil {
+		return "", "", "", ""
+	}
+	return string(c.Status), string(c.Severity), c.Reason, c.Message
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func Test_printObjectTree(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-10 * time.Minute))
+
+	tree := &client.ObjectTree{
+		Root: &cluster.ObjectTreeNode{
+			Object:            corev1.ObjectReference{Kind: "Cluster", Name: "cluster1"},
+			CreationTimestamp: created,
+			ReadyCondition:    &clusterv1.Condition{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue},
+			Children: []*cluster.ObjectTreeNode{
+				{
+					Object:            corev1.ObjectReference{Kind: "GenericInfrastructureCluster", Name: "cluster1"},
+					CreationTimestamp: created,
+				},
+				{
+					Object:            corev1.ObjectReference{Kind: "Machine"},
+					CreationTimestamp: created,
+					GroupItems:        []string{"m1", "m2"},
+					ReadyCondition: &clusterv1.Condition{
+						Type:     clusterv1.ReadyCondition,
+						Status:   corev1.ConditionFalse,
+						Severity: clusterv1.ConditionSeverityWarning,
+						Reason:   "WaitingForNode",
+						Message:  "1 of 2 completed",
+					},
+				},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	printObjectTree(out, tree, now)
+
+	g.Expect(out.String()).To(Equal(
+		"NAME                                      READY     SEVERITY   REASON           AGE       MESSAGE\n" +
+			"Cluster/cluster1                          True                                  10m       \n" +
+			"├─GenericInfrastructureCluster/cluster1                                         10m       \n" +
+			"└─2 Machines...                           False     Warning    WaitingForNode   10m       1 of 2 completed\n",
+	))
+}
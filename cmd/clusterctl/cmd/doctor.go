@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+type doctorOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	output            string
+}
+
+var do = &doctorOptions{}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the health and the consistency of a management cluster",
+	Long: LongDesc(`
+		Check the health and the consistency of a management cluster.
+
+		The doctor command runs a set of read-only checks against the management cluster, verifying that
+		the provider controllers are running the expected versions, that web-hooks are reachable and have
+		valid certificates, that the CRDs storage versions match the provider contracts, and that there are
+		no orphaned provider objects, clusters stuck in an interrupted move or overlapping watching namespaces.
+
+		Each check reports pass, warn or fail; warnings and failures include a hint about how to fix the problem.
+		The command exits with an error if any check fails.`),
+
+	Example: Examples(`
+		# Check the health of the management cluster.
+		clusterctl doctor
+
+		# Check the health of the management cluster and print the results in json format.
+		clusterctl doctor -o json`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(os.Stdout)
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&do.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	doctorCmd.Flags().StringVar(&do.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	addOutputFlag(doctorCmd.Flags(), &do.output)
+
+	RootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(out io.Writer) error {
+	if err := validateOutput(do.output); err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.Doctor(client.DoctorOptions{
+		Kubeconfig: client.Kubeconfig{Path: do.kubeconfig, Context: do.kubeconfigContext},
+	})
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput(do.output) {
+		if err := printStructuredOutput(out, do.output, toOutputCheckResultList(results)); err != nil {
+			return err
+		}
+	} else {
+		printCheckResults(out, results)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == cluster.CheckFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
+
+// printCheckResults prints the results of the doctor checks in text format, followed by the
+// remediation hints for the checks that did not pass.
+func printCheckResults(out io.Writer, results []client.CheckResult) {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tCHECK\tMESSAGE")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Status, r.Check, r.Message)
+	}
+	w.Flush()
+
+	hints := false
+	for _, r := range results {
+		if r.Status == cluster.CheckPassed || r.Remediation == "" {
+			continue
+		}
+		if !hints {
+			fmt.Fprintln(out, "\nRemediation hints:")
+			hints = true
+		}
+		fmt.Fprintf(out, "- [%s] %s: %s\n", r.Status, r.Check, r.Remediation)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type getKubeconfigOptions struct {
+	kubeconfig          string
+	kubeconfigContext   string
+	namespace           string
+	certificateDuration time.Duration
+	contextName         string
+	file                string
+	merge               bool
+}
+
+var gk = &getKubeconfigOptions{}
+
+var getKubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig NAME",
+	Short: "Gets the kubeconfig file for accessing a workload cluster.",
+	Long: LongDesc(`
+		Gets the kubeconfig file for accessing a workload cluster.
+
+		By default, the admin kubeconfig stored in the <cluster>-kubeconfig secret is returned; use
+		the --cert-duration flag for generating a kubeconfig with a new, short-lived client certificate
+		signed by the cluster CA instead of sharing the long-lived admin credentials.`),
+
+	Example: Examples(`
+		# Get the kubeconfig for the workload cluster named test-1.
+		clusterctl get kubeconfig test-1
+
+		# Write the kubeconfig for the workload cluster named test-1 to a file.
+		clusterctl get kubeconfig test-1 --file=test-1.kubeconfig
+
+		# Merge the kubeconfig for the workload cluster named test-1 into the default kubeconfig file,
+		# using test-1 as a context name.
+		clusterctl get kubeconfig test-1 --merge --context-name=test-1
+
+		# Get a kubeconfig with a client certificate valid for one hour.
+		clusterctl get kubeconfig test-1 --cert-duration=1h`),
+
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("please specify a workload cluster name")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGetKubeconfig(args[0])
+	},
+}
+
+func init() {
+	getKubeconfigCmd.Flags().StringVar(&gk.kubeconfig, "kubeconfig", "",
+		"Path to a kubeconfig file to use for the management cluster. If empty, default discovery rules apply.")
+	getKubeconfigCmd.Flags().StringVar(&gk.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	getKubeconfigCmd.Flags().StringVarP(&gk.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is located. If unspecified, the current namespace will be used.")
+
+	getKubeconfigCmd.Flags().DurationVar(&gk.certificateDuration, "cert-duration", 0,
+		"If set, generate a new client certificate signed by the cluster CA and valid for the given duration, instead of returning the admin kubeconfig.")
+	getKubeconfigCmd.Flags().StringVar(&gk.contextName, "context-name", "",
+		"If set, the name used for the context, the cluster and the user in the kubeconfig.")
+	getKubeconfigCmd.Flags().StringVar(&gk.file, "file", "",
+		"The file where the kubeconfig should be written. If unspecified, the kubeconfig is printed to stdout.")
+	getKubeconfigCmd.Flags().BoolVar(&gk.merge, "merge", false,
+		"Merge the kubeconfig into the file specified by --file, or into the default kubeconfig file if --file is not set.")
+
+	getCmd.AddCommand(getKubeconfigCmd)
+}
+
+func runGetKubeconfig(workloadClusterName string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := c.GetKubeconfig(client.GetKubeconfigOptions{
+		Kubeconfig:          client.Kubeconfig{Path: gk.kubeconfig, Context: gk.kubeconfigContext},
+		Namespace:           gk.namespace,
+		WorkloadClusterName: workloadClusterName,
+		CertificateDuration: gk.certificateDuration,
+		ContextName:         gk.contextName,
+		File:                gk.file,
+		Merge:               gk.merge,
+	})
+	if err != nil {
+		return err
+	}
+
+	if gk.file == "" && !gk.merge {
+		fmt.Print(string(data))
+	}
+	return nil
+}
@@ -18,8 +18,11 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/spf13/cobra"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
 )
 
@@ -33,6 +36,7 @@ type initOptions struct {
 	targetNamespace         string
 	watchingNamespace       string
 	listImages              bool
+	output                  string
 }
 
 var initOpts = &initOptions{}
@@ -82,10 +86,13 @@ var initCmd = &cobra.Command{
 		# Lists the container images required for initializing the management cluster.
 		#
 		# Note: This command is a dry-run; it won't perform any action other than printing to screen.
-		clusterctl init --infrastructure aws --list-images`),
+		clusterctl init --infrastructure aws --list-images
+
+		# Initialize a management cluster and print the installed providers in yaml format.
+		clusterctl init --infrastructure aws -o yaml`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runInit()
+		return runInit(os.Stdout)
 	},
 }
 
@@ -110,11 +117,16 @@ func init() {
 	// TODO: Move this to a sub-command or similar, it shouldn't really be a flag.
 	initCmd.Flags().BoolVar(&initOpts.listImages, "list-images", false,
 		"Lists the container images required for initializing the management cluster (without actually installing the providers)")
+	addOutputFlag(initCmd.Flags(), &initOpts.output)
 
 	RootCmd.AddCommand(initCmd)
 }
 
-func runInit() error {
+func runInit(out io.Writer) error {
+	if err := validateOutput(initOpts.output); err != nil {
+		return err
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -128,7 +140,7 @@ func runInit() error {
 		InfrastructureProviders: initOpts.infrastructureProviders,
 		TargetNamespace:         initOpts.targetNamespace,
 		WatchingNamespace:       initOpts.watchingNamespace,
-		LogUsageInstructions:    true,
+		LogUsageInstructions:    !isStructuredOutput(initOpts.output),
 	}
 
 	if initOpts.listImages {
@@ -137,14 +149,27 @@ func runInit() error {
 			return err
 		}
 
+		if isStructuredOutput(initOpts.output) {
+			return printStructuredOutput(out, initOpts.output, toOutputImageList(images))
+		}
+
 		for _, i := range images {
-			fmt.Println(i)
+			fmt.Fprintln(out, i)
 		}
 		return nil
 	}
 
-	if _, err := c.Init(options); err != nil {
+	components, err := c.Init(options)
+	if err != nil {
 		return err
 	}
+
+	if isStructuredOutput(initOpts.output) {
+		providers := make([]clusterctlv1.Provider, 0, len(components))
+		for _, c := range components {
+			providers = append(providers, c.InventoryObject())
+		}
+		return printStructuredOutput(out, initOpts.output, toOutputProviderList(providers))
+	}
 	return nil
 }
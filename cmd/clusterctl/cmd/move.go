@@ -17,6 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"io"
+	"os"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
@@ -28,6 +31,9 @@ type moveOptions struct {
 	toKubeconfig          string
 	toKubeconfigContext   string
 	namespace             string
+	dryRun                bool
+	rollback              bool
+	output                string
 }
 
 var mo = &moveOptions{}
@@ -38,14 +44,27 @@ var moveCmd = &cobra.Command{
 	Long: LongDesc(`
 		Move Cluster API objects and all dependencies between management clusters.
 
-		Note: The destination cluster MUST have the required provider components installed.`),
+		Note: The destination cluster MUST have the required provider components installed.
+
+		If move is interrupted, e.g. because of a network error, run move again to resume from the last completed step,
+		or use the --rollback flag to delete the objects already created in the destination cluster and
+		resume the reconciliation of the Clusters in the source cluster.`),
 
 	Example: Examples(`
 		Move Cluster API objects and all dependencies between management clusters.
-		clusterctl move --to-kubeconfig=target-kubeconfig.yaml`),
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml
+
+		Show the objects that would be moved, without pausing, creating or deleting any object.
+		clusterctl move --dry-run
+
+		Show the objects that would be moved in json format.
+		clusterctl move --dry-run -o json
+
+		Rollback an interrupted move.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --rollback`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runMove()
+		return runMove(os.Stdout)
 	},
 }
 
@@ -60,12 +79,21 @@ func init() {
 		"Context to be used within the kubeconfig file for the destination management cluster. If empty, current context will be used.")
 	moveCmd.Flags().StringVarP(&mo.namespace, "namespace", "n", "",
 		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	moveCmd.Flags().BoolVar(&mo.dryRun, "dry-run", false,
+		"Log the objects to be moved, grouped in the order they will be created and deleted, without making any change. The destination management cluster is optional.")
+	moveCmd.Flags().BoolVar(&mo.rollback, "rollback", false,
+		"Rollback an interrupted move, deleting the objects already created in the destination management cluster and unpausing the Clusters in the source management cluster.")
+	addOutputFlag(moveCmd.Flags(), &mo.output)
 
 	RootCmd.AddCommand(moveCmd)
 }
 
-func runMove() error {
-	if mo.toKubeconfig == "" {
+func runMove(out io.Writer) error {
+	if err := validateOutput(mo.output); err != nil {
+		return err
+	}
+
+	if mo.toKubeconfig == "" && !mo.dryRun {
 		return errors.New("please specify a target cluster using the --to-kubeconfig flag")
 	}
 
@@ -74,12 +102,19 @@ func runMove() error {
 		return err
 	}
 
-	if err := c.Move(client.MoveOptions{
+	movedObjects, err := c.Move(client.MoveOptions{
 		FromKubeconfig: client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
 		ToKubeconfig:   client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
 		Namespace:      mo.namespace,
-	}); err != nil {
+		DryRun:         mo.dryRun,
+		Rollback:       mo.rollback,
+	})
+	if err != nil {
 		return err
 	}
+
+	if isStructuredOutput(mo.output) {
+		return printStructuredOutput(out, mo.output, toOutputMoveResult(movedObjects, mo.dryRun))
+	}
 	return nil
 }
@@ -0,0 +1,246 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	outputv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/output/v1alpha1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OutputText is an option used to print the result of a command in text format.
+	OutputText = "text"
+	// OutputJSON is an option used to print the result of a command in json format.
+	OutputJSON = "json"
+	// OutputYaml is an option used to print the result of a command in yaml format.
+	OutputYaml = "yaml"
+)
+
+var (
+	// Outputs is a list of valid outputs for the commands supporting structured output.
+	Outputs = []string{OutputText, OutputJSON, OutputYaml}
+)
+
+// addOutputFlag adds the --output flag to the flag set of a command supporting structured output.
+func addOutputFlag(flags *pflag.FlagSet, output *string) {
+	flags.StringVarP(output, "output", "o", OutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", Outputs))
+}
+
+// validateOutput checks the value of the --output flag.
+func validateOutput(output string) error {
+	if output != OutputText && output != OutputJSON && output != OutputYaml {
+		return errors.Errorf("Invalid output format %q. Valid values: %v.", output, Outputs)
+	}
+	return nil
+}
+
+// isStructuredOutput returns true if the result of a command should be printed in json or yaml format.
+func isStructuredOutput(output string) bool {
+	return output == OutputJSON || output == OutputYaml
+}
+
+// printStructuredOutput prints an output object in json or yaml format.
+func printStructuredOutput(out io.Writer, output string, obj interface{}) error {
+	var b []byte
+	var err error
+	switch output {
+	case OutputJSON:
+		b, err = json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to convert output to json")
+		}
+		b = append(b, '\n')
+	case OutputYaml:
+		b, err = yaml.Marshal(obj)
+		if err != nil {
+			return errors.Wrap(err, "failed to convert output to yaml")
+		}
+	default:
+		return errors.Errorf("output format %q is not a structured output", output)
+	}
+
+	if _, err := out.Write(b); err != nil {
+		return errors.Wrap(err, "failed to write output")
+	}
+	return nil
+}
+
+// outputTypeMeta returns the TypeMeta for a kind of the clusterctl output API.
+func outputTypeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{
+		APIVersion: outputv1.GroupVersion.String(),
+		Kind:       kind,
+	}
+}
+
+func toOutputProvider(p clusterctlv1.Provider) outputv1.Provider {
+	return outputv1.Provider{
+		Name:             p.ProviderName,
+		Namespace:        p.Namespace,
+		Type:             p.Type,
+		Version:          p.Version,
+		WatchedNamespace: p.WatchedNamespace,
+	}
+}
+
+func toOutputProviderList(providers []clusterctlv1.Provider) *outputv1.ProviderList {
+	ret := &outputv1.ProviderList{
+		TypeMeta: outputTypeMeta("ProviderList"),
+		Items:    []outputv1.Provider{},
+	}
+	for _, p := range providers {
+		ret.Items = append(ret.Items, toOutputProvider(p))
+	}
+	return ret
+}
+
+func toOutputImageList(images []string) *outputv1.ImageList {
+	ret := &outputv1.ImageList{
+		TypeMeta: outputTypeMeta("ImageList"),
+		Images:   []string{},
+	}
+	ret.Images = append(ret.Images, images...)
+	return ret
+}
+
+func toOutputVariableList(variables []string, schema *yamlprocessor.VariablesSchema) *outputv1.VariableList {
+	ret := &outputv1.VariableList{
+		TypeMeta:  outputTypeMeta("VariableList"),
+		Variables: []outputv1.Variable{},
+	}
+	for _, v := range variables {
+		variable := outputv1.Variable{Name: v}
+		if spec, ok := schema.Get(v); ok {
+			variable.Description = spec.Description
+			variable.Type = string(spec.Type)
+			variable.Default = spec.Default
+			variable.Enum = spec.Enum
+			variable.Required = spec.Required
+		}
+		ret.Variables = append(ret.Variables, variable)
+	}
+	return ret
+}
+
+// toOutputUpgradePlanList converts upgrade plans to the output format; diffs, if not nil, holds the
+// changes to the provider components for each upgrade plan.
+func toOutputUpgradePlanList(plans []client.UpgradePlan, certManager *client.CertManagerUpgradePlan, diffs [][]client.ComponentsDiff) *outputv1.UpgradePlanList {
+	ret := &outputv1.UpgradePlanList{
+		TypeMeta: outputTypeMeta("UpgradePlanList"),
+		Items:    []outputv1.UpgradePlan{},
+	}
+	if certManager != nil {
+		ret.CertManager = &outputv1.CertManagerUpgradePlan{
+			Installed:         certManager.Installed,
+			ExternallyManaged: certManager.ExternallyManaged,
+			Version:           certManager.From,
+		}
+		if certManager.ShouldUpgrade {
+			ret.CertManager.NextVersion = certManager.To
+		}
+	}
+	for i, plan := range plans {
+		p := outputv1.UpgradePlan{
+			ManagementGroup: plan.CoreProvider.InstanceName(),
+			Contract:        plan.Contract,
+			Providers:       []outputv1.UpgradeItem{},
+		}
+		for _, item := range plan.Providers {
+			outputItem := outputv1.UpgradeItem{
+				Provider:          toOutputProvider(item.Provider),
+				NextVersion:       item.NextVersion,
+				VersionConstraint: item.VersionConstraint,
+			}
+			if i < len(diffs) {
+				for _, diff := range diffs[i] {
+					if diff.InstanceName() == item.InstanceName() {
+						outputItem.Changes = toOutputComponentChanges(diff)
+					}
+				}
+			}
+			p.Providers = append(p.Providers, outputItem)
+		}
+		ret.Items = append(ret.Items, p)
+	}
+	return ret
+}
+
+func toOutputComponentChanges(diff client.ComponentsDiff) []outputv1.ComponentChange {
+	ret := []outputv1.ComponentChange{}
+	for _, o := range diff.Objects {
+		ret = append(ret, outputv1.ComponentChange{
+			Object: outputv1.ObjectReference{
+				APIVersion: o.Object.APIVersion,
+				Kind:       o.Object.Kind,
+				Namespace:  o.Object.Namespace,
+				Name:       o.Object.Name,
+			},
+			Type: string(o.Type),
+			Diff: o.Diff,
+		})
+	}
+	return ret
+}
+
+func toOutputMoveResult(groups [][]corev1.ObjectReference, dryRun bool) *outputv1.MoveResult {
+	ret := &outputv1.MoveResult{
+		TypeMeta: outputTypeMeta("MoveResult"),
+		DryRun:   dryRun,
+		Groups:   [][]outputv1.ObjectReference{},
+	}
+	for _, group := range groups {
+		refs := []outputv1.ObjectReference{}
+		for _, ref := range group {
+			refs = append(refs, outputv1.ObjectReference{
+				APIVersion: ref.APIVersion,
+				Kind:       ref.Kind,
+				Namespace:  ref.Namespace,
+				Name:       ref.Name,
+			})
+		}
+		ret.Groups = append(ret.Groups, refs)
+	}
+	return ret
+}
+
+func toOutputCheckResultList(results []client.CheckResult) *outputv1.CheckResultList {
+	ret := &outputv1.CheckResultList{
+		TypeMeta: outputTypeMeta("CheckResultList"),
+		Items:    []outputv1.CheckResult{},
+	}
+	for _, r := range results {
+		ret.Items = append(ret.Items, outputv1.CheckResult{
+			Check:       r.Check,
+			Status:      string(r.Status),
+			Message:     r.Message,
+			Remediation: r.Remediation,
+		})
+	}
+	return ret
+}
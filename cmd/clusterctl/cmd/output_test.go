@@ -0,0 +1,221 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+)
+
+func Test_validateOutput(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, output := range Outputs {
+		g.Expect(validateOutput(output)).To(Succeed())
+	}
+	g.Expect(validateOutput("wide")).ToNot(Succeed())
+}
+
+func Test_printStructuredOutput(t *testing.T) {
+	core := clusterctlv1.Provider{
+		ObjectMeta:   metav1.ObjectMeta{Namespace: "capi-system", Name: "cluster-api"},
+		ProviderName: "cluster-api",
+		Type:         string(clusterctlv1.CoreProviderType),
+		Version:      "v0.3.0",
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		obj     interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "upgrade plan in yaml format",
+			output: OutputYaml,
+			obj: toOutputUpgradePlanList([]client.UpgradePlan{
+				{
+					Contract:     "v1alpha3",
+					CoreProvider: core,
+					Providers:    []cluster.UpgradeItem{{Provider: core, NextVersion: "v0.3.1"}},
+				},
+			}, &client.CertManagerUpgradePlan{Installed: true, From: "v0.10.0", To: "v0.11.0", ShouldUpgrade: true}, nil),
+			want: `apiVersion: output.clusterctl.cluster.x-k8s.io/v1alpha1
+certManager:
+  installed: true
+  nextVersion: v0.11.0
+  version: v0.10.0
+items:
+- contract: v1alpha3
+  managementGroup: capi-system/cluster-api
+  providers:
+  - name: cluster-api
+    namespace: capi-system
+    nextVersion: v0.3.1
+    type: CoreProvider
+    version: v0.3.0
+kind: UpgradePlanList
+`,
+		},
+		{
+			name:   "upgrade plan with version constraint and diff in yaml format",
+			output: OutputYaml,
+			obj: toOutputUpgradePlanList([]client.UpgradePlan{
+				{
+					Contract:     "v1alpha3",
+					CoreProvider: core,
+					Providers:    []cluster.UpgradeItem{{Provider: core, NextVersion: "v0.3.1", VersionConstraint: "v0.3.x"}},
+				},
+			}, nil, [][]client.ComponentsDiff{
+				{
+					{
+						Provider:    core,
+						NextVersion: "v0.3.1",
+						Objects: []cluster.ObjectDiff{
+							{
+								Object: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "capi-system", Name: "capi-controller-manager"},
+								Type:   cluster.ObjectChanged,
+								Diff:   "image changed",
+							},
+						},
+					},
+				},
+			}),
+			want: `apiVersion: output.clusterctl.cluster.x-k8s.io/v1alpha1
+items:
+- contract: v1alpha3
+  managementGroup: capi-system/cluster-api
+  providers:
+  - changes:
+    - diff: image changed
+      object:
+        apiVersion: apps/v1
+        kind: Deployment
+        name: capi-controller-manager
+        namespace: capi-system
+      type: Changed
+    name: cluster-api
+    namespace: capi-system
+    nextVersion: v0.3.1
+    type: CoreProvider
+    version: v0.3.0
+    versionConstraint: v0.3.x
+kind: UpgradePlanList
+`,
+		},
+		{
+			name:   "variables in json format",
+			output: OutputJSON,
+			obj: toOutputVariableList([]string{"CLUSTER_NAME", "KUBERNETES_VERSION"}, &yamlprocessor.VariablesSchema{
+				Variables: map[string]yamlprocessor.VariableSpec{
+					"KUBERNETES_VERSION": {Description: "The Kubernetes version.", Type: yamlprocessor.KubernetesVersionVariableType, Required: true},
+				},
+			}),
+			want: `{
+  "kind": "VariableList",
+  "apiVersion": "output.clusterctl.cluster.x-k8s.io/v1alpha1",
+  "variables": [
+    {
+      "name": "CLUSTER_NAME"
+    },
+    {
+      "name": "KUBERNETES_VERSION",
+      "description": "The Kubernetes version.",
+      "type": "kubernetesVersion",
+      "required": true
+    }
+  ]
+}
+`,
+		},
+		{
+			name:   "empty lists are printed as empty arrays",
+			output: OutputJSON,
+			obj:    toOutputImageList(nil),
+			want: `{
+  "kind": "ImageList",
+  "apiVersion": "output.clusterctl.cluster.x-k8s.io/v1alpha1",
+  "images": []
+}
+`,
+		},
+		{
+			name:   "move result in yaml format",
+			output: OutputYaml,
+			obj: toOutputMoveResult([][]corev1.ObjectReference{
+				{{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "Cluster", Namespace: "ns1", Name: "foo"}},
+			}, true),
+			want: `apiVersion: output.clusterctl.cluster.x-k8s.io/v1alpha1
+dryRun: true
+groups:
+- - apiVersion: cluster.x-k8s.io/v1alpha3
+    kind: Cluster
+    name: foo
+    namespace: ns1
+kind: MoveResult
+`,
+		},
+		{
+			name:   "doctor results in yaml format",
+			output: OutputYaml,
+			obj: toOutputCheckResultList([]client.CheckResult{
+				{Check: "Inventory", Status: cluster.CheckPassed, Message: "Found 1 provider"},
+				{Check: "Controllers", Status: cluster.CheckFailed, Message: "Deployment capi-system/capi-controller-manager has no available replicas", Remediation: "Check the Deployment events."},
+			}),
+			want: `apiVersion: output.clusterctl.cluster.x-k8s.io/v1alpha1
+items:
+- check: Inventory
+  message: Found 1 provider
+  status: pass
+- check: Controllers
+  message: Deployment capi-system/capi-controller-manager has no available replicas
+  remediation: Check the Deployment events.
+  status: fail
+kind: CheckResultList
+`,
+		},
+		{
+			name:    "fails for text output",
+			output:  OutputText,
+			obj:     toOutputProviderList(nil),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			buf := &bytes.Buffer{}
+			err := printStructuredOutput(buf, tt.output, tt.obj)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(buf.String()).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type pivotOptions struct {
+	fromKubeconfig        string
+	fromKubeconfigContext string
+	toKubeconfig          string
+	toKubeconfigContext   string
+	namespace             string
+	timeout               time.Duration
+	deleteSource          bool
+}
+
+var pv = &pivotOptions{}
+
+var pivotCmd = &cobra.Command{
+	Use:   "pivot",
+	Short: "Make a workload cluster manage itself.",
+	Long: LongDesc(`
+		Make a workload cluster manage itself, by turning it into a management cluster.
+
+		Pivot installs in the target cluster the same providers, with the same versions, namespaces and watching
+		namespaces, installed in the source management cluster; then it moves the Cluster API objects to the target
+		cluster and waits for the moved Clusters to be reconciled by the providers running in the target cluster.
+
+		Optionally, once the moved Clusters are reconciled, the providers and cert-manager are deleted from the source
+		management cluster, usually a temporary bootstrap cluster.`),
+
+	Example: Examples(`
+		# Make the workload cluster accessed using target-kubeconfig.yaml manage itself.
+		clusterctl pivot --to-kubeconfig=target-kubeconfig.yaml
+
+		# Make the workload cluster manage itself, and then delete all the providers from the bootstrap cluster.
+		clusterctl pivot --to-kubeconfig=target-kubeconfig.yaml --delete-source`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPivot()
+	},
+}
+
+func init() {
+	pivotCmd.Flags().StringVar(&pv.fromKubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file for the source management cluster. If unspecified, default discovery rules apply.")
+	pivotCmd.Flags().StringVar(&pv.toKubeconfig, "to-kubeconfig", "",
+		"Path to the kubeconfig file to use for the target cluster.")
+	pivotCmd.Flags().StringVar(&pv.fromKubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file for the source management cluster. If empty, current context will be used.")
+	pivotCmd.Flags().StringVar(&pv.toKubeconfigContext, "to-kubeconfig-context", "",
+		"Context to be used within the kubeconfig file for the target cluster. If empty, current context will be used.")
+	pivotCmd.Flags().StringVarP(&pv.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	pivotCmd.Flags().DurationVar(&pv.timeout, "timeout", 0,
+		"The maximum time to wait for the moved Clusters to be reconciled in the target cluster. If zero, defaults to 10 minutes.")
+	pivotCmd.Flags().BoolVar(&pv.deleteSource, "delete-source", false,
+		"Delete the providers, their namespaces and CRDs, and the cert-manager installed by clusterctl from the source management cluster once the moved Clusters are reconciled. Fails if the source management cluster hosts Clusters in other namespaces or providers watching other namespaces.")
+
+	RootCmd.AddCommand(pivotCmd)
+}
+
+func runPivot() error {
+	if pv.toKubeconfig == "" {
+		return errors.New("please specify a target cluster using the --to-kubeconfig flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.Pivot(client.PivotOptions{
+		FromKubeconfig: client.Kubeconfig{Path: pv.fromKubeconfig, Context: pv.fromKubeconfigContext},
+		ToKubeconfig:   client.Kubeconfig{Path: pv.toKubeconfig, Context: pv.toKubeconfigContext},
+		Namespace:      pv.namespace,
+		Timeout:        pv.timeout,
+		DeleteSource:   pv.deleteSource,
+	})
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type rolloutOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+}
+
+var rlo = &rolloutOptions{}
+
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Manage the rollout of MachineDeployments.",
+	Long: LongDesc(`
+		Manage the rollout of MachineDeployments.
+
+		MachineDeployments can be specified either by name or using the machinedeployment/NAME notation.`),
+}
+
+func init() {
+	rolloutCmd.PersistentFlags().StringVar(&rlo.kubeconfig, "kubeconfig", "",
+		"Path to a kubeconfig file to use for the management cluster. If empty, default discovery rules apply.")
+	rolloutCmd.PersistentFlags().StringVar(&rlo.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	rolloutCmd.PersistentFlags().StringVarP(&rlo.namespace, "namespace", "n", "",
+		"The namespace where the MachineDeployment is located. If unspecified, the current namespace will be used.")
+
+	RootCmd.AddCommand(rolloutCmd)
+}
+
+// rolloutArgs validates the arguments of the rollout commands.
+func rolloutArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("please specify a MachineDeployment name")
+	}
+	_, err := parseMachineDeploymentArg(args[0])
+	return err
+}
+
+// parseMachineDeploymentArg returns the name of a MachineDeployment, specified either as NAME or as machinedeployment/NAME.
+func parseMachineDeploymentArg(arg string) (string, error) {
+	parts := strings.Split(arg, "/")
+	switch len(parts) {
+	case 1:
+		return parts[0], nil
+	case 2:
+		switch strings.ToLower(parts[0]) {
+		case "machinedeployment", "machinedeployments", "md":
+			return parts[1], nil
+		}
+		return "", errors.Errorf("invalid resource type %q; only MachineDeployments are supported", parts[0])
+	}
+	return "", errors.Errorf("invalid MachineDeployment %q", arg)
+}
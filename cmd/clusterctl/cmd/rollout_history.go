@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutHistoryCmd = &cobra.Command{
+	Use:   "history MACHINEDEPLOYMENT",
+	Short: "Show the revisions of a MachineDeployment.",
+	Long: LongDesc(`
+		Show the revisions of a MachineDeployment, as recorded by the MachineSets
+		controlled by the MachineDeployment.`),
+
+	Example: Examples(`
+		# Show the revisions of the MachineDeployment named md-0.
+		clusterctl rollout history machinedeployment/md-0`),
+
+	Args: rolloutArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutHistory(args[0], os.Stdout)
+	},
+}
+
+func init() {
+	rolloutCmd.AddCommand(rolloutHistoryCmd)
+}
+
+func runRolloutHistory(arg string, out io.Writer) error {
+	name, err := parseMachineDeploymentArg(arg)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := c.RolloutHistory(client.RolloutOptions{
+		Kubeconfig:        client.Kubeconfig{Path: rlo.kubeconfig, Context: rlo.kubeconfigContext},
+		Namespace:         rlo.namespace,
+		MachineDeployment: name,
+	})
+	if err != nil {
+		return err
+	}
+
+	printRolloutHistory(out, revisions)
+	return nil
+}
+
+// printRolloutHistory prints the revisions of a MachineDeployment in text format.
+func printRolloutHistory(out io.Writer, revisions []client.RolloutRevision) {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tMACHINESET\tREPLICAS\tVERSION\tINFRASTRUCTURE")
+	for _, r := range revisions {
+		version := ""
+		if r.Template.Spec.Version != nil {
+			version = *r.Template.Spec.Version
+		}
+		infrastructure := fmt.Sprintf("%s/%s", r.Template.Spec.InfrastructureRef.Kind, r.Template.Spec.InfrastructureRef.Name)
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", r.Revision, r.MachineSet, r.Replicas, version, infrastructure)
+	}
+	w.Flush()
+}
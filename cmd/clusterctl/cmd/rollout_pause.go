@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutPauseCmd = &cobra.Command{
+	Use:   "pause MACHINEDEPLOYMENT",
+	Short: "Pause the rollout of a MachineDeployment.",
+	Long: LongDesc(`
+		Pause the rollout of a MachineDeployment.
+
+		Changes to a paused MachineDeployment do not trigger the rollout of new Machines
+		until the MachineDeployment is resumed.`),
+
+	Example: Examples(`
+		# Pause the MachineDeployment named md-0.
+		clusterctl rollout pause machinedeployment/md-0`),
+
+	Args: rolloutArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutPause(args[0])
+	},
+}
+
+func init() {
+	rolloutCmd.AddCommand(rolloutPauseCmd)
+}
+
+func runRolloutPause(arg string) error {
+	name, err := parseMachineDeploymentArg(arg)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := c.RolloutPause(client.RolloutOptions{
+		Kubeconfig:        client.Kubeconfig{Path: rlo.kubeconfig, Context: rlo.kubeconfigContext},
+		Namespace:         rlo.namespace,
+		MachineDeployment: name,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("MachineDeployment %q paused\n", name)
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutRestartCmd = &cobra.Command{
+	Use:   "restart MACHINEDEPLOYMENT",
+	Short: "Restart a MachineDeployment, replacing all its Machines.",
+	Long: LongDesc(`
+		Restart a MachineDeployment, replacing all its Machines without changing the MachineDeployment spec.
+
+		The rollout is triggered by adding the clusterctl.cluster.x-k8s.io/restartedAt annotation
+		to the machine template, so the new Machines are created following the MachineDeployment strategy.`),
+
+	Example: Examples(`
+		# Restart the MachineDeployment named md-0.
+		clusterctl rollout restart machinedeployment/md-0`),
+
+	Args: rolloutArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutRestart(args[0])
+	},
+}
+
+func init() {
+	rolloutCmd.AddCommand(rolloutRestartCmd)
+}
+
+func runRolloutRestart(arg string) error {
+	name, err := parseMachineDeploymentArg(arg)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := c.RolloutRestart(client.RolloutOptions{
+		Kubeconfig:        client.Kubeconfig{Path: rlo.kubeconfig, Context: rlo.kubeconfigContext},
+		Namespace:         rlo.namespace,
+		MachineDeployment: name,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("MachineDeployment %q restarted\n", name)
+	return nil
+}
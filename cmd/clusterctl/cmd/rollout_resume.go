@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutResumeCmd = &cobra.Command{
+	Use:   "resume MACHINEDEPLOYMENT",
+	Short: "Resume the rollout of a paused MachineDeployment.",
+	Long: LongDesc(`
+		Resume the rollout of a paused MachineDeployment.`),
+
+	Example: Examples(`
+		# Resume the MachineDeployment named md-0.
+		clusterctl rollout resume machinedeployment/md-0`),
+
+	Args: rolloutArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutResume(args[0])
+	},
+}
+
+func init() {
+	rolloutCmd.AddCommand(rolloutResumeCmd)
+}
+
+func runRolloutResume(arg string) error {
+	name, err := parseMachineDeploymentArg(arg)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := c.RolloutResume(client.RolloutOptions{
+		Kubeconfig:        client.Kubeconfig{Path: rlo.kubeconfig, Context: rlo.kubeconfigContext},
+		Namespace:         rlo.namespace,
+		MachineDeployment: name,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("MachineDeployment %q resumed\n", name)
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type rolloutStatusOptions struct {
+	watch   bool
+	timeout time.Duration
+}
+
+var rso = &rolloutStatusOptions{}
+
+var rolloutStatusCmd = &cobra.Command{
+	Use:   "status MACHINEDEPLOYMENT",
+	Short: "Show the status of the rollout of a MachineDeployment.",
+	Long: LongDesc(`
+		Show the status of the rollout of a MachineDeployment.
+
+		By default, the command waits until the rollout is complete; if the MachineDeployment
+		does not progress for more than its ProgressDeadlineSeconds, the command fails.`),
+
+	Example: Examples(`
+		# Wait for the rollout of the MachineDeployment named md-0 to complete.
+		clusterctl rollout status machinedeployment/md-0
+
+		# Show the status of the rollout of the MachineDeployment named md-0 without waiting.
+		clusterctl rollout status md-0 --watch=false`),
+
+	Args: rolloutArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutStatus(args[0])
+	},
+}
+
+func init() {
+	rolloutStatusCmd.Flags().BoolVarP(&rso.watch, "watch", "w", true,
+		"Wait for the rollout to complete.")
+	rolloutStatusCmd.Flags().DurationVar(&rso.timeout, "timeout", 0,
+		"The maximum time to wait for the rollout to complete. If zero, wait until the rollout completes or exceeds its progress deadline.")
+
+	rolloutCmd.AddCommand(rolloutStatusCmd)
+}
+
+func runRolloutStatus(arg string) error {
+	name, err := parseMachineDeploymentArg(arg)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	status, err := c.RolloutStatus(client.RolloutStatusOptions{
+		Kubeconfig:        client.Kubeconfig{Path: rlo.kubeconfig, Context: rlo.kubeconfigContext},
+		Namespace:         rlo.namespace,
+		MachineDeployment: name,
+		Wait:              rso.watch,
+		Timeout:           rso.timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(status.Message)
+	return nil
+}
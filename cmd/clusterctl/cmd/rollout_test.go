@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_parseMachineDeploymentArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "name",
+			arg:     "md-0",
+			want:    "md-0",
+			wantErr: false,
+		},
+		{
+			name:    "resource notation",
+			arg:     "machinedeployment/md-0",
+			want:    "md-0",
+			wantErr: false,
+		},
+		{
+			name:    "resource notation with short name",
+			arg:     "md/md-0",
+			want:    "md-0",
+			wantErr: false,
+		},
+		{
+			name:    "resource notation with unsupported resource type",
+			arg:     "machineset/ms-0",
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			arg:     "machinedeployment/md-0/foo",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseMachineDeploymentArg(tt.arg)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type rolloutUndoOptions struct {
+	toRevision int64
+}
+
+var ruo = &rolloutUndoOptions{}
+
+var rolloutUndoCmd = &cobra.Command{
+	Use:   "undo MACHINEDEPLOYMENT",
+	Short: "Rollback a MachineDeployment to a previous revision.",
+	Long: LongDesc(`
+		Rollback a MachineDeployment to a previous revision, restoring the machine template
+		of the MachineSet hosting the revision.`),
+
+	Example: Examples(`
+		# Rollback the MachineDeployment named md-0 to the previous revision.
+		clusterctl rollout undo machinedeployment/md-0
+
+		# Rollback the MachineDeployment named md-0 to revision 3.
+		clusterctl rollout undo machinedeployment/md-0 --to-revision=3`),
+
+	Args: rolloutArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutUndo(args[0])
+	},
+}
+
+func init() {
+	rolloutUndoCmd.Flags().Int64Var(&ruo.toRevision, "to-revision", 0,
+		"The revision to rollback to. If zero, rollback to the previous revision.")
+
+	rolloutCmd.AddCommand(rolloutUndoCmd)
+}
+
+func runRolloutUndo(arg string) error {
+	name, err := parseMachineDeploymentArg(arg)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := c.RolloutUndo(client.RolloutUndoOptions{
+		Kubeconfig:        client.Kubeconfig{Path: rlo.kubeconfig, Context: rlo.kubeconfigContext},
+		Namespace:         rlo.namespace,
+		MachineDeployment: name,
+		ToRevision:        ruo.toRevision,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("MachineDeployment %q rolled back\n", name)
+	return nil
+}
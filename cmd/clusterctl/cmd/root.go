@@ -37,6 +37,7 @@ type stackTracer interface {
 var (
 	cfgFile   string
 	verbosity *int
+	offline   bool
 )
 
 var RootCmd = &cobra.Command{
@@ -70,6 +71,8 @@ func init() {
 	RootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "",
 		"Path to clusterctl configuration (default is `$HOME/.cluster-api/clusterctl.yaml`)")
+	RootCmd.PersistentFlags().BoolVar(&offline, "offline", false,
+		"Serve provider repository artifacts only from the local cache. When set, either to true or to false, this overrides the CLUSTERCTL_OFFLINE variable.")
 
 	cobra.OnInitialize(initConfig)
 }
@@ -92,6 +95,16 @@ func initConfig() {
 	}
 
 	logf.SetLogger(logf.NewLogger(logf.WithThreshold(verbosity)))
+
+	// the offline flag, when set, is propagated to the clusterctl library via the CLUSTERCTL_OFFLINE environment variable,
+	// so it applies to all the config clients created by the commands and overrides the value from the environment
+	// or from the config file, both when set to true and to false.
+	if RootCmd.PersistentFlags().Changed("offline") {
+		if err := os.Setenv(config.OfflineVariable, strconv.FormatBool(offline)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set %s. err=%s\n", config.OfflineVariable, err.Error())
+			os.Exit(1)
+		}
+	}
 }
 
 const Indentation = `  `
@@ -25,7 +25,7 @@ import (
 
 var upgradeCmd = &cobra.Command{
 	Use:   "upgrade",
-	Short: "Upgrade core and provider components in a management cluster.",
+	Short: "Upgrade core and provider components in a management cluster, or the Kubernetes version of workload clusters.",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return cmd.Help()
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type upgradeClusterOptions struct {
+	kubeconfig             string
+	kubeconfigContext      string
+	namespace              string
+	kubernetesVersion      string
+	machineDeploymentOrder []string
+	timeout                time.Duration
+	dryRun                 bool
+}
+
+var uc = &upgradeClusterOptions{}
+
+var upgradeClusterCmd = &cobra.Command{
+	Use:   "cluster NAME",
+	Short: "Upgrade the Kubernetes version of a workload cluster.",
+	Long: LongDesc(`
+		Upgrade the Kubernetes version of a workload cluster.
+
+		The control plane is upgraded first, then each MachineDeployment is upgraded one at time;
+		each step waits for the rollout to complete before moving to the next one.
+
+		Upgrades skipping minor versions or downgrades are not allowed, and the upgrade stops if any
+		Machine in the workload cluster reports a false health check condition.`),
+
+	Example: Examples(`
+		# Preview the steps for upgrading the workload cluster named test-1 to Kubernetes v1.18.2.
+		clusterctl upgrade cluster test-1 --kubernetes-version=v1.18.2 --dry-run
+
+		# Upgrade the workload cluster named test-1 to Kubernetes v1.18.2.
+		clusterctl upgrade cluster test-1 --kubernetes-version=v1.18.2
+
+		# Upgrade the workload cluster named test-1 to Kubernetes v1.18.2, upgrading the MachineDeployment
+		# named test-1-md-canary before the other MachineDeployments.
+		clusterctl upgrade cluster test-1 --kubernetes-version=v1.18.2 --machine-deployment-order=test-1-md-canary`),
+
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("please specify a cluster name")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUpgradeCluster(args[0], os.Stdout)
+	},
+}
+
+func init() {
+	upgradeClusterCmd.Flags().StringVar(&uc.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	upgradeClusterCmd.Flags().StringVar(&uc.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	upgradeClusterCmd.Flags().StringVarP(&uc.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is located. If unspecified, the current namespace will be used.")
+
+	upgradeClusterCmd.Flags().StringVar(&uc.kubernetesVersion, "kubernetes-version", "",
+		"The target Kubernetes version.")
+	upgradeClusterCmd.Flags().StringSliceVar(&uc.machineDeploymentOrder, "machine-deployment-order", nil,
+		"The names of the MachineDeployments to be upgraded first, in order. The other MachineDeployments are upgraded afterwards, in alphabetical order.")
+	upgradeClusterCmd.Flags().DurationVar(&uc.timeout, "timeout", 0,
+		"The maximum time to wait for the control plane or for each MachineDeployment to be upgraded. If zero, defaults to 30 minutes.")
+	upgradeClusterCmd.Flags().BoolVar(&uc.dryRun, "dry-run", false,
+		"Print the upgrade plan without upgrading the cluster.")
+
+	upgradeCmd.AddCommand(upgradeClusterCmd)
+}
+
+func runUpgradeCluster(clusterName string, out io.Writer) error {
+	if uc.kubernetesVersion == "" {
+		return errors.New("please specify the target Kubernetes version using the --kubernetes-version flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	options := client.ClusterUpgradeOptions{
+		Kubeconfig:             client.Kubeconfig{Path: uc.kubeconfig, Context: uc.kubeconfigContext},
+		Namespace:              uc.namespace,
+		ClusterName:            clusterName,
+		KubernetesVersion:      uc.kubernetesVersion,
+		MachineDeploymentOrder: uc.machineDeploymentOrder,
+		Timeout:                uc.timeout,
+	}
+
+	plan, err := c.PlanClusterUpgrade(options)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Steps) == 0 {
+		fmt.Fprintf(out, "Cluster %q is already at Kubernetes %s\n", clusterName, uc.kubernetesVersion)
+		return nil
+	}
+
+	printKubernetesUpgradePlan(out, plan)
+	if uc.dryRun {
+		return nil
+	}
+
+	fmt.Fprintln(out, "")
+	if err := c.ApplyClusterUpgrade(options); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Cluster %q upgraded to Kubernetes %s\n", clusterName, uc.kubernetesVersion)
+	return nil
+}
+
+// printKubernetesUpgradePlan prints the steps of a Kubernetes upgrade plan in text format.
+func printKubernetesUpgradePlan(out io.Writer, plan *client.KubernetesUpgradePlan) {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "STEP\tKIND\tNAME\tCURRENT VERSION\tTARGET VERSION")
+	for i, step := range plan.Steps {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, step.Object.Kind, step.Object.Name, step.CurrentVersion, step.TargetVersion)
+	}
+	w.Flush()
+}
@@ -18,7 +18,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -28,6 +30,8 @@ import (
 type upgradePlanOptions struct {
 	kubeconfig        string
 	kubeconfigContext string
+	output            string
+	diff              bool
 }
 
 var up = &upgradePlanOptions{}
@@ -43,14 +47,29 @@ var upgradePlanCmd = &cobra.Command{
 
 		Then, for each provider in a management group, the following upgrade options are provided:
 		- The latest patch release for the current API Version of Cluster API (contract).
-		- The latest patch release for the next API Version of Cluster API (contract), if available.`),
+		- The latest patch release for the next API Version of Cluster API (contract), if available.
+
+		Providers pinned to a range of versions in the versionConstraints section of the clusterctl configuration
+		are upgraded only to versions in that range.
+
+		Use the --diff flag for showing the changes to the provider components, e.g. CRDs, RBAC rules and Deployments,
+		between the current and the target version of each provider.
+
+		The upgrade plan includes also cert-manager, if installed by clusterctl; cert-manager is upgraded
+		to the version embedded in clusterctl when applying an upgrade plan.`),
 
 	Example: Examples(`
 		# Gets the recommended target versions for upgrading Cluster API providers.
-		clusterctl upgrade plan`),
+		clusterctl upgrade plan
+
+		# Gets the recommended target versions for upgrading Cluster API providers in json format.
+		clusterctl upgrade plan -o json
+
+		# Gets the recommended target versions, together with the changes to the provider components.
+		clusterctl upgrade plan --diff`),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runUpgradePlan()
+		return runUpgradePlan(os.Stdout)
 	},
 }
 
@@ -59,59 +78,150 @@ func init() {
 		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
 	upgradePlanCmd.Flags().StringVar(&up.kubeconfigContext, "kubeconfig-context", "",
 		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	upgradePlanCmd.Flags().BoolVar(&up.diff, "diff", false,
+		"Show the changes to the provider components between the current and the target version of each provider.")
+	addOutputFlag(upgradePlanCmd.Flags(), &up.output)
 }
 
-func runUpgradePlan() error {
+func runUpgradePlan(out io.Writer) error {
+	if err := validateOutput(up.output); err != nil {
+		return err
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
 	}
 
-	upgradePlans, err := c.PlanUpgrade(client.PlanUpgradeOptions{
+	options := client.PlanUpgradeOptions{
 		Kubeconfig: client.Kubeconfig{Path: up.kubeconfig, Context: up.kubeconfigContext},
-	})
+	}
+
+	certManagerPlan, err := c.PlanCertManagerUpgrade(options)
+	if err != nil {
+		return err
+	}
+
+	upgradePlans, err := c.PlanUpgrade(options)
 	if err != nil {
 		return err
 	}
 
 	// ensure upgrade plans are sorted consistently (by CoreProvider.Namespace, Contract).
 	sortUpgradePlans(upgradePlans)
+	for _, plan := range upgradePlans {
+		// ensure provider are sorted consistently (by Type, Name, Namespace).
+		sortUpgradeItems(plan)
+	}
+
+	// Gets the changes to the provider components for each upgrade plan, if requested.
+	var diffs [][]client.ComponentsDiff
+	if up.diff {
+		for _, plan := range upgradePlans {
+			diff, err := c.DiffUpgrade(client.DiffUpgradeOptions{
+				Kubeconfig:      options.Kubeconfig,
+				ManagementGroup: plan.CoreProvider.InstanceName(),
+				Contract:        plan.Contract,
+			})
+			if err != nil {
+				return err
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+
+	if isStructuredOutput(up.output) {
+		return printStructuredOutput(out, up.output, toOutputUpgradePlanList(upgradePlans, &certManagerPlan, diffs))
+	}
+
+	printCertManagerUpgradePlan(out, certManagerPlan)
 
 	if len(upgradePlans) == 0 {
-		fmt.Println("There are no management groups in the cluster. Please use clusterctl init to initialize a Cluster API management cluster.")
+		fmt.Fprintln(out, "There are no management groups in the cluster. Please use clusterctl init to initialize a Cluster API management cluster.")
 		return nil
 	}
 
-	for _, plan := range upgradePlans {
-		// ensure provider are sorted consistently (by Type, Name, Namespace).
-		sortUpgradeItems(plan)
-
+	for i, plan := range upgradePlans {
 		upgradeAvailable := false
 
-		fmt.Println("")
-		fmt.Printf("Management group: %s, latest release available for the %s API Version of Cluster API (contract):\n", plan.CoreProvider.InstanceName(), plan.Contract)
-		fmt.Println("")
-		w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(out, "")
+		fmt.Fprintf(out, "Management group: %s, latest release available for the %s API Version of Cluster API (contract):\n", plan.CoreProvider.InstanceName(), plan.Contract)
+		fmt.Fprintln(out, "")
+		w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
 		fmt.Fprintln(w, "NAME\tNAMESPACE\tTYPE\tCURRENT VERSION\tNEXT VERSION")
 		for _, upgradeItem := range plan.Providers {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", upgradeItem.Provider.Name, upgradeItem.Provider.Namespace, upgradeItem.Provider.Type, upgradeItem.Provider.Version, prettifyTargetVersion(upgradeItem.NextVersion))
+			nextVersion := prettifyTargetVersion(upgradeItem.NextVersion)
+			if upgradeItem.VersionConstraint != "" {
+				nextVersion = fmt.Sprintf("%s (pinned to %s)", nextVersion, upgradeItem.VersionConstraint)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", upgradeItem.Provider.Name, upgradeItem.Provider.Namespace, upgradeItem.Provider.Type, upgradeItem.Provider.Version, nextVersion)
 			if upgradeItem.NextVersion != "" {
 				upgradeAvailable = true
 			}
 		}
 		w.Flush()
-		fmt.Println("")
+		fmt.Fprintln(out, "")
+
+		if i < len(diffs) {
+			printComponentsDiffs(out, diffs[i])
+		}
 
 		if upgradeAvailable {
-			fmt.Println("You can now apply the upgrade by executing the following command:")
-			fmt.Println("")
-			fmt.Printf("   upgrade apply --management-group %s --contract %s\n", plan.CoreProvider.InstanceName(), plan.Contract)
+			fmt.Fprintln(out, "You can now apply the upgrade by executing the following command:")
+			fmt.Fprintln(out, "")
+			fmt.Fprintf(out, "   upgrade apply --management-group %s --contract %s\n", plan.CoreProvider.InstanceName(), plan.Contract)
 		} else {
-			fmt.Println("You are already up to date!")
+			fmt.Fprintln(out, "You are already up to date!")
 		}
-		fmt.Println("")
+		fmt.Fprintln(out, "")
 
 	}
 
 	return nil
 }
+
+func printCertManagerUpgradePlan(out io.Writer, plan client.CertManagerUpgradePlan) {
+	if !plan.Installed {
+		return
+	}
+
+	fmt.Fprintln(out, "")
+	switch {
+	case plan.ExternallyManaged:
+		fmt.Fprintln(out, "cert-manager is not managed by clusterctl, skipping the cert-manager upgrade.")
+	case plan.ShouldUpgrade:
+		from := plan.From
+		if from == "" {
+			from = "unknown version"
+		}
+		fmt.Fprintf(out, "cert-manager will be upgraded from %s to %s when applying the upgrade.\n", from, plan.To)
+	default:
+		fmt.Fprintf(out, "cert-manager is already up to date (%s).\n", plan.From)
+	}
+}
+
+// printComponentsDiffs prints the changes to the provider components resulting from an upgrade plan.
+func printComponentsDiffs(out io.Writer, diffs []client.ComponentsDiff) {
+	for _, diff := range diffs {
+		fmt.Fprintf(out, "Changes to the %s provider components (%s -> %s):\n", diff.InstanceName(), diff.Version, diff.NextVersion)
+		fmt.Fprintln(out, "")
+		if len(diff.Objects) == 0 {
+			fmt.Fprintln(out, "   No changes")
+			fmt.Fprintln(out, "")
+			continue
+		}
+		for _, o := range diff.Objects {
+			name := o.Object.Name
+			if o.Object.Namespace != "" {
+				name = fmt.Sprintf("%s/%s", o.Object.Namespace, name)
+			}
+			fmt.Fprintf(out, "   %s %s %s\n", o.Type, o.Object.Kind, name)
+			if o.Diff != "" {
+				for _, line := range strings.Split(strings.TrimRight(o.Diff, "\n"), "\n") {
+					fmt.Fprintf(out, "     %s\n", line)
+				}
+			}
+		}
+		fmt.Fprintln(out, "")
+	}
+}
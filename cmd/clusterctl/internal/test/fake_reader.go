@@ -28,6 +28,7 @@ type FakeReader struct {
 	variables   map[string]string
 	providers   []configProvider
 	imageMetas  map[string]imageMeta
+	constraints map[string]string
 }
 
 // configProvider is a mirror of config.Provider, re-implemented here in order to
@@ -71,8 +72,9 @@ func (f *FakeReader) UnmarshalKey(key string, rawval interface{}) error {
 
 func NewFakeReader() *FakeReader {
 	return &FakeReader{
-		variables:  map[string]string{},
-		imageMetas: map[string]imageMeta{},
+		variables:   map[string]string{},
+		imageMetas:  map[string]imageMeta{},
+		constraints: map[string]string{},
 	}
 }
 
@@ -105,3 +107,12 @@ func (f *FakeReader) WithImageMeta(component, repository, tag string) *FakeReade
 
 	return f
 }
+
+func (f *FakeReader) WithVersionConstraint(provider, constraint string) *FakeReader {
+	f.constraints[provider] = constraint
+
+	yaml, _ := yaml.Marshal(f.constraints)
+	f.variables["versionConstraints"] = string(yaml)
+
+	return f
+}
@@ -26,6 +26,14 @@ import (
 
 type GenericControlPlaneSpec struct {
 	InfrastructureTemplate corev1.ObjectReference `json:"infrastructureTemplate"`
+	Version                string                 `json:"version,omitempty"`
+}
+
+type GenericControlPlaneStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	Replicas           int32 `json:"replicas,omitempty"`
+	UpdatedReplicas    int32 `json:"updatedReplicas,omitempty"`
+	ReadyReplicas      int32 `json:"readyReplicas,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -33,7 +41,8 @@ type GenericControlPlaneSpec struct {
 type GenericControlPlane struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec              GenericControlPlaneSpec `json:"spec,omitempty"`
+	Spec              GenericControlPlaneSpec   `json:"spec,omitempty"`
+	Status            GenericControlPlaneStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
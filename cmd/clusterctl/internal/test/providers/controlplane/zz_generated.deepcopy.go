@@ -30,6 +30,7 @@ func (in *GenericControlPlane) DeepCopyInto(out *GenericControlPlane) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	out.Status = in.Status
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControlPlane.
@@ -97,3 +98,18 @@ func (in *GenericControlPlaneSpec) DeepCopy() *GenericControlPlaneSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericControlPlaneStatus) DeepCopyInto(out *GenericControlPlaneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControlPlaneStatus.
+func (in *GenericControlPlaneStatus) DeepCopy() *GenericControlPlaneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericControlPlaneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -19,6 +19,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -62,6 +64,10 @@ var (
 	errClusterIsBeingDeleted = errors.New("cluster is being deleted")
 )
 
+// drainRetryInterval is the time a single drain attempt waits for pods to be evicted; if the drain does not
+// complete in this interval, it is retried at the next reconcile.
+const drainRetryInterval = 20 * time.Second
+
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;create;update;patch;delete
@@ -278,6 +284,12 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	logger := r.Log.WithValues("machine", m.Name, "namespace", m.Namespace)
 	logger = logger.WithValues("cluster", cluster.Name)
 
+	// Wait for the pre-drain hooks to be removed before draining the node.
+	if hooks := reconcileDeleteHooks(m, clusterv1.PreDrainDeleteHookAnnotationPrefix, clusterv1.PreDrainDeleteHookSucceededCondition); len(hooks) > 0 {
+		logger.Info("Waiting for pre-drain hooks to be removed", "hooks", hooks)
+		return ctrl.Result{}, nil
+	}
+
 	err := r.isDeleteNodeAllowed(ctx, cluster, m)
 	isDeleteNodeAllowed := err == nil
 	if err != nil {
@@ -292,15 +304,18 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	if isDeleteNodeAllowed {
 		// Drain node before deletion.
 		if _, exists := m.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; !exists {
-			logger.Info("Draining node", "node", m.Status.NodeRef.Name)
-			if err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name, m.Name); err != nil {
-				r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
-				return ctrl.Result{}, err
+			if result, err := r.reconcileDrainNode(ctx, cluster, m); !result.IsZero() || err != nil {
+				return result, err
 			}
-			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
 		}
 	}
 
+	// Wait for the pre-terminate hooks to be removed before deleting the bootstrap and infrastructure objects.
+	if hooks := reconcileDeleteHooks(m, clusterv1.PreTerminateDeleteHookAnnotationPrefix, clusterv1.PreTerminateDeleteHookSucceededCondition); len(hooks) > 0 {
+		logger.Info("Waiting for pre-terminate hooks to be removed", "hooks", hooks)
+		return ctrl.Result{}, nil
+	}
+
 	if ok, err := r.reconcileDeleteExternal(ctx, m); !ok || err != nil {
 		// Return early and don't remove the finalizer if we got an error or
 		// the external reconciliation deletion isn't ready.
@@ -329,6 +344,26 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	return ctrl.Result{}, nil
 }
 
+// reconcileDeleteHooks returns the names of the deletion hooks registered on a machine with the given annotation prefix,
+// and reports them in the given condition; the deletion must be paused until all the hooks are removed.
+// NOTE: a change to the annotations triggers a new reconcile, so there is no need to requeue while waiting.
+func reconcileDeleteHooks(m *clusterv1.Machine, prefix string, conditionType clusterv1.ConditionType) []string {
+	hooks := []string{}
+	for k := range m.GetAnnotations() {
+		if strings.HasPrefix(k, prefix+"/") {
+			hooks = append(hooks, strings.TrimPrefix(k, prefix+"/"))
+		}
+	}
+	if len(hooks) == 0 {
+		conditions.MarkTrue(m, conditionType)
+		return nil
+	}
+
+	sort.Strings(hooks)
+	conditions.MarkFalse(m, conditionType, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "Waiting for hooks: %s", strings.Join(hooks, ", "))
+	return hooks
+}
+
 // isDeleteNodeAllowed returns nil only if the Machine's NodeRef is not nil
 // and if the Machine is not the last control plane node in the cluster.
 func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
@@ -362,7 +397,89 @@ func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, cluster *cl
 	}
 }
 
-func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cluster, nodeName string, machineName string) error {
+// reconcileDrainNode drains the node hosted by a machine being deleted according to the machine's node drain spec,
+// reporting progress in the DrainingSucceeded condition; a non zero result is returned while the drain is in progress.
+func (r *MachineReconciler) reconcileDrainNode(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	logger := r.Log.WithValues("machine", m.Name, "namespace", m.Namespace, "cluster", cluster.Name)
+
+	// Return early if the drain is already completed or if it timed out in a previous reconcile.
+	if conditions.IsTrue(m, clusterv1.DrainingSucceededCondition) ||
+		conditions.GetReason(m, clusterv1.DrainingSucceededCondition) == clusterv1.DrainingTimedOutReason {
+		return ctrl.Result{}, nil
+	}
+
+	// Record when the drain started, so the drain timeout can be enforced across reconciles.
+	if m.Status.NodeDrainStartTime == nil {
+		now := metav1.Now()
+		m.Status.NodeDrainStartTime = &now
+	}
+
+	if isNodeDrainTimeoutExpired(m) {
+		timeout := m.Spec.NodeDrain.Timeout.Duration
+		logger.Info("Timed out draining node, moving on", "node", m.Status.NodeRef.Name, "timeout", timeout)
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "DrainNodeTimedOut", "timed out draining Machine's node %q after %s, moving on", m.Status.NodeRef.Name, timeout)
+		conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingTimedOutReason, clusterv1.ConditionSeverityWarning, "Drain did not complete within %s", timeout)
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Draining node", "node", m.Status.NodeRef.Name)
+	conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before deletion")
+	if err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name, m.Name, m.Spec.NodeDrain); err != nil {
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+		conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		// Machine will be re-reconciled after a drain failure.
+		return ctrl.Result{RequeueAfter: drainRetryInterval}, nil
+	}
+
+	r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
+	conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
+	return ctrl.Result{}, nil
+}
+
+// isNodeDrainTimeoutExpired returns true if the machine has a drain timeout and the drain of its node
+// started longer than the timeout ago.
+func isNodeDrainTimeoutExpired(m *clusterv1.Machine) bool {
+	if m.Spec.NodeDrain == nil || m.Spec.NodeDrain.Timeout == nil || m.Status.NodeDrainStartTime == nil {
+		return false
+	}
+	return time.Since(m.Status.NodeDrainStartTime.Time) >= m.Spec.NodeDrain.Timeout.Duration
+}
+
+// newDrainHelper returns a drain helper configured according to a machine's node drain spec;
+// if the spec is not set, the drain evicts all the pods but DaemonSet ones, deleting emptyDir data and
+// honoring the pods' own termination grace period.
+func newDrainHelper(spec *clusterv1.NodeDrainSpec) (*kubedrain.Helper, error) {
+	drainer := &kubedrain.Helper{
+		Force:               true,
+		IgnoreAllDaemonSets: true,
+		DeleteLocalData:     true,
+		GracePeriodSeconds:  -1,
+		// If a pod is not evicted in 20 seconds, retry the eviction next time the
+		// machine gets reconciled again (to allow other machines to be reconciled).
+		Timeout: drainRetryInterval,
+	}
+	if spec == nil {
+		return drainer, nil
+	}
+
+	if spec.DeleteEmptyDirData != nil {
+		drainer.DeleteLocalData = *spec.DeleteEmptyDirData
+		drainer.SkipLocalData = !*spec.DeleteEmptyDirData
+	}
+	if spec.GracePeriodSeconds != nil {
+		drainer.GracePeriodSeconds = int(*spec.GracePeriodSeconds)
+	}
+	for i := range spec.SkipPodSelectors {
+		selector, err := metav1.LabelSelectorAsSelector(&spec.SkipPodSelectors[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid skip pod selector %d", i)
+		}
+		drainer.SkipPodSelectors = append(drainer.SkipPodSelectors, selector)
+	}
+	return drainer, nil
+}
+
+func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cluster, nodeName string, machineName string, spec *clusterv1.NodeDrainSpec) error {
 	logger := r.Log.WithValues("machine", machineName, "node", nodeName, "cluster", cluster.Name, "namespace", cluster.Namespace)
 
 	restConfig, err := remote.RESTConfig(ctx, r.Client, util.ObjectKey(cluster))
@@ -386,27 +503,21 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 		return errors.Errorf("unable to get node %q: %v", nodeName, err)
 	}
 
-	drainer := &kubedrain.Helper{
-		Client:              kubeClient,
-		Force:               true,
-		IgnoreAllDaemonSets: true,
-		DeleteLocalData:     true,
-		GracePeriodSeconds:  -1,
-		// If a pod is not evicted in 20 seconds, retry the eviction next time the
-		// machine gets reconciled again (to allow other machines to be reconciled).
-		Timeout: 20 * time.Second,
-		OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
-			verbStr := "Deleted"
-			if usingEviction {
-				verbStr = "Evicted"
-			}
-			logger.Info(fmt.Sprintf("%s pod from Node", verbStr),
-				"pod", fmt.Sprintf("%s/%s", pod.Name, pod.Namespace))
-		},
-		Out:    writer{klog.Info},
-		ErrOut: writer{klog.Error},
-		DryRun: false,
+	drainer, err := newDrainHelper(spec)
+	if err != nil {
+		return err
+	}
+	drainer.Client = kubeClient
+	drainer.OnPodDeletedOrEvicted = func(pod *corev1.Pod, usingEviction bool) {
+		verbStr := "Deleted"
+		if usingEviction {
+			verbStr = "Evicted"
+		}
+		logger.Info(fmt.Sprintf("%s pod from Node", verbStr),
+			"pod", fmt.Sprintf("%s/%s", pod.Name, pod.Namespace))
 	}
+	drainer.Out = writer{klog.Info}
+	drainer.ErrOut = writer{klog.Error}
 
 	if noderefutil.IsNodeUnreachable(node) {
 		// When the node is unreachable and some pods are not evicted for as long as this timeout, we ignore them.
@@ -422,7 +533,7 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 	if err := kubedrain.RunNodeDrain(drainer, node.Name); err != nil {
 		// Machine will be re-reconciled after a drain failure.
 		logger.Error(err, "Drain failed")
-		return &capierrors.RequeueAfterError{RequeueAfter: 20 * time.Second}
+		return errors.Errorf("unable to drain node %s: %v", node.Name, err)
 	}
 
 	logger.Info("Drain successful", "")
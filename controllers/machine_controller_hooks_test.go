@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Machine deletion hooks", func() {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "machine-hooks-test"}}
+	testCluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: "test-cluster"}}
+
+	preDrainHook := clusterv1.PreDrainDeleteHookAnnotationPrefix + "/storage"
+	preTerminateHook := clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/lb"
+
+	BeforeEach(func() {
+		By("Creating the namespace")
+		Expect(testEnv.Create(ctx, namespace)).To(Succeed())
+		By("Creating the Cluster")
+		Expect(testEnv.Create(ctx, testCluster)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		By("Deleting the Cluster")
+		Expect(testEnv.Delete(ctx, testCluster)).To(Succeed())
+		By("Deleting the namespace")
+		Expect(testEnv.Delete(ctx, namespace)).To(Succeed())
+	})
+
+	It("Should pause the deletion until the pre-drain and pre-terminate hooks are removed", func() {
+		infraMachine := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha3",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": namespace.Name,
+				},
+				"spec": map[string]interface{}{},
+			},
+		}
+		Expect(testEnv.Create(ctx, infraMachine)).To(Succeed())
+
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "machine-hooks",
+				Namespace: namespace.Name,
+				Annotations: map[string]string{
+					preDrainHook:     "storage-controller",
+					preTerminateHook: "lb-controller",
+				},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: testCluster.Name,
+				Bootstrap:   clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("data")},
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+					Kind:       "InfrastructureMachine",
+					Name:       "infra-config1",
+				},
+			},
+		}
+		Expect(testEnv.Create(ctx, machine)).To(Succeed())
+		key := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Name}
+		infraKey := client.ObjectKey{Namespace: namespace.Name, Name: "infra-config1"}
+
+		By("Waiting for the Machine finalizer")
+		Eventually(func() []string {
+			m := &clusterv1.Machine{}
+			if err := testEnv.Get(ctx, key, m); err != nil {
+				return nil
+			}
+			return m.Finalizers
+		}, timeout).Should(ContainElement(clusterv1.MachineFinalizer))
+
+		By("Deleting the Machine")
+		Expect(testEnv.Delete(ctx, machine)).To(Succeed())
+
+		waitForHookCondition := func(conditionType clusterv1.ConditionType, status corev1.ConditionStatus) {
+			Eventually(func() bool {
+				m := &clusterv1.Machine{}
+				if err := testEnv.Get(ctx, key, m); err != nil {
+					return false
+				}
+				c := conditions.Get(m, conditionType)
+				return c != nil && c.Status == status
+			}, timeout).Should(BeTrue())
+		}
+		removeHook := func(hook string) {
+			Eventually(func() error {
+				m := &clusterv1.Machine{}
+				if err := testEnv.Get(ctx, key, m); err != nil {
+					return err
+				}
+				delete(m.Annotations, hook)
+				return testEnv.Update(ctx, m)
+			}, timeout).Should(Succeed())
+		}
+		infraMachineExists := func() bool {
+			obj := infraMachine.DeepCopy()
+			return testEnv.Get(ctx, infraKey, obj) == nil
+		}
+
+		By("Checking the deletion waits for the pre-drain hook")
+		waitForHookCondition(clusterv1.PreDrainDeleteHookSucceededCondition, corev1.ConditionFalse)
+		Consistently(infraMachineExists, "2s").Should(BeTrue())
+
+		By("Removing the pre-drain hook")
+		removeHook(preDrainHook)
+		waitForHookCondition(clusterv1.PreDrainDeleteHookSucceededCondition, corev1.ConditionTrue)
+
+		By("Checking the deletion waits for the pre-terminate hook")
+		waitForHookCondition(clusterv1.PreTerminateDeleteHookSucceededCondition, corev1.ConditionFalse)
+		Consistently(infraMachineExists, "2s").Should(BeTrue())
+
+		By("Removing the pre-terminate hook")
+		removeHook(preTerminateHook)
+
+		By("Checking the Machine and the InfrastructureMachine are deleted")
+		Eventually(infraMachineExists, timeout).Should(BeFalse())
+		Eventually(func() bool {
+			m := &clusterv1.Machine{}
+			return apierrors.IsNotFound(testEnv.Get(ctx, key, m))
+		}, timeout).Should(BeTrue())
+	})
+})
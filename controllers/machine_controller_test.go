@@ -22,23 +22,32 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/test/helpers"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kubedrain "sigs.k8s.io/cluster-api/third_party/kubernetes-drain"
 )
 
 func TestMachineFinalizer(t *testing.T) {
@@ -613,6 +622,87 @@ func TestRemoveMachineFinalizerAfterDeleteReconcile(t *testing.T) {
 	g.Expect(actual.ObjectMeta.Finalizers).To(BeEmpty())
 }
 
+func TestReconcileDeleteHooks(t *testing.T) {
+	g := NewWithT(t)
+
+	dt := metav1.Now()
+
+	testCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+	}
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "delete123",
+			Namespace:         "default",
+			Finalizers:        []string{clusterv1.MachineFinalizer},
+			DeletionTimestamp: &dt,
+			Annotations: map[string]string{
+				clusterv1.PreDrainDeleteHookAnnotationPrefix + "/storage":     "storage-controller",
+				clusterv1.PreDrainDeleteHookAnnotationPrefix + "/lb":          "lb-controller",
+				clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/storage": "storage-controller",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+				Kind:       "InfrastructureMachine",
+				Name:       "infra-config1",
+			},
+			Bootstrap: clusterv1.Bootstrap{Data: pointer.StringPtr("data")},
+		},
+	}
+	key := client.ObjectKey{Namespace: m.Namespace, Name: m.Name}
+	mr := &MachineReconciler{
+		Client: helpers.NewFakeClientWithScheme(scheme.Scheme, testCluster, m),
+		Log:    log.Log,
+		scheme: scheme.Scheme,
+	}
+
+	removeAnnotation := func(annotation string) {
+		machine := &clusterv1.Machine{}
+		g.Expect(mr.Client.Get(ctx, key, machine)).To(Succeed())
+		delete(machine.Annotations, annotation)
+		g.Expect(mr.Client.Update(ctx, machine)).To(Succeed())
+	}
+
+	// The deletion waits for the pre-drain hooks.
+	_, err := mr.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	actual := &clusterv1.Machine{}
+	g.Expect(mr.Client.Get(ctx, key, actual)).To(Succeed())
+	g.Expect(actual.Finalizers).To(ConsistOf(clusterv1.MachineFinalizer))
+	g.Expect(conditions.IsFalse(actual, clusterv1.PreDrainDeleteHookSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(actual, clusterv1.PreDrainDeleteHookSucceededCondition)).To(Equal(clusterv1.WaitingExternalHookReason))
+	g.Expect(conditions.GetMessage(actual, clusterv1.PreDrainDeleteHookSucceededCondition)).To(Equal("Waiting for hooks: lb, storage"))
+	g.Expect(conditions.Has(actual, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(BeFalse())
+
+	// The deletion waits for the pre-terminate hooks once all the pre-drain hooks are removed.
+	removeAnnotation(clusterv1.PreDrainDeleteHookAnnotationPrefix + "/storage")
+	removeAnnotation(clusterv1.PreDrainDeleteHookAnnotationPrefix + "/lb")
+	_, err = mr.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	actual = &clusterv1.Machine{}
+	g.Expect(mr.Client.Get(ctx, key, actual)).To(Succeed())
+	g.Expect(actual.Finalizers).To(ConsistOf(clusterv1.MachineFinalizer))
+	g.Expect(conditions.IsTrue(actual, clusterv1.PreDrainDeleteHookSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.IsFalse(actual, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.GetMessage(actual, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(Equal("Waiting for hooks: storage"))
+
+	// The deletion completes once all the pre-terminate hooks are removed.
+	removeAnnotation(clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/storage")
+	_, err = mr.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	actual = &clusterv1.Machine{}
+	g.Expect(mr.Client.Get(ctx, key, actual)).To(Succeed())
+	g.Expect(actual.Finalizers).To(BeEmpty())
+	g.Expect(conditions.IsTrue(actual, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(BeTrue())
+}
+
 func TestReconcileMetrics(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -983,3 +1073,257 @@ func TestIsDeleteNodeAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDrainHelper(t *testing.T) {
+	tests := []struct {
+		name                   string
+		spec                   *clusterv1.NodeDrainSpec
+		wantDeleteLocalData    bool
+		wantSkipLocalData      bool
+		wantGracePeriodSeconds int
+		wantSkipPodSelectors   []string
+		wantErr                bool
+	}{
+		{
+			name:                   "defaults when node drain is not set",
+			spec:                   nil,
+			wantDeleteLocalData:    true,
+			wantGracePeriodSeconds: -1,
+		},
+		{
+			name: "node drain overrides",
+			spec: &clusterv1.NodeDrainSpec{
+				SkipPodSelectors: []metav1.LabelSelector{
+					{MatchLabels: map[string]string{"app": "foo"}},
+					{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpExists}}},
+				},
+				DeleteEmptyDirData: pointer.BoolPtr(false),
+				GracePeriodSeconds: pointer.Int64Ptr(30),
+			},
+			wantDeleteLocalData:    false,
+			wantSkipLocalData:      true,
+			wantGracePeriodSeconds: 30,
+			wantSkipPodSelectors:   []string{"app=foo", "tier"},
+		},
+		{
+			name: "invalid skip pod selector",
+			spec: &clusterv1.NodeDrainSpec{
+				SkipPodSelectors: []metav1.LabelSelector{
+					{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Foo"}}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			drainer, err := newDrainHelper(tt.spec)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(drainer.Force).To(BeTrue())
+			g.Expect(drainer.IgnoreAllDaemonSets).To(BeTrue())
+			g.Expect(drainer.Timeout).To(Equal(drainRetryInterval))
+			g.Expect(drainer.DeleteLocalData).To(Equal(tt.wantDeleteLocalData))
+			g.Expect(drainer.SkipLocalData).To(Equal(tt.wantSkipLocalData))
+			g.Expect(drainer.GracePeriodSeconds).To(Equal(tt.wantGracePeriodSeconds))
+
+			skipPodSelectors := []string{}
+			for _, s := range drainer.SkipPodSelectors {
+				skipPodSelectors = append(skipPodSelectors, s.String())
+			}
+			g.Expect(skipPodSelectors).To(ConsistOf(tt.wantSkipPodSelectors))
+		})
+	}
+}
+
+func TestReconcileDrainNode(t *testing.T) {
+	testCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+	}
+
+	tests := []struct {
+		name          string
+		nodeDrain     *clusterv1.NodeDrainSpec
+		startTime     *metav1.Time
+		conditions    clusterv1.Conditions
+		wantStatus    corev1.ConditionStatus
+		wantReason    string
+		wantStartTime bool
+	}{
+		{
+			name:          "drains the node and records the drain start time",
+			wantStatus:    corev1.ConditionTrue,
+			wantStartTime: true,
+		},
+		{
+			name:          "does not drain the node again after the drain succeeded",
+			conditions:    clusterv1.Conditions{*conditions.TrueCondition(clusterv1.DrainingSucceededCondition)},
+			wantStatus:    corev1.ConditionTrue,
+			wantStartTime: false,
+		},
+		{
+			name:          "moves on when the drain timeout is expired",
+			nodeDrain:     &clusterv1.NodeDrainSpec{Timeout: &metav1.Duration{Duration: time.Minute}},
+			startTime:     &metav1.Time{Time: time.Now().Add(-2 * time.Minute)},
+			conditions:    clusterv1.Conditions{*conditions.FalseCondition(clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, "")},
+			wantStatus:    corev1.ConditionFalse,
+			wantReason:    clusterv1.DrainingTimedOutReason,
+			wantStartTime: true,
+		},
+		{
+			name:          "keeps draining when the drain timeout is not expired",
+			nodeDrain:     &clusterv1.NodeDrainSpec{Timeout: &metav1.Duration{Duration: time.Hour}},
+			startTime:     &metav1.Time{Time: time.Now().Add(-2 * time.Minute)},
+			conditions:    clusterv1.Conditions{*conditions.FalseCondition(clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, "")},
+			wantStatus:    corev1.ConditionTrue,
+			wantStartTime: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "test-cluster",
+					NodeDrain:   tt.nodeDrain,
+				},
+				Status: clusterv1.MachineStatus{
+					NodeRef:            &corev1.ObjectReference{Name: "node"},
+					NodeDrainStartTime: tt.startTime,
+					Conditions:         tt.conditions,
+				},
+			}
+
+			// NB. there is no kubeconfig secret for the test cluster, so the drain is skipped and considered successful.
+			mr := &MachineReconciler{
+				Client:   helpers.NewFakeClientWithScheme(scheme.Scheme, testCluster, m),
+				Log:      log.Log,
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			res, err := mr.reconcileDrainNode(ctx, testCluster, m)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(res.IsZero()).To(BeTrue())
+
+			g.Expect(conditions.Get(m, clusterv1.DrainingSucceededCondition).Status).To(Equal(tt.wantStatus))
+			g.Expect(conditions.GetReason(m, clusterv1.DrainingSucceededCondition)).To(Equal(tt.wantReason))
+			g.Expect(m.Status.NodeDrainStartTime != nil).To(Equal(tt.wantStartTime))
+		})
+	}
+}
+
+func TestDrainNodeEmptyDirData(t *testing.T) {
+	newPod := func(name string, volumes ...corev1.Volume) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", Controller: pointer.BoolPtr(true)}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node", Volumes: volumes},
+		}
+	}
+	emptyDir := corev1.Volume{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+
+	tests := []struct {
+		name         string
+		spec         *clusterv1.NodeDrainSpec
+		wantRemained []string
+	}{
+		{
+			name:         "evicts pods with emptyDir volumes by default",
+			spec:         nil,
+			wantRemained: []string{},
+		},
+		{
+			name:         "leaves pods with emptyDir volumes running when deleting emptyDir data is disabled",
+			spec:         &clusterv1.NodeDrainSpec{DeleteEmptyDirData: pointer.BoolPtr(false)},
+			wantRemained: []string{"with-empty-dir"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			kubeClient := fakeclientset.NewSimpleClientset(newPod("with-empty-dir", emptyDir), newPod("without-empty-dir"))
+
+			drainer, err := newDrainHelper(tt.spec)
+			g.Expect(err).NotTo(HaveOccurred())
+			drainer.Client = kubeClient
+			g.Expect(kubedrain.RunNodeDrain(drainer, "node")).To(Succeed())
+
+			pods, err := kubeClient.CoreV1().Pods("default").List(metav1.ListOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			remained := []string{}
+			for _, p := range pods.Items {
+				remained = append(remained, p.Name)
+			}
+			g.Expect(remained).To(ConsistOf(tt.wantRemained))
+		})
+	}
+}
+
+func TestDrainNodeFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pod",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", Controller: pointer.BoolPtr(true)}},
+		},
+		Spec: corev1.PodSpec{NodeName: "node"},
+	}
+	kubeClient := fakeclientset.NewSimpleClientset(pod)
+	kubeClient.PrependReactor("delete", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("failed to delete pod")
+	})
+
+	drainer, err := newDrainHelper(nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	drainer.Client = kubeClient
+	g.Expect(kubedrain.RunNodeDrain(drainer, "node")).NotTo(Succeed())
+}
+
+func TestReconcileDrainNodeFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	testCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+	}
+	// The kubeconfig points to an unreachable API server, so the drain fails.
+	kubeconfigSecret := kubeconfig.GenerateSecret(testCluster, kubeconfig.FromEnvTestConfig(&rest.Config{Host: "https://127.0.0.1:1"}, testCluster))
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+		Spec:       clusterv1.MachineSpec{ClusterName: "test-cluster"},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "node"},
+		},
+	}
+
+	recorder := record.NewFakeRecorder(32)
+	mr := &MachineReconciler{
+		Client:   helpers.NewFakeClientWithScheme(scheme.Scheme, testCluster, kubeconfigSecret, m),
+		Log:      log.Log,
+		scheme:   scheme.Scheme,
+		recorder: recorder,
+	}
+
+	res, err := mr.reconcileDrainNode(ctx, testCluster, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(Equal(drainRetryInterval))
+
+	g.Expect(conditions.Get(m, clusterv1.DrainingSucceededCondition).Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(conditions.GetReason(m, clusterv1.DrainingSucceededCondition)).To(Equal(clusterv1.DrainingFailedReason))
+	g.Expect(m.Status.NodeDrainStartTime).NotTo(BeNil())
+	g.Expect(recorder.Events).To(Receive(HavePrefix("Warning FailedDrainNode")))
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -26,9 +27,11 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/patch"
@@ -56,7 +59,9 @@ type MachineDeploymentReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
-	recorder record.EventRecorder
+	recorder           record.EventRecorder
+	scheme             *runtime.Scheme
+	remoteClientGetter remote.ClusterClientGetter
 }
 
 func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -91,7 +96,12 @@ func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, options
 		return errors.Wrap(err, "failed to add Watch for Clusters to controller manager")
 	}
 
+	if r.remoteClientGetter == nil {
+		r.remoteClientGetter = remote.NewClusterClient
+	}
+
 	r.recorder = mgr.GetEventRecorderFor("machinedeployment-controller")
+	r.scheme = mgr.GetScheme()
 	return nil
 }
 
@@ -193,14 +203,23 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, cluster *cl
 	}
 
 	if d.Spec.Paused {
-		return ctrl.Result{}, r.sync(d, msList)
+		return ctrl.Result{}, r.sync(ctx, d, msList)
 	}
 
-	if d.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType {
-		return ctrl.Result{}, r.rolloutRolling(d, msList)
+	switch d.Spec.Strategy.Type {
+	case clusterv1.RollingUpdateMachineDeploymentStrategyType:
+		err = r.rolloutRolling(ctx, d, msList)
+	case clusterv1.OnDeleteMachineDeploymentStrategyType:
+		err = r.rolloutOnDelete(ctx, d, msList)
+	default:
+		return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", d.Spec.Strategy.Type)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", d.Spec.Strategy.Type)
+	// Requeue to check the progress deadline of a stuck rollout.
+	return ctrl.Result{RequeueAfter: requeueAfterProgressDeadline(d, time.Now())}, nil
 }
 
 // getMachineSetsForDeployment returns a list of MachineSets associated with a MachineDeployment.
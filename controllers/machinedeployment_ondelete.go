@@ -0,0 +1,190 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/integer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutOnDelete implements the logic for the OnDelete strategy, where the machines of the old machine sets
+// are replaced only when deleted by the user.
+func (r *MachineDeploymentReconciler) rolloutOnDelete(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
+	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(d, msList, true)
+	if err != nil {
+		return err
+	}
+
+	// newMS can be nil in case there is already a MachineSet associated with this deployment,
+	// but there are only either changes in annotations or MinReadySeconds. Or in other words,
+	// this can be nil if there are changes, but no replacement of existing machines is needed.
+	if newMS == nil {
+		return nil
+	}
+
+	allMSs := append(oldMSs, newMS)
+
+	// Scale up, if we can.
+	if err := r.reconcileNewMachineSetOnDelete(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	// Scale down, accounting for the machines deleted by the user.
+	if err := r.reconcileOldMachineSetsOnDelete(allMSs, oldMSs, d); err != nil {
+		return err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if mdutil.DeploymentComplete(d, &d.Status) {
+		if err := r.cleanupDeployment(oldMSs, d); err != nil {
+			return err
+		}
+	}
+
+	return r.syncRolloutProgress(ctx, oldMSs, newMS, d)
+}
+
+// reconcileNewMachineSetOnDelete scales up the new machine set to replace the machines deleted from the old ones.
+func (r *MachineDeploymentReconciler) reconcileNewMachineSetOnDelete(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+	// The new machine set could have been an old one before a rollback, so make sure it can create machines.
+	if err := r.setDisableMachineCreate(newMS, false); err != nil {
+		return err
+	}
+
+	// Given that NewMSNewReplicas never scales above the deployment replicas, the logic is the same of RollingUpdate.
+	return r.reconcileNewMachineSet(allMSs, newMS, deployment)
+}
+
+// reconcileOldMachineSetsOnDelete scales down the old machine sets by the number of their machines being deleted,
+// so the deleted machines are not replaced by the old machine sets; if the deployment has more replicas than desired,
+// e.g. because it was scaled down, the old machine sets are scaled down further.
+func (r *MachineDeploymentReconciler) reconcileOldMachineSetsOnDelete(allMSs []*clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+	logger := r.Log.WithValues("machinedeployment", deployment.Name, "namespace", deployment.Namespace)
+
+	if deployment.Spec.Replicas == nil {
+		return errors.Errorf("spec replicas for MachineDeployment %q/%q is nil, this is unexpected",
+			deployment.Namespace, deployment.Name)
+	}
+
+	scaleDownCount := mdutil.GetReplicaCountForMachineSets(allMSs) - *(deployment.Spec.Replicas)
+	for _, oldMS := range oldMSs {
+		if oldMS.Spec.Replicas == nil {
+			return errors.Errorf("spec replicas for MachineSet %q/%q is nil, this is unexpected",
+				oldMS.Namespace, oldMS.Name)
+		}
+
+		// Prevent the old machine set from replacing the machines deleted by the user.
+		if err := r.setDisableMachineCreate(oldMS, true); err != nil {
+			return err
+		}
+
+		if *(oldMS.Spec.Replicas) == 0 {
+			// cannot scale down this machine set.
+			continue
+		}
+
+		selectorMap, err := metav1.LabelSelectorAsMap(&oldMS.Spec.Selector)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert MachineSet %q label selector to a map", oldMS.Name)
+		}
+		machines := &clusterv1.MachineList{}
+		if err := r.Client.List(context.Background(), machines, client.InNamespace(oldMS.Namespace), client.MatchingLabels(selectorMap)); err != nil {
+			return errors.Wrapf(err, "failed to list machines for MachineSet %q/%q", oldMS.Namespace, oldMS.Name)
+		}
+
+		// Do not count the machines being deleted, nor the ones not created yet.
+		newReplicasCount := integer.Int32Min(*(oldMS.Spec.Replicas), int32(len(machines.Items))-mdutil.GetDeletingMachineCount(machines))
+		if newReplicasCount < 0 {
+			newReplicasCount = 0
+		}
+		scaleDownCount -= *(oldMS.Spec.Replicas) - newReplicasCount
+
+		// Scale down further if the deployment still has more replicas than desired.
+		if scaleDownCount > 0 {
+			extraScaleDownCount := integer.Int32Min(scaleDownCount, newReplicasCount)
+			newReplicasCount -= extraScaleDownCount
+			scaleDownCount -= extraScaleDownCount
+		}
+
+		if newReplicasCount == *(oldMS.Spec.Replicas) {
+			continue
+		}
+
+		logger.V(4).Info("Scaling down old MachineSet", "machineset", oldMS.Name, "replicas", newReplicasCount)
+		if err := r.scaleMachineSet(oldMS, newReplicasCount, deployment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enableMachineCreate removes the DisableMachineCreateAnnotation from the machine sets, patching only the
+// machine sets having the annotation.
+func (r *MachineDeploymentReconciler) enableMachineCreate(msList []*clusterv1.MachineSet) error {
+	for _, ms := range msList {
+		if !isMachineCreateDisabled(ms) {
+			continue
+		}
+		if err := r.setDisableMachineCreate(ms, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isMachineCreateDisabled returns true if the machine set has the DisableMachineCreateAnnotation.
+func isMachineCreateDisabled(ms *clusterv1.MachineSet) bool {
+	_, ok := ms.Annotations[clusterv1.DisableMachineCreateAnnotation]
+	return ok
+}
+
+// setDisableMachineCreate adds or removes the DisableMachineCreateAnnotation on a machine set; the machine set
+// is patched only if the annotation changes.
+func (r *MachineDeploymentReconciler) setDisableMachineCreate(ms *clusterv1.MachineSet, disable bool) error {
+	if isMachineCreateDisabled(ms) == disable {
+		return nil
+	}
+
+	patchHelper, err := patch.NewHelper(ms, r.Client)
+	if err != nil {
+		return err
+	}
+	if disable {
+		if ms.Annotations == nil {
+			ms.Annotations = map[string]string{}
+		}
+		ms.Annotations[clusterv1.DisableMachineCreateAnnotation] = "true"
+	} else {
+		delete(ms.Annotations, clusterv1.DisableMachineCreateAnnotation)
+	}
+	return patchHelper.Patch(context.Background(), ms)
+}
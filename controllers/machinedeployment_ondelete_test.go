@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/test/helpers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newOnDeleteMachineSet(name string, replicas int32, annotations map[string]string) *clusterv1.MachineSet {
+	return &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: clusterv1.MachineSetSpec{
+			Replicas: pointer.Int32Ptr(replicas),
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"set": name}},
+		},
+	}
+}
+
+func newOnDeleteMachines(ms *clusterv1.MachineSet, count, deleting int) []runtime.Object {
+	objs := []runtime.Object{}
+	for i := 0; i < count; i++ {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", ms.Name, i),
+				Namespace: ms.Namespace,
+				Labels:    map[string]string{"set": ms.Name},
+			},
+		}
+		if i < deleting {
+			now := metav1.Now()
+			m.DeletionTimestamp = &now
+		}
+		objs = append(objs, m)
+	}
+	return objs
+}
+
+func TestMachineDeploymentReconcileOldMachineSetsOnDelete(t *testing.T) {
+	tests := []struct {
+		name             string
+		replicas         int32
+		oldMSReplicas    int32
+		machines         int
+		deletingMachines int
+		newMSReplicas    int32
+		wantOldReplicas  int32
+	}{
+		{
+			name:            "no machines deleted",
+			replicas:        3,
+			oldMSReplicas:   3,
+			machines:        3,
+			newMSReplicas:   0,
+			wantOldReplicas: 3,
+		},
+		{
+			name:             "machines being deleted are not replaced by the old machine set",
+			replicas:         3,
+			oldMSReplicas:    3,
+			machines:         3,
+			deletingMachines: 2,
+			newMSReplicas:    0,
+			wantOldReplicas:  1,
+		},
+		{
+			name:            "scale down the old machine set when the deployment is scaled down",
+			replicas:        2,
+			oldMSReplicas:   3,
+			machines:        3,
+			newMSReplicas:   0,
+			wantOldReplicas: 2,
+		},
+		{
+			name:             "scale down the old machine set when the deployment is scaled down and machines are being deleted",
+			replicas:         1,
+			oldMSReplicas:    3,
+			machines:         3,
+			deletingMachines: 1,
+			newMSReplicas:    1,
+			wantOldReplicas:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			deployment := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas: pointer.Int32Ptr(tt.replicas),
+					Strategy: &clusterv1.MachineDeploymentStrategy{Type: clusterv1.OnDeleteMachineDeploymentStrategyType},
+				},
+			}
+			oldMS := newOnDeleteMachineSet("old", tt.oldMSReplicas, nil)
+			newMS := newOnDeleteMachineSet("new", tt.newMSReplicas, nil)
+
+			objs := []runtime.Object{deployment, oldMS, newMS}
+			objs = append(objs, newOnDeleteMachines(oldMS, tt.machines, tt.deletingMachines)...)
+			r := &MachineDeploymentReconciler{
+				Client:   helpers.NewFakeClientWithScheme(scheme.Scheme, objs...),
+				Log:      log.Log,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			err := r.reconcileOldMachineSetsOnDelete([]*clusterv1.MachineSet{oldMS, newMS}, []*clusterv1.MachineSet{oldMS}, deployment)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			actual := &clusterv1.MachineSet{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "old"}, actual)).To(Succeed())
+			g.Expect(*actual.Spec.Replicas).To(Equal(tt.wantOldReplicas))
+			g.Expect(actual.Annotations).To(HaveKey(clusterv1.DisableMachineCreateAnnotation))
+		})
+	}
+}
+
+func TestMachineDeploymentReconcileNewMachineSetOnDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	deployment := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+		Spec: clusterv1.MachineDeploymentSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Strategy: &clusterv1.MachineDeploymentStrategy{Type: clusterv1.OnDeleteMachineDeploymentStrategyType},
+		},
+	}
+	oldMS := newOnDeleteMachineSet("old", 2, map[string]string{clusterv1.DisableMachineCreateAnnotation: "true"})
+	// The new machine set was an old one before a rollback.
+	newMS := newOnDeleteMachineSet("new", 0, map[string]string{clusterv1.DisableMachineCreateAnnotation: "true"})
+
+	r := &MachineDeploymentReconciler{
+		Client:   helpers.NewFakeClientWithScheme(scheme.Scheme, deployment, oldMS, newMS),
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	err := r.reconcileNewMachineSetOnDelete([]*clusterv1.MachineSet{oldMS, newMS}, newMS, deployment)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The new machine set replaces only the machine deleted from the old machine set.
+	actual := &clusterv1.MachineSet{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "new"}, actual)).To(Succeed())
+	g.Expect(*actual.Spec.Replicas).To(Equal(int32(1)))
+	g.Expect(actual.Annotations).NotTo(HaveKey(clusterv1.DisableMachineCreateAnnotation))
+}
+
+// patchCountingClient is a client counting the patch calls.
+type patchCountingClient struct {
+	client.Client
+	patches int
+}
+
+func (c *patchCountingClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patches++
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestMachineDeploymentEnableMachineCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	enabledMS := newOnDeleteMachineSet("enabled", 1, nil)
+	disabledMS := newOnDeleteMachineSet("disabled", 1, map[string]string{clusterv1.DisableMachineCreateAnnotation: "true"})
+
+	c := &patchCountingClient{Client: helpers.NewFakeClientWithScheme(scheme.Scheme, enabledMS, disabledMS)}
+	r := &MachineDeploymentReconciler{
+		Client:   c,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	// Only the machine set with machine creation disabled is patched.
+	g.Expect(r.enableMachineCreate([]*clusterv1.MachineSet{enabledMS, disabledMS})).To(Succeed())
+	g.Expect(c.patches).To(Equal(1))
+
+	actual := &clusterv1.MachineSet{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "disabled"}, actual)).To(Succeed())
+	g.Expect(actual.Annotations).NotTo(HaveKey(clusterv1.DisableMachineCreateAnnotation))
+
+	// Machine sets are not patched again on the following reconciles.
+	g.Expect(r.enableMachineCreate([]*clusterv1.MachineSet{enabledMS, disabledMS})).To(Succeed())
+	g.Expect(c.patches).To(Equal(1))
+}
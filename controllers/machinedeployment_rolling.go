@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"sort"
 
 	"github.com/pkg/errors"
@@ -26,7 +27,7 @@ import (
 )
 
 // rolloutRolling implements the logic for rolling a new machine set.
-func (r *MachineDeploymentReconciler) rolloutRolling(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
+func (r *MachineDeploymentReconciler) rolloutRolling(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
 	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(d, msList, true)
 	if err != nil {
 		return err
@@ -41,6 +42,11 @@ func (r *MachineDeploymentReconciler) rolloutRolling(d *clusterv1.MachineDeploym
 
 	allMSs := append(oldMSs, newMS)
 
+	// Allow all the machine sets to create machines, in case the deployment previously used the OnDelete strategy.
+	if err := r.enableMachineCreate(allMSs); err != nil {
+		return err
+	}
+
 	// Scale up, if we can.
 	if err := r.reconcileNewMachineSet(allMSs, newMS, d); err != nil {
 		return err
@@ -65,7 +71,7 @@ func (r *MachineDeploymentReconciler) rolloutRolling(d *clusterv1.MachineDeploym
 		}
 	}
 
-	return nil
+	return r.syncRolloutProgress(ctx, oldMSs, newMS, d)
 }
 
 func (r *MachineDeploymentReconciler) reconcileNewMachineSet(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -31,14 +33,16 @@ import (
 	"k8s.io/client-go/util/retry"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // sync is responsible for reconciling deployments on scaling events or when they
 // are paused.
-func (r *MachineDeploymentReconciler) sync(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
+func (r *MachineDeploymentReconciler) sync(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
 	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(d, msList, false)
 	if err != nil {
 		return err
@@ -54,7 +58,10 @@ func (r *MachineDeploymentReconciler) sync(d *clusterv1.MachineDeployment, msLis
 	// // TODO: Clean up the deployment when it's paused and no rollback is in flight.
 	//
 	allMSs := append(oldMSs, newMS)
-	return r.syncDeploymentStatus(allMSs, newMS, d)
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+	return r.syncRolloutProgress(ctx, oldMSs, newMS, d)
 }
 
 // getAllMachineSetsAndSyncRevision returns all the machine sets for the provided deployment (new and all old), with new MS's and deployment's revision updated.
@@ -341,10 +348,191 @@ func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeploym
 
 // syncDeploymentStatus checks if the status is up-to-date and sync it if necessary
 func (r *MachineDeploymentReconciler) syncDeploymentStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, d *clusterv1.MachineDeployment) error {
-	d.Status = calculateStatus(allMSs, newMS, d)
+	newStatus := calculateStatus(allMSs, newMS, d)
+
+	// Track the progress of the rollout, which is not estimated while the deployment is paused.
+	// A change of the deployment spec, e.g. a new machine template, restarts the progress deadline.
+	switch {
+	case d.Spec.Paused || mdutil.DeploymentComplete(d, &newStatus):
+		newStatus.LastProgressTime = nil
+	case newStatus.LastProgressTime == nil || d.Status.ObservedGeneration != d.Generation || mdutil.DeploymentProgressing(d, &newStatus):
+		now := metav1.Now()
+		newStatus.LastProgressTime = &now
+	}
+
+	d.Status = newStatus
 	return nil
 }
 
+// syncRolloutProgress updates the Progressing condition of the deployment. When the rollout exceeds the
+// progress deadline, it reports the machines of the new machine set that are not ready and, if
+// AutoRollback is enabled, it rolls the deployment back to the previous revision.
+func (r *MachineDeploymentReconciler) syncRolloutProgress(ctx context.Context, oldMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, d *clusterv1.MachineDeployment) error {
+	if d.Spec.Paused {
+		conditions.MarkUnknown(d, clusterv1.MachineDeploymentProgressingCondition, mdutil.PausedDeployReason, "Deployment is paused")
+		return nil
+	}
+
+	if newMS == nil || !mdutil.DeploymentTimedOut(d, &d.Status, time.Now()) {
+		conditions.MarkTrue(d, clusterv1.MachineDeploymentProgressingCondition)
+		return nil
+	}
+
+	// The failure is reported only once, when the rollout exceeds the deadline.
+	if conditions.GetReason(d, clusterv1.MachineDeploymentProgressingCondition) == clusterv1.ProgressDeadlineExceededReason {
+		return nil
+	}
+
+	// With the OnDelete strategy the rollout waits for the user to delete the old machines, which is not a
+	// failure; the rollout times out only if the machines of the new machine set fail to become ready.
+	// Nb. this check runs on every reconcile until the old machines are deleted, so it relies on the Ready
+	// condition of the machines instead of reading the nodes from the workload cluster.
+	onDelete := d.Spec.Strategy != nil && d.Spec.Strategy.Type == clusterv1.OnDeleteMachineDeploymentStrategyType
+	notReady, err := r.getNotReadyMachineNames(ctx, newMS, !onDelete)
+	if err != nil {
+		return err
+	}
+	if onDelete && len(notReady) == 0 {
+		conditions.MarkTrue(d, clusterv1.MachineDeploymentProgressingCondition)
+		return nil
+	}
+
+	conditions.MarkFalse(d, clusterv1.MachineDeploymentProgressingCondition, clusterv1.ProgressDeadlineExceededReason, clusterv1.ConditionSeverityError,
+		"MachineSet %q has not made progress for more than %ds", newMS.Name, *d.Spec.ProgressDeadlineSeconds)
+	r.recorder.Eventf(d, corev1.EventTypeWarning, "ProgressDeadlineExceeded",
+		"MachineSet %q has not made progress for more than %ds, machines that are not ready: [%s]",
+		newMS.Name, *d.Spec.ProgressDeadlineSeconds, strings.Join(notReady, ", "))
+
+	if !d.Spec.AutoRollback {
+		return nil
+	}
+	return r.rollback(oldMSs, newMS, d)
+}
+
+// getNotReadyMachineNames returns the sorted names of the machines of a machine set that never got a node or
+// that are not ready. If checkNodes is true, the nodes are read from the workload cluster; otherwise, or if
+// the nodes cannot be read, the Ready condition of the machines is used instead.
+func (r *MachineDeploymentReconciler) getNotReadyMachineNames(ctx context.Context, ms *clusterv1.MachineSet, checkNodes bool) ([]string, error) {
+	logger := r.Log.WithValues("machineset", ms.Name, "namespace", ms.Namespace)
+
+	selectorMap, err := metav1.LabelSelectorAsMap(&ms.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert MachineSet %q label selector to a map", ms.Name)
+	}
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(ms.Namespace), client.MatchingLabels(selectorMap)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list machines for MachineSet %q/%q", ms.Namespace, ms.Name)
+	}
+
+	var remoteClient client.Client
+	var remoteClientErr error
+	names := []string{}
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if m.Status.NodeRef == nil {
+			names = append(names, m.Name)
+			continue
+		}
+
+		if checkNodes && remoteClient == nil && remoteClientErr == nil {
+			remoteClient, remoteClientErr = r.remoteClientGetter(ctx, r.Client, client.ObjectKey{Namespace: ms.Namespace, Name: ms.Spec.ClusterName}, r.scheme)
+			if remoteClientErr != nil {
+				logger.Error(remoteClientErr, "Failed to create a client for the workload cluster, using the Ready condition of the machines")
+				remoteClient = nil
+			}
+		}
+		if !r.isMachineReady(ctx, remoteClient, m) {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isMachineReady reports whether the node of a machine is ready. If the node cannot be read, it falls back to the
+// Ready condition of the machine.
+func (r *MachineDeploymentReconciler) isMachineReady(ctx context.Context, remoteClient client.Client, m *clusterv1.Machine) bool {
+	if remoteClient != nil {
+		node := &corev1.Node{}
+		err := remoteClient.Get(ctx, client.ObjectKey{Name: m.Status.NodeRef.Name}, node)
+		if err == nil {
+			return noderefutil.IsNodeReady(node)
+		}
+		r.Log.Error(err, "Failed to get the node of the machine, using the Ready condition of the machine",
+			"machine", m.Name, "namespace", m.Namespace, "node", m.Status.NodeRef.Name)
+	}
+	return !conditions.IsFalse(m, clusterv1.ReadyCondition)
+}
+
+// rollback restores the machine template of the previous revision of the deployment, so the machine set of the
+// previous revision is scaled back up and the machine set of the failed revision is scaled down by the next rollout.
+// The failed machine set is marked with the RolloutFailedAnnotation, so it is never used as a rollback target.
+func (r *MachineDeploymentReconciler) rollback(oldMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, d *clusterv1.MachineDeployment) error {
+	logger := r.Log.WithValues("machinedeployment", d.Name, "namespace", d.Namespace)
+
+	if _, ok := newMS.Annotations[clusterv1.RolloutFailedAnnotation]; !ok {
+		patchHelper, err := patch.NewHelper(newMS, r.Client)
+		if err != nil {
+			return err
+		}
+		if newMS.Annotations == nil {
+			newMS.Annotations = map[string]string{}
+		}
+		newMS.Annotations[clusterv1.RolloutFailedAnnotation] = "true"
+		if err := patchHelper.Patch(context.Background(), newMS); err != nil {
+			return errors.Wrapf(err, "failed to mark MachineSet %q as failed", newMS.Name)
+		}
+	}
+
+	var previousMS *clusterv1.MachineSet
+	var previousRevision int64
+	for _, ms := range oldMSs {
+		if _, failed := ms.Annotations[clusterv1.RolloutFailedAnnotation]; failed {
+			continue
+		}
+		revision, err := mdutil.Revision(ms)
+		if err != nil {
+			logger.Error(err, "Couldn't parse revision for machine set, it will not be used for rollback", "machineset", ms.Name)
+			continue
+		}
+		if previousMS == nil || revision > previousRevision {
+			previousMS, previousRevision = ms, revision
+		}
+	}
+	if previousMS == nil {
+		r.recorder.Eventf(d, corev1.EventTypeWarning, "RollbackRevisionNotFound", "Unable to find a previous revision to roll back to")
+		return nil
+	}
+
+	// Restore the machine template of the previous revision, dropping the label added to the machine set template.
+	template := previousMS.Spec.Template.DeepCopy()
+	delete(template.Labels, mdutil.DefaultMachineDeploymentUniqueLabelKey)
+	d.Spec.Template = *template
+
+	logger.Info("Rolled back to previous revision", "machineset", previousMS.Name, "revision", previousRevision)
+	r.recorder.Eventf(d, corev1.EventTypeNormal, "SuccessfulRollback", "Rolled back to revision %d of MachineSet %q", previousRevision, previousMS.Name)
+	return nil
+}
+
+// requeueAfterProgressDeadline returns how long to wait before checking the progress deadline of a deployment
+// being rolled out, given that no event triggers a reconcile while a rollout is stuck; it returns zero when
+// the progress of the deployment is not tracked or the deadline has already passed, given that in this case
+// the deadline has been checked by the current reconcile.
+func requeueAfterProgressDeadline(d *clusterv1.MachineDeployment, now time.Time) time.Duration {
+	if d.Spec.ProgressDeadlineSeconds == nil || d.Status.LastProgressTime == nil ||
+		conditions.GetReason(d, clusterv1.MachineDeploymentProgressingCondition) == clusterv1.ProgressDeadlineExceededReason {
+		return 0
+	}
+	deadline := d.Status.LastProgressTime.Add(time.Duration(*d.Spec.ProgressDeadlineSeconds) * time.Second)
+	if !deadline.After(now) {
+		return 0
+	}
+	return deadline.Sub(now) + time.Second
+}
+
 // calculateStatus calculates the latest status for the provided deployment by looking into the provided machine sets.
 func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) clusterv1.MachineDeploymentStatus {
 	availableReplicas := mdutil.GetAvailableReplicaCountForMachineSets(allMSs)
@@ -369,6 +557,8 @@ func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet
 		ReadyReplicas:       mdutil.GetReadyReplicaCountForMachineSets(allMSs),
 		AvailableReplicas:   availableReplicas,
 		UnavailableReplicas: unavailableReplicas,
+		LastProgressTime:    deployment.Status.LastProgressTime,
+		Conditions:          deployment.Status.Conditions,
 	}
 
 	if *deployment.Spec.Replicas == status.ReadyReplicas {
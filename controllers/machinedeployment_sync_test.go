@@ -17,14 +17,27 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	fakeremote "sigs.k8s.io/cluster-api/controllers/remote/fake"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/test/helpers"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func TestMachineDeploymentSyncStatus(t *testing.T) {
@@ -220,3 +233,454 @@ func TestMachineDeploymentSyncStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineDeploymentSyncDeploymentStatusProgress(t *testing.T) {
+	lastProgressTime := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	newMS := &clusterv1.MachineSet{
+		Spec:   clusterv1.MachineSetSpec{Replicas: pointer.Int32Ptr(2)},
+		Status: clusterv1.MachineSetStatus{Replicas: 2, ReadyReplicas: 1, AvailableReplicas: 1},
+	}
+
+	var tests = map[string]struct {
+		deployment    *clusterv1.MachineDeployment
+		newMS         *clusterv1.MachineSet
+		expectTracked bool
+		expectReset   bool
+	}{
+		"rollout starts": {
+			deployment: &clusterv1.MachineDeployment{
+				Spec: clusterv1.MachineDeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+			},
+			newMS:         newMS,
+			expectTracked: true,
+			expectReset:   true,
+		},
+		"rollout does not make progress": {
+			deployment: &clusterv1.MachineDeployment{
+				Spec: clusterv1.MachineDeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+				Status: clusterv1.MachineDeploymentStatus{
+					Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 1, AvailableReplicas: 1,
+					LastProgressTime: &lastProgressTime,
+				},
+			},
+			newMS:         newMS,
+			expectTracked: true,
+			expectReset:   false,
+		},
+		"rollout makes progress": {
+			deployment: &clusterv1.MachineDeployment{
+				Spec: clusterv1.MachineDeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+				Status: clusterv1.MachineDeploymentStatus{
+					Replicas: 2, UpdatedReplicas: 2,
+					LastProgressTime: &lastProgressTime,
+				},
+			},
+			newMS:         newMS,
+			expectTracked: true,
+			expectReset:   true,
+		},
+		"deployment spec changes": {
+			deployment: &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       clusterv1.MachineDeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+				Status: clusterv1.MachineDeploymentStatus{
+					ObservedGeneration: 1,
+					Replicas:           2, UpdatedReplicas: 2, ReadyReplicas: 1, AvailableReplicas: 1,
+					LastProgressTime: &lastProgressTime,
+				},
+			},
+			newMS:         newMS,
+			expectTracked: true,
+			expectReset:   true,
+		},
+		"rollout is complete": {
+			deployment: &clusterv1.MachineDeployment{
+				Spec: clusterv1.MachineDeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+				Status: clusterv1.MachineDeploymentStatus{
+					LastProgressTime: &lastProgressTime,
+				},
+			},
+			newMS: &clusterv1.MachineSet{
+				Spec:   clusterv1.MachineSetSpec{Replicas: pointer.Int32Ptr(2)},
+				Status: clusterv1.MachineSetStatus{Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			},
+			expectTracked: false,
+		},
+		"deployment is paused": {
+			deployment: &clusterv1.MachineDeployment{
+				Spec: clusterv1.MachineDeploymentSpec{Replicas: pointer.Int32Ptr(2), Paused: true},
+				Status: clusterv1.MachineDeploymentStatus{
+					LastProgressTime: &lastProgressTime,
+				},
+			},
+			newMS:         newMS,
+			expectTracked: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineDeploymentReconciler{}
+			g.Expect(r.syncDeploymentStatus([]*clusterv1.MachineSet{test.newMS}, test.newMS, test.deployment)).To(Succeed())
+
+			if !test.expectTracked {
+				g.Expect(test.deployment.Status.LastProgressTime).To(BeNil())
+				return
+			}
+			g.Expect(test.deployment.Status.LastProgressTime).NotTo(BeNil())
+			g.Expect(test.deployment.Status.LastProgressTime.Equal(&lastProgressTime)).To(Equal(!test.expectReset))
+		})
+	}
+}
+
+func TestMachineDeploymentSyncRolloutProgress(t *testing.T) {
+	newMachineSet := func(name, revision string, annotations map[string]string) *clusterv1.MachineSet {
+		ms := &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{clusterv1.RevisionAnnotation: revision},
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Replicas: pointer.Int32Ptr(2),
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"set": name}},
+				Template: clusterv1.MachineTemplateSpec{
+					ObjectMeta: clusterv1.ObjectMeta{
+						Labels: map[string]string{
+							"app": "md",
+							mdutil.DefaultMachineDeploymentUniqueLabelKey: name,
+						},
+					},
+					Spec: clusterv1.MachineSpec{Version: pointer.StringPtr(name)},
+				},
+			},
+		}
+		for k, v := range annotations {
+			ms.Annotations[k] = v
+		}
+		return ms
+	}
+	newMachine := func(name, ms string, ready bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"set": ms},
+			},
+		}
+		if ready {
+			m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: name}
+		}
+		return m
+	}
+	failedAnnotation := map[string]string{clusterv1.RolloutFailedAnnotation: "true"}
+
+	var tests = map[string]struct {
+		lastProgress    time.Duration
+		paused          bool
+		autoRollback    bool
+		onDelete        bool
+		newMachineReady bool
+		notReadyNode    string
+		notReadyMachine string
+		remoteErr       bool
+		oldMSs          []*clusterv1.MachineSet
+		expectStatus    corev1.ConditionStatus
+		expectEvents    []string
+		expectVersion   string
+		expectNewFailed bool
+	}{
+		"rollout within the deadline": {
+			lastProgress:  time.Minute,
+			oldMSs:        []*clusterv1.MachineSet{newMachineSet("v1", "1", nil)},
+			expectStatus:  corev1.ConditionTrue,
+			expectVersion: "v2",
+		},
+		"rollout paused": {
+			lastProgress:  time.Hour,
+			paused:        true,
+			oldMSs:        []*clusterv1.MachineSet{newMachineSet("v1", "1", nil)},
+			expectStatus:  corev1.ConditionUnknown,
+			expectVersion: "v2",
+		},
+		"rollout exceeding the deadline": {
+			lastProgress:  time.Hour,
+			oldMSs:        []*clusterv1.MachineSet{newMachineSet("v1", "1", nil)},
+			expectStatus:  corev1.ConditionFalse,
+			expectEvents:  []string{"Warning ProgressDeadlineExceeded MachineSet \"v2\" has not made progress for more than 600s, machines that are not ready: [v2-b, v2-c]"},
+			expectVersion: "v2",
+		},
+		"rollout exceeding the deadline with a node not ready": {
+			lastProgress:    time.Hour,
+			newMachineReady: true,
+			notReadyNode:    "v2-b",
+			oldMSs:          []*clusterv1.MachineSet{newMachineSet("v1", "1", nil)},
+			expectStatus:    corev1.ConditionFalse,
+			expectEvents:    []string{"Warning ProgressDeadlineExceeded MachineSet \"v2\" has not made progress for more than 600s, machines that are not ready: [v2-b]"},
+			expectVersion:   "v2",
+		},
+		"rollout exceeding the deadline without access to the workload cluster": {
+			lastProgress:    time.Hour,
+			newMachineReady: true,
+			notReadyMachine: "v2-c",
+			remoteErr:       true,
+			oldMSs:          []*clusterv1.MachineSet{newMachineSet("v1", "1", nil)},
+			expectStatus:    corev1.ConditionFalse,
+			expectEvents:    []string{"Warning ProgressDeadlineExceeded MachineSet \"v2\" has not made progress for more than 600s, machines that are not ready: [v2-c]"},
+			expectVersion:   "v2",
+		},
+		"rollout exceeding the deadline with auto rollback": {
+			lastProgress: time.Hour,
+			autoRollback: true,
+			oldMSs: []*clusterv1.MachineSet{
+				newMachineSet("v0", "1", nil),
+				newMachineSet("v1", "2", nil),
+			},
+			expectStatus: corev1.ConditionFalse,
+			expectEvents: []string{
+				"Warning ProgressDeadlineExceeded",
+				"Normal SuccessfulRollback Rolled back to revision 2 of MachineSet \"v1\"",
+			},
+			expectVersion:   "v1",
+			expectNewFailed: true,
+		},
+		"rollout exceeding the deadline with auto rollback does not use failed revisions": {
+			lastProgress: time.Hour,
+			autoRollback: true,
+			oldMSs: []*clusterv1.MachineSet{
+				newMachineSet("v0", "1", nil),
+				newMachineSet("v1", "2", failedAnnotation),
+			},
+			expectStatus: corev1.ConditionFalse,
+			expectEvents: []string{
+				"Warning ProgressDeadlineExceeded",
+				"Normal SuccessfulRollback Rolled back to revision 1 of MachineSet \"v0\"",
+			},
+			expectVersion:   "v0",
+			expectNewFailed: true,
+		},
+		"OnDelete rollout waiting for the deletion of old machines after the deadline": {
+			lastProgress:    time.Hour,
+			autoRollback:    true,
+			onDelete:        true,
+			newMachineReady: true,
+			oldMSs:          []*clusterv1.MachineSet{newMachineSet("v1", "1", nil)},
+			expectStatus:    corev1.ConditionTrue,
+			expectVersion:   "v2",
+		},
+		"OnDelete rollout with machines not becoming ready after the deadline": {
+			lastProgress: time.Hour,
+			autoRollback: true,
+			onDelete:     true,
+			oldMSs:       []*clusterv1.MachineSet{newMachineSet("v1", "1", nil)},
+			expectStatus: corev1.ConditionFalse,
+			expectEvents: []string{
+				"Warning ProgressDeadlineExceeded MachineSet \"v2\" has not made progress for more than 600s, machines that are not ready: [v2-b, v2-c]",
+				"Normal SuccessfulRollback Rolled back to revision 1 of MachineSet \"v1\"",
+			},
+			expectVersion:   "v1",
+			expectNewFailed: true,
+		},
+		"rollout exceeding the deadline with auto rollback and no previous revision": {
+			lastProgress: time.Hour,
+			autoRollback: true,
+			oldMSs:       []*clusterv1.MachineSet{newMachineSet("v1", "1", failedAnnotation)},
+			expectStatus: corev1.ConditionFalse,
+			expectEvents: []string{
+				"Warning ProgressDeadlineExceeded",
+				"Warning RollbackRevisionNotFound",
+			},
+			expectVersion:   "v2",
+			expectNewFailed: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newMS := newMachineSet("v2", "3", nil)
+			lastProgressTime := metav1.NewTime(time.Now().Add(-test.lastProgress))
+			deployment := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas:                pointer.Int32Ptr(2),
+					Paused:                  test.paused,
+					AutoRollback:            test.autoRollback,
+					ProgressDeadlineSeconds: pointer.Int32Ptr(600),
+					Template: clusterv1.MachineTemplateSpec{
+						ObjectMeta: clusterv1.ObjectMeta{Labels: map[string]string{"app": "md"}},
+						Spec:       clusterv1.MachineSpec{Version: pointer.StringPtr("v2")},
+					},
+				},
+				Status: clusterv1.MachineDeploymentStatus{LastProgressTime: &lastProgressTime},
+			}
+			if test.onDelete {
+				deployment.Spec.Strategy = &clusterv1.MachineDeploymentStrategy{Type: clusterv1.OnDeleteMachineDeploymentStrategyType}
+			}
+
+			objs := []runtime.Object{deployment, newMS}
+			for _, m := range []*clusterv1.Machine{
+				newMachine("v2-a", "v2", true),
+				newMachine("v2-b", "v2", test.newMachineReady),
+				newMachine("v2-c", "v2", test.newMachineReady),
+			} {
+				if m.Name == test.notReadyMachine {
+					conditions.MarkFalse(m, clusterv1.ReadyCondition, "NotReady", clusterv1.ConditionSeverityWarning, "")
+				}
+				objs = append(objs, m)
+				if m.Status.NodeRef == nil {
+					continue
+				}
+				nodeStatus := corev1.ConditionTrue
+				if m.Name == test.notReadyNode {
+					nodeStatus = corev1.ConditionFalse
+				}
+				objs = append(objs, &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: m.Status.NodeRef.Name},
+					Status: corev1.NodeStatus{
+						Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: nodeStatus}},
+					},
+				})
+			}
+			for _, ms := range test.oldMSs {
+				objs = append(objs, ms)
+			}
+			recorder := record.NewFakeRecorder(32)
+			r := &MachineDeploymentReconciler{
+				Client:             helpers.NewFakeClientWithScheme(scheme.Scheme, objs...),
+				Log:                log.Log,
+				recorder:           recorder,
+				remoteClientGetter: fakeremote.NewClusterClient,
+			}
+			if test.remoteErr {
+				r.remoteClientGetter = func(context.Context, client.Client, client.ObjectKey, *runtime.Scheme) (client.Client, error) {
+					return nil, errors.New("failed to connect to the workload cluster")
+				}
+			}
+
+			g.Expect(r.syncRolloutProgress(ctx, test.oldMSs, newMS, deployment)).To(Succeed())
+
+			g.Expect(conditions.Get(deployment, clusterv1.MachineDeploymentProgressingCondition).Status).To(Equal(test.expectStatus))
+			if test.expectStatus == corev1.ConditionFalse {
+				g.Expect(conditions.GetReason(deployment, clusterv1.MachineDeploymentProgressingCondition)).To(Equal(clusterv1.ProgressDeadlineExceededReason))
+			}
+
+			close(recorder.Events)
+			events := []string{}
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			g.Expect(events).To(HaveLen(len(test.expectEvents)))
+			for i := range test.expectEvents {
+				g.Expect(events[i]).To(HavePrefix(test.expectEvents[i]))
+			}
+
+			g.Expect(*deployment.Spec.Template.Spec.Version).To(Equal(test.expectVersion))
+			g.Expect(deployment.Spec.Template.Labels).NotTo(HaveKey(mdutil.DefaultMachineDeploymentUniqueLabelKey))
+
+			actualNewMS := &clusterv1.MachineSet{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "v2"}, actualNewMS)).To(Succeed())
+			_, failed := actualNewMS.Annotations[clusterv1.RolloutFailedAnnotation]
+			g.Expect(failed).To(Equal(test.expectNewFailed))
+		})
+	}
+}
+
+func TestMachineDeploymentSyncRolloutProgressRemoteClient(t *testing.T) {
+	var tests = map[string]struct {
+		onDelete          bool
+		expectStatus      corev1.ConditionStatus
+		expectRemoteCalls int
+	}{
+		"OnDelete rollout does not read the nodes while waiting for the deletion of old machines": {
+			onDelete:          true,
+			expectStatus:      corev1.ConditionTrue,
+			expectRemoteCalls: 0,
+		},
+		"RollingUpdate rollout reads the nodes only when the deadline is exceeded": {
+			expectStatus:      corev1.ConditionFalse,
+			expectRemoteCalls: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newMS := &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "v2", Namespace: "default"},
+				Spec: clusterv1.MachineSetSpec{
+					ClusterName: "cluster",
+					Selector:    metav1.LabelSelector{MatchLabels: map[string]string{"set": "v2"}},
+				},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "v2-a", Namespace: "default", Labels: map[string]string{"set": "v2"}},
+				Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Kind: "Node", Name: "v2-a"}},
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "v2-a"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+				},
+			}
+			lastProgressTime := metav1.NewTime(time.Now().Add(-time.Hour))
+			deployment := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+				Spec: clusterv1.MachineDeploymentSpec{
+					ProgressDeadlineSeconds: pointer.Int32Ptr(600),
+				},
+				Status: clusterv1.MachineDeploymentStatus{LastProgressTime: &lastProgressTime},
+			}
+			if test.onDelete {
+				deployment.Spec.Strategy = &clusterv1.MachineDeploymentStrategy{Type: clusterv1.OnDeleteMachineDeploymentStrategyType}
+			}
+
+			remoteCalls := 0
+			r := &MachineDeploymentReconciler{
+				Client:   helpers.NewFakeClientWithScheme(scheme.Scheme, deployment, newMS, machine, node),
+				Log:      log.Log,
+				recorder: record.NewFakeRecorder(32),
+				remoteClientGetter: func(ctx context.Context, c client.Client, cluster client.ObjectKey, scheme *runtime.Scheme) (client.Client, error) {
+					remoteCalls++
+					return fakeremote.NewClusterClient(ctx, c, cluster, scheme)
+				},
+			}
+
+			// Reconcile the deployment several times after the deadline.
+			for i := 0; i < 3; i++ {
+				g.Expect(r.syncRolloutProgress(ctx, nil, newMS, deployment)).To(Succeed())
+				g.Expect(conditions.Get(deployment, clusterv1.MachineDeploymentProgressingCondition).Status).To(Equal(test.expectStatus))
+			}
+			g.Expect(remoteCalls).To(Equal(test.expectRemoteCalls))
+		})
+	}
+}
+
+func TestRequeueAfterProgressDeadline(t *testing.T) {
+	now := time.Now()
+	lastProgressTime := metav1.NewTime(now.Add(-4 * time.Minute))
+
+	g := NewWithT(t)
+
+	d := &clusterv1.MachineDeployment{
+		Spec: clusterv1.MachineDeploymentSpec{ProgressDeadlineSeconds: pointer.Int32Ptr(600)},
+	}
+	g.Expect(requeueAfterProgressDeadline(d, now)).To(BeZero())
+
+	d.Status.LastProgressTime = &lastProgressTime
+	g.Expect(requeueAfterProgressDeadline(d, now)).To(Equal(6*time.Minute + time.Second))
+
+	passedProgressTime := metav1.NewTime(now.Add(-11 * time.Minute))
+	d.Status.LastProgressTime = &passedProgressTime
+	g.Expect(requeueAfterProgressDeadline(d, now)).To(BeZero())
+
+	d.Status.LastProgressTime = &lastProgressTime
+
+	conditions.MarkFalse(d, clusterv1.MachineDeploymentProgressingCondition, clusterv1.ProgressDeadlineExceededReason, clusterv1.ConditionSeverityError, "")
+	g.Expect(requeueAfterProgressDeadline(d, now)).To(BeZero())
+}
@@ -310,6 +310,12 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 	switch {
 	case diff < 0:
 		diff *= -1
+		if isMachineCreateDisabled(ms) {
+			// The machine deployment owning this machine set is going to scale it down, replacing the missing
+			// machines with machines of the new machine set.
+			logger.V(4).Info("Too few replicas, but machine creation is disabled", "need", *(ms.Spec.Replicas), "missing", diff)
+			return nil
+		}
 		logger.Info("Too few replicas", "need", *(ms.Spec.Replicas), "creating", diff)
 
 		var (
@@ -384,7 +390,12 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 	case diff > 0:
 		logger.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
 
-		deletePriorityFunc, err := getDeletePriorityFunc(ms)
+		// Nb. The LeastDisruptive delete policy reads the nodes and their pods using an uncached client, so no informer
+		// is started for all the pods in the workload cluster.
+		getRemoteClient := func(ctx context.Context, cluster client.ObjectKey) (client.Client, error) {
+			return remote.NewClusterClient(ctx, r.Client, cluster, r.scheme)
+		}
+		deletePriorityFunc, err := getDeletePriorityFunc(ctx, logger, ms, machines, getRemoteClient)
 		if err != nil {
 			return err
 		}
@@ -17,12 +17,19 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"math"
 	"sort"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type (
@@ -139,7 +146,12 @@ func getMachinesToDeletePrioritized(filteredMachines []*clusterv1.Machine, diff
 	return sortable.machines[:diff]
 }
 
-func getDeletePriorityFunc(ms *clusterv1.MachineSet) (deletePriorityFunc, error) {
+// remoteClientGetter returns a client for the workload cluster with the given key.
+type remoteClientGetter func(ctx context.Context, cluster client.ObjectKey) (client.Client, error)
+
+// getDeletePriorityFunc returns the delete priority function for the MachineSet delete policy; getRemoteClient
+// is used only by the delete policies scoring the given machines using the state of the workload cluster.
+func getDeletePriorityFunc(ctx context.Context, logger logr.Logger, ms *clusterv1.MachineSet, machines []*clusterv1.Machine, getRemoteClient remoteClientGetter) (deletePriorityFunc, error) {
 	// Map the Spec.DeletePolicy value to the appropriate delete priority function
 	switch msdp := clusterv1.MachineSetDeletePolicy(ms.Spec.DeletePolicy); msdp {
 	case clusterv1.RandomMachineSetDeletePolicy:
@@ -148,9 +160,192 @@ func getDeletePriorityFunc(ms *clusterv1.MachineSet) (deletePriorityFunc, error)
 		return newestDeletePriority, nil
 	case clusterv1.OldestMachineSetDeletePolicy:
 		return oldestDeletePriority, nil
+	case clusterv1.LeastDisruptiveMachineSetDeletePolicy:
+		disruptions := newMachineDisruptions(machines)
+		remoteClient, err := getRemoteClient(ctx, client.ObjectKey{Namespace: ms.Namespace, Name: ms.Spec.ClusterName})
+		if err == nil {
+			err = addNodeDisruptions(ctx, remoteClient, disruptions)
+		}
+		if err != nil {
+			// Scaling down must not be blocked by an unreachable workload cluster, so the machines are ranked
+			// using only the state of the machines.
+			logger.Error(err, "Failed to read the nodes from the workload cluster, ranking machines for deletion using only the machine state")
+			disruptions = newMachineDisruptions(machines)
+		}
+		return leastDisruptiveDeletePriority(disruptions), nil
 	case "":
 		return randomDeletePolicy, nil
 	default:
-		return nil, errors.Errorf("Unsupported delete policy %s. Must be one of 'Random', 'Newest', or 'Oldest'", msdp)
+		return nil, errors.Errorf("Unsupported delete policy %s. Must be one of 'Random', 'Newest', 'Oldest' or 'LeastDisruptive'", msdp)
+	}
+}
+
+// nodeDisruption describes how disruptive the deletion of a machine is for the workload cluster.
+type nodeDisruption struct {
+	machine *clusterv1.Machine
+	// unhealthy is true when the node is missing, NotReady or has a pressure condition, or the machine failed a health check.
+	unhealthy bool
+	// cordoned is true when the node is unschedulable.
+	cordoned bool
+	// pods is the number of pods running on the node, excluding the ones owned by a DaemonSet.
+	pods int
+	// failureDomain is the failure domain of the machine.
+	failureDomain string
+	// failureDomainMachines is the number of machines in the failure domain of the machine not yet picked for deletion.
+	failureDomainMachines int
+}
+
+// lessDisruptive returns true if deleting the machine of a is less disruptive than deleting the machine of b.
+func (a *nodeDisruption) lessDisruptive(b *nodeDisruption) bool {
+	if a.unhealthy != b.unhealthy {
+		return a.unhealthy
+	}
+	if a.cordoned != b.cordoned {
+		return a.cordoned
+	}
+	if a.pods != b.pods {
+		return a.pods < b.pods
+	}
+	if a.failureDomainMachines != b.failureDomainMachines {
+		return a.failureDomainMachines > b.failureDomainMachines
+	}
+	return a.machine.Name < b.machine.Name
+}
+
+// newMachineDisruptions returns the disruptions for the given machines, scored using only the state of the machines:
+// the failure domain and the machine health check result.
+func newMachineDisruptions(machines []*clusterv1.Machine) []*nodeDisruption {
+	disruptions := make([]*nodeDisruption, 0, len(machines))
+	for _, m := range machines {
+		disruption := &nodeDisruption{
+			machine:   m,
+			unhealthy: conditions.IsFalse(m, clusterv1.MachineHealthCheckSuccededCondition),
+		}
+		if m.Spec.FailureDomain != nil {
+			disruption.failureDomain = *m.Spec.FailureDomain
+		}
+		disruptions = append(disruptions, disruption)
+	}
+	return disruptions
+}
+
+// addNodeDisruptions scores the disruptions using the state of the nodes of the machines in the workload cluster.
+// Pods are listed node by node, so remoteClient is expected to be an uncached client in order to avoid starting
+// an informer for all the pods in the workload cluster.
+func addNodeDisruptions(ctx context.Context, remoteClient client.Client, disruptions []*nodeDisruption) error {
+	for _, disruption := range disruptions {
+		m := disruption.machine
+		if m.Status.NodeRef == nil {
+			continue
+		}
+
+		node := &corev1.Node{}
+		if err := remoteClient.Get(ctx, client.ObjectKey{Name: m.Status.NodeRef.Name}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				disruption.unhealthy = true
+				continue
+			}
+			return errors.Wrapf(err, "failed to get node %s for machine %s/%s", m.Status.NodeRef.Name, m.Namespace, m.Name)
+		}
+		disruption.unhealthy = disruption.unhealthy || !isNodeHealthy(node)
+		disruption.cordoned = node.Spec.Unschedulable
+
+		pods := &corev1.PodList{}
+		if err := remoteClient.List(ctx, pods, client.MatchingFields{"spec.nodeName": node.Name}); err != nil {
+			return errors.Wrapf(err, "failed to list pods on node %s for machine %s/%s", node.Name, m.Namespace, m.Name)
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Spec.NodeName != node.Name || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil && controllerRef.Kind == "DaemonSet" {
+				continue
+			}
+			disruption.pods++
+		}
+	}
+	return nil
+}
+
+// leastDisruptiveDeletePriority returns a delete priority function that ranks the machines from the least
+// to the most disruptive to delete.
+func leastDisruptiveDeletePriority(disruptions []*nodeDisruption) deletePriorityFunc {
+	// Rank the machines picking them one at a time, starting from the ones that must be deleted, and decrementing
+	// the machines count of the failure domain of each picked machine, so the machines left are spread across
+	// failure domains.
+	machinesPerFailureDomain := map[string]int{}
+	for _, disruption := range disruptions {
+		machinesPerFailureDomain[disruption.failureDomain]++
+	}
+	remaining := append([]*nodeDisruption{}, disruptions...)
+	ranked := make([]*nodeDisruption, 0, len(disruptions))
+	for len(remaining) > 0 {
+		for _, disruption := range remaining {
+			disruption.failureDomainMachines = machinesPerFailureDomain[disruption.failureDomain]
+		}
+		next := 0
+		for i := 1; i < len(remaining); i++ {
+			a, b := remaining[i], remaining[next]
+			if mustDeleteMachine(a.machine) != mustDeleteMachine(b.machine) {
+				if mustDeleteMachine(a.machine) {
+					next = i
+				}
+				continue
+			}
+			if a.lessDisruptive(b) {
+				next = i
+			}
+		}
+		ranked = append(ranked, remaining[next])
+		machinesPerFailureDomain[remaining[next].failureDomain]--
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+
+	// Map the ranking onto the priority range between mustNotDelete and mustDelete, both excluded.
+	priorities := make(map[*clusterv1.Machine]deletePriority, len(ranked))
+	for i, disruption := range ranked {
+		priorities[disruption.machine] = deletePriority(float64(mustDelete) * float64(len(ranked)-i) / float64(len(ranked)+1))
+	}
+
+	return func(machine *clusterv1.Machine) deletePriority {
+		if mustDeleteMachine(machine) {
+			return mustDelete
+		}
+		return priorities[machine]
+	}
+}
+
+// mustDeleteMachine returns true if the machine must be deleted before any other machine, because it is
+// already being deleted, it is marked for deletion, it has no node or it failed.
+func mustDeleteMachine(machine *clusterv1.Machine) bool {
+	if !machine.DeletionTimestamp.IsZero() {
+		return true
+	}
+	if machine.ObjectMeta.Annotations != nil && machine.ObjectMeta.Annotations[DeleteNodeAnnotation] != "" {
+		return true
+	}
+	if _, ok := machine.ObjectMeta.Annotations[DeleteMachineAnnotation]; ok {
+		return true
+	}
+	if machine.Status.NodeRef == nil {
+		return true
+	}
+	return machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil
+}
+
+// isNodeHealthy returns true if the node is Ready and has none of the pressure or network unavailable conditions.
+func isNodeHealthy(node *corev1.Node) bool {
+	if !noderefutil.IsNodeReady(node) {
+		return false
+	}
+	for _, c := range node.Status.Conditions {
+		switch c.Type {
+		case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure, corev1.NodeNetworkUnavailable:
+			if c.Status == corev1.ConditionTrue {
+				return false
+			}
+		}
 	}
+	return true
 }
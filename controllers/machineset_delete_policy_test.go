@@ -17,14 +17,24 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/test/helpers"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func TestMachineToDelete(t *testing.T) {
@@ -436,3 +446,314 @@ func TestMachineOldestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineLeastDisruptiveDelete(t *testing.T) {
+	newMachine := func(name string, failureDomain *string, withNode bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       clusterv1.MachineSpec{FailureDomain: failureDomain},
+		}
+		if withNode {
+			m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: name}
+		}
+		return m
+	}
+	newNode := func(name string, ready, unschedulable bool) *corev1.Node {
+		status := corev1.ConditionTrue
+		if !ready {
+			status = corev1.ConditionFalse
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			},
+		}
+	}
+	newPods := func(node string, count int, daemonSet bool, phase corev1.PodPhase) []runtime.Object {
+		pods := []runtime.Object{}
+		for i := 0; i < count; i++ {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%t-%s-%d", node, daemonSet, phase, i), Namespace: "default"},
+				Spec:       corev1.PodSpec{NodeName: node},
+				Status:     corev1.PodStatus{Phase: phase},
+			}
+			if daemonSet {
+				pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "ds", Controller: pointer.BoolPtr(true)}}
+			}
+			pods = append(pods, pod)
+		}
+		return pods
+	}
+
+	annotated := newMachine("annotated", nil, true)
+	annotated.Annotations = map[string]string{DeleteMachineAnnotation: ""}
+	nodeMissing := newMachine("node-missing", nil, true)
+	nodeNotReady := newMachine("node-not-ready", nil, true)
+	healthCheckFailed := newMachine("health-check-failed", nil, true)
+	conditions.MarkFalse(healthCheckFailed, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeStartupTimeout, clusterv1.ConditionSeverityWarning, "")
+	cordoned := newMachine("cordoned", nil, true)
+	idleA := newMachine("idle-a", pointer.StringPtr("a"), true)
+	busyA := newMachine("busy-a", pointer.StringPtr("a"), true)
+	idleB := newMachine("idle-b", pointer.StringPtr("b"), true)
+	daemonSetsOnly := newMachine("daemonsets-only", nil, true)
+	withoutNode := newMachine("without-node", nil, false)
+
+	objs := []runtime.Object{
+		newNode("annotated", true, false),
+		newNode("node-not-ready", false, false),
+		newNode("health-check-failed", true, false),
+		newNode("cordoned", true, true),
+		newNode("idle-a", true, false),
+		newNode("busy-a", true, false),
+		newNode("idle-b", true, false),
+		newNode("daemonsets-only", true, false),
+	}
+	objs = append(objs, newPods("node-not-ready", 1, false, corev1.PodRunning)...)
+	objs = append(objs, newPods("health-check-failed", 2, false, corev1.PodRunning)...)
+	objs = append(objs, newPods("cordoned", 10, false, corev1.PodRunning)...)
+	objs = append(objs, newPods("busy-a", 5, false, corev1.PodRunning)...)
+	objs = append(objs, newPods("daemonsets-only", 3, true, corev1.PodRunning)...)
+	objs = append(objs, newPods("daemonsets-only", 1, false, corev1.PodRunning)...)
+	objs = append(objs, newPods("idle-b", 4, false, corev1.PodSucceeded)...)
+	remoteClient := helpers.NewFakeClientWithScheme(scheme.Scheme, objs...)
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: clusterv1.MachineSetSpec{
+			ClusterName:  "test-cluster",
+			DeletePolicy: string(clusterv1.LeastDisruptiveMachineSetDeletePolicy),
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		machines []*clusterv1.Machine
+		diff     int
+		expect   []*clusterv1.Machine
+	}{
+		{
+			desc: "func=leastDisruptiveDeletePriority, diff=1",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				busyA, idleA, cordoned, nodeNotReady,
+			},
+			expect: []*clusterv1.Machine{nodeNotReady},
+		},
+		{
+			desc: "func=leastDisruptiveDeletePriority, diff=1 (DeleteMachineAnnotation)",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				busyA, idleA, nodeMissing, annotated,
+			},
+			expect: []*clusterv1.Machine{annotated},
+		},
+		{
+			desc: "func=leastDisruptiveDeletePriority, diff=1 (deleteMachineWithoutNodeRef)",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				busyA, idleA, nodeMissing, withoutNode,
+			},
+			expect: []*clusterv1.Machine{withoutNode},
+		},
+		{
+			desc: "func=leastDisruptiveDeletePriority, diff=2 (cordoned)",
+			diff: 2,
+			machines: []*clusterv1.Machine{
+				busyA, idleA, cordoned, idleB,
+			},
+			expect: []*clusterv1.Machine{cordoned, idleA},
+		},
+		{
+			desc: "func=leastDisruptiveDeletePriority, diff=8",
+			diff: 8,
+			machines: []*clusterv1.Machine{
+				busyA, daemonSetsOnly, idleB, idleA, cordoned, healthCheckFailed, nodeNotReady, nodeMissing, annotated,
+			},
+			expect: []*clusterv1.Machine{annotated, nodeMissing, nodeNotReady, healthCheckFailed, cordoned, idleA, idleB, daemonSetsOnly},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			getRemoteClient := func(_ context.Context, cluster client.ObjectKey) (client.Client, error) {
+				g.Expect(cluster).To(Equal(client.ObjectKey{Namespace: "default", Name: "test-cluster"}))
+				return remoteClient, nil
+			}
+			priorityFunc, err := getDeletePriorityFunc(ctx, log.Log, ms, test.machines, getRemoteClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			result := getMachinesToDeletePrioritized(test.machines, test.diff, priorityFunc)
+			g.Expect(result).To(Equal(test.expect))
+		})
+	}
+}
+
+func TestGetDeletePriorityFunc(t *testing.T) {
+	failingRemoteClient := func(context.Context, client.ObjectKey) (client.Client, error) {
+		return nil, errors.New("cluster not reachable")
+	}
+
+	tests := []struct {
+		policy  clusterv1.MachineSetDeletePolicy
+		wantErr bool
+	}{
+		{policy: "", wantErr: false},
+		{policy: clusterv1.RandomMachineSetDeletePolicy, wantErr: false},
+		{policy: clusterv1.NewestMachineSetDeletePolicy, wantErr: false},
+		{policy: clusterv1.OldestMachineSetDeletePolicy, wantErr: false},
+		{policy: clusterv1.LeastDisruptiveMachineSetDeletePolicy, wantErr: false},
+		{policy: "Unknown", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.policy), func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := &clusterv1.MachineSet{Spec: clusterv1.MachineSetSpec{DeletePolicy: string(test.policy)}}
+			priorityFunc, err := getDeletePriorityFunc(ctx, log.Log, ms, nil, failingRemoteClient)
+			if test.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(priorityFunc).NotTo(BeNil())
+		})
+	}
+}
+
+// failingListClient is a client failing to list objects.
+type failingListClient struct {
+	client.Client
+}
+
+func (c failingListClient) List(context.Context, runtime.Object, ...client.ListOption) error {
+	return errors.New("failed to list")
+}
+
+func TestMachineLeastDisruptiveDeleteWithoutWorkloadCluster(t *testing.T) {
+	newMachine := func(name string, failureDomain string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       clusterv1.MachineSpec{FailureDomain: pointer.StringPtr(failureDomain)},
+			Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Kind: "Node", Name: name}},
+		}
+	}
+	// The machines are ranked using only the machine health check result and the failure domain.
+	healthCheckFailed := newMachine("health-check-failed", "a")
+	conditions.MarkFalse(healthCheckFailed, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeStartupTimeout, clusterv1.ConditionSeverityWarning, "")
+	crowdedA1 := newMachine("crowded-a1", "a")
+	crowdedA2 := newMachine("crowded-a2", "a")
+	aloneB := newMachine("alone-b", "b")
+	machines := []*clusterv1.Machine{aloneB, crowdedA2, crowdedA1, healthCheckFailed}
+	expect := []*clusterv1.Machine{healthCheckFailed, crowdedA1}
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: clusterv1.MachineSetSpec{
+			ClusterName:  "test-cluster",
+			DeletePolicy: string(clusterv1.LeastDisruptiveMachineSetDeletePolicy),
+		},
+	}
+
+	// The node of the machine in the failure domain b has no pods, and it would be deleted first if the pods could be listed.
+	nodes := []runtime.Object{}
+	for _, m := range machines {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: m.Name},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+		})
+	}
+
+	tests := []struct {
+		desc            string
+		getRemoteClient remoteClientGetter
+	}{
+		{
+			desc: "failing to get a client for the workload cluster",
+			getRemoteClient: func(context.Context, client.ObjectKey) (client.Client, error) {
+				return nil, errors.New("cluster not reachable")
+			},
+		},
+		{
+			desc: "failing to list pods in the workload cluster",
+			getRemoteClient: func(context.Context, client.ObjectKey) (client.Client, error) {
+				return failingListClient{Client: helpers.NewFakeClientWithScheme(scheme.Scheme, nodes...)}, nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			priorityFunc, err := getDeletePriorityFunc(ctx, log.Log, ms, machines, test.getRemoteClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			result := getMachinesToDeletePrioritized(machines, 2, priorityFunc)
+			g.Expect(result).To(Equal(expect))
+		})
+	}
+}
+
+func TestMachineLeastDisruptiveDeleteFailureDomains(t *testing.T) {
+	newMachine := func(name string, failureDomain string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       clusterv1.MachineSpec{FailureDomain: pointer.StringPtr(failureDomain)},
+			Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Kind: "Node", Name: name}},
+		}
+	}
+	a1 := newMachine("a1", "a")
+	a2 := newMachine("a2", "a")
+	a3 := newMachine("a3", "a")
+	b1 := newMachine("b1", "b")
+	b2 := newMachine("b2", "b")
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: clusterv1.MachineSetSpec{
+			ClusterName:  "test-cluster",
+			DeletePolicy: string(clusterv1.LeastDisruptiveMachineSetDeletePolicy),
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		machines []*clusterv1.Machine
+		diff     int
+		expect   []*clusterv1.Machine
+	}{
+		{
+			desc:     "diff=2 from a 3/1 split",
+			machines: []*clusterv1.Machine{b1, a3, a2, a1},
+			diff:     2,
+			expect:   []*clusterv1.Machine{a1, a2},
+		},
+		{
+			desc:     "diff=3 from a 3/2 split",
+			machines: []*clusterv1.Machine{b2, b1, a3, a2, a1},
+			diff:     3,
+			expect:   []*clusterv1.Machine{a1, a2, b1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// The machines are ranked using only the state of the machines.
+			getRemoteClient := func(context.Context, client.ObjectKey) (client.Client, error) {
+				return nil, errors.New("cluster not reachable")
+			}
+			priorityFunc, err := getDeletePriorityFunc(ctx, log.Log, ms, test.machines, getRemoteClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			result := getMachinesToDeletePrioritized(test.machines, test.diff, priorityFunc)
+			g.Expect(result).To(Equal(test.expect))
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-logr/logr"
@@ -494,6 +495,17 @@ func GetAvailableReplicaCountForMachineSets(machineSets []*clusterv1.MachineSet)
 	return totalAvailableReplicas
 }
 
+// GetDeletingMachineCount returns the number of machines in the list being deleted.
+func GetDeletingMachineCount(machineList *clusterv1.MachineList) int32 {
+	count := int32(0)
+	for _, machine := range machineList.Items {
+		if !machine.ObjectMeta.DeletionTimestamp.IsZero() {
+			count++
+		}
+	}
+	return count
+}
+
 // IsRollingUpdate returns true if the strategy type is a rolling update.
 func IsRollingUpdate(deployment *clusterv1.MachineDeployment) bool {
 	return deployment.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType
@@ -508,6 +520,33 @@ func DeploymentComplete(deployment *clusterv1.MachineDeployment, newStatus *clus
 		newStatus.ObservedGeneration >= deployment.Generation
 }
 
+// DeploymentProgressing reports progress for a deployment. Progress is estimated by comparing the
+// current with the new status of the deployment that the controller is observing. More specifically,
+// when new machines are scaled up or become ready or available, or old machines are scaled down, then
+// we consider the deployment is progressing.
+func DeploymentProgressing(deployment *clusterv1.MachineDeployment, newStatus *clusterv1.MachineDeploymentStatus) bool {
+	oldStatus := deployment.Status
+
+	// Old replicas that need to be scaled down
+	oldStatusOldReplicas := oldStatus.Replicas - oldStatus.UpdatedReplicas
+	newStatusOldReplicas := newStatus.Replicas - newStatus.UpdatedReplicas
+
+	return newStatus.UpdatedReplicas > oldStatus.UpdatedReplicas ||
+		newStatusOldReplicas < oldStatusOldReplicas ||
+		newStatus.ReadyReplicas > oldStatus.ReadyReplicas ||
+		newStatus.AvailableReplicas > oldStatus.AvailableReplicas
+}
+
+// DeploymentTimedOut considers a deployment to have timed out once its rollout did not make any progress
+// for longer than its progressDeadlineSeconds.
+func DeploymentTimedOut(deployment *clusterv1.MachineDeployment, newStatus *clusterv1.MachineDeploymentStatus, now time.Time) bool {
+	if deployment.Spec.ProgressDeadlineSeconds == nil || newStatus.LastProgressTime == nil {
+		return false
+	}
+	deadline := newStatus.LastProgressTime.Add(time.Duration(*deployment.Spec.ProgressDeadlineSeconds) * time.Second)
+	return !deadline.After(now)
+}
+
 // NewMSNewReplicas calculates the number of replicas a deployment's new MS should have.
 // When one of the following is true, we're rolling out the deployment; otherwise, we're scaling it.
 // 1) The new MS is saturated: newMS's replicas == deployment's replicas
@@ -532,6 +571,17 @@ func NewMSNewReplicas(deployment *clusterv1.MachineDeployment, allMSs []*cluster
 		// Do not exceed the number of desired replicas.
 		scaleUpCount = integer.Int32Min(scaleUpCount, *(deployment.Spec.Replicas)-*(newMS.Spec.Replicas))
 		return *(newMS.Spec.Replicas) + scaleUpCount, nil
+	case clusterv1.OnDeleteMachineDeploymentStrategyType:
+		// Find the total number of machines
+		currentMachineCount := GetReplicaCountForMachineSets(allMSs)
+		if currentMachineCount >= *(deployment.Spec.Replicas) {
+			// Cannot scale up as more replicas exist than desired number of replicas in the deployment.
+			return *(newMS.Spec.Replicas), nil
+		}
+		// Scale up the new machine set so the total number of replicas across all the machine sets
+		// matches the desired number of replicas in the deployment.
+		scaleUpCount := *(deployment.Spec.Replicas) - currentMachineCount
+		return *(newMS.Spec.Replicas) + scaleUpCount, nil
 	default:
 		// Check if we can scale up.
 		maxSurge, err := intstrutil.GetValueFromIntOrPercent(deployment.Spec.Strategy.RollingUpdate.MaxSurge, int(*(deployment.Spec.Replicas)), true)
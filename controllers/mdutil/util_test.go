@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
 
@@ -524,6 +525,16 @@ func TestNewMSNewReplicas(t *testing.T) {
 			clusterv1.RollingUpdateMachineDeploymentStrategyType,
 			6, 2, 10, 6,
 		},
+		{
+			"on delete can not scale up - to newMSReplicas",
+			clusterv1.OnDeleteMachineDeploymentStrategyType,
+			4, 0, 1, 0,
+		},
+		{
+			"on delete scale up - to replace the deleted machines only",
+			clusterv1.OnDeleteMachineDeploymentStrategyType,
+			8, 1, 10, 4,
+		},
 	}
 	newDeployment := generateDeployment("nginx")
 	newRC := generateMS(newDeployment)
@@ -631,6 +642,147 @@ func TestDeploymentComplete(t *testing.T) {
 	}
 }
 
+func TestDeploymentProgressing(t *testing.T) {
+	deployment := func(current, updated, ready, available int32) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			Status: clusterv1.MachineDeploymentStatus{
+				Replicas:          current,
+				UpdatedReplicas:   updated,
+				ReadyReplicas:     ready,
+				AvailableReplicas: available,
+			},
+		}
+	}
+	newStatus := func(current, updated, ready, available int32) clusterv1.MachineDeploymentStatus {
+		return clusterv1.MachineDeploymentStatus{
+			Replicas:          current,
+			UpdatedReplicas:   updated,
+			ReadyReplicas:     ready,
+			AvailableReplicas: available,
+		}
+	}
+
+	tests := []struct {
+		name string
+
+		d         *clusterv1.MachineDeployment
+		newStatus clusterv1.MachineDeploymentStatus
+
+		expected bool
+	}{
+		{
+			name: "progressing: updated machines",
+
+			d:         deployment(10, 4, 4, 4),
+			newStatus: newStatus(10, 5, 4, 4),
+			expected:  true,
+		},
+		{
+			name: "progressing: ready machines",
+
+			d:         deployment(10, 10, 4, 4),
+			newStatus: newStatus(10, 10, 5, 4),
+			expected:  true,
+		},
+		{
+			name: "progressing: available machines",
+
+			d:         deployment(10, 10, 10, 4),
+			newStatus: newStatus(10, 10, 10, 5),
+			expected:  true,
+		},
+		{
+			name: "progressing: old machines scaled down",
+
+			d:         deployment(10, 5, 5, 5),
+			newStatus: newStatus(9, 5, 5, 5),
+			expected:  true,
+		},
+		{
+			name: "not progressing",
+
+			d:         deployment(10, 5, 5, 5),
+			newStatus: newStatus(10, 5, 5, 5),
+			expected:  false,
+		},
+		{
+			name: "not progressing: machines became unavailable",
+
+			d:         deployment(10, 5, 5, 5),
+			newStatus: newStatus(10, 5, 4, 4),
+			expected:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(DeploymentProgressing(test.d, &test.newStatus)).To(Equal(test.expected))
+		})
+	}
+}
+
+func TestDeploymentTimedOut(t *testing.T) {
+	now := time.Now()
+	deployment := func(progressDeadlineSeconds *int32, lastProgressTime *time.Time) *clusterv1.MachineDeployment {
+		d := &clusterv1.MachineDeployment{
+			Spec: clusterv1.MachineDeploymentSpec{
+				ProgressDeadlineSeconds: progressDeadlineSeconds,
+			},
+		}
+		if lastProgressTime != nil {
+			d.Status.LastProgressTime = &metav1.Time{Time: *lastProgressTime}
+		}
+		return d
+	}
+	ago := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+
+	tests := []struct {
+		name string
+
+		d *clusterv1.MachineDeployment
+
+		expected bool
+	}{
+		{
+			name: "no progress deadline",
+
+			d:        deployment(nil, ago(time.Hour)),
+			expected: false,
+		},
+		{
+			name: "progress not tracked",
+
+			d:        deployment(pointer.Int32Ptr(600), nil),
+			expected: false,
+		},
+		{
+			name: "within the deadline",
+
+			d:        deployment(pointer.Int32Ptr(600), ago(5*time.Minute)),
+			expected: false,
+		},
+		{
+			name: "deadline exceeded",
+
+			d:        deployment(pointer.Int32Ptr(600), ago(11*time.Minute)),
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(DeploymentTimedOut(test.d, &test.d.Status, now)).To(Equal(test.expected))
+		})
+	}
+}
+
 func TestMaxUnavailable(t *testing.T) {
 	deployment := func(replicas int32, maxUnavailable intstr.IntOrString) clusterv1.MachineDeployment {
 		return clusterv1.MachineDeployment{
@@ -164,7 +164,8 @@ func Move(ctx context.Context, input MoveInput) {
 		Namespace:      input.Namespace,
 	}
 
-	Expect(clusterctlClient.Move(options)).To(Succeed(), "Failed to run clusterctl move")
+	_, err := clusterctlClient.Move(options)
+	Expect(err).ToNot(HaveOccurred(), "Failed to run clusterctl move")
 }
 
 func getClusterctlClientWithLogger(configPath, logName, logFolder string) (clusterctlclient.Client, *logger.LogFile) {
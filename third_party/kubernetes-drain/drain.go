@@ -63,6 +63,16 @@ type Helper struct {
 	// won't drain otherwise
 	SkipWaitForDeleteTimeoutSeconds int
 
+	// SkipPodSelectors excludes from the drain the pods matching any of the
+	// selectors, leaving them running on the node.
+	// NOTE: this is not part of the upstream kubectl drain package.
+	SkipPodSelectors []labels.Selector
+
+	// SkipLocalData leaves the pods with local storage running on the node
+	// instead of failing the drain when DeleteLocalData is false.
+	// NOTE: this is not part of the upstream kubectl drain package.
+	SkipLocalData bool
+
 	Out    io.Writer
 	ErrOut io.Writer
 
@@ -25,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -139,6 +140,7 @@ func makePodDeleteStatusWithError(message string) podDeleteStatus {
 func (d *Helper) makeFilters() []podFilter {
 	return []podFilter{
 		d.skipDeletedFilter,
+		d.skipPodSelectorsFilter,
 		d.daemonSetFilter,
 		d.mirrorPodFilter,
 		d.localStorageFilter,
@@ -204,6 +206,10 @@ func (d *Helper) localStorageFilter(pod corev1.Pod) podDeleteStatus {
 		return makePodDeleteStatusOkay()
 	}
 	if !d.DeleteLocalData {
+		// SkipLocalData is not part of the upstream kubectl drain package.
+		if d.SkipLocalData {
+			return makePodDeleteStatusSkip()
+		}
 		return makePodDeleteStatusWithError(localStorageFatal)
 	}
 
@@ -241,3 +247,13 @@ func (d *Helper) skipDeletedFilter(pod corev1.Pod) podDeleteStatus {
 	}
 	return makePodDeleteStatusOkay()
 }
+
+// skipPodSelectorsFilter is not part of the upstream kubectl drain package.
+func (d *Helper) skipPodSelectorsFilter(pod corev1.Pod) podDeleteStatus {
+	for _, selector := range d.SkipPodSelectors {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return makePodDeleteStatusSkip()
+		}
+	}
+	return makePodDeleteStatusOkay()
+}
@@ -49,13 +49,26 @@ func FromSecret(ctx context.Context, c client.Reader, cluster client.ObjectKey)
 	return toKubeconfigBytes(out)
 }
 
+// FromCA generates a Kubeconfig for a Cluster with a new client certificate signed by the cluster CA
+// and valid for the given duration.
+func FromCA(ctx context.Context, c client.Reader, cluster client.ObjectKey, endpoint string, certDuration time.Duration) ([]byte, error) {
+	return generateKubeconfigWithCertDuration(ctx, c, cluster, endpoint, certDuration)
+}
+
 // New creates a new Kubeconfig using the cluster name and specified endpoint.
 func New(clusterName, endpoint string, caCert *x509.Certificate, caKey crypto.Signer) (*api.Config, error) {
+	return NewWithCertDuration(clusterName, endpoint, caCert, caKey, certs.DefaultCertDuration)
+}
+
+// NewWithCertDuration creates a new Kubeconfig using the cluster name and specified endpoint,
+// with a client certificate valid for the given duration.
+func NewWithCertDuration(clusterName, endpoint string, caCert *x509.Certificate, caKey crypto.Signer, certDuration time.Duration) (*api.Config, error) {
 
 	cfg := &certs.Config{
 		CommonName:   "kubernetes-admin",
 		Organization: []string{"system:masters"},
 		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Duration:     certDuration,
 	}
 
 	clientKey, err := certs.NewPrivateKey()
@@ -199,6 +212,10 @@ func RegenerateSecret(ctx context.Context, c client.Client, configSecret *corev1
 }
 
 func generateKubeconfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string) ([]byte, error) {
+	return generateKubeconfigWithCertDuration(ctx, c, clusterName, endpoint, certs.DefaultCertDuration)
+}
+
+func generateKubeconfigWithCertDuration(ctx context.Context, c client.Reader, clusterName client.ObjectKey, endpoint string, certDuration time.Duration) ([]byte, error) {
 	clusterCA, err := secret.GetFromNamespacedName(ctx, c, clusterName, secret.ClusterCA)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -221,7 +238,7 @@ func generateKubeconfig(ctx context.Context, c client.Client, clusterName client
 		return nil, errors.New("CA private key not found")
 	}
 
-	cfg, err := New(clusterName.Name, endpoint, cert, key)
+	cfg, err := NewWithCertDuration(clusterName.Name, endpoint, cert, key, certDuration)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate a kubeconfig")
 	}
@@ -421,3 +421,41 @@ func TestRegenerateClientCerts(t *testing.T) {
 
 	g.Expect(newCert.NotAfter).To(BeTemporally(">", oldCert.NotAfter))
 }
+
+func TestFromCA(t *testing.T) {
+	g := NewWithT(t)
+
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test1-ca",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			secret.TLSKeyDataName: certs.EncodePrivateKeyPEM(caKey),
+			secret.TLSCrtDataName: certs.EncodeCertPEM(caCert),
+		},
+	}
+
+	c := fake.NewFakeClientWithScheme(setupScheme(), caSecret)
+
+	out, err := FromCA(context.Background(), c, client.ObjectKey{Name: "test1", Namespace: "test"}, "https://localhost:6443", time.Hour)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	config, err := clientcmd.Load(out)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Clusters["test1"].Server).To(Equal("https://localhost:6443"))
+
+	cert, err := certs.DecodeCertPEM(config.AuthInfos["test1-admin"].ClientCertificateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+	// Fails if the cluster CA does not exist.
+	_, err = FromCA(context.Background(), c, client.ObjectKey{Name: "test2", Namespace: "test"}, "https://localhost:6443", time.Hour)
+	g.Expect(err).To(MatchError(ErrDependentCertificateNotFound))
+}